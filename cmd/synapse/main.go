@@ -21,14 +21,38 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
+	"os"
 	"os/signal"
 	"syscall"
 
+	"github.com/apache/synapse-go/internal/app/cli"
 	"github.com/apache/synapse-go/internal/app/synapse"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "validate":
+			os.Exit(cli.Validate(os.Args[2:], os.Stdout, os.Stderr))
+		case "vault":
+			os.Exit(cli.Vault(os.Args[2:], os.Stdin, os.Stdout, os.Stderr))
+		case "ctl":
+			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			code := cli.Ctl(ctx, os.Args[2:], os.Stdout, os.Stderr)
+			stop()
+			os.Exit(code)
+		}
+	}
+	opts, err := cli.ServerOptions(os.Args[1:], os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(cli.ExitOK)
+	}
+	if err != nil {
+		os.Exit(cli.ExitUsage)
+	}
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
-	synapse.Run(ctx)
+	synapse.Run(ctx, opts)
 }
@@ -30,10 +30,19 @@ import (
 	"github.com/apache/synapse-go/internal/app/core/domain"
 	"github.com/apache/synapse-go/internal/app/core/ports"
 	"github.com/apache/synapse-go/internal/pkg/core/synctx"
+	"github.com/apache/synapse-go/internal/pkg/loggerfactory"
+	"github.com/apache/synapse-go/internal/pkg/metrics"
+	"github.com/apache/synapse-go/internal/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 )
 
+const (
+	componentName = "inbound.file"
+)
+
 // FileInboundEndpoint handles file-based inbound operations
 type FileInboundEndpoint struct {
 	config          domain.InboundConfig
@@ -42,6 +51,9 @@ type FileInboundEndpoint struct {
 	mediator        ports.InboundMessageMediator
 	processingFiles sync.Map
 	protocolHandler ProtocolHandler
+	logger          *slog.Logger
+	// started is closed once the endpoint polls
+	started chan struct{}
 }
 
 // NewFileInboundEndpoint creates a new FileInboundEndpoint instance
@@ -49,11 +61,18 @@ func NewFileInboundEndpoint(
 	config domain.InboundConfig,
 	mediator ports.InboundMessageMediator,
 ) *FileInboundEndpoint {
-	return &FileInboundEndpoint{
+	f := &FileInboundEndpoint{
 		config:   config,
 		clock:    NewFileClock(),
 		mediator: mediator,
+		started:  make(chan struct{}),
 	}
+	f.logger = loggerfactory.GetArtifactLogger(componentName, "inbound", config.Name, f)
+	return f
+}
+
+func (f *FileInboundEndpoint) UpdateLogger() {
+	f.logger = loggerfactory.GetArtifactLogger(componentName, "inbound", f.config.Name, f)
 }
 
 func (f *FileInboundEndpoint) Start(ctx context.Context, mediator ports.InboundMessageMediator) error {
@@ -67,7 +86,7 @@ func (f *FileInboundEndpoint) Start(ctx context.Context, mediator ports.InboundM
 	}
 
 	if err := f.validateConfig(); err != nil {
-		slog.Error("invalid configuration", "error", err)
+		f.logger.Error("invalid configuration", "error", err)
 		return fmt.Errorf("configuration validation failed: %w", err)
 	}
 
@@ -75,25 +94,31 @@ func (f *FileInboundEndpoint) Start(ctx context.Context, mediator ports.InboundM
 	vfsFactory := &VFSProtocolHandlerFactory{}
 	handler, err := vfsFactory.CreateHandler(f.config)
 	if err != nil {
-		slog.Error("failed to create protocol handler", "error", err)
+		f.logger.Error("failed to create protocol handler", "error", err)
 		return fmt.Errorf("failed to create protocol handler: %w", err)
 	}
 	f.protocolHandler = handler
 
-	slog.Info("starting file inbound endpoint")
+	f.logger.Info("starting file inbound endpoint")
+	close(f.started)
 
 	// Start polling
 	err = f.poll(ctx)
 
 	// When context is cancelled, wait for all processing to complete
-	slog.Info("waiting for in-progress file operations to complete")
+	f.logger.Info("waiting for in-progress file operations to complete")
 
 	return err
 }
 
+// Started is closed once the endpoint polls its folder
+func (f *FileInboundEndpoint) Started() <-chan struct{} {
+	return f.started
+}
+
 // Call this using a channel
 func (f *FileInboundEndpoint) Stop(ctx context.Context) error {
-	slog.Info("stopping file inbound endpoint")
+	f.logger.Info("stopping file inbound endpoint")
 	f.isRunning = false
 	return nil
 }
@@ -101,7 +126,7 @@ func (f *FileInboundEndpoint) Stop(ctx context.Context) error {
 func (f *FileInboundEndpoint) poll(ctx context.Context) error {
 	interval, err := strconv.Atoi(f.config.Parameters["interval"])
 	if err != nil {
-		slog.Error("invalid interval value", "error", err)
+		f.logger.Error("invalid interval value", "error", err)
 		return fmt.Errorf("invalid interval value: %w", err)
 	}
 	ticker := f.clock.NewTicker(time.Duration(interval) * time.Millisecond)
@@ -112,7 +137,7 @@ func (f *FileInboundEndpoint) poll(ctx context.Context) error {
 	for {
 		select {
 		case <-ctx.Done():
-			slog.Info("received shutdown signal, stopping file polling")
+			f.logger.Info("received shutdown signal, stopping file polling")
 			// Wait for all processing to complete before returning
 			processingWg.Wait()
 			return ctx.Err()
@@ -123,7 +148,7 @@ func (f *FileInboundEndpoint) poll(ctx context.Context) error {
 				defer processingWg.Done()
 				if err := f.processingCycle(ctx); err != nil {
 					if err != context.Canceled {
-						slog.Error("error in processing cycle", "error", err)
+						f.logger.Error("error in processing cycle", "error", err)
 					}
 				}
 			}()
@@ -163,27 +188,27 @@ func (f *FileInboundEndpoint) processFiles(ctx context.Context, files []string)
 	for _, file := range files {
 		select {
 		case <-ctx.Done():
-			slog.Info("cancelling remaining file processing")
+			f.logger.Info("cancelling remaining file processing")
 			// Wait for all processing to complete before returning
 			fileWg.Wait()
 			return ctx.Err()
 		default:
 			// Check if file is already being processed
 			if _, exists := f.processingFiles.LoadOrStore(file, true); exists {
-				slog.Debug("skipping file - already being processed", "file", file)
+				f.logger.Debug("skipping file - already being processed", "file", file)
 				continue
 			}
 
 			if sequential {
 				if err := f.processFile(ctx, file); err != nil {
-					slog.Error("failed to process file", "error", err)
+					f.logger.Error("failed to process file", "file", file, "error", err)
 				}
 			} else {
 				fileWg.Add(1)
 				go func(fileName string) {
 					defer fileWg.Done()
 					if err := f.processFile(ctx, fileName); err != nil {
-						slog.Error("failed to process file", "error", err)
+						f.logger.Error("failed to process file", "file", fileName, "error", err)
 					}
 				}(file)
 			}
@@ -220,23 +245,36 @@ func (f *FileInboundEndpoint) processFile(ctx context.Context, fileURI string) e
 
 	properties := map[string]interface{}{
 		"isInbound":            "true",
-		"ARTIFACT_NAME":        "inboundendpointfile",
+		"ARTIFACT_NAME":        f.config.Name,
 		"inboundEndpointName":  "file",
 		"ClientApiNonBlocking": "true",
 	}
 
 	// Create a message context with metadata but no content yet
 	msgContext := &synctx.MsgContext{
+		MessageID:  synctx.NewMessageID(),
 		Properties: properties,
 		Message: synctx.Message{
 			ContentType: f.config.Parameters["transport.vfs.ContentType"],
 		},
 		Headers: headers,
+		Logger:  f.logger,
 	}
 
+	// Every file starts a trace
+	ctx, span := tracing.Tracer().Start(ctx, "inbound "+f.config.Name, trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(append(tracing.MessageAttributes(msgContext.MessageID, msgContext.CorrelationID()),
+			attribute.String("synapse.file.name", fileName))...))
+	defer span.End()
+	defer metrics.TrackInFlight(metrics.KindInbound, f.config.Name)()
+	metrics.InboundMessage(f.config.Name, f.config.Protocol)
+
 	// Read the file content
 	content, err := f.protocolHandler.ReadFile(fileURI)
 	if err != nil {
+		tracing.SetError(span, err)
+		metrics.InboundFailure(f.config.Name, f.config.Protocol)
+		metrics.InboundFile(f.config.Name, metrics.FileFailed)
 		return fmt.Errorf("failed to read file: %w", err)
 	}
 
@@ -251,10 +289,14 @@ func (f *FileInboundEndpoint) processFile(ctx context.Context, fileURI string) e
 
 		// Process the file through mediator
 		if err := f.mediator.MediateInboundMessage(ctx, f.config.SequenceName, msgContext); err != nil {
+			tracing.SetError(span, err)
+			metrics.InboundFailure(f.config.Name, f.config.Protocol)
+			metrics.InboundFile(f.config.Name, metrics.FileFailed)
 			if err := f.handleFileAction(fileURI, "Failure"); err != nil {
 				return fmt.Errorf("failed to handle file after failure: %w", err)
 			}
 		} else {
+			metrics.InboundFile(f.config.Name, metrics.FileProcessed)
 			if err := f.handleFileAction(fileURI, "Process"); err != nil {
 				return fmt.Errorf("failed to handle file after process: %w", err)
 			}
@@ -265,22 +307,28 @@ func (f *FileInboundEndpoint) processFile(ctx context.Context, fileURI string) e
 
 // handleFileAction handles file operations based on the configured action (MOVE, DELETE)
 func (f *FileInboundEndpoint) handleFileAction(fileURI, actionType string) error {
-	slog.Info("handling file action")
 	titleCaser := cases.Title(language.English)
 	actionKey := fmt.Sprintf("transport.vfs.ActionAfter%s", titleCaser.String(actionType))
-	if action, exists := f.config.Parameters[actionKey]; exists {
-		slog.Info(action)
-		if action == "MOVE" {
-			movePathKey := fmt.Sprintf("transport.vfs.MoveAfter%s", titleCaser.String(actionType))
-			movePath, exists := f.config.Parameters[movePathKey]
-			if !exists || movePath == "" {
-				return fmt.Errorf("move path not specified for %s action", actionType)
-			}
-			return f.protocolHandler.MoveFile(fileURI, movePath)
+	if action, exists := f.config.Parameters[actionKey]; exists && action == "MOVE" {
+		movePathKey := fmt.Sprintf("transport.vfs.MoveAfter%s", titleCaser.String(actionType))
+		movePath, exists := f.config.Parameters[movePathKey]
+		if !exists || movePath == "" {
+			return fmt.Errorf("move path not specified for %s action", actionType)
+		}
+		f.logger.Debug("handling file action", "file", fileURI, "action", action, "destination", movePath)
+		if err := f.protocolHandler.MoveFile(fileURI, movePath); err != nil {
+			return err
 		}
+		metrics.InboundFileAction(f.config.Name, metrics.ActionMove)
+		return nil
 	}
 	// Default to DELETE as per specification
-	return f.protocolHandler.DeleteFile(fileURI)
+	f.logger.Debug("handling file action", "file", fileURI, "action", "DELETE")
+	if err := f.protocolHandler.DeleteFile(fileURI); err != nil {
+		return err
+	}
+	metrics.InboundFileAction(f.config.Name, metrics.ActionDelete)
+	return nil
 }
 
 func (f *FileInboundEndpoint) validateConfig() error {
@@ -24,6 +24,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"strconv"
 	"time"
@@ -31,13 +32,15 @@ import (
 	"github.com/apache/synapse-go/internal/app/core/domain"
 	"github.com/apache/synapse-go/internal/app/core/ports"
 	"github.com/apache/synapse-go/internal/pkg/core/artifacts"
+	"github.com/apache/synapse-go/internal/pkg/core/router"
 	"github.com/apache/synapse-go/internal/pkg/core/synctx"
-	"github.com/apache/synapse-go/internal/pkg/core/utils"
 	"github.com/apache/synapse-go/internal/pkg/loggerfactory"
+	"github.com/apache/synapse-go/internal/pkg/metrics"
+	"github.com/apache/synapse-go/internal/pkg/tracing"
 )
 
 const (
-	componentName = "http"
+	componentName = "inbound.http"
 )
 
 // HTTPInboundEndpoint handles http-based inbound operations
@@ -48,6 +51,8 @@ type HTTPInboundEndpoint struct {
 	server    *http.Server
 	router    *http.ServeMux
 	logger    *slog.Logger
+	// started is closed once the endpoint listens on its port
+	started chan struct{}
 }
 
 // NewHTTPInboundEndpoint creates a new HTTPInboundEndpoint instance
@@ -56,10 +61,11 @@ func NewHTTPInboundEndpoint(
 	mediator ports.InboundMessageMediator,
 ) *HTTPInboundEndpoint {
 	h := &HTTPInboundEndpoint{
-		config: config,
-		router: http.NewServeMux(),
+		config:  config,
+		router:  http.NewServeMux(),
+		started: make(chan struct{}),
 	}
-	h.logger = loggerfactory.GetLogger(componentName, h)
+	h.logger = loggerfactory.GetArtifactLogger(componentName, "inbound", h.config.Name, h)
 	return h
 }
 
@@ -80,14 +86,25 @@ func (h *HTTPInboundEndpoint) Start(ctx context.Context, mediator ports.InboundM
 	h.router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		// Create message context
 		msgContext := synctx.CreateMsgContext()
+		msgContext.Properties["ARTIFACT_NAME"] = h.config.Name
+		msgContext.Logger = h.logger
+		msgContext.SetCorrelationID(r.Header.Get(artifacts.CorrelationHeader(ctx)))
+		mediationCtx, span := tracing.StartServerSpan(ctx, r, "inbound "+h.config.Name,
+			tracing.MessageAttributes(msgContext.MessageID, msgContext.CorrelationID())...)
+		defer span.End()
+		defer metrics.TrackInFlight(metrics.KindInbound, h.config.Name)()
+		metrics.InboundMessage(h.config.Name, h.config.Protocol)
 
 		// Set request into message context properties
 		msgContext.Properties["http_request_body"] = r.Body
 
 		// Mediate the inbound message
-		if err := h.mediator.MediateInboundMessage(ctx, h.config.SequenceName, msgContext); err != nil {
-			h.logger.Error("Error mediating inbound message", "error", err)
+		if err := h.mediator.MediateInboundMessage(mediationCtx, h.config.SequenceName, msgContext); err != nil {
+			h.logger.ErrorContext(loggerfactory.ContextWithAttrs(mediationCtx, msgContext.LogAttrs()...), "Error mediating inbound message", "error", err)
 			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			tracing.SetStatusCode(span, http.StatusInternalServerError)
+			metrics.InboundFailure(h.config.Name, h.config.Protocol)
+			tracing.SetError(span, err)
 			return
 		}
 
@@ -98,6 +115,7 @@ func (h *HTTPInboundEndpoint) Start(ctx context.Context, mediator ports.InboundM
 			h.logger.Debug("http-response flag not set, sending 202 Accepted response")
 			// Send 202 Accepted status
 			w.WriteHeader(http.StatusAccepted)
+			tracing.SetStatusCode(span, http.StatusAccepted)
 			return
 		}
 
@@ -105,6 +123,7 @@ func (h *HTTPInboundEndpoint) Start(ctx context.Context, mediator ports.InboundM
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte(`{"message": "Inbound Mediation successful"}`))
+		tracing.SetStatusCode(span, http.StatusOK)
 	})
 
 	inboundPortStr := h.config.Parameters["inbound.http.port"]
@@ -119,7 +138,11 @@ func (h *HTTPInboundEndpoint) Start(ctx context.Context, mediator ports.InboundM
 
 	var hostname string
 	// Get config context from the context
-	configCtx := ctx.Value(utils.ConfigContextKey).(*artifacts.ConfigContext)
+	configCtx, ok := artifacts.ConfigFromContext(ctx)
+	if !ok {
+		h.logger.Error("Config context not found in context")
+		return errors.New("config context not found")
+	}
 
 	if serverConfig, ok := configCtx.DeploymentConfig["server"].(map[string]string); ok {
 		hostname = serverConfig["hostname"]
@@ -143,13 +166,21 @@ func (h *HTTPInboundEndpoint) Start(ctx context.Context, mediator ports.InboundM
 	// Create a new HTTP server
 	h.server = &http.Server{
 		Addr:    listenAddr,
-		Handler: h.router,
+		Handler: router.AccessLogMiddleware(h.router, ctx),
+	}
+
+	// Listen before serving, so that a port in use fails the start
+	listener, err := net.Listen("tcp", listenAddr)
+	if err != nil {
+		h.logger.Error("HTTP Inbound listener error", "error", err)
+		return fmt.Errorf("failed to listen on %s: %w", listenAddr, err)
 	}
+	close(h.started)
 
 	// Start the server in a goroutine
 	go func() {
 		h.logger.Info("Starting HTTP Inbound listener", "address", listenAddr)
-		if err := h.server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		if err := h.server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
 			h.logger.Error("HTTP Inbound listener error", "error", err)
 		}
 		h.logger.Info("HTTP inbound server stopped serving new connections")
@@ -162,6 +193,11 @@ func (h *HTTPInboundEndpoint) Start(ctx context.Context, mediator ports.InboundM
 	return nil
 }
 
+// Started is closed once the endpoint listens on its port
+func (h *HTTPInboundEndpoint) Started() <-chan struct{} {
+	return h.started
+}
+
 // Stops HTTP server gracefully
 func (h *HTTPInboundEndpoint) Stop(ctx context.Context) error {
 	<-ctx.Done()
@@ -176,5 +212,5 @@ func (h *HTTPInboundEndpoint) Stop(ctx context.Context) error {
 }
 
 func (h *HTTPInboundEndpoint) UpdateLogger() {
-	h.logger = loggerfactory.GetLogger(componentName, h)
+	h.logger = loggerfactory.GetArtifactLogger(componentName, "inbound", h.config.Name, h)
 }
\ No newline at end of file
@@ -26,7 +26,6 @@ import (
 
 	"github.com/apache/synapse-go/internal/pkg/core/artifacts"
 	"github.com/apache/synapse-go/internal/pkg/core/synctx"
-	"github.com/apache/synapse-go/internal/pkg/core/utils"
 	"github.com/apache/synapse-go/internal/pkg/loggerfactory"
 )
 
@@ -49,7 +48,15 @@ func (m *MediationEngine) UpdateLogger() {
 }
 
 func (m *MediationEngine) MediateInboundMessage(ctx context.Context, seqName string, msg *synctx.MsgContext) error {
-	configContext := ctx.Value(utils.ConfigContextKey).(*artifacts.ConfigContext)
+	defer msg.Done()
+	// The message is mediated against the configuration it arrived with, and
+	// records logged meanwhile carry the IDs of the message
+	ctx = loggerfactory.ContextWithAttrs(artifacts.WithSnapshot(ctx), msg.LogAttrs()...)
+	configContext, ok := artifacts.ConfigFromContext(ctx)
+	if !ok {
+		m.logger.Error("Config context not found in context")
+		return errors.New("config context not found")
+	}
 	select {
 	case <-ctx.Done():
 		m.logger.Info("Mediation of sequence stopped since context is done")
@@ -57,9 +64,10 @@ func (m *MediationEngine) MediateInboundMessage(ctx context.Context, seqName str
 	default:
 		sequence, exists := configContext.SequenceMap[seqName]
 		if !exists {
-			m.logger.Error("Sequence " + seqName + " not found")
+			m.logger.ErrorContext(ctx, "Sequence "+seqName+" not found", "config_version", configContext.Version)
 			return errors.New("sequence not found")
 		}
+		m.logger.DebugContext(ctx, "Mediating inbound message", "sequence", seqName, "config_version", configContext.Version)
 		sequence.Execute(msg, ctx)
 	}
 	return nil
@@ -0,0 +1,278 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package processor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/apache/synapse-go/internal/app/core/domain"
+	"github.com/apache/synapse-go/internal/app/core/ports"
+	"github.com/apache/synapse-go/internal/pkg/core/artifacts"
+	"github.com/apache/synapse-go/internal/pkg/core/messagestore"
+	"github.com/apache/synapse-go/internal/pkg/core/synctx"
+	"github.com/apache/synapse-go/internal/pkg/loggerfactory"
+	"github.com/apache/synapse-go/internal/pkg/metrics"
+)
+
+// ForwardingProcessor drains a message store to an endpoint. A message is
+// removed from the store only after the endpoint accepted it; failed
+// deliveries are retried until max.delivery.attempts is reached.
+type ForwardingProcessor struct {
+	config    domain.MessageProcessorConfig
+	mediator  ports.InboundMessageMediator
+	scheduler scheduler
+	active    atomic.Bool
+	attempts  int
+	logger    *slog.Logger
+
+	interval        time.Duration
+	retryInterval   time.Duration
+	maxAttempts     int
+	deactivate      bool
+	faultSequence   string
+	replySequence   string
+	deadLetterStore string
+	retryCodes      map[int]bool
+	nonRetryCodes   map[int]bool
+}
+
+func NewForwardingProcessor(config domain.MessageProcessorConfig) *ForwardingProcessor {
+	p := &ForwardingProcessor{
+		config: config,
+	}
+	p.logger = loggerfactory.GetLogger(componentName, p)
+	return p
+}
+
+func (p *ForwardingProcessor) UpdateLogger() {
+	p.logger = loggerfactory.GetLogger(componentName, p)
+}
+
+func (p *ForwardingProcessor) Start(ctx context.Context, mediator ports.InboundMessageMediator) error {
+	// Check if context is already canceled before proceeding
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	if err := p.validateConfig(); err != nil {
+		return fmt.Errorf("configuration validation failed for message processor %s: %w", p.config.Name, err)
+	}
+	p.mediator = mediator
+
+	p.logger.Info("Starting message processor", "name", p.config.Name, "store", p.config.MessageStore, "endpoint", p.config.TargetEndpoint)
+	p.scheduler.run(ctx, p.interval, p.forward)
+	p.logger.Info("Message processor stopped", "name", p.config.Name)
+	return nil
+}
+
+func (p *ForwardingProcessor) Stop(ctx context.Context) error {
+	p.scheduler.stop()
+	return nil
+}
+
+// Activate resumes forwarding after the processor was deactivated
+func (p *ForwardingProcessor) Activate() {
+	p.active.Store(true)
+}
+
+// Deactivate pauses forwarding, leaving messages in the store
+func (p *ForwardingProcessor) Deactivate() {
+	p.active.Store(false)
+}
+
+func (p *ForwardingProcessor) IsActive() bool {
+	return p.active.Load()
+}
+
+// forward delivers the oldest message in the store and returns the delay
+// before the next attempt
+func (p *ForwardingProcessor) forward(ctx context.Context) time.Duration {
+	if !p.IsActive() {
+		return p.interval
+	}
+
+	store, err := lookupStore(ctx, p.config.MessageStore)
+	if err != nil {
+		p.logger.Error("Error resolving message store", "processor", p.config.Name, "error", err)
+		return p.interval
+	}
+	entry, err := store.Peek()
+	if err != nil {
+		p.logger.Error("Error reading message store", "processor", p.config.Name, "error", err)
+		return p.interval
+	}
+	if entry == nil {
+		return p.interval
+	}
+
+	msg := entry.Context
+	defer metrics.TrackInFlight(metrics.KindProcessor, p.config.Name)()
+	// Records about the message carry its IDs, which are also sent to the endpoint
+	ctx = loggerfactory.ContextWithAttrs(ctx, msg.LogAttrs()...)
+	err = p.deliver(ctx, msg)
+	if err == nil {
+		p.attempts = 0
+		p.remove(store, entry.ID)
+		if p.replySequence != "" {
+			p.mediate(ctx, p.replySequence, msg)
+		}
+		return p.interval
+	}
+
+	p.attempts++
+	p.logger.WarnContext(ctx, "Message delivery failed", "processor", p.config.Name, "attempt", p.attempts, "error", err)
+	msg.Properties["ERROR_MESSAGE"] = err.Error()
+
+	if p.maxAttempts > 0 && p.attempts >= p.maxAttempts {
+		p.attempts = 0
+		p.onMaxAttempts(ctx, store, entry.ID, msg)
+		return p.interval
+	}
+	return p.retryInterval
+}
+
+func (p *ForwardingProcessor) deliver(ctx context.Context, msg *synctx.MsgContext) error {
+	callMediator := artifacts.CallMediator{
+		EndpointRef: p.config.TargetEndpoint,
+		Position:    artifacts.Position{Hierarchy: p.config.Name},
+	}
+	if _, err := callMediator.Execute(msg, ctx); err != nil {
+		return err
+	}
+	if statusCode, ok := msg.Properties["HTTP_SC"].(int); ok && p.failed(statusCode) {
+		return fmt.Errorf("endpoint %s responded with status %d", p.config.TargetEndpoint, statusCode)
+	}
+	return nil
+}
+
+// failed reports whether an endpoint response status is a failed delivery.
+// Server errors and client errors other than 400 and 404, which a retry
+// cannot fix, are failures; retry.http.status.codes and
+// non.retry.status.codes add and remove codes.
+func (p *ForwardingProcessor) failed(statusCode int) bool {
+	if p.nonRetryCodes[statusCode] {
+		return false
+	}
+	if p.retryCodes[statusCode] {
+		return true
+	}
+	return statusCode >= 500 || (statusCode >= 400 && statusCode != 400 && statusCode != 404)
+}
+
+// onMaxAttempts runs the fault sequence, then moves the message to the dead
+// letter store, deactivates the processor or drops the message, in that order
+// of preference
+func (p *ForwardingProcessor) onMaxAttempts(ctx context.Context, store messagestore.Store, id string, failed *synctx.MsgContext) {
+	if p.faultSequence != "" {
+		p.mediate(ctx, p.faultSequence, failed)
+	}
+
+	if p.deadLetterStore != "" {
+		deadLetterStore, err := lookupStore(ctx, p.deadLetterStore)
+		if err == nil {
+			// Re-read the message so the dead letter store gets the original payload
+			var original *messagestore.Entry
+			original, err = store.Peek()
+			if err == nil && original != nil {
+				err = deadLetterStore.Store(original.Context)
+			}
+		}
+		if err != nil {
+			p.logger.ErrorContext(ctx, "Error moving message to dead letter store", "processor", p.config.Name, "store", p.deadLetterStore, "error", err)
+		} else {
+			p.logger.WarnContext(ctx, "Moved undeliverable message to dead letter store", "processor", p.config.Name, "store", p.deadLetterStore)
+			p.remove(store, id)
+			return
+		}
+	}
+
+	if p.deactivate {
+		p.logger.WarnContext(ctx, "Deactivating message processor after maximum delivery attempts", "processor", p.config.Name)
+		p.Deactivate()
+		return
+	}
+
+	p.logger.WarnContext(ctx, "Dropping undeliverable message", "processor", p.config.Name)
+	p.remove(store, id)
+}
+
+func (p *ForwardingProcessor) remove(store messagestore.Store, id string) {
+	if err := store.Remove(id); err != nil {
+		p.logger.Error("Error removing message", "processor", p.config.Name, "error", err)
+	}
+}
+
+func (p *ForwardingProcessor) mediate(ctx context.Context, seqName string, msg *synctx.MsgContext) {
+	if p.mediator == nil {
+		return
+	}
+	msg.Logger = p.logger
+	if err := p.mediator.MediateInboundMessage(ctx, seqName, msg); err != nil {
+		p.logger.ErrorContext(ctx, "Error mediating message", "processor", p.config.Name, "sequence", seqName, "error", err)
+	}
+}
+
+func (p *ForwardingProcessor) validateConfig() error {
+	if p.config.MessageStore == "" {
+		return fmt.Errorf("missing required message store")
+	}
+	if p.config.TargetEndpoint == "" {
+		return fmt.Errorf("missing required target endpoint")
+	}
+
+	var err error
+	params := p.config.Parameters
+	if p.interval, err = durationParam(params, "interval", time.Second); err != nil {
+		return err
+	}
+	if p.retryInterval, err = durationParam(params, "client.retry.interval", p.interval); err != nil {
+		return err
+	}
+	// Values below 1 retry forever
+	if p.maxAttempts, err = intParam(params, "max.delivery.attempts", 4); err != nil {
+		return err
+	}
+	if p.deactivate, err = boolParam(params, "message.processor.deactivate.on.failure", true); err != nil {
+		return err
+	}
+	active, err := boolParam(params, "is.active", true)
+	if err != nil {
+		return err
+	}
+	p.active.Store(active)
+
+	if p.retryCodes, err = statusCodesParam(params, "retry.http.status.codes"); err != nil {
+		return err
+	}
+	if p.nonRetryCodes, err = statusCodesParam(params, "non.retry.status.codes"); err != nil {
+		return err
+	}
+
+	p.faultSequence = params["message.processor.fault.sequence"]
+	p.replySequence = params["message.processor.reply.sequence"]
+	p.deadLetterStore = params["message.processor.dead.letter.store"]
+	return nil
+}
@@ -0,0 +1,158 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+// Package processor implements message processors, which drain message
+// stores in the background. They run under the same WaitGroup lifecycle as
+// inbound endpoints, so shutdown waits for the message in flight.
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apache/synapse-go/internal/app/core/domain"
+	"github.com/apache/synapse-go/internal/app/core/ports"
+	"github.com/apache/synapse-go/internal/pkg/core/artifacts"
+	"github.com/apache/synapse-go/internal/pkg/core/messagestore"
+)
+
+const (
+	componentName = "processor"
+)
+
+var (
+	ErrProcessorTypeNotFound = errors.New("message processor type not found")
+)
+
+func NewMessageProcessor(config domain.MessageProcessorConfig) (ports.MessageProcessor, error) {
+	switch config.Type {
+	case artifacts.MessageProcessorScheduledForwarding:
+		return NewForwardingProcessor(config), nil
+	case artifacts.MessageProcessorSampling:
+		return NewSamplingProcessor(config), nil
+	default:
+		return nil, ErrProcessorTypeNotFound
+	}
+}
+
+// scheduler runs a processing step repeatedly until it is stopped. Each step
+// returns the delay before the next one.
+type scheduler struct {
+	mu     sync.Mutex
+	cancel context.CancelFunc
+}
+
+func (s *scheduler) run(ctx context.Context, initialDelay time.Duration, step func(ctx context.Context) time.Duration) {
+	ctx, cancel := context.WithCancel(ctx)
+	s.mu.Lock()
+	s.cancel = cancel
+	s.mu.Unlock()
+	defer cancel()
+
+	timer := time.NewTimer(initialDelay)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			timer.Reset(step(ctx))
+		}
+	}
+}
+
+func (s *scheduler) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		s.cancel()
+	}
+}
+
+// lookupStore resolves a message store from the config context
+func lookupStore(ctx context.Context, name string) (messagestore.Store, error) {
+	configContext, ok := artifacts.ConfigFromContext(ctx)
+	if !ok {
+		return nil, errors.New("config context not found in context")
+	}
+	messageStore, exists := configContext.MessageStoreMap[name]
+	if !exists || messageStore.Store == nil {
+		return nil, fmt.Errorf("message store not found: %s", name)
+	}
+	return messageStore.Store, nil
+}
+
+// durationParam reads a parameter in milliseconds
+func durationParam(parameters map[string]string, name string, defaultValue time.Duration) (time.Duration, error) {
+	value, exists := parameters[name]
+	if !exists || value == "" {
+		return defaultValue, nil
+	}
+	ms, err := strconv.Atoi(value)
+	if err != nil || ms <= 0 {
+		return 0, fmt.Errorf("invalid %s value: must be a positive integer, got '%s'", name, value)
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+func intParam(parameters map[string]string, name string, defaultValue int) (int, error) {
+	value, exists := parameters[name]
+	if !exists || value == "" {
+		return defaultValue, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s value: must be an integer, got '%s'", name, value)
+	}
+	return n, nil
+}
+
+// statusCodesParam parses a comma-separated list of HTTP status codes
+func statusCodesParam(parameters map[string]string, name string) (map[int]bool, error) {
+	codes := make(map[int]bool)
+	value := parameters[name]
+	if value == "" {
+		return codes, nil
+	}
+	for _, field := range strings.Split(value, ",") {
+		code, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || code < 100 || code > 599 {
+			return nil, fmt.Errorf("invalid %s value: must be comma-separated HTTP status codes, got '%s'", name, value)
+		}
+		codes[code] = true
+	}
+	return codes, nil
+}
+
+func boolParam(parameters map[string]string, name string, defaultValue bool) (bool, error) {
+	value, exists := parameters[name]
+	if !exists || value == "" {
+		return defaultValue, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s value: must be true/false, got '%s'", name, value)
+	}
+	return b, nil
+}
@@ -0,0 +1,265 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package processor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/apache/synapse-go/internal/app/core/domain"
+	"github.com/apache/synapse-go/internal/pkg/core/artifacts"
+	"github.com/apache/synapse-go/internal/pkg/core/messagestore"
+	"github.com/apache/synapse-go/internal/pkg/core/synctx"
+	"github.com/apache/synapse-go/internal/pkg/core/utils"
+	"github.com/apache/synapse-go/internal/pkg/loggerfactory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	configManager := loggerfactory.GetConfigManager()
+	configManager.SetLogLevelMap(&map[string]string{componentName: "error"})
+	configManager.SetSlogHandlerConfig(loggerfactory.SlogHandlerConfig{Format: "text", OutputPath: "stdout"})
+	os.Exit(m.Run())
+}
+
+// recordingMediator records the sequences messages were mediated through
+type recordingMediator struct {
+	mu        sync.Mutex
+	sequences []string
+}
+
+func (m *recordingMediator) MediateInboundMessage(ctx context.Context, seqName string, msg *synctx.MsgContext) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sequences = append(m.sequences, seqName)
+	return nil
+}
+
+func (m *recordingMediator) calls() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.sequences...)
+}
+
+func newTestContext(backendURL string, stores ...messagestore.Store) context.Context {
+	configContext := &artifacts.ConfigContext{
+		EndpointMap: map[string]artifacts.Endpoint{
+			"backend": {Name: "backend", EndpointUrl: artifacts.EndpointUrl{Method: "POST", URITemplate: backendURL}},
+		},
+		MessageStoreMap: map[string]artifacts.MessageStore{},
+	}
+	for _, store := range stores {
+		configContext.MessageStoreMap[store.Name()] = artifacts.MessageStore{Name: store.Name(), Store: store}
+	}
+	return context.WithValue(context.Background(), utils.ConfigContextKey, configContext)
+}
+
+func storeMessage(t *testing.T, store messagestore.Store, payload string) {
+	msg := synctx.CreateMsgContext()
+	msg.Message.RawPayload = []byte(payload)
+	require.NoError(t, store.Store(msg))
+}
+
+func TestForwardingProcessor_Delivers(t *testing.T) {
+	var received atomic.Int32
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer backend.Close()
+
+	store := messagestore.NewMemoryStore("orders")
+	storeMessage(t, store, `{"order":1}`)
+	storeMessage(t, store, `{"order":2}`)
+
+	ctx, cancel := context.WithCancel(newTestContext(backend.URL, store))
+	defer cancel()
+
+	mediator := &recordingMediator{}
+	p := NewForwardingProcessor(domain.MessageProcessorConfig{
+		Name:           "forwarder",
+		Type:           artifacts.MessageProcessorScheduledForwarding,
+		MessageStore:   "orders",
+		TargetEndpoint: "backend",
+		Parameters: map[string]string{
+			"interval":                         "10",
+			"message.processor.reply.sequence": "reply",
+		},
+	})
+	go p.Start(ctx, mediator)
+
+	assert.Eventually(t, func() bool {
+		size, _ := store.Size()
+		return size == 0
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, int32(2), received.Load())
+	assert.Equal(t, []string{"reply", "reply"}, mediator.calls())
+}
+
+func TestForwardingProcessor_DeadLetterStore(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer backend.Close()
+
+	store := messagestore.NewMemoryStore("orders")
+	deadLetters := messagestore.NewMemoryStore("dlq")
+	storeMessage(t, store, `{"order":1}`)
+
+	ctx, cancel := context.WithCancel(newTestContext(backend.URL, store, deadLetters))
+	defer cancel()
+
+	mediator := &recordingMediator{}
+	p := NewForwardingProcessor(domain.MessageProcessorConfig{
+		Name:           "forwarder",
+		MessageStore:   "orders",
+		TargetEndpoint: "backend",
+		Parameters: map[string]string{
+			"interval":                            "10",
+			"client.retry.interval":               "10",
+			"max.delivery.attempts":               "2",
+			"message.processor.fault.sequence":    "fault",
+			"message.processor.dead.letter.store": "dlq",
+		},
+	})
+	go p.Start(ctx, mediator)
+
+	assert.Eventually(t, func() bool {
+		size, _ := deadLetters.Size()
+		return size == 1
+	}, 2*time.Second, 10*time.Millisecond)
+
+	size, _ := store.Size()
+	assert.Equal(t, 0, size)
+	assert.Equal(t, []string{"fault"}, mediator.calls())
+
+	entry, err := deadLetters.Peek()
+	require.NoError(t, err)
+	assert.Equal(t, `{"order":1}`, string(entry.Context.Message.RawPayload))
+	assert.True(t, p.IsActive())
+}
+
+func TestForwardingProcessor_DeactivatesOnFailure(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer backend.Close()
+
+	store := messagestore.NewMemoryStore("orders")
+	storeMessage(t, store, `{"order":1}`)
+
+	ctx, cancel := context.WithCancel(newTestContext(backend.URL, store))
+	defer cancel()
+
+	p := NewForwardingProcessor(domain.MessageProcessorConfig{
+		Name:           "forwarder",
+		MessageStore:   "orders",
+		TargetEndpoint: "backend",
+		Parameters: map[string]string{
+			"interval":              "10",
+			"client.retry.interval": "10",
+			"max.delivery.attempts": "2",
+		},
+	})
+	go p.Start(ctx, &recordingMediator{})
+
+	assert.Eventually(t, func() bool {
+		return !p.IsActive()
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// The message stays in the store for when the processor is reactivated
+	size, _ := store.Size()
+	assert.Equal(t, 1, size)
+}
+
+func TestForwardingProcessor_FailedStatusCodes(t *testing.T) {
+	p := NewForwardingProcessor(domain.MessageProcessorConfig{
+		MessageStore:   "orders",
+		TargetEndpoint: "backend",
+		Parameters: map[string]string{
+			"retry.http.status.codes": "404",
+			"non.retry.status.codes":  "409, 503",
+		},
+	})
+	require.NoError(t, p.validateConfig())
+
+	failed := map[int]bool{200: false, 202: false, 400: false, 401: true, 404: true, 409: false, 429: true, 500: true, 503: false}
+	for statusCode, expected := range failed {
+		assert.Equal(t, expected, p.failed(statusCode), "status %d", statusCode)
+	}
+
+	p.config.Parameters = map[string]string{"retry.http.status.codes": "4xx"}
+	assert.ErrorContains(t, p.validateConfig(), "invalid retry.http.status.codes value")
+}
+
+func TestSamplingProcessor(t *testing.T) {
+	store := messagestore.NewMemoryStore("orders")
+	for i := 0; i < 3; i++ {
+		storeMessage(t, store, `{}`)
+	}
+
+	ctx, cancel := context.WithCancel(newTestContext("", store))
+	defer cancel()
+
+	mediator := &recordingMediator{}
+	p := NewSamplingProcessor(domain.MessageProcessorConfig{
+		Name:         "sampler",
+		MessageStore: "orders",
+		Parameters:   map[string]string{"interval": "10", "concurrency": "2", "sequence": "process"},
+	})
+
+	done := make(chan struct{})
+	go func() {
+		p.Start(ctx, mediator)
+		close(done)
+	}()
+
+	assert.Eventually(t, func() bool {
+		return len(mediator.calls()) == 3
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// Stop ends the processing loop
+	p.Stop(ctx)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("sampling processor did not stop")
+	}
+}
+
+func TestProcessor_InvalidConfig(t *testing.T) {
+	p := NewSamplingProcessor(domain.MessageProcessorConfig{
+		Name:         "sampler",
+		MessageStore: "orders",
+		Parameters:   map[string]string{"sequence": "process", "interval": "-5"},
+	})
+	err := p.Start(context.Background(), &recordingMediator{})
+	assert.ErrorContains(t, err, "invalid interval value")
+
+	_, err = NewMessageProcessor(domain.MessageProcessorConfig{Type: "resequencing"})
+	assert.ErrorIs(t, err, ErrProcessorTypeNotFound)
+}
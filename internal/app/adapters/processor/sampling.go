@@ -0,0 +1,134 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package processor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/apache/synapse-go/internal/app/core/domain"
+	"github.com/apache/synapse-go/internal/app/core/ports"
+	"github.com/apache/synapse-go/internal/pkg/loggerfactory"
+	"github.com/apache/synapse-go/internal/pkg/metrics"
+)
+
+// SamplingProcessor takes up to concurrency messages from a store every
+// interval and injects them into a sequence. Messages are removed from the
+// store before they are mediated.
+type SamplingProcessor struct {
+	config    domain.MessageProcessorConfig
+	mediator  ports.InboundMessageMediator
+	scheduler scheduler
+	logger    *slog.Logger
+
+	interval    time.Duration
+	concurrency int
+	sequence    string
+}
+
+func NewSamplingProcessor(config domain.MessageProcessorConfig) *SamplingProcessor {
+	p := &SamplingProcessor{
+		config: config,
+	}
+	p.logger = loggerfactory.GetLogger(componentName, p)
+	return p
+}
+
+func (p *SamplingProcessor) UpdateLogger() {
+	p.logger = loggerfactory.GetLogger(componentName, p)
+}
+
+func (p *SamplingProcessor) Start(ctx context.Context, mediator ports.InboundMessageMediator) error {
+	// Check if context is already canceled before proceeding
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	if err := p.validateConfig(); err != nil {
+		return fmt.Errorf("configuration validation failed for message processor %s: %w", p.config.Name, err)
+	}
+	p.mediator = mediator
+
+	p.logger.Info("Starting message processor", "name", p.config.Name, "store", p.config.MessageStore, "sequence", p.sequence)
+	p.scheduler.run(ctx, p.interval, p.sample)
+	p.logger.Info("Message processor stopped", "name", p.config.Name)
+	return nil
+}
+
+func (p *SamplingProcessor) Stop(ctx context.Context) error {
+	p.scheduler.stop()
+	return nil
+}
+
+func (p *SamplingProcessor) sample(ctx context.Context) time.Duration {
+	store, err := lookupStore(ctx, p.config.MessageStore)
+	if err != nil {
+		p.logger.Error("Error resolving message store", "processor", p.config.Name, "error", err)
+		return p.interval
+	}
+
+	for i := 0; i < p.concurrency; i++ {
+		entry, err := store.Peek()
+		if err != nil {
+			p.logger.Error("Error reading message store", "processor", p.config.Name, "error", err)
+			break
+		}
+		if entry == nil {
+			break
+		}
+		if err := store.Remove(entry.ID); err != nil {
+			p.logger.Error("Error removing message", "processor", p.config.Name, "error", err)
+			break
+		}
+		entry.Context.Logger = p.logger
+		done := metrics.TrackInFlight(metrics.KindProcessor, p.config.Name)
+		if err := p.mediator.MediateInboundMessage(ctx, p.sequence, entry.Context); err != nil {
+			p.logger.Error("Error mediating message", "processor", p.config.Name, "sequence", p.sequence, "error", err)
+		}
+		done()
+	}
+	return p.interval
+}
+
+func (p *SamplingProcessor) validateConfig() error {
+	if p.config.MessageStore == "" {
+		return fmt.Errorf("missing required message store")
+	}
+	p.sequence = p.config.Parameters["sequence"]
+	if p.sequence == "" {
+		return fmt.Errorf("missing required parameter: 'sequence'")
+	}
+
+	var err error
+	if p.interval, err = durationParam(p.config.Parameters, "interval", time.Second); err != nil {
+		return err
+	}
+	if p.concurrency, err = intParam(p.config.Parameters, "concurrency", 1); err != nil {
+		return err
+	}
+	if p.concurrency <= 0 {
+		return fmt.Errorf("invalid concurrency value: must be positive, got '%d'", p.concurrency)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+// Package task implements scheduled tasks, which inject a message into a
+// sequence on an interval or cron schedule. Tasks run under the same
+// WaitGroup lifecycle as inbound endpoints.
+package task
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/apache/synapse-go/internal/app/core/domain"
+	"github.com/apache/synapse-go/internal/app/core/ports"
+	"github.com/apache/synapse-go/internal/pkg/core/artifacts"
+	"github.com/apache/synapse-go/internal/pkg/core/synctx"
+	"github.com/apache/synapse-go/internal/pkg/loggerfactory"
+	"github.com/apache/synapse-go/internal/pkg/metrics"
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	componentName = "task"
+)
+
+type ScheduledTask struct {
+	config   domain.TaskConfig
+	clock    Clock
+	mediator ports.InboundMessageMediator
+	logger   *slog.Logger
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+}
+
+func NewScheduledTask(config domain.TaskConfig) *ScheduledTask {
+	t := &ScheduledTask{
+		config: config,
+		clock:  NewTaskClock(),
+	}
+	t.logger = loggerfactory.GetLogger(componentName, t)
+	return t
+}
+
+func (t *ScheduledTask) UpdateLogger() {
+	t.logger = loggerfactory.GetLogger(componentName, t)
+}
+
+// Start runs the task until the context is cancelled, Stop is called or the
+// trigger count is reached
+func (t *ScheduledTask) Start(ctx context.Context, mediator ports.InboundMessageMediator) error {
+	schedule, err := t.schedule()
+	if err != nil {
+		return fmt.Errorf("configuration validation failed for task %s: %w", t.config.Name, err)
+	}
+	t.mediator = mediator
+
+	ctx, cancel := context.WithCancel(ctx)
+	t.mu.Lock()
+	t.cancel = cancel
+	t.mu.Unlock()
+	defer cancel()
+
+	t.logger.Info("Starting task", "name", t.config.Name, "sequence", t.config.SequenceName)
+	defer t.logger.Info("Task stopped", "name", t.config.Name)
+
+	// Interval triggers fire as soon as the task starts, like Quartz simple triggers
+	next := t.clock.Now()
+	if t.config.Interval == 0 {
+		next = schedule.Next(next)
+	}
+	for runs := 0; t.config.Count < 1 || runs < t.config.Count; runs++ {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-t.clock.After(next.Sub(t.clock.Now())):
+		}
+		t.inject(ctx)
+		// Runs that overlap the next fire time skip it rather than queueing up
+		next = schedule.Next(t.clock.Now())
+	}
+	return nil
+}
+
+func (t *ScheduledTask) Stop(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.cancel != nil {
+		t.cancel()
+	}
+	return nil
+}
+
+func (t *ScheduledTask) schedule() (cron.Schedule, error) {
+	if t.config.SequenceName == "" {
+		return nil, fmt.Errorf("missing required sequence")
+	}
+	if t.config.Interval > 0 {
+		return intervalSchedule(t.config.Interval), nil
+	}
+	if t.config.Cron == "" {
+		return nil, fmt.Errorf("missing trigger interval or cron expression")
+	}
+	return artifacts.ParseCronSchedule(t.config.Cron)
+}
+
+func (t *ScheduledTask) inject(ctx context.Context) {
+	defer metrics.TrackInFlight(metrics.KindTask, t.config.Name)()
+	msgContext := synctx.CreateMsgContext()
+	msgContext.Properties["ARTIFACT_NAME"] = t.config.Name
+	msgContext.Properties["taskName"] = t.config.Name
+	msgContext.Logger = t.logger
+	if t.config.Payload != "" {
+		msgContext.Message.RawPayload = []byte(t.config.Payload)
+		msgContext.Message.ContentType = t.config.ContentType
+	}
+
+	if err := t.mediator.MediateInboundMessage(ctx, t.config.SequenceName, msgContext); err != nil {
+		t.logger.Error("Error mediating task message", "task", t.config.Name, "sequence", t.config.SequenceName, "error", err)
+	}
+}
+
+// intervalSchedule fires at a fixed delay after the previous run finished
+type intervalSchedule time.Duration
+
+func (s intervalSchedule) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(s))
+}
@@ -0,0 +1,220 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package task
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apache/synapse-go/internal/app/core/domain"
+	"github.com/apache/synapse-go/internal/pkg/core/synctx"
+	"github.com/apache/synapse-go/internal/pkg/loggerfactory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	configManager := loggerfactory.GetConfigManager()
+	configManager.SetLogLevelMap(&map[string]string{componentName: "error"})
+	configManager.SetSlogHandlerConfig(loggerfactory.SlogHandlerConfig{Format: "text", OutputPath: "stdout"})
+	os.Exit(m.Run())
+}
+
+type fakeWaiter struct {
+	deadline time.Time
+	ch       chan time.Time
+}
+
+// fakeClock only moves when Advance is called
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeWaiter
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+	c.waiters = append(c.waiters, fakeWaiter{deadline: c.now.Add(d), ch: ch})
+	return ch
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	remaining := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.deadline.After(c.now) {
+			remaining = append(remaining, w)
+			continue
+		}
+		w.ch <- c.now
+	}
+	c.waiters = remaining
+}
+
+func (c *fakeClock) pending() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
+
+type recordingMediator struct {
+	mu       sync.Mutex
+	messages []*synctx.MsgContext
+	times    []time.Time
+	clock    Clock
+}
+
+func (m *recordingMediator) MediateInboundMessage(ctx context.Context, seqName string, msg *synctx.MsgContext) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, msg)
+	m.times = append(m.times, m.clock.Now())
+	return nil
+}
+
+func (m *recordingMediator) count() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.messages)
+}
+
+func startTask(t *testing.T, task *ScheduledTask, mediator *recordingMediator) chan error {
+	done := make(chan error, 1)
+	go func() {
+		done <- task.Start(context.Background(), mediator)
+	}()
+	t.Cleanup(func() { task.Stop(context.Background()) })
+	return done
+}
+
+// waitForSchedule waits until the task is sleeping until its next run
+func waitForSchedule(t *testing.T, clock *fakeClock) {
+	require.Eventually(t, func() bool { return clock.pending() == 1 }, time.Second, time.Millisecond)
+}
+
+func TestScheduledTask_Interval(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	mediator := &recordingMediator{clock: clock}
+	task := NewScheduledTask(domain.TaskConfig{
+		Name:         "heartbeat",
+		SequenceName: "heartbeatSeq",
+		Interval:     10 * time.Second,
+		Count:        3,
+		Payload:      `{"ping":true}`,
+		ContentType:  "application/json",
+	})
+	task.clock = clock
+	done := startTask(t, task, mediator)
+
+	// The first run happens as soon as the task starts
+	waitForSchedule(t, clock)
+	assert.Equal(t, 1, mediator.count())
+
+	clock.Advance(9 * time.Second)
+	assert.Equal(t, 1, mediator.count())
+	clock.Advance(time.Second)
+	waitForSchedule(t, clock)
+	assert.Equal(t, 2, mediator.count())
+
+	clock.Advance(10 * time.Second)
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("task did not stop after reaching its count")
+	}
+
+	require.Equal(t, 3, mediator.count())
+	msg := mediator.messages[2]
+	assert.Equal(t, `{"ping":true}`, string(msg.Message.RawPayload))
+	assert.Equal(t, "application/json", msg.Message.ContentType)
+	assert.Equal(t, "heartbeat", msg.Properties["taskName"])
+	assert.Equal(t, time.Date(2026, 1, 1, 0, 0, 20, 0, time.UTC), mediator.times[2])
+}
+
+func TestScheduledTask_Cron(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 1, 59, 0, 0, time.UTC)}
+	mediator := &recordingMediator{clock: clock}
+	task := NewScheduledTask(domain.TaskConfig{
+		Name:         "nightly",
+		SequenceName: "batchSeq",
+		Cron:         "0 0 2 * * ?",
+		Count:        -1,
+	})
+	task.clock = clock
+	startTask(t, task, mediator)
+
+	waitForSchedule(t, clock)
+	assert.Equal(t, 0, mediator.count())
+
+	clock.Advance(time.Minute)
+	waitForSchedule(t, clock)
+	clock.Advance(24 * time.Hour)
+	require.Eventually(t, func() bool { return mediator.count() == 2 }, time.Second, time.Millisecond)
+
+	assert.Equal(t, time.Date(2026, 1, 1, 2, 0, 0, 0, time.UTC), mediator.times[0])
+	assert.Equal(t, time.Date(2026, 1, 2, 2, 0, 0, 0, time.UTC), mediator.times[1])
+	assert.Nil(t, mediator.messages[0].Message.RawPayload)
+}
+
+func TestScheduledTask_Stop(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	mediator := &recordingMediator{clock: clock}
+	task := NewScheduledTask(domain.TaskConfig{Name: "poller", SequenceName: "pollSeq", Interval: time.Minute})
+	task.clock = clock
+	done := startTask(t, task, mediator)
+
+	waitForSchedule(t, clock)
+	task.Stop(context.Background())
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("task did not stop")
+	}
+	assert.Equal(t, 1, mediator.count())
+}
+
+func TestScheduledTask_InvalidConfig(t *testing.T) {
+	task := NewScheduledTask(domain.TaskConfig{Name: "broken", SequenceName: "seq", Cron: "not a cron"})
+	err := task.Start(context.Background(), &recordingMediator{})
+	assert.ErrorContains(t, err, "invalid cron expression")
+
+	task = NewScheduledTask(domain.TaskConfig{Name: "broken", Interval: time.Second})
+	err = task.Start(context.Background(), &recordingMediator{})
+	assert.ErrorContains(t, err, "missing required sequence")
+}
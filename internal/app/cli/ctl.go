@@ -0,0 +1,615 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/apache/synapse-go/internal/app/synapse"
+	"github.com/apache/synapse-go/internal/pkg/health"
+	"github.com/apache/synapse-go/internal/pkg/loggerfactory"
+	"github.com/apache/synapse-go/internal/pkg/management"
+)
+
+// Output formats of synapse ctl
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+)
+
+// followInterval is how often synapse ctl logs -f asks for new lines
+var followInterval = time.Second
+
+// profileName is the form of profile names, which are TOML keys
+var profileName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// ctlUsage lists the commands of synapse ctl
+const ctlUsage = `Usage: synapse ctl [-config <file>] [-profile <name>] [-o table|json] <command>
+
+Commands:
+  info                                   version, uptime and deployment of the server
+  version                                versions of synapse ctl and of the server
+  list apis|sequences|endpoints|inbounds|processors
+                                         deployed artifacts with their state
+  describe <kind> <name>                 an artifact with its definition
+  suspend endpoint|inbound|processor <name>
+                                         deactivate an endpoint or a message processor,
+                                         or pause an inbound endpoint
+  resume endpoint|inbound|processor <name>
+                                         activate an endpoint or a message processor,
+                                         or resume an inbound endpoint
+  restart inbound <name>                 restart an inbound endpoint
+  redeploy                               redeploy the artifacts folder
+  logs [-n <lines>] [-f]                 last lines logged, -f to keep printing new ones
+  log-level <logger> [<level>]           show or set the level of a logger
+  profile list                           server profiles
+  profile add <name> -url <url> [-username <user>] [-password <password>]
+  profile use <name>                     select the profile used by default
+  profile remove <name>
+
+Flags:`
+
+// ctl is a run of synapse ctl
+type ctl struct {
+	stdout     io.Writer
+	stderr     io.Writer
+	configPath string
+	config     ctlConfig
+	profile    string
+	output     string
+}
+
+// Ctl runs synapse ctl, the command line client of the management API. The
+// servers are named profiles in a local file. ctx stops logs -f.
+func Ctl(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) int {
+	c := &ctl{stdout: stdout, stderr: stderr}
+	flags := flag.NewFlagSet("ctl", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.StringVar(&c.configPath, "config", "", "profiles file (default "+CtlConfigEnv+" or synapse/ctl.toml in the user configuration folder)")
+	flags.StringVar(&c.profile, "profile", "", "server profile (default the current profile)")
+	flags.StringVar(&c.output, "o", OutputTable, "output format: table or json")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, ctlUsage)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return ExitUsage
+	}
+	if c.output != OutputTable && c.output != OutputJSON {
+		fmt.Fprintln(stderr, "invalid output format: "+c.output)
+		return ExitUsage
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return ExitUsage
+	}
+	if c.configPath == "" {
+		path, err := defaultCtlConfigPath()
+		if err != nil {
+			fmt.Fprintln(stderr, "Error locating the profiles file: "+err.Error())
+			return ExitUsage
+		}
+		c.configPath = path
+	}
+	config, err := loadCtlConfig(c.configPath)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	c.config = config
+
+	command, args := flags.Arg(0), flags.Args()[1:]
+	switch command {
+	case "profile":
+		return c.profiles(args)
+	case "version":
+		return c.version()
+	case "info":
+		return c.run(args, 0, c.info)
+	case "list":
+		return c.run(args, 1, c.list)
+	case "describe":
+		return c.run(args, 2, c.describe)
+	case "suspend", "resume", "restart":
+		return c.run(args, 2, func(client *ctlClient, args []string) error { return c.control(client, command, args) })
+	case "redeploy":
+		return c.run(args, 0, c.redeploy)
+	case "log-level":
+		n := 1
+		if len(args) == 2 {
+			n = 2
+		}
+		return c.run(args, n, c.logLevel)
+	case "logs":
+		return c.logs(ctx, args)
+	}
+	fmt.Fprintln(stderr, "unknown command: "+command)
+	flags.Usage()
+	return ExitUsage
+}
+
+// run calls a command that takes n arguments with a client of the profile
+func (c *ctl) run(args []string, n int, command func(*ctlClient, []string) error) int {
+	if len(args) != n {
+		fmt.Fprintln(c.stderr, ctlUsage)
+		return ExitUsage
+	}
+	client, err := c.client()
+	if err != nil {
+		fmt.Fprintln(c.stderr, err)
+		return ExitUsage
+	}
+	if err := command(client, args); err != nil {
+		fmt.Fprintln(c.stderr, "Error: "+err.Error())
+		return ExitInvalid
+	}
+	return ExitOK
+}
+
+func (c *ctl) client() (*ctlClient, error) {
+	profile, err := c.config.profile(c.profile)
+	if err != nil {
+		return nil, err
+	}
+	return &ctlClient{profile: profile, http: &http.Client{Timeout: time.Minute}}, nil
+}
+
+func (c *ctl) info(client *ctlClient, args []string) error {
+	var info management.Info
+	return c.call(client, http.MethodGet, "/management/server", nil, &info, func(w io.Writer) {
+		kinds := make([]string, 0, len(info.Artifacts))
+		for kind, count := range info.Artifacts {
+			kinds = append(kinds, fmt.Sprintf("%s %d", kind, count))
+		}
+		sort.Strings(kinds)
+		deployment := "in progress"
+		if info.Deployment.Completed {
+			deployment = "completed"
+		}
+		if info.Deployment.Error != "" {
+			deployment += ", last deployment failed: " + info.Deployment.Error
+		}
+		fmt.Fprintf(w, "Server:\t%s\n", client.profile.URL)
+		fmt.Fprintf(w, "Version:\t%s\n", info.Version)
+		fmt.Fprintf(w, "Go version:\t%s\n", info.GoVersion)
+		fmt.Fprintf(w, "Platform:\t%s\n", info.Platform)
+		fmt.Fprintf(w, "Hostname:\t%s\n", info.Hostname)
+		fmt.Fprintf(w, "Started:\t%s (up %s)\n", info.StartedAt.Local().Format(time.RFC3339), info.Uptime)
+		fmt.Fprintf(w, "Status:\t%s\n", info.Status)
+		fmt.Fprintf(w, "Deployment:\t%s\n", deployment)
+		fmt.Fprintf(w, "Artifacts:\t%s\n", strings.Join(kinds, ", "))
+	})
+}
+
+// version prints the version of synapse ctl and, when a profile is
+// selected, the version of its server
+func (c *ctl) version() int {
+	var info management.Info
+	client, err := c.client()
+	if err == nil {
+		err = client.do(http.MethodGet, "/management/server", nil, &info)
+	}
+	if c.output == OutputJSON {
+		versions := map[string]string{"client": synapse.Version}
+		if err == nil {
+			versions["server"] = info.Version
+		}
+		c.writeJSON(versions)
+	} else {
+		fmt.Fprintln(c.stdout, "Client version: "+synapse.Version)
+		if err == nil {
+			fmt.Fprintln(c.stdout, "Server version: "+info.Version)
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(c.stderr, "Error getting the server version: "+err.Error())
+		return ExitInvalid
+	}
+	return ExitOK
+}
+
+func (c *ctl) list(client *ctlClient, args []string) error {
+	kind, err := artifactKind(args[0])
+	if err != nil {
+		return err
+	}
+	var artifacts []management.Artifact
+	return c.call(client, http.MethodGet, "/management/"+kind, nil, &artifacts, func(w io.Writer) {
+		switch kind {
+		case management.KindAPIs:
+			fmt.Fprintln(w, "NAME\tCONTEXT\tVERSION\tSTATE\tFILE")
+			for _, a := range artifacts {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", a.Name, a.Context, a.Version, a.State, location(a))
+			}
+		case management.KindEndpoints:
+			fmt.Fprintln(w, "NAME\tURL\tSTATE\tFILE")
+			for _, a := range artifacts {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", a.Name, a.URL, a.State, location(a))
+			}
+		case management.KindInbounds:
+			fmt.Fprintln(w, "NAME\tPROTOCOL\tSEQUENCE\tSTATE\tFILE")
+			for _, a := range artifacts {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", a.Name, a.Protocol, a.Sequence, a.State, location(a))
+			}
+		case management.KindProcessors:
+			fmt.Fprintln(w, "NAME\tSTORE\tENDPOINT\tSTATE\tFILE")
+			for _, a := range artifacts {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", a.Name, a.Store, a.Endpoint, a.State, location(a))
+			}
+		default:
+			fmt.Fprintln(w, "NAME\tSTATE\tFILE")
+			for _, a := range artifacts {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", a.Name, a.State, location(a))
+			}
+		}
+	})
+}
+
+func (c *ctl) describe(client *ctlClient, args []string) error {
+	kind, err := artifactKind(args[0])
+	if err != nil {
+		return err
+	}
+	var artifact management.Artifact
+	return c.call(client, http.MethodGet, "/management/"+kind+"/"+url.PathEscape(args[1]), nil, &artifact, func(w io.Writer) {
+		writeArtifact(w, artifact)
+		var definition bytes.Buffer
+		if err := json.Indent(&definition, artifact.Definition, "  ", "  "); err == nil {
+			fmt.Fprintf(w, "Definition:\n  %s\n", definition.String())
+		}
+	})
+}
+
+// control suspends, resumes or restarts an endpoint or inbound endpoint
+func (c *ctl) control(client *ctlClient, action string, args []string) error {
+	kind, err := artifactKind(args[0])
+	if err != nil {
+		return err
+	}
+	actions := map[string]string{
+		management.KindEndpoints + " suspend":  "deactivate",
+		management.KindEndpoints + " resume":   "activate",
+		management.KindInbounds + " suspend":   "pause",
+		management.KindInbounds + " resume":    "resume",
+		management.KindInbounds + " restart":   "restart",
+		management.KindProcessors + " suspend": "deactivate",
+		management.KindProcessors + " resume":  "activate",
+	}
+	path, ok := actions[kind+" "+action]
+	if !ok {
+		return fmt.Errorf("%s cannot be applied to %s", action, kind)
+	}
+	var artifact management.Artifact
+	return c.call(client, http.MethodPost, "/management/"+kind+"/"+url.PathEscape(args[1])+"/"+path, nil, &artifact, func(w io.Writer) {
+		writeArtifact(w, artifact)
+	})
+}
+
+func (c *ctl) redeploy(client *ctlClient, args []string) error {
+	var deployment health.DeploymentStatus
+	return c.call(client, http.MethodPost, "/management/deployments", nil, &deployment, func(w io.Writer) {
+		if deployment.Error != "" {
+			fmt.Fprintln(w, "Deployment failed: "+deployment.Error)
+			return
+		}
+		fmt.Fprintln(w, "Deployment completed")
+	})
+}
+
+// logLevel shows the level of a logger or, given one, sets it
+func (c *ctl) logLevel(client *ctlClient, args []string) error {
+	method, body := http.MethodGet, interface{}(nil)
+	if len(args) == 2 {
+		method, body = http.MethodPut, management.LoggerLevel{Level: args[1]}
+	}
+	var level management.LoggerLevel
+	return c.call(client, method, "/management/loggers/"+url.PathEscape(args[0]), body, &level, func(w io.Writer) {
+		fmt.Fprintf(w, "%s\t%s\n", level.Name, level.Level)
+	})
+}
+
+// logs prints the last lines logged by the server and, with -f, the lines
+// logged after them until ctx is done
+func (c *ctl) logs(ctx context.Context, args []string) int {
+	flags := flag.NewFlagSet("ctl logs", flag.ContinueOnError)
+	flags.SetOutput(c.stderr)
+	lines := flags.Int("n", 100, "number of lines")
+	follow := flags.Bool("f", false, "keep printing the lines logged")
+	if err := flags.Parse(args); err != nil {
+		return ExitUsage
+	}
+	if flags.NArg() > 0 || *lines <= 0 {
+		flags.Usage()
+		return ExitUsage
+	}
+	client, err := c.client()
+	if err != nil {
+		fmt.Fprintln(c.stderr, err)
+		return ExitUsage
+	}
+	var after uint64
+	for {
+		var logLines []loggerfactory.LogLine
+		query := url.Values{"lines": {strconv.Itoa(*lines)}}
+		if after > 0 {
+			query.Set("after", strconv.FormatUint(after, 10))
+		}
+		if err := client.do(http.MethodGet, "/management/logs?"+query.Encode(), nil, &logLines); err != nil {
+			fmt.Fprintln(c.stderr, "Error: "+err.Error())
+			return ExitInvalid
+		}
+		for _, line := range logLines {
+			if c.output == OutputJSON {
+				fmt.Fprintln(c.stdout, line.Line)
+			} else {
+				fmt.Fprintln(c.stdout, formatLogLine(line.Line))
+			}
+			after = line.Seq
+		}
+		if !*follow {
+			return ExitOK
+		}
+		select {
+		case <-ctx.Done():
+			return ExitOK
+		case <-time.After(followInterval):
+		}
+	}
+}
+
+// profiles lists, adds, selects and removes server profiles
+func (c *ctl) profiles(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(c.stderr, ctlUsage)
+		return ExitUsage
+	}
+	switch args[0] {
+	case "list":
+		if c.output == OutputJSON {
+			c.writeJSON(c.config.Profiles)
+			return ExitOK
+		}
+		w := tabwriter.NewWriter(c.stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "CURRENT\tNAME\tURL\tUSERNAME")
+		for _, name := range c.config.names() {
+			current := ""
+			if name == c.config.Current {
+				current = "*"
+			}
+			profile := c.config.Profiles[name]
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", current, name, profile.URL, profile.Username)
+		}
+		w.Flush()
+		return ExitOK
+	case "add":
+		return c.addProfile(args[1:])
+	case "use", "remove":
+		if len(args) != 2 {
+			fmt.Fprintln(c.stderr, ctlUsage)
+			return ExitUsage
+		}
+		name := args[1]
+		if _, ok := c.config.Profiles[name]; !ok {
+			fmt.Fprintln(c.stderr, "unknown server profile: "+name)
+			return ExitInvalid
+		}
+		if args[0] == "use" {
+			c.config.Current = name
+		} else {
+			delete(c.config.Profiles, name)
+			if c.config.Current == name {
+				c.config.Current = ""
+			}
+		}
+		return c.saveConfig()
+	}
+	fmt.Fprintln(c.stderr, "unknown profile command: "+args[0])
+	return ExitUsage
+}
+
+// addProfile adds or replaces a profile, which becomes the current one if
+// there is none. The flags can come before or after the name.
+func (c *ctl) addProfile(args []string) int {
+	flags := flag.NewFlagSet("ctl profile add", flag.ContinueOnError)
+	flags.SetOutput(c.stderr)
+	var profile ctlProfile
+	flags.StringVar(&profile.URL, "url", "", "URL of the management API, such as http://localhost:9164")
+	flags.StringVar(&profile.Username, "username", "", "management username")
+	flags.StringVar(&profile.Password, "password", "", "management password (default "+CtlPasswordEnv+")")
+	if err := flags.Parse(args); err != nil {
+		return ExitUsage
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return ExitUsage
+	}
+	name := flags.Arg(0)
+	if err := flags.Parse(flags.Args()[1:]); err != nil {
+		return ExitUsage
+	}
+	if flags.NArg() > 0 || profile.URL == "" {
+		flags.Usage()
+		return ExitUsage
+	}
+	if !profileName.MatchString(name) {
+		fmt.Fprintln(c.stderr, "invalid profile name: "+name+", use letters, digits, - and _")
+		return ExitUsage
+	}
+	if parsed, err := url.Parse(profile.URL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		fmt.Fprintln(c.stderr, "invalid profile URL: "+profile.URL+", must be an http or https URL")
+		return ExitUsage
+	}
+	c.config.Profiles[name] = profile
+	if c.config.Current == "" {
+		c.config.Current = name
+	}
+	return c.saveConfig()
+}
+
+func (c *ctl) saveConfig() int {
+	if err := c.config.save(c.configPath); err != nil {
+		fmt.Fprintln(c.stderr, "Error writing "+c.configPath+": "+err.Error())
+		return ExitInvalid
+	}
+	return ExitOK
+}
+
+// call sends a request and prints the response, as it is in JSON output or
+// decoded into v and written by table otherwise
+func (c *ctl) call(client *ctlClient, method string, path string, body interface{}, v interface{}, table func(w io.Writer)) error {
+	var raw json.RawMessage
+	if err := client.do(method, path, body, &raw); err != nil {
+		return err
+	}
+	if c.output == OutputJSON {
+		c.writeJSON(raw)
+		return nil
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("invalid response: %w", err)
+	}
+	w := tabwriter.NewWriter(c.stdout, 0, 4, 2, ' ', 0)
+	table(w)
+	return w.Flush()
+}
+
+func (c *ctl) writeJSON(v interface{}) {
+	encoder := json.NewEncoder(c.stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(v)
+}
+
+// ctlClient calls the management API of a server
+type ctlClient struct {
+	profile ctlProfile
+	http    *http.Client
+}
+
+// do sends a request with the credentials of the profile and decodes the
+// JSON response into v. Failed requests return the error of the response.
+func (c *ctlClient) do(method string, path string, body interface{}, v interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, strings.TrimRight(c.profile.URL, "/")+path, reader)
+	if err != nil {
+		return err
+	}
+	if c.profile.Username != "" || c.profile.Password != "" {
+		req.SetBasicAuth(c.profile.Username, c.profile.Password)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		var failure management.Error
+		if json.Unmarshal(data, &failure) == nil && failure.Error != "" {
+			return errors.New(failure.Error)
+		}
+		return errors.New(resp.Status)
+	}
+	return json.Unmarshal(data, v)
+}
+
+// artifactKind returns the management path of a kind of artifact, given in
+// the singular or the plural
+func artifactKind(kind string) (string, error) {
+	kind = strings.ToLower(kind)
+	for _, known := range []string{management.KindAPIs, management.KindSequences, management.KindEndpoints, management.KindInbounds, management.KindProcessors} {
+		if kind == known || kind+"s" == known {
+			return known, nil
+		}
+	}
+	return "", fmt.Errorf("unknown artifact kind: %s, expected apis, sequences, endpoints, inbounds or processors", kind)
+}
+
+// location is the file and line an artifact is defined at
+func location(a management.Artifact) string {
+	if a.Line > 0 {
+		return a.File + ":" + strconv.Itoa(a.Line)
+	}
+	return a.File
+}
+
+// writeArtifact writes the fields of an artifact that are set
+func writeArtifact(w io.Writer, a management.Artifact) {
+	fields := [][2]string{
+		{"Name", a.Name}, {"Type", a.Type}, {"File", location(a)}, {"State", a.State}, {"Reason", a.Reason},
+		{"Context", a.Context}, {"Version", a.Version}, {"URL", a.URL}, {"Protocol", a.Protocol}, {"Sequence", a.Sequence},
+		{"Store", a.Store}, {"Endpoint", a.Endpoint},
+	}
+	for _, field := range fields {
+		if field[1] != "" {
+			fmt.Fprintf(w, "%s:\t%s\n", field[0], field[1])
+		}
+	}
+}
+
+// formatLogLine writes a JSON log record as its time, level and message
+// followed by its other attributes in key=value form. Lines that are not
+// JSON records are returned as they are.
+func formatLogLine(line string) string {
+	var record map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &record); err != nil {
+		return line
+	}
+	text := fmt.Sprintf("%v %-5v %v", record[slog.TimeKey], record[slog.LevelKey], record[slog.MessageKey])
+	keys := make([]string, 0, len(record))
+	for key := range record {
+		if key != slog.TimeKey && key != slog.LevelKey && key != slog.MessageKey {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value, _ := json.Marshal(record[key])
+		if s, ok := record[key].(string); ok && !strings.ContainsAny(s, " \"=") {
+			value = []byte(s)
+		}
+		text += " " + key + "=" + string(value)
+	}
+	return text
+}
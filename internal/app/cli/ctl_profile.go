@@ -0,0 +1,145 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/knadh/koanf/parsers/toml"
+	"github.com/knadh/koanf/providers/file"
+	"github.com/knadh/koanf/v2"
+)
+
+// Environment variables read by synapse ctl
+const (
+	// CtlConfigEnv is the path of the profiles file, overriding the default
+	CtlConfigEnv = "SYNAPSE_CTL_CONFIG"
+	// CtlPasswordEnv is the password of the profiles that have none
+	CtlPasswordEnv = "SYNAPSE_CTL_PASSWORD"
+)
+
+// ctlProfile is a server managed by synapse ctl
+type ctlProfile struct {
+	URL      string `koanf:"url"`
+	Username string `koanf:"username"`
+	Password string `koanf:"password"`
+}
+
+// ctlConfig is the profiles file of synapse ctl:
+//
+//	current = "local"
+//
+//	[profiles.local]
+//	url = "http://localhost:9164"
+//	username = "admin"
+//	password = "admin"
+type ctlConfig struct {
+	Current  string                `koanf:"current"`
+	Profiles map[string]ctlProfile `koanf:"profiles"`
+}
+
+// defaultCtlConfigPath returns the path of the profiles file in the user
+// configuration folder, such as ~/.config/synapse/ctl.toml
+func defaultCtlConfigPath() (string, error) {
+	if path := os.Getenv(CtlConfigEnv); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "synapse", "ctl.toml"), nil
+}
+
+// loadCtlConfig reads the profiles file, a missing file holds no profiles
+func loadCtlConfig(path string) (ctlConfig, error) {
+	config := ctlConfig{Profiles: make(map[string]ctlProfile)}
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return config, nil
+	}
+	k := koanf.New(".")
+	if err := k.Load(file.Provider(path), toml.Parser()); err != nil {
+		return config, fmt.Errorf("error reading %s: %w", path, err)
+	}
+	if err := k.Unmarshal("", &config); err != nil {
+		return config, fmt.Errorf("error reading %s: %w", path, err)
+	}
+	if config.Profiles == nil {
+		config.Profiles = make(map[string]ctlProfile)
+	}
+	return config, nil
+}
+
+// save writes the profiles file, readable by its owner only since it can
+// hold passwords
+func (c ctlConfig) save(path string) error {
+	profiles := make(map[string]interface{}, len(c.Profiles))
+	for name, profile := range c.Profiles {
+		settings := map[string]interface{}{"url": profile.URL}
+		if profile.Username != "" {
+			settings["username"] = profile.Username
+		}
+		if profile.Password != "" {
+			settings["password"] = profile.Password
+		}
+		profiles[name] = settings
+	}
+	data, err := toml.Parser().Marshal(map[string]interface{}{"current": c.Current, "profiles": profiles})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// profile returns the profile named name, or the current profile when name
+// is empty. A profile without a password takes it from SYNAPSE_CTL_PASSWORD.
+func (c ctlConfig) profile(name string) (ctlProfile, error) {
+	if name == "" {
+		name = c.Current
+	}
+	if name == "" {
+		return ctlProfile{}, errors.New("no server profile is selected, add one with synapse ctl profile add")
+	}
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return ctlProfile{}, fmt.Errorf("unknown server profile: %s", name)
+	}
+	if profile.Password == "" {
+		profile.Password = os.Getenv(CtlPasswordEnv)
+	}
+	return profile, nil
+}
+
+// names returns the names of the profiles in order
+func (c ctlConfig) names() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,269 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apache/synapse-go/internal/app/synapse"
+	"github.com/apache/synapse-go/internal/pkg/loggerfactory"
+	"github.com/apache/synapse-go/internal/pkg/management"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newManagementServer fakes the management API of a server with an API,
+// an endpoint and a message processor
+func newManagementServer(t *testing.T) *httptest.Server {
+	var mu sync.Mutex
+	endpoint := management.Artifact{Name: "StockEP", Type: "Endpoints", File: "stock.xml", Line: 2, State: "deployed", URL: "http://stock:8080"}
+	logs := loggerfactory.NewRecentLogs(10)
+	logs.Write([]byte(`{"time":"2026-10-18T16:00:00Z","level":"INFO","msg":"Deployed API","api_name":"OrdersAPI"}` + "\n"))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /management/server", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(management.Info{Version: "1.2.0", Status: "READY", Artifacts: map[string]int{"APIs": 1, "Endpoints": 1}})
+	})
+	mux.HandleFunc("GET /management/apis", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]management.Artifact{{Name: "OrdersAPI", Type: "APIs", File: "orders.xml", State: "deployed", Context: "/orders", Version: "1.0"}})
+	})
+	mux.HandleFunc("GET /management/endpoints/{name}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("name") != "StockEP" {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(management.Error{Error: "endpoint not found: " + r.PathValue("name")})
+			return
+		}
+		mu.Lock()
+		described := endpoint
+		mu.Unlock()
+		described.Definition = json.RawMessage(`{"Name":"StockEP"}`)
+		json.NewEncoder(w).Encode(described)
+	})
+	mux.HandleFunc("POST /management/endpoints/StockEP/deactivate", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		endpoint.State = "suspended"
+		mu.Unlock()
+		json.NewEncoder(w).Encode(endpoint)
+	})
+	mux.HandleFunc("POST /management/processors/{name}/activate", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(management.Artifact{Name: r.PathValue("name"), Type: "MessageProcessors", File: "forwarder.xml",
+			State: "deployed", Store: "OrdersStore", Endpoint: "StockEP"})
+	})
+	mux.HandleFunc("PUT /management/loggers/{name}", func(w http.ResponseWriter, r *http.Request) {
+		var level management.LoggerLevel
+		json.NewDecoder(r.Body).Decode(&level)
+		json.NewEncoder(w).Encode(management.LoggerLevel{Name: r.PathValue("name"), Level: level.Level})
+	})
+	mux.HandleFunc("GET /management/logs", func(w http.ResponseWriter, r *http.Request) {
+		after, _ := strconv.ParseUint(r.URL.Query().Get("after"), 10, 64)
+		lines, _ := strconv.Atoi(r.URL.Query().Get("lines"))
+		json.NewEncoder(w).Encode(logs.Since(after, lines))
+		// Every poll logs a line for the next one
+		logs.Write([]byte(`{"time":"2026-10-18T16:00:01Z","level":"WARN","msg":"Polled"}` + "\n"))
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); !ok || username != "admin" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(management.Error{Error: "unauthorized"})
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// runCtl runs synapse ctl with a profiles file in a temporary folder
+func runCtl(t *testing.T, config string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := Ctl(context.Background(), append([]string{"-config", config}, args...), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestCtlProfiles(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "synapse", "ctl.toml")
+
+	code, _, stderr := runCtl(t, config, "profile", "add", "local", "-url", "http://localhost:9164", "-username", "admin")
+	require.Equal(t, ExitOK, code, stderr)
+	code, _, stderr = runCtl(t, config, "profile", "add", "-url", "https://prod:9164", "-username", "ops", "-password", "p", "prod")
+	require.Equal(t, ExitOK, code, stderr)
+	info, err := os.Stat(config)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	code, stdout, _ := runCtl(t, config, "profile", "list")
+	assert.Equal(t, ExitOK, code)
+	assert.Equal(t, "CURRENT  NAME   URL                    USERNAME\n"+
+		"*        local  http://localhost:9164  admin\n"+
+		"         prod   https://prod:9164      ops\n", stdout)
+
+	code, _, _ = runCtl(t, config, "profile", "use", "prod")
+	assert.Equal(t, ExitOK, code)
+	code, _, _ = runCtl(t, config, "profile", "remove", "local")
+	assert.Equal(t, ExitOK, code)
+	loaded, err := loadCtlConfig(config)
+	require.NoError(t, err)
+	assert.Equal(t, ctlConfig{Current: "prod", Profiles: map[string]ctlProfile{
+		"prod": {URL: "https://prod:9164", Username: "ops", Password: "p"},
+	}}, loaded)
+
+	code, _, stderr = runCtl(t, config, "profile", "add", "eu.west", "-url", "http://eu:9164")
+	assert.Equal(t, ExitUsage, code)
+	assert.Contains(t, stderr, "invalid profile name: eu.west")
+	code, _, stderr = runCtl(t, config, "profile", "add", "eu", "-url", "eu:9164")
+	assert.Equal(t, ExitUsage, code)
+	assert.Contains(t, stderr, "invalid profile URL: eu:9164")
+	code, _, stderr = runCtl(t, config, "profile", "use", "local")
+	assert.Equal(t, ExitInvalid, code)
+	assert.Contains(t, stderr, "unknown server profile: local")
+}
+
+// addProfile adds the profile of server, without a password unless given
+func addProfile(t *testing.T, server *httptest.Server, password string) string {
+	config := filepath.Join(t.TempDir(), "ctl.toml")
+	args := []string{"profile", "add", "test", "-url", server.URL, "-username", "admin"}
+	if password != "" {
+		args = append(args, "-password", password)
+	}
+	code, _, stderr := runCtl(t, config, args...)
+	require.Equal(t, ExitOK, code, stderr)
+	return config
+}
+
+func TestCtlList(t *testing.T) {
+	config := addProfile(t, newManagementServer(t), "secret")
+
+	code, stdout, stderr := runCtl(t, config, "list", "apis")
+	require.Equal(t, ExitOK, code, stderr)
+	assert.Equal(t, "NAME       CONTEXT  VERSION  STATE     FILE\n"+
+		"OrdersAPI  /orders  1.0      deployed  orders.xml\n", stdout)
+
+	code, stdout, _ = runCtl(t, config, "-o", "json", "list", "api")
+	require.Equal(t, ExitOK, code)
+	var apis []management.Artifact
+	require.NoError(t, json.Unmarshal([]byte(stdout), &apis))
+	assert.Equal(t, "OrdersAPI", apis[0].Name)
+
+	code, _, stderr = runCtl(t, config, "list", "proxies")
+	assert.Equal(t, ExitInvalid, code)
+	assert.Contains(t, stderr, "unknown artifact kind: proxies")
+}
+
+func TestCtlEndpoints(t *testing.T) {
+	config := addProfile(t, newManagementServer(t), "")
+	t.Setenv(CtlPasswordEnv, "secret")
+
+	code, stdout, stderr := runCtl(t, config, "describe", "endpoint", "StockEP")
+	require.Equal(t, ExitOK, code, stderr)
+	assert.Equal(t, "Name:   StockEP\n"+
+		"Type:   Endpoints\n"+
+		"File:   stock.xml:2\n"+
+		"State:  deployed\n"+
+		"URL:    http://stock:8080\n"+
+		"Definition:\n  {\n    \"Name\": \"StockEP\"\n  }\n", stdout)
+
+	code, stdout, stderr = runCtl(t, config, "suspend", "endpoint", "StockEP")
+	require.Equal(t, ExitOK, code, stderr)
+	assert.Contains(t, stdout, "State:  suspended")
+
+	code, _, stderr = runCtl(t, config, "describe", "endpoint", "PaymentsEP")
+	assert.Equal(t, ExitInvalid, code)
+	assert.Equal(t, "Error: endpoint not found: PaymentsEP\n", stderr)
+
+	code, _, stderr = runCtl(t, config, "restart", "endpoint", "StockEP")
+	assert.Equal(t, ExitInvalid, code)
+	assert.Contains(t, stderr, "restart cannot be applied to endpoints")
+
+	t.Setenv(CtlPasswordEnv, "wrong")
+	code, _, stderr = runCtl(t, config, "describe", "endpoint", "StockEP")
+	assert.Equal(t, ExitInvalid, code)
+	assert.Equal(t, "Error: unauthorized\n", stderr)
+}
+
+func TestCtlProcessors(t *testing.T) {
+	config := addProfile(t, newManagementServer(t), "secret")
+
+	code, stdout, stderr := runCtl(t, config, "resume", "processor", "OrdersForwarder")
+	require.Equal(t, ExitOK, code, stderr)
+	assert.Equal(t, "Name:      OrdersForwarder\n"+
+		"Type:      MessageProcessors\n"+
+		"File:      forwarder.xml\n"+
+		"State:     deployed\n"+
+		"Store:     OrdersStore\n"+
+		"Endpoint:  StockEP\n", stdout)
+
+	code, _, stderr = runCtl(t, config, "restart", "processor", "OrdersForwarder")
+	assert.Equal(t, ExitInvalid, code)
+	assert.Contains(t, stderr, "restart cannot be applied to processors")
+}
+
+func TestCtlInfoAndVersion(t *testing.T) {
+	config := addProfile(t, newManagementServer(t), "secret")
+
+	code, stdout, stderr := runCtl(t, config, "info")
+	require.Equal(t, ExitOK, code, stderr)
+	assert.Contains(t, stdout, "Version:     1.2.0\n")
+	assert.Contains(t, stdout, "Status:      READY\n")
+	assert.Contains(t, stdout, "Artifacts:   APIs 1, Endpoints 1\n")
+
+	code, stdout, _ = runCtl(t, config, "-o", "json", "version")
+	require.Equal(t, ExitOK, code)
+	assert.JSONEq(t, `{"client":"`+synapse.Version+`","server":"1.2.0"}`, stdout)
+
+	code, stdout, stderr = runCtl(t, filepath.Join(t.TempDir(), "ctl.toml"), "version")
+	assert.Equal(t, ExitInvalid, code)
+	assert.Equal(t, "Client version: "+synapse.Version+"\n", stdout)
+	assert.Contains(t, stderr, "no server profile is selected")
+}
+
+func TestCtlLogs(t *testing.T) {
+	config := addProfile(t, newManagementServer(t), "secret")
+
+	code, stdout, stderr := runCtl(t, config, "log-level", "router", "debug")
+	require.Equal(t, ExitOK, code, stderr)
+	assert.Equal(t, "router  debug\n", stdout)
+
+	code, stdout, stderr = runCtl(t, config, "logs", "-n", "5")
+	require.Equal(t, ExitOK, code, stderr)
+	assert.Equal(t, "2026-10-18T16:00:00Z INFO  Deployed API api_name=OrdersAPI\n", stdout)
+
+	defer func(interval time.Duration) { followInterval = interval }(followInterval)
+	followInterval = 10 * time.Millisecond
+	ctx, cancel := context.WithTimeout(context.Background(), 35*time.Millisecond)
+	defer cancel()
+	var out, errOut bytes.Buffer
+	code = Ctl(ctx, []string{"-config", config, "-o", "json", "logs", "-n", "1", "-f"}, &out, &errOut)
+	require.Equal(t, ExitOK, code, errOut.String())
+	lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+	require.GreaterOrEqual(t, len(lines), 2, "follows the lines logged after the first poll")
+	for _, line := range lines {
+		assert.JSONEq(t, `{"time":"2026-10-18T16:00:01Z","level":"WARN","msg":"Polled"}`, string(line))
+	}
+}
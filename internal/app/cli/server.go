@@ -0,0 +1,89 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/apache/synapse-go/internal/app/synapse"
+	"github.com/apache/synapse-go/internal/pkg/config"
+)
+
+// Levels accepted by --log-level
+var logLevels = []string{"debug", "info", "warn", "error"}
+
+// ServerOptions parses the flags of synapse when it runs the server. It
+// returns flag.ErrHelp if help was requested.
+func ServerOptions(args []string, stderr io.Writer) (synapse.Options, error) {
+	var opts synapse.Options
+	flags := flag.NewFlagSet("synapse", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.StringVar(&opts.ConfPath, "conf", "", "folder holding deployment.toml and LoggerConfig.toml (default ../conf from the binary)")
+	flags.StringVar(&opts.ArtifactsPath, "artifacts", "", "folder holding the artifacts (default ../artifacts from the binary)")
+	portOffset := flags.Int("port-offset", 0, "offset added to the HTTP port, overriding server.offset")
+	flags.StringVar(&opts.Overrides.LogLevel, "log-level", "", "level of every logger: debug, info, warn or error")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: synapse [flags]")
+		fmt.Fprintln(stderr, "       synapse validate [-format human|json|junit] <dir>")
+		fmt.Fprintln(stderr, "       synapse vault encrypt [-conf <dir>] <alias>")
+		fmt.Fprintln(stderr, "       synapse ctl [-profile <name>] [-o table|json] <command>")
+		fmt.Fprintln(stderr, "\nFlags:")
+		flags.PrintDefaults()
+		fmt.Fprintln(stderr, "\nAny deployment.toml key can also be set with a "+config.EnvPrefix+"<SECTION>_<KEY> environment variable.")
+	}
+	if err := flags.Parse(args); err != nil {
+		return opts, err
+	}
+	if flags.NArg() > 0 {
+		err := fmt.Errorf("unknown command: %s", flags.Arg(0))
+		fmt.Fprintln(stderr, err)
+		flags.Usage()
+		return opts, err
+	}
+
+	opts.Overrides.LogLevel = strings.ToLower(opts.Overrides.LogLevel)
+	if opts.Overrides.LogLevel != "" && !slices.Contains(logLevels, opts.Overrides.LogLevel) {
+		err := fmt.Errorf("invalid log level: %s", opts.Overrides.LogLevel)
+		fmt.Fprintln(stderr, err)
+		return opts, err
+	}
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == "port-offset" {
+			opts.Overrides.Deployment = map[string]string{"server.offset": strconv.Itoa(*portOffset)}
+		}
+	})
+	for _, path := range []*string{&opts.ConfPath, &opts.ArtifactsPath} {
+		if *path == "" {
+			continue
+		}
+		absPath, err := filepath.Abs(*path)
+		if err != nil {
+			return opts, err
+		}
+		*path = absPath
+	}
+	return opts, nil
+}
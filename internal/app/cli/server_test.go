@@ -0,0 +1,67 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package cli
+
+import (
+	"bytes"
+	"flag"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerOptionsDefaults(t *testing.T) {
+	var stderr bytes.Buffer
+	opts, err := ServerOptions(nil, &stderr)
+	require.NoError(t, err)
+	assert.Empty(t, opts.ConfPath)
+	assert.Empty(t, opts.ArtifactsPath)
+	assert.Empty(t, opts.Overrides.LogLevel)
+	assert.Nil(t, opts.Overrides.Deployment)
+}
+
+func TestServerOptionsFlags(t *testing.T) {
+	var stderr bytes.Buffer
+	opts, err := ServerOptions([]string{"--conf", "conf", "--artifacts=/srv/artifacts", "--port-offset", "0", "--log-level", "DEBUG"}, &stderr)
+	require.NoError(t, err)
+	conf, _ := filepath.Abs("conf")
+	assert.Equal(t, conf, opts.ConfPath)
+	assert.Equal(t, "/srv/artifacts", opts.ArtifactsPath)
+	assert.Equal(t, "debug", opts.Overrides.LogLevel)
+	// An explicit offset of 0 still overrides server.offset
+	assert.Equal(t, map[string]string{"server.offset": "0"}, opts.Overrides.Deployment)
+}
+
+func TestServerOptionsErrors(t *testing.T) {
+	var stderr bytes.Buffer
+	_, err := ServerOptions([]string{"-h"}, &stderr)
+	assert.ErrorIs(t, err, flag.ErrHelp)
+
+	_, err = ServerOptions([]string{"--log-level", "verbose"}, &stderr)
+	assert.EqualError(t, err, "invalid log level: verbose")
+
+	_, err = ServerOptions([]string{"--port-offset", "ten"}, &stderr)
+	assert.Error(t, err)
+
+	_, err = ServerOptions([]string{"serve"}, &stderr)
+	assert.EqualError(t, err, "unknown command: serve")
+}
@@ -0,0 +1,257 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+// Package cli implements the subcommands of the synapse binary other than
+// running the server.
+package cli
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/apache/synapse-go/internal/pkg/config"
+	"github.com/apache/synapse-go/internal/pkg/core/deployers"
+	"github.com/apache/synapse-go/internal/pkg/loggerfactory"
+	"github.com/apache/synapse-go/internal/pkg/vault"
+)
+
+// Exit codes of the subcommands
+const (
+	ExitOK      = 0
+	ExitInvalid = 1
+	ExitUsage   = 2
+)
+
+// deploymentFile is the path of deployment.toml in a Synapse home folder
+var deploymentFile = filepath.Join("conf", "deployment.toml")
+
+// Validate runs synapse validate: it checks the artifacts of a Synapse home
+// folder, or of an artifacts folder, and the deployment.toml next to them,
+// without starting the server. It returns ExitInvalid if any problem is found.
+func Validate(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("validate", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	format := flags.String("format", "human", "output format: human, json or junit")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: synapse validate [-format human|json|junit] <dir>")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return ExitUsage
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return ExitUsage
+	}
+	var write func(io.Writer, *deployers.ValidationReport) error
+	switch *format {
+	case "human":
+		write = writeHuman
+	case "json":
+		write = writeJSON
+	case "junit":
+		write = writeJUnit
+	default:
+		fmt.Fprintln(stderr, "unknown format: "+*format)
+		return ExitUsage
+	}
+
+	report, err := validate(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(stderr, "Error validating "+flags.Arg(0)+": "+err.Error())
+		return ExitUsage
+	}
+	if err := write(stdout, report); err != nil {
+		fmt.Fprintln(stderr, "Error writing report: "+err.Error())
+		return ExitUsage
+	}
+	if !report.Valid() {
+		return ExitInvalid
+	}
+	return ExitOK
+}
+
+// validate checks dir, which is either a Synapse home folder holding
+// artifacts/ and conf/, or an artifacts folder
+func validate(dir string) (*deployers.ValidationReport, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, errors.New(dir + " is not a directory")
+	}
+	artifactsPath := dir
+	if info, err := os.Stat(filepath.Join(dir, "artifacts")); err == nil && info.IsDir() {
+		artifactsPath = filepath.Join(dir, "artifacts")
+	}
+
+	// Deployer logs go to stderr so that they do not mix with the report
+	cm := loggerfactory.GetConfigManager()
+	cm.SetLogLevelMap(&map[string]string{"deployers": "ERROR"})
+	cm.SetSlogHandlerConfig(loggerfactory.SlogHandlerConfig{Format: "text", OutputPath: "stderr"})
+
+	// Secret references resolve against the vault of the conf folder
+	var vaultErr error
+	if info, err := os.Stat(filepath.Join(dir, "conf")); err == nil && info.IsDir() {
+		vaultErr = vault.GetVault().AddFileProvider(filepath.Join(dir, "conf"))
+	}
+
+	// Placeholders resolve against deployment.toml, if there is one
+	var deploymentConfig map[string]interface{}
+	var configErr error
+	configPath := filepath.Join(dir, deploymentFile)
+	_, statErr := os.Stat(configPath)
+	if statErr == nil {
+		deploymentConfig, configErr = config.ReadDeploymentFile(configPath)
+	}
+
+	report, err := deployers.NewDeployer(artifactsPath, nil, nil).Validate(deploymentConfig)
+	if err != nil {
+		return nil, err
+	}
+	if vaultErr != nil {
+		secretsFile := filepath.Join("conf", vault.SecurityFolder, vault.SecretsFileName)
+		report.Files = append(report.Files, secretsFile)
+		report.Problems = append(report.Problems, deployers.Problem{File: secretsFile, Message: vaultErr.Error()})
+	}
+	if statErr == nil {
+		report.Files = append(report.Files, deploymentFile)
+		if configErr != nil {
+			report.Problems = append(report.Problems, deployers.Problem{File: deploymentFile, Message: configErr.Error()})
+		}
+	}
+	return report, nil
+}
+
+func writeHuman(w io.Writer, report *deployers.ValidationReport) error {
+	for _, problem := range report.Problems {
+		location := problem.File
+		if problem.Position.LineNo > 0 {
+			location += ":" + strconv.Itoa(problem.Position.LineNo)
+		}
+		line := location + ": " + problem.Message
+		if problem.Position.Hierarchy != "" {
+			line += " (" + problem.Position.Hierarchy + ")"
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "%d files checked, %d problems found\n", len(report.Files), len(report.Problems))
+	return err
+}
+
+type jsonReport struct {
+	Valid    bool          `json:"valid"`
+	Files    []string      `json:"files"`
+	Problems []jsonProblem `json:"problems"`
+}
+
+type jsonProblem struct {
+	File      string `json:"file"`
+	Line      int    `json:"line,omitempty"`
+	Hierarchy string `json:"hierarchy,omitempty"`
+	Message   string `json:"message"`
+}
+
+func writeJSON(w io.Writer, report *deployers.ValidationReport) error {
+	out := jsonReport{Valid: report.Valid(), Files: report.Files, Problems: []jsonProblem{}}
+	if out.Files == nil {
+		out.Files = []string{}
+	}
+	for _, problem := range report.Problems {
+		out.Problems = append(out.Problems, jsonProblem{
+			File:      problem.File,
+			Line:      problem.Position.LineNo,
+			Hierarchy: problem.Position.Hierarchy,
+			Message:   problem.Message,
+		})
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(out)
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string         `xml:"name,attr"`
+	ClassName string         `xml:"classname,attr"`
+	Failures  []junitFailure `xml:"failure"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// writeJUnit writes one test case per file, failed by each of its problems
+func writeJUnit(w io.Writer, report *deployers.ValidationReport) error {
+	suite := junitTestSuite{Name: "synapse validate", Tests: len(report.Files), Failures: len(report.Problems)}
+	cases := make(map[string]int)
+	for _, file := range report.Files {
+		cases[file] = len(suite.Cases)
+		suite.Cases = append(suite.Cases, junitTestCase{Name: file, ClassName: "synapse.validate"})
+	}
+	for _, problem := range report.Problems {
+		i, ok := cases[problem.File]
+		if !ok {
+			cases[problem.File] = len(suite.Cases)
+			i = len(suite.Cases)
+			suite.Cases = append(suite.Cases, junitTestCase{Name: problem.File, ClassName: "synapse.validate"})
+			suite.Tests++
+		}
+		text := problem.File
+		if problem.Position.LineNo > 0 {
+			text += ":" + strconv.Itoa(problem.Position.LineNo)
+		}
+		if problem.Position.Hierarchy != "" {
+			text += " (" + problem.Position.Hierarchy + ")"
+		}
+		suite.Cases[i].Failures = append(suite.Cases[i].Failures, junitFailure{Message: problem.Message, Text: text})
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(junitTestSuites{Suites: []junitTestSuite{suite}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
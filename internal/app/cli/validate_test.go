@@ -0,0 +1,161 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const validSequence = `<sequence name="main" xmlns="http://ws.apache.org/ns/synapse">
+    <call>
+        <endpoint key="backend"/>
+    </call>
+</sequence>`
+
+const validEndpoint = `<endpoint name="backend" xmlns="http://ws.apache.org/ns/synapse">
+    <http method="GET" uri-template="http://localhost:9000/backend"/>
+</endpoint>`
+
+// writeHome creates a Synapse home folder with the given files
+func writeHome(t *testing.T, files map[string]string) string {
+	t.Helper()
+	home := t.TempDir()
+	for name, data := range files {
+		path := filepath.Join(home, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(data), 0o644))
+	}
+	return home
+}
+
+func TestValidateValidHome(t *testing.T) {
+	home := writeHome(t, map[string]string{
+		"artifacts/Sequences/main.xml":    validSequence,
+		"artifacts/Endpoints/backend.xml": validEndpoint,
+		"conf/deployment.toml":            "[server]\nhostname = \"localhost\"\n",
+	})
+
+	var stdout, stderr bytes.Buffer
+	code := Validate([]string{home}, &stdout, &stderr)
+	assert.Equal(t, ExitOK, code, stderr.String())
+	assert.Equal(t, "3 files checked, 0 problems found\n", stdout.String())
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	home := writeHome(t, map[string]string{
+		"artifacts/Sequences/main.xml": validSequence,
+		"artifacts/APIs/orders.xml": `<api name="orders" context="/orders">
+    <resource methods="GET" uri-template="/{id}/{id}">
+        <inSequence><respond/></inSequence>
+    </resource>
+</api>`,
+		"conf/deployment.toml": "[server]\nhostname = \"\"\n",
+	})
+
+	var stdout, stderr bytes.Buffer
+	code := Validate([]string{home}, &stdout, &stderr)
+	assert.Equal(t, ExitInvalid, code)
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	require.Len(t, lines, 4)
+	assert.True(t, strings.HasPrefix(lines[0], "Sequences/main.xml:2: endpoint not found with reference: backend"), lines[0])
+	assert.Contains(t, lines[0], "(main->sequence->call)")
+	assert.True(t, strings.HasPrefix(lines[1], "APIs/orders.xml: invalid URI template"), lines[1])
+	assert.Equal(t, "conf/deployment.toml: server hostname cannot be empty", lines[2])
+	assert.Equal(t, "3 files checked, 3 problems found", lines[3])
+}
+
+func TestValidateArtifactsFolder(t *testing.T) {
+	home := writeHome(t, map[string]string{
+		"Sequences/main.xml": validSequence,
+	})
+
+	var stdout, stderr bytes.Buffer
+	code := Validate([]string{"-format", "json", home}, &stdout, &stderr)
+	assert.Equal(t, ExitInvalid, code)
+
+	var report jsonReport
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &report))
+	assert.False(t, report.Valid)
+	assert.Equal(t, []string{"Sequences/main.xml"}, report.Files)
+	require.Len(t, report.Problems, 1)
+	assert.Equal(t, "Sequences/main.xml", report.Problems[0].File)
+	assert.Equal(t, 2, report.Problems[0].Line)
+	assert.Equal(t, "main->sequence->call", report.Problems[0].Hierarchy)
+}
+
+func TestValidateJUnit(t *testing.T) {
+	home := writeHome(t, map[string]string{
+		"artifacts/Sequences/main.xml":  validSequence,
+		"artifacts/Sequences/other.xml": `<sequence name="other"><log/></sequence>`,
+	})
+
+	var stdout, stderr bytes.Buffer
+	code := Validate([]string{"-format", "junit", home}, &stdout, &stderr)
+	assert.Equal(t, ExitInvalid, code)
+
+	var suites junitTestSuites
+	require.NoError(t, xml.Unmarshal(stdout.Bytes(), &suites))
+	require.Len(t, suites.Suites, 1)
+	suite := suites.Suites[0]
+	assert.Equal(t, 2, suite.Tests)
+	assert.Equal(t, 1, suite.Failures)
+	require.Len(t, suite.Cases, 2)
+	assert.Equal(t, "Sequences/main.xml", suite.Cases[0].Name)
+	require.Len(t, suite.Cases[0].Failures, 1)
+	assert.Equal(t, "Sequences/main.xml:2 (main->sequence->call)", suite.Cases[0].Failures[0].Text)
+	assert.Empty(t, suite.Cases[1].Failures)
+}
+
+func TestValidateUsage(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	assert.Equal(t, ExitUsage, Validate(nil, &stdout, &stderr))
+	assert.Equal(t, ExitUsage, Validate([]string{"-format", "yaml", t.TempDir()}, &stdout, &stderr))
+	assert.Equal(t, ExitUsage, Validate([]string{filepath.Join(t.TempDir(), "missing")}, &stdout, &stderr))
+	assert.Empty(t, stdout.String())
+}
+
+func TestValidatePlaceholders(t *testing.T) {
+	t.Setenv("BACKEND_URL", "http://localhost:9000/backend")
+	home := writeHome(t, map[string]string{
+		"artifacts/Sequences/main.xml": validSequence,
+		"artifacts/Endpoints/backend.xml": `<endpoint name="backend" xmlns="http://ws.apache.org/ns/synapse">
+    <http method="${config:backend.method:-GET}" uri-template="${env:BACKEND_URL}"/>
+</endpoint>`,
+		"artifacts/Endpoints/other.xml": `<endpoint name="other" xmlns="http://ws.apache.org/ns/synapse">
+    <http method="GET" uri-template="http://${config:server.backend_host}/other"/>
+</endpoint>`,
+		"conf/deployment.toml": "[server]\nhostname = \"localhost\"\n",
+	})
+
+	var stdout, stderr bytes.Buffer
+	code := Validate([]string{home}, &stdout, &stderr)
+	assert.Equal(t, ExitInvalid, code)
+	assert.Equal(t, "Endpoints/other.xml:2: unresolved placeholder ${config:server.backend_host} in other.xml at line 2\n"+
+		"4 files checked, 1 problems found\n", stdout.String())
+}
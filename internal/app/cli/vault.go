@@ -0,0 +1,94 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/apache/synapse-go/internal/pkg/vault"
+)
+
+// Vault runs synapse vault encrypt, which encrypts the value read from stdin
+// into the secrets file of the conf folder under an alias
+func Vault(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	usage := func() {
+		fmt.Fprintln(stderr, "Usage: echo -n <value> | synapse vault encrypt [-conf <dir>] <alias>")
+	}
+	if len(args) == 0 || args[0] != "encrypt" {
+		usage()
+		return ExitUsage
+	}
+	flags := flag.NewFlagSet("vault encrypt", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	confPath := flags.String("conf", "", "conf folder holding the security folder (default ../conf from the binary)")
+	flags.Usage = func() {
+		usage()
+		flags.PrintDefaults()
+		fmt.Fprintln(stderr, "\nThe key is derived from "+vault.PassphraseEnv+" if it is set, otherwise read from the key file, which is created if needed.")
+	}
+	if err := flags.Parse(args[1:]); err != nil {
+		return ExitUsage
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return ExitUsage
+	}
+	alias := flags.Arg(0)
+
+	if *confPath == "" {
+		exePath, err := os.Executable()
+		if err != nil {
+			fmt.Fprintln(stderr, "Error getting executable path: "+err.Error())
+			return ExitUsage
+		}
+		*confPath = filepath.Join(filepath.Dir(exePath), "..", "conf")
+	}
+	// Read the value from stdin, so that it stays out of the shell history
+	data, err := io.ReadAll(stdin)
+	if err != nil {
+		fmt.Fprintln(stderr, "Error reading value: "+err.Error())
+		return ExitUsage
+	}
+	value := strings.TrimSuffix(strings.TrimSuffix(string(data), "\n"), "\r")
+	if value == "" {
+		fmt.Fprintln(stderr, "No value to encrypt on standard input")
+		return ExitUsage
+	}
+
+	securityPath := filepath.Join(*confPath, vault.SecurityFolder)
+	provider, err := vault.OpenFileProvider(securityPath)
+	if err == nil {
+		err = provider.Encrypt(alias, value)
+	}
+	if err == nil {
+		err = provider.Save()
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "Error encrypting secret: "+err.Error())
+		return ExitInvalid
+	}
+	fmt.Fprintf(stdout, "Encrypted secret %s into %s\nRefer to it as {secret:%s}\n", alias, filepath.Join(securityPath, vault.SecretsFileName), alias)
+	return ExitOK
+}
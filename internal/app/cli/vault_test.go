@@ -0,0 +1,58 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package cli
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/apache/synapse-go/internal/pkg/vault"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVaultEncrypt(t *testing.T) {
+	t.Setenv(vault.PassphraseEnv, "")
+	conf := t.TempDir()
+
+	var stdout, stderr bytes.Buffer
+	code := Vault([]string{"encrypt", "-conf", conf, "ftp_password"}, strings.NewReader("s3cr3t\n"), &stdout, &stderr)
+	require.Equal(t, ExitOK, code, stderr.String())
+	assert.Contains(t, stdout.String(), "Refer to it as {secret:ftp_password}")
+
+	provider, err := vault.OpenFileProvider(filepath.Join(conf, vault.SecurityFolder))
+	require.NoError(t, err)
+	secret, err := provider.Secret("ftp_password")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", secret)
+}
+
+func TestVaultEncryptErrors(t *testing.T) {
+	t.Setenv(vault.PassphraseEnv, "")
+	conf := t.TempDir()
+	var stdout, stderr bytes.Buffer
+	assert.Equal(t, ExitUsage, Vault(nil, strings.NewReader("x"), &stdout, &stderr))
+	assert.Equal(t, ExitUsage, Vault([]string{"decrypt", "a"}, strings.NewReader("x"), &stdout, &stderr))
+	assert.Equal(t, ExitUsage, Vault([]string{"encrypt", "-conf", conf, "a"}, strings.NewReader(""), &stdout, &stderr))
+	assert.Equal(t, ExitInvalid, Vault([]string{"encrypt", "-conf", conf, "ftp.password"}, strings.NewReader("x"), &stdout, &stderr))
+	assert.Empty(t, stdout.String())
+}
@@ -31,6 +31,18 @@ type InboundEndpoint interface {
 	Stop(ctx context.Context) error
 }
 
+// Secondary/Driven Port
+type MessageProcessor interface {
+	Start(ctx context.Context, mediator InboundMessageMediator) error
+	Stop(ctx context.Context) error
+}
+
+// Secondary/Driven Port
+type Task interface {
+	Start(ctx context.Context, mediator InboundMessageMediator) error
+	Stop(ctx context.Context) error
+}
+
 // Primary/Driving Port
 type InboundMessageMediator interface {
 	MediateInboundMessage(ctx context.Context, seqName string, msg *synctx.MsgContext) error
@@ -26,6 +26,7 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"sync"
 
 	"time"
@@ -34,11 +35,29 @@ import (
 	"github.com/apache/synapse-go/internal/pkg/config"
 	"github.com/apache/synapse-go/internal/pkg/core/artifacts"
 	"github.com/apache/synapse-go/internal/pkg/core/deployers"
+	"github.com/apache/synapse-go/internal/pkg/core/registry"
 	"github.com/apache/synapse-go/internal/pkg/core/router"
 	"github.com/apache/synapse-go/internal/pkg/core/utils"
+	"github.com/apache/synapse-go/internal/pkg/loggerfactory"
+	"github.com/apache/synapse-go/internal/pkg/management"
+	"github.com/apache/synapse-go/internal/pkg/tracing"
+	"github.com/apache/synapse-go/internal/pkg/vault"
 )
 
-func Run(ctx context.Context) error {
+// Version is the version of the server and of synapse ctl. Release builds
+// set it with -ldflags "-X github.com/apache/synapse-go/internal/app/synapse.Version=<version>".
+var Version = "dev"
+
+// Options locate the folders of a Synapse home and override its
+// configuration. Empty paths default to the folders next to the bin folder
+// of the executable.
+type Options struct {
+	ConfPath      string
+	ArtifactsPath string
+	Overrides     config.Overrides
+}
+
+func Run(ctx context.Context, opts Options) error {
 
 	start := time.Now()
 	PrintWelcomeMessage()
@@ -50,9 +69,9 @@ func Run(ctx context.Context) error {
 
 	// create instace variables
 
-	// Adding config context to the GO context
-	conCtx := artifacts.GetConfigContext()
-	ctx = context.WithValue(ctx, utils.ConfigContextKey, conCtx)
+	// Adding the config store to the GO context
+	configStore := artifacts.GetConfigStore()
+	ctx = context.WithValue(ctx, utils.ConfigContextKey, configStore)
 
 	exePath, err := os.Executable()
 	if err != nil {
@@ -60,23 +79,52 @@ func Run(ctx context.Context) error {
 	}
 
 	binDir := filepath.Dir(exePath)
-	confPath := filepath.Join(binDir, "..", "conf")
+	confPath := opts.ConfPath
+	if confPath == "" {
+		confPath = filepath.Join(binDir, "..", "conf")
+	}
+	artifactsPath := opts.ArtifactsPath
+	if artifactsPath == "" {
+		artifactsPath = filepath.Join(binDir, "..", "artifacts")
+	}
 
-	errConfig := config.InitializeConfig(ctx, confPath)
+	// Secrets can be referred to from deployment.toml, so the vault opens first
+	if err := vault.GetVault().AddFileProvider(confPath); err != nil {
+		log.Fatalf("Error opening secure vault: %s", err.Error())
+	}
+
+	errConfig := config.InitializeConfig(ctx, confPath, opts.Overrides)
 	if errConfig != nil {
 		log.Fatalf("Initialization error: %s", errConfig.Error())
 	}
+	PrintEffectiveConfig(confPath, artifactsPath, opts.Overrides.LogLevel, configStore.Snapshot().DeploymentConfig)
+
+	// Tracing is set up before anything can receive messages
+	tracingSettings, _ := configStore.Snapshot().DeploymentConfig["tracing"].(map[string]string)
+	tracingConfig, err := tracing.ParseConfig(tracingSettings)
+	if err != nil {
+		log.Fatalf("Error configuring tracing: %s", err.Error())
+	}
+	if tracingConfig.Path != "" && !filepath.IsAbs(tracingConfig.Path) {
+		tracingConfig.Path = filepath.Join(filepath.Dir(confPath), tracingConfig.Path)
+	}
+	shutdownTracing, err := tracing.Setup(ctx, tracingConfig)
+	if err != nil {
+		log.Fatalf("Error setting up tracing: %s", err.Error())
+	}
 
 	mediationEngine := mediation.NewMediationEngine()
 
 	// Define default port
 	httpServerPort := 8290
+	portOffset := 0
 	var hostname string
-	if serverConfig, ok := conCtx.DeploymentConfig["server"].(map[string]string); ok {
+	if serverConfig, ok := configStore.Snapshot().DeploymentConfig["server"].(map[string]string); ok {
 		hostname = serverConfig["hostname"]
 		if offsetStr, offsetExists := serverConfig["offset"]; offsetExists {
 			if offsetInt, err := strconv.Atoi(offsetStr); err == nil {
 				httpServerPort += offsetInt
+				portOffset = offsetInt
 				log.Printf("Using port offset: %d, final port: %d", offsetInt, httpServerPort)
 			} else {
 				log.Printf("Warning: Invalid offset value '%s', using default port", offsetStr)
@@ -90,26 +138,95 @@ func Run(ctx context.Context) error {
 	// Initialize the router service with the calculated port
 	routerService := router.NewRouterService(listenPort, hostname)
 
-	artifactsPath := filepath.Join(binDir, "..", "artifacts")
+	// The registry is in the Synapse home, the parent of the conf folder
+	configRegistry := registry.New(filepath.Join(filepath.Dir(confPath), "registry"))
+	if _, err := configStore.Update(func(next *artifacts.ConfigContext) {
+		next.Registry = configRegistry
+	}); err != nil {
+		log.Fatalf("Error configuring registry: %s", err.Error())
+	}
+
 	deployer := deployers.NewDeployer(artifactsPath, mediationEngine, routerService)
 	err = deployer.Deploy(ctx)
 	if err != nil {
 		log.Printf("Error deploying artifacts: %v", err)
 	}
 
+	// Hot deployment is enabled unless turned off in deployment.toml
+	hotDeploy := true
+	if deploymentConfig, ok := configStore.Snapshot().DeploymentConfig["deployment"].(map[string]string); ok && deploymentConfig["hot_deploy"] != "" {
+		hotDeploy, _ = strconv.ParseBool(deploymentConfig["hot_deploy"])
+	}
+	if hotDeploy {
+		if err := deployer.Watch(ctx, deployers.DefaultWatchDelay); err != nil {
+			log.Printf("Error watching artifacts for changes: %v", err)
+		}
+	}
+
 	// Start HTTP Server
 	routerService.StartServer(ctx)
 
+	// The management API has its own listener, which is off by default
+	managementSettings, _ := configStore.Snapshot().DeploymentConfig["management"].(map[string]string)
+	managementConfig, err := management.ParseConfig(managementSettings)
+	if err != nil {
+		log.Fatalf("Error configuring management API: %s", err.Error())
+	}
+	var managementServer *management.Server
+	if managementConfig.Enabled {
+		managementConfig.Port += portOffset
+		if managementConfig.Hostname == "" {
+			managementConfig.Hostname = hostname
+		}
+		for _, path := range []*string{&managementConfig.CertFile, &managementConfig.KeyFile} {
+			if *path != "" && !filepath.IsAbs(*path) {
+				*path = filepath.Join(filepath.Dir(confPath), *path)
+			}
+		}
+		managementServer = management.NewServer(managementConfig, deployer, Version)
+		if err := managementServer.Start(ctx); err != nil {
+			log.Printf("Error starting management API: %v", err)
+		}
+	}
+
 	elapsed := time.Since(start)
 	log.Printf("Server started in: %v", elapsed)
 
 	<-ctx.Done()
+	if managementServer != nil {
+		managementServer.Stop()
+	}
 	wg.Wait()
 	routerService.StopServer()
 	log.Println("HTTP server shutdown gracefully")
+
+	// Close message stores once nothing can write to them anymore
+	for name, messageStore := range configStore.Snapshot().MessageStoreMap {
+		if err := messageStore.Store.Close(); err != nil {
+			log.Printf("Error closing message store %s: %v", name, err)
+		}
+	}
+	// Export the spans that are still buffered
+	shutdownCtx, shutdownRelease := context.WithTimeout(context.Background(), 5*time.Second)
+	defer shutdownRelease()
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		log.Printf("Error exporting traces: %v", err)
+	}
+	loggerfactory.CloseFiles()
 	return nil
 }
 
+// PrintEffectiveConfig logs the resolved paths and deployment configuration,
+// after the environment and command line overrides, with secrets masked
+func PrintEffectiveConfig(confPath string, artifactsPath string, logLevel string, deploymentConfig map[string]interface{}) {
+	lines := []string{"conf = " + confPath, "artifacts = " + artifactsPath}
+	if logLevel != "" {
+		lines = append(lines, "log level = "+logLevel)
+	}
+	lines = append(lines, config.Describe(deploymentConfig)...)
+	log.Printf("Effective configuration:\n  %s", strings.Join(lines, "\n  "))
+}
+
 func PrintWelcomeMessage() {
 	colors := []string{
 		"\033[31m", // Red
@@ -25,20 +25,41 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/apache/synapse-go/internal/pkg/core/artifacts"
-	"github.com/apache/synapse-go/internal/pkg/core/utils"
+	"github.com/apache/synapse-go/internal/pkg/health"
 	"github.com/apache/synapse-go/internal/pkg/loggerfactory"
+	"github.com/apache/synapse-go/internal/pkg/management"
+	"github.com/apache/synapse-go/internal/pkg/tracing"
+	"github.com/apache/synapse-go/internal/pkg/vault"
 
 	"github.com/knadh/koanf/parsers/toml"
+	"github.com/knadh/koanf/providers/env"
 	"github.com/knadh/koanf/providers/file"
 	"github.com/knadh/koanf/v2"
 )
 
+// EnvPrefix is the prefix of the environment variables that override
+// deployment.toml keys, such as SYNAPSE_SERVER_HOSTNAME for server.hostname
+const EnvPrefix = "SYNAPSE_"
+
 // Config implements the common.ConfigProvider interface
 type Config struct {
 	koanf *koanf.Koanf
+	// logLevel replaces the level of every package when not empty
+	logLevel string
+}
+
+// Overrides holds the configuration given on the command line. It takes
+// precedence over the environment, which takes precedence over the files.
+type Overrides struct {
+	// Deployment holds deployment.toml keys, such as server.offset
+	Deployment map[string]string
+	// LogLevel sets the level of every package when not empty
+	LogLevel string
 }
 
 func ReadFile(filename string) (*Config, error) {
@@ -77,12 +98,22 @@ func (c *Config) Watch(ctx context.Context, filename string) {
 
 		// Update the logger configuration
 		var levelMap map[string]string
-		var slogHandlerConfig loggerfactory.SlogHandlerConfig
 
 		c.MustUnmarshal("logger.level.packages", &levelMap)
-		c.MustUnmarshal("logger.handler", &slogHandlerConfig)
+		slogHandlerConfig, err := c.slogHandlerConfig(filepath.Dir(filepath.Dir(filename)))
+		if err != nil {
+			log.Printf("error loading new config: %v", err)
+			return
+		}
+		defaultLevel := c.koanf.String("logger.level.default")
+		overrideLevels(levelMap, &defaultLevel, c.logLevel)
 
 		cm := loggerfactory.GetConfigManager()
+		if err := c.setMaskConfig(cm); err != nil {
+			log.Printf("error loading new config: %v", err)
+			return
+		}
+		cm.SetDefaultLevel(defaultLevel)
 		cm.SetLogLevelMap(&levelMap)
 		cm.SetSlogHandlerConfig(slogHandlerConfig)
 	})
@@ -103,15 +134,21 @@ func (c *Config) MustUnmarshal(key string, out interface{}) {
 	}
 }
 
-func InitializeConfig(ctx context.Context, confFolderPath string) error {
+// InitializeConfig loads LoggerConfig.toml and deployment.toml from
+// confFolderPath. Keys of deployment.toml are overridden by SYNAPSE_*
+// environment variables and then by overrides.
+func InitializeConfig(ctx context.Context, confFolderPath string, overrides Overrides) error {
 	files, err := os.ReadDir(confFolderPath)
-	configContext := ctx.Value(utils.ConfigContextKey).(*artifacts.ConfigContext)
 	if err != nil {
 		return err
 	}
 	if len(files) == 0 {
 		return fmt.Errorf("no config files found in %s", confFolderPath)
 	}
+	configStore, ok := artifacts.ConfigStoreFromContext(ctx)
+	if !ok {
+		return fmt.Errorf("config store not found in context")
+	}
 	// {"LoggerConfig", "deployment"}
 	for _, configurationType := range []string{"LoggerConfig", "deployment"} {
 		configFilePath := filepath.Join(confFolderPath, configurationType+".toml")
@@ -124,13 +161,23 @@ func InitializeConfig(ctx context.Context, confFolderPath string) error {
 		case "LoggerConfig":
 			var levelMap map[string]string
 			var slogHandlerConfig loggerfactory.SlogHandlerConfig
+			defaultLevel := cfg.koanf.String("logger.level.default")
 
 			if cfg.IsSet("logger") {
-				cfg.MustUnmarshal("logger.handler", &slogHandlerConfig)
+				slogHandlerConfig, err = cfg.slogHandlerConfig(filepath.Dir(confFolderPath))
+				if err != nil {
+					return err
+				}
 				cfg.MustUnmarshal("logger.level.packages", &levelMap)
 			}
+			cfg.logLevel = overrides.LogLevel
+			overrideLevels(levelMap, &defaultLevel, cfg.logLevel)
 
 			cm := loggerfactory.GetConfigManager()
+			if err := cfg.setMaskConfig(cm); err != nil {
+				return err
+			}
+			cm.SetDefaultLevel(defaultLevel)
 			cm.SetLogLevelMap(&levelMap)
 			cm.SetSlogHandlerConfig(slogHandlerConfig)
 
@@ -138,39 +185,305 @@ func InitializeConfig(ctx context.Context, confFolderPath string) error {
 			cfg.Watch(context.Background(), configFilePath)
 
 		case "deployment":
-			deploymentConfigMap := make(map[string]interface{})
-			if cfg.IsSet("server") {
-				var serverConfigMap map[string]string
-				cfg.MustUnmarshal("server", &serverConfigMap)
-
-				// Validate required hostname key
-				hostname, exists := serverConfigMap["hostname"]
-				if !exists {
-					return fmt.Errorf("missing required server configuration key: hostname")
-				}
+			if err := cfg.override(overrides.Deployment); err != nil {
+				return err
+			}
+			deploymentConfigMap, err := parseDeploymentConfig(cfg)
+			if err != nil {
+				return err
+			}
+			if _, err := configStore.Update(func(next *artifacts.ConfigContext) {
+				next.AddDeploymentConfig(deploymentConfigMap)
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
 
-				// Validate hostname value
-				if hostname == "" {
-					return fmt.Errorf("server hostname cannot be empty")
-				}
+// slogHandlerConfig reads logger.handler. Relative log file and folder
+// paths are resolved against homePath, the parent of the conf folder.
+func (c *Config) slogHandlerConfig(homePath string) (loggerfactory.SlogHandlerConfig, error) {
+	var slogHandlerConfig loggerfactory.SlogHandlerConfig
+	if err := c.Unmarshal("logger.handler", &slogHandlerConfig); err != nil {
+		return slogHandlerConfig, err
+	}
+	for _, path := range []*string{&slogHandlerConfig.File.Path, &slogHandlerConfig.Artifacts.Folder} {
+		if *path != "" && !filepath.IsAbs(*path) {
+			*path = filepath.Join(homePath, *path)
+		}
+	}
+	return slogHandlerConfig, nil
+}
 
-				// Validate offset if it exists (optional)
-				if offsetStr, hasOffset := serverConfigMap["offset"]; hasOffset && offsetStr != "" {
-					offset, err := strconv.Atoi(offsetStr)
-					if err != nil {
-						return fmt.Errorf("invalid server offset value: %s, must be an integer", offsetStr)
-					}
-					if offset < 0 {
-						return fmt.Errorf("server offset must be non-negative, got: %d", offset)
-					}
-				}
-				deploymentConfigMap["server"] = serverConfigMap
-			} else {
-				return fmt.Errorf("server configuration section is required in deployment.toml")
-			}
+// setMaskConfig applies the logger.mask rules for logged messages
+func (c *Config) setMaskConfig(cm *loggerfactory.ConfigManager) error {
+	var maskConfig loggerfactory.MaskConfig
+	if c.IsSet("logger.mask") {
+		if err := c.Unmarshal("logger.mask", &maskConfig); err != nil {
+			return err
+		}
+	}
+	return cm.SetMaskConfig(maskConfig)
+}
 
-			configContext.AddDeploymentConfig(deploymentConfigMap)
+// override layers the SYNAPSE_* environment variables and then the given
+// keys over the loaded file
+func (c *Config) override(keys map[string]string) error {
+	sections := append(slices.Clone(knownSections), c.koanf.MapKeys("")...)
+	toKey := func(name string) string {
+		return envKey(name, sections)
+	}
+	if err := c.koanf.Load(env.Provider(EnvPrefix, ".", toKey), nil); err != nil {
+		return fmt.Errorf("cannot read environment: %w", err)
+	}
+	for key, value := range keys {
+		if err := c.koanf.Set(key, value); err != nil {
+			return fmt.Errorf("cannot override %s: %w", key, err)
 		}
 	}
 	return nil
 }
+
+// knownSections are the deployment.toml sections that the server reads, which
+// environment variables can set even when the file leaves them out
+var knownSections = []string{"server", "deployment", "tracing", "health", "management"}
+
+// envKey maps an environment variable to a deployment.toml key. A double
+// underscore separates the levels of the key, so that
+// SYNAPSE_ORDER_SERVICE__BASE_URL sets order_service.base_url. Otherwise the
+// longest of sections that the name starts with is the section, and else the
+// first underscore after the prefix separates the section from the key, so
+// that SYNAPSE_DEPLOYMENT_HOT_DEPLOY sets deployment.hot_deploy. Variables
+// without a section, and the ones of synapse ctl, are ignored.
+func envKey(name string, sections []string) string {
+	name = strings.ToLower(strings.TrimPrefix(name, EnvPrefix))
+	if strings.Contains(name, "__") {
+		parts := strings.Split(name, "__")
+		if slices.Contains(parts, "") {
+			return ""
+		}
+		return strings.Join(parts, ".")
+	}
+	section := ""
+	for _, known := range sections {
+		if len(known) > len(section) && strings.HasPrefix(name, known+"_") {
+			section = known
+		}
+	}
+	if section == "" {
+		section, _, _ = strings.Cut(name, "_")
+	}
+	key := strings.TrimPrefix(name, section+"_")
+	// SYNAPSE_CTL_* configure synapse ctl, not the server
+	if section == "" || section == "ctl" || key == "" || key == name {
+		return ""
+	}
+	return section + "." + key
+}
+
+// overrideLevels sets the default level and every package in levelMap to
+// level, if not empty
+func overrideLevels(levelMap map[string]string, defaultLevel *string, level string) {
+	if level == "" {
+		return
+	}
+	*defaultLevel = level
+	for packageName := range levelMap {
+		levelMap[packageName] = level
+	}
+}
+
+// Words that mark a configuration key as holding a secret
+var secretWords = []string{"password", "secret", "token", "credential", "private"}
+
+// expandSecrets replaces the {secret:alias} references in the values of a
+// section with secrets from the vault
+func expandSecrets(section string, settings map[string]string) error {
+	for key, value := range settings {
+		expanded, err := vault.GetVault().Expand(value, nil)
+		if err != nil {
+			return fmt.Errorf("invalid %s.%s value: %w", section, key, err)
+		}
+		settings[key] = expanded
+	}
+	return nil
+}
+
+// Describe lists the keys of a deployment configuration as sorted
+// "section.key = value" lines, with the values of secrets masked
+func Describe(deploymentConfig map[string]interface{}) []string {
+	var lines []string
+	for section, values := range deploymentConfig {
+		settings, ok := values.(map[string]string)
+		if !ok {
+			continue
+		}
+		for key, value := range settings {
+			if isSecret(key) {
+				value = "********"
+			}
+			value = vault.GetVault().Mask(value)
+			lines = append(lines, section+"."+key+" = "+value)
+		}
+	}
+	slices.Sort(lines)
+	return lines
+}
+
+func isSecret(key string) bool {
+	key = strings.ToLower(key)
+	return slices.ContainsFunc(secretWords, func(word string) bool {
+		return strings.Contains(key, word)
+	})
+}
+
+// ReadDeploymentFile reads and validates a deployment.toml, with the
+// SYNAPSE_* environment variables applied, without applying it
+func ReadDeploymentFile(filename string) (map[string]interface{}, error) {
+	cfg, err := ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read config file: %w", err)
+	}
+	if err := cfg.override(nil); err != nil {
+		return nil, err
+	}
+	return parseDeploymentConfig(cfg)
+}
+
+// parseDeploymentConfig validates the sections of deployment.toml that the
+// server reads and returns every section by name
+func parseDeploymentConfig(cfg *Config) (map[string]interface{}, error) {
+	deploymentConfigMap := make(map[string]interface{})
+	if cfg.IsSet("server") {
+		var serverConfigMap map[string]string
+		if err := cfg.Unmarshal("server", &serverConfigMap); err != nil {
+			return nil, err
+		}
+		if err := expandSecrets("server", serverConfigMap); err != nil {
+			return nil, err
+		}
+
+		// Validate required hostname key
+		hostname, exists := serverConfigMap["hostname"]
+		if !exists {
+			return nil, fmt.Errorf("missing required server configuration key: hostname")
+		}
+
+		// Validate hostname value
+		if hostname == "" {
+			return nil, fmt.Errorf("server hostname cannot be empty")
+		}
+
+		// Validate offset if it exists (optional)
+		if offsetStr, hasOffset := serverConfigMap["offset"]; hasOffset && offsetStr != "" {
+			offset, err := strconv.Atoi(offsetStr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid server offset value: %s, must be an integer", offsetStr)
+			}
+			if offset < 0 {
+				return nil, fmt.Errorf("server offset must be non-negative, got: %d", offset)
+			}
+		}
+
+		// Validate correlation_header if it exists (optional)
+		if header, ok := serverConfigMap["correlation_header"]; ok && header != "" && !isHeaderName(header) {
+			return nil, fmt.Errorf("invalid server correlation_header value: %s, must be an HTTP header name", header)
+		}
+		deploymentConfigMap["server"] = serverConfigMap
+	} else {
+		return nil, fmt.Errorf("server configuration section is required in deployment.toml")
+	}
+
+	if cfg.IsSet("deployment") {
+		var deploymentSettings map[string]string
+		if err := cfg.Unmarshal("deployment", &deploymentSettings); err != nil {
+			return nil, err
+		}
+		if err := expandSecrets("deployment", deploymentSettings); err != nil {
+			return nil, err
+		}
+
+		// Validate hot_deploy if it exists (optional)
+		if hotDeploy, ok := deploymentSettings["hot_deploy"]; ok && hotDeploy != "" {
+			if _, err := strconv.ParseBool(hotDeploy); err != nil {
+				return nil, fmt.Errorf("invalid deployment hot_deploy value: %s, must be true or false", hotDeploy)
+			}
+		}
+		deploymentConfigMap["deployment"] = deploymentSettings
+	}
+
+	if cfg.IsSet("tracing") {
+		var tracingSettings map[string]string
+		if err := cfg.Unmarshal("tracing", &tracingSettings); err != nil {
+			return nil, err
+		}
+		if err := expandSecrets("tracing", tracingSettings); err != nil {
+			return nil, err
+		}
+		if _, err := tracing.ParseConfig(tracingSettings); err != nil {
+			return nil, err
+		}
+		deploymentConfigMap["tracing"] = tracingSettings
+	}
+
+	if cfg.IsSet("health") {
+		var healthSettings map[string]string
+		if err := cfg.Unmarshal("health", &healthSettings); err != nil {
+			return nil, err
+		}
+		if err := expandSecrets("health", healthSettings); err != nil {
+			return nil, err
+		}
+		if _, err := health.ParseConfig(healthSettings); err != nil {
+			return nil, err
+		}
+		deploymentConfigMap["health"] = healthSettings
+	}
+
+	if cfg.IsSet("management") {
+		var managementSettings map[string]string
+		if err := cfg.Unmarshal("management", &managementSettings); err != nil {
+			return nil, err
+		}
+		if err := expandSecrets("management", managementSettings); err != nil {
+			return nil, err
+		}
+		if _, err := management.ParseConfig(managementSettings); err != nil {
+			return nil, err
+		}
+		deploymentConfigMap["management"] = managementSettings
+	}
+
+	// The other sections are kept for artifacts to refer to as
+	// ${config:section.key}, with nested tables as dotted keys
+	for _, section := range cfg.koanf.MapKeys("") {
+		if slices.Contains(knownSections, section) {
+			continue
+		}
+		if _, ok := cfg.koanf.Get(section).(map[string]interface{}); !ok {
+			continue
+		}
+		settings := make(map[string]string)
+		for key, value := range cfg.koanf.Cut(section).All() {
+			settings[key] = fmt.Sprint(value)
+		}
+		if err := expandSecrets(section, settings); err != nil {
+			return nil, err
+		}
+		deploymentConfigMap[section] = settings
+	}
+	return deploymentConfigMap, nil
+}
+
+// isHeaderName reports whether name is a valid HTTP header name
+func isHeaderName(name string) bool {
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.IndexByte("!#$%&'*+-.^_`|~", c) >= 0) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,80 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvKey(t *testing.T) {
+	sections := append(slices.Clone(knownSections), "order_service")
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"SYNAPSE_SERVER_HOSTNAME", "server.hostname"},
+		{"SYNAPSE_DEPLOYMENT_HOT_DEPLOY", "deployment.hot_deploy"},
+		{"SYNAPSE_TRACING_SAMPLING_RATIO", "tracing.sampling_ratio"},
+		{"SYNAPSE_ORDER_SERVICE_BASE_URL", "order_service.base_url"},
+		{"SYNAPSE_PAYMENT_GATEWAY__API_KEY", "payment_gateway.api_key"},
+		{"SYNAPSE_HEALTH__ENDPOINTS__ORDER_DB", "health.endpoints.order_db"},
+		{"SYNAPSE_CUSTOM_URL", "custom.url"},
+		{"SYNAPSE_CTL_PASSWORD", ""},
+		{"SYNAPSE_HOSTNAME", ""},
+		{"SYNAPSE_SERVER_", ""},
+		{"SYNAPSE_SERVER__", ""},
+		{"SYNAPSE___KEY", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, envKey(tt.name, sections))
+		})
+	}
+}
+
+func TestReadDeploymentFile_CustomSections(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "deployment.toml")
+	require.NoError(t, os.WriteFile(filename, []byte(`
+[server]
+hostname = "localhost"
+
+[order_service]
+base_url = "http://orders.local"
+timeout = 30
+
+[order_service.retry]
+count = 3
+`), 0o644))
+	t.Setenv("SYNAPSE_ORDER_SERVICE__BASE_URL", "http://orders.prod")
+	t.Setenv("SYNAPSE_PAYMENTS_URL", "http://payments.prod")
+	t.Setenv("SYNAPSE_CTL_PASSWORD", "secret")
+
+	deploymentConfig, err := ReadDeploymentFile(filename)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"base_url": "http://orders.prod", "timeout": "30", "retry.count": "3"}, deploymentConfig["order_service"])
+	assert.Equal(t, map[string]string{"url": "http://payments.prod"}, deploymentConfig["payments"])
+	assert.NotContains(t, deploymentConfig, "ctl")
+}
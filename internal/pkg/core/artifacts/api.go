@@ -36,6 +36,7 @@ type Resource struct {
 	URITemplate   URITemplateInfo
 	InSequence    Sequence
 	FaultSequence Sequence
+	RateLimit     RateLimitConfig
 }
 
 type URITemplateInfo struct {
@@ -53,6 +54,7 @@ type API struct {
 	Resources   []Resource
 	Position    Position
 	CORSConfig  CORSConfig
+	RateLimit   RateLimitConfig
 }
 
 func (r *Resource) Mediate(context *synctx.MsgContext, ctx context.Context) bool {
@@ -20,9 +20,11 @@
 package artifacts
 
 import (
-	"sync"
+	"fmt"
+	"maps"
 
 	"github.com/apache/synapse-go/internal/pkg/core/common"
+	"github.com/apache/synapse-go/internal/pkg/core/registry"
 )
 
 // Use the Position from common package
@@ -33,12 +35,30 @@ type EndpointProvider interface {
 	GetEndpoint(epName string) *Endpoint
 }
 
+// ResourceProvider resolves local entry and registry keys to their content
+type ResourceProvider interface {
+	GetResource(key string) ([]byte, error)
+}
+
+// ConfigContext is a snapshot of the deployed configuration. Committed
+// snapshots are shared by concurrent readers and must not be modified, see
+// ConfigStore.
 type ConfigContext struct {
-	ApiMap           map[string]API
-	EndpointMap      map[string]Endpoint
-	SequenceMap      map[string]Sequence
-	InboundMap       map[string]Inbound
-	DeploymentConfig map[string]interface{}
+	// Version increases with every committed snapshot
+	Version             uint64
+	ApiMap              map[string]API
+	EndpointMap         map[string]Endpoint
+	SequenceMap         map[string]Sequence
+	InboundMap          map[string]Inbound
+	MessageStoreMap     map[string]MessageStore
+	MessageProcessorMap map[string]MessageProcessor
+	TaskMap             map[string]Task
+	ProxyServiceMap     map[string]ProxyService
+	LocalEntryMap       map[string]LocalEntry
+	TemplateMap         map[string]Template
+	DeploymentConfig    map[string]interface{}
+	// Registry resolves conf: and gov: keys, it is nil when no registry is set
+	Registry *registry.Registry
 }
 
 func (c *ConfigContext) AddAPI(api API) {
@@ -57,6 +77,30 @@ func (c *ConfigContext) AddInbound(inbound Inbound) {
 	c.InboundMap[inbound.Name] = inbound
 }
 
+func (c *ConfigContext) AddMessageStore(messageStore MessageStore) {
+	c.MessageStoreMap[messageStore.Name] = messageStore
+}
+
+func (c *ConfigContext) AddMessageProcessor(messageProcessor MessageProcessor) {
+	c.MessageProcessorMap[messageProcessor.Name] = messageProcessor
+}
+
+func (c *ConfigContext) AddTask(task Task) {
+	c.TaskMap[task.Name] = task
+}
+
+func (c *ConfigContext) AddProxyService(proxyService ProxyService) {
+	c.ProxyServiceMap[proxyService.Name] = proxyService
+}
+
+func (c *ConfigContext) AddLocalEntry(localEntry LocalEntry) {
+	c.LocalEntryMap[localEntry.Key] = localEntry
+}
+
+func (c *ConfigContext) AddTemplate(template Template) {
+	c.TemplateMap[template.Name] = template
+}
+
 func (c *ConfigContext) AddDeploymentConfig(deploymentConfig map[string]interface{}) {
 	c.DeploymentConfig = deploymentConfig
 }
@@ -69,20 +113,54 @@ func (c *ConfigContext) GetEndpoint(epName string) *Endpoint {
 	return &endpoint
 }
 
-var instance *ConfigContext
+// GetResource returns the content of a conf: or gov: registry resource, or
+// of the local entry with the given key
+func (c *ConfigContext) GetResource(key string) ([]byte, error) {
+	if registry.IsRegistryKey(key) {
+		if c.Registry == nil {
+			return nil, fmt.Errorf("registry is not configured, cannot resolve %s", key)
+		}
+		return c.Registry.Get(key)
+	}
+	localEntry, exists := c.LocalEntryMap[key]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", registry.ErrResourceNotFound, key)
+	}
+	return localEntry.Content()
+}
 
-var once sync.Once
+func newConfigContext() *ConfigContext {
+	return &ConfigContext{
+		ApiMap:              make(map[string]API),
+		EndpointMap:         make(map[string]Endpoint),
+		SequenceMap:         make(map[string]Sequence),
+		InboundMap:          make(map[string]Inbound),
+		MessageStoreMap:     make(map[string]MessageStore),
+		MessageProcessorMap: make(map[string]MessageProcessor),
+		TaskMap:             make(map[string]Task),
+		ProxyServiceMap:     make(map[string]ProxyService),
+		LocalEntryMap:       make(map[string]LocalEntry),
+		TemplateMap:         make(map[string]Template),
+		DeploymentConfig:    make(map[string]interface{}),
+	}
+}
 
-// singleton instance of the ConfigContext
-func GetConfigContext() *ConfigContext {
-	once.Do(func() {
-		instance = &ConfigContext{
-			ApiMap:           make(map[string]API),
-			EndpointMap:      make(map[string]Endpoint),
-			SequenceMap:      make(map[string]Sequence),
-			InboundMap:       make(map[string]Inbound),
-			DeploymentConfig: make(map[string]interface{}),
-		}
-	})
-	return instance
+// clone copies the maps of c so that the copy can be changed without
+// affecting readers of c. Artifacts themselves are shared.
+func (c *ConfigContext) clone() *ConfigContext {
+	return &ConfigContext{
+		Version:             c.Version,
+		ApiMap:              maps.Clone(c.ApiMap),
+		EndpointMap:         maps.Clone(c.EndpointMap),
+		SequenceMap:         maps.Clone(c.SequenceMap),
+		InboundMap:          maps.Clone(c.InboundMap),
+		MessageStoreMap:     maps.Clone(c.MessageStoreMap),
+		MessageProcessorMap: maps.Clone(c.MessageProcessorMap),
+		TaskMap:             maps.Clone(c.TaskMap),
+		ProxyServiceMap:     maps.Clone(c.ProxyServiceMap),
+		LocalEntryMap:       maps.Clone(c.LocalEntryMap),
+		TemplateMap:         maps.Clone(c.TemplateMap),
+		DeploymentConfig:    maps.Clone(c.DeploymentConfig),
+		Registry:            c.Registry,
+	}
 }
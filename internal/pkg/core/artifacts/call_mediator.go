@@ -22,39 +22,42 @@ package artifacts
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"time"
 
 	"github.com/apache/synapse-go/internal/pkg/core/synctx"
 	"github.com/apache/synapse-go/internal/pkg/core/utils"
+	"github.com/apache/synapse-go/internal/pkg/metrics"
+	"github.com/apache/synapse-go/internal/pkg/tracing"
+	"github.com/apache/synapse-go/internal/pkg/vault"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type CallMediator struct {
 	EndpointRef string
+	// Endpoint is an inline endpoint that is used instead of EndpointRef
+	Endpoint    *Endpoint
 	Position    Position
 }
 
 func (cm CallMediator) Execute(msgContext *synctx.MsgContext, ctx context.Context) (bool, error) {
-	if cm.EndpointRef == "" {
-		return false, fmt.Errorf("endpoint reference not provided in call mediator at %s", cm.Position.Hierarchy)
-	}
-
-	// Get the ConfigContext from the context
-	configContextValue := ctx.Value(utils.ConfigContextKey)
-	if configContextValue == nil {
-		return false, fmt.Errorf("config context not found in context at %s", cm.Position.Hierarchy)
-	}
-
-	configContext, ok := configContextValue.(*ConfigContext)
-	if !ok {
-		return false, fmt.Errorf("invalid config context type")
+	// The endpoint key can refer to a template parameter
+	cm.EndpointRef = msgContext.ResolveFuncParams(cm.EndpointRef)
+	endpoint := cm.Endpoint
+	if endpoint == nil {
+		var err error
+		if endpoint, err = cm.lookupEndpoint(ctx); err != nil {
+			return false, err
+		}
 	}
-
-	// Find the endpoint in the ConfigContext's EndpointMap
-	endpoint, exists := configContext.EndpointMap[cm.EndpointRef]
-	if !exists {
-		return false, fmt.Errorf("endpoint not found with reference: %s at %s", cm.EndpointRef, cm.Position.Hierarchy)
+	if endpoint.Name != "" && !GetEndpointStates().IsActive(endpoint.Name) {
+		return false, fmt.Errorf("endpoint %s is deactivated at %s", endpoint.Name, cm.Position.Hierarchy)
 	}
 
 	// Create an HTTP client for making requests
@@ -72,8 +75,17 @@ func (cm CallMediator) Execute(msgContext *synctx.MsgContext, ctx context.Contex
 		return false, fmt.Errorf("endpoint URL is empty for endpoint: %s at %s", cm.EndpointRef, cm.Position.Hierarchy)
 	}
 
+	// Convert the message to the endpoint format
+	payload, contentType, err := formatMessage(&msgContext.Message, endpoint.EndpointUrl.Format)
+	if err != nil {
+		return false, fmt.Errorf("failed to format message for endpoint %s: %v at %s", cm.EndpointRef, err, cm.Position.Hierarchy)
+	}
+	if endpoint.EndpointUrl.Format == EndpointFormatREST && bodylessMethod(method) {
+		payload = nil
+	}
+
 	// Create an io.Reader from the byte slice
-	payloadReader := bytes.NewReader(msgContext.Message.RawPayload)
+	payloadReader := bytes.NewReader(payload)
 
 	// Create request
 	req, err := http.NewRequest(method, url, payloadReader)
@@ -82,24 +94,98 @@ func (cm CallMediator) Execute(msgContext *synctx.MsgContext, ctx context.Contex
 	}
 
 	// Add content-type header from msgContext ContentType
-	req.Header.Set("Content-Type", msgContext.Message.ContentType)
+	req.Header.Set("Content-Type", contentType)
+	setSOAPAction(req, contentType, msgContext.Message.SOAPAction)
+	// Backends log the correlation ID of the request that called them
+	req.Header.Set(CorrelationHeader(ctx), msgContext.CorrelationID())
+
+	// The backend continues the trace of the message
+	ctx, span := tracing.Tracer().Start(ctx, method, trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.HTTPRequestMethodKey.String(method), semconv.URLFull(spanURL(req.URL))))
+	defer span.End()
+	tracing.Inject(ctx, req.Header)
 
 	// Execute the HTTP request
+	start := time.Now()
 	resp, err := client.Do(req)
 	if err != nil {
-		return false, fmt.Errorf("failed to execute request for endpoint %s: %v", cm.EndpointRef, err)
+		metrics.ObserveEndpointCall(endpointName(endpoint, cm.EndpointRef), 0, time.Since(start), err)
+		err = fmt.Errorf("failed to execute request for endpoint %s: %v", cm.EndpointRef, err)
+		tracing.SetError(span, errors.New(vault.GetVault().Mask(err.Error())))
+		return false, err
 	}
 	defer resp.Body.Close()
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+	if resp.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(codes.Error, resp.Status)
+	}
 
 	// Read the response body
 	bodyBytes, err := io.ReadAll(resp.Body)
+	metrics.ObserveEndpointCall(endpointName(endpoint, cm.EndpointRef), resp.StatusCode, time.Since(start), err)
 	if err != nil {
 		return false, fmt.Errorf("failed to read response body for endpoint %s: %v", cm.EndpointRef, err)
 	}
-	// Set the response body to the message context
+	// Set the response status and body to the message context
+	msgContext.Properties["HTTP_SC"] = resp.StatusCode
 	msgContext.Message.RawPayload = bodyBytes
 	msgContext.Message.ContentType = resp.Header.Get("Content-Type")
+
+	// Backend SOAP faults are mediated through the fault sequence
+	if msgContext.Message.SOAPVersion() != "" {
+		if envelope, err := msgContext.Message.Envelope(); err == nil && envelope.Fault != nil {
+			msgContext.Properties["ERROR_CODE"] = envelope.Fault.Code
+			msgContext.Properties["ERROR_MESSAGE"] = envelope.Fault.Reason
+			msgContext.Properties["ERROR_DETAIL"] = envelope.Fault.Detail
+			return false, fmt.Errorf("SOAP fault from endpoint %s: %s at %s", cm.EndpointRef, envelope.Fault.Reason, cm.Position.Hierarchy)
+		}
+	}
 	
 	// Return true to continue mediation
 	return true, nil
 }
+
+// endpointName labels the statistics of an endpoint with its name, which
+// inline endpoints may leave empty
+func endpointName(endpoint *Endpoint, ref string) string {
+	switch {
+	case endpoint.Name != "":
+		return endpoint.Name
+	case ref != "":
+		return ref
+	}
+	return "anonymous"
+}
+
+// spanURL is the URL of a request as recorded on its span, which is exported
+// from the server, without credentials and with the secrets of the vault
+// masked
+func spanURL(u *url.URL) string {
+	redacted := *u
+	redacted.User = nil
+	return vault.GetVault().Mask(redacted.String())
+}
+
+// lookupEndpoint finds the referenced endpoint in the ConfigContext
+func (cm CallMediator) lookupEndpoint(ctx context.Context) (*Endpoint, error) {
+	if cm.EndpointRef == "" {
+		return nil, fmt.Errorf("endpoint reference not provided in call mediator at %s", cm.Position.Hierarchy)
+	}
+
+	// Get the ConfigContext from the context
+	if ctx.Value(utils.ConfigContextKey) == nil {
+		return nil, fmt.Errorf("config context not found in context at %s", cm.Position.Hierarchy)
+	}
+
+	configContext, ok := ConfigFromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("invalid config context type at %s", cm.Position.Hierarchy)
+	}
+
+	// Find the endpoint in the ConfigContext's EndpointMap
+	endpoint, exists := configContext.EndpointMap[cm.EndpointRef]
+	if !exists {
+		return nil, fmt.Errorf("endpoint not found with reference: %s at %s", cm.EndpointRef, cm.Position.Hierarchy)
+	}
+	return &endpoint, nil
+}
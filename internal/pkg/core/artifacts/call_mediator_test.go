@@ -246,3 +246,73 @@ func TestCallMediatorServerErrors(t *testing.T) {
 	assert.Equal(t, "application/json", msgContext.Message.ContentType)
 	assert.Contains(t, string(msgContext.Message.RawPayload), "error")
 }
+
+// TestCallMediatorCorrelationHeader tests that the correlation ID is sent to
+// the backend in the configured header
+func TestCallMediatorCorrelationHeader(t *testing.T) {
+	var received http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	mediator := CallMediator{
+		Endpoint: &Endpoint{EndpointUrl: EndpointUrl{Method: "GET", URITemplate: server.URL}},
+		Position: Position{Hierarchy: "test.hierarchy"},
+	}
+
+	// Without a correlation ID the message ID is sent in X-Correlation-ID
+	msgContext := synctx.CreateMsgContext()
+	configContext := &ConfigContext{DeploymentConfig: map[string]interface{}{}}
+	ctx := context.WithValue(context.Background(), utils.ConfigContextKey, configContext)
+	_, err := mediator.Execute(msgContext, ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, msgContext.MessageID, received.Get(DefaultCorrelationHeader))
+
+	// The header name is set with server.correlation_header
+	msgContext = synctx.CreateMsgContext()
+	msgContext.SetCorrelationID("order-42")
+	configContext.DeploymentConfig["server"] = map[string]string{"correlation_header": "X-Request-ID"}
+	_, err = mediator.Execute(msgContext, ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "order-42", received.Get("X-Request-ID"))
+	assert.Empty(t, received.Get(DefaultCorrelationHeader))
+}
+
+// TestCallMediatorDeactivatedEndpoint tests that calls to a deactivated
+// endpoint fail without reaching the backend until it is activated again
+func TestCallMediatorDeactivatedEndpoint(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	mediator := CallMediator{
+		EndpointRef: "stockEndpoint",
+		Position:    Position{Hierarchy: "test.hierarchy"},
+	}
+	configContext := &ConfigContext{
+		EndpointMap: map[string]Endpoint{
+			"stockEndpoint": {
+				Name:        "stockEndpoint",
+				EndpointUrl: EndpointUrl{Method: "GET", URITemplate: server.URL},
+			},
+		},
+	}
+	ctx := context.WithValue(context.Background(), utils.ConfigContextKey, configContext)
+
+	GetEndpointStates().Deactivate("stockEndpoint")
+	result, err := mediator.Execute(synctx.CreateMsgContext(), ctx)
+	assert.False(t, result)
+	assert.EqualError(t, err, "endpoint stockEndpoint is deactivated at test.hierarchy")
+	assert.Equal(t, 0, calls)
+
+	GetEndpointStates().Activate("stockEndpoint")
+	result, err = mediator.Execute(synctx.CreateMsgContext(), ctx)
+	assert.True(t, result)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,88 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package artifacts
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/apache/synapse-go/internal/pkg/core/synctx"
+)
+
+// maxTemplateDepth is how many templates a message can be nested in. A
+// template that calls itself, directly or through others, fails the message
+// at this depth instead of exhausting the stack.
+const maxTemplateDepth = 64
+
+// TemplateArgument is a with-param of a call-template mediator. Expression is
+// a $func:name or $ctx:name reference that is evaluated in the caller.
+type TemplateArgument struct {
+	Name       string
+	Value      string
+	Expression string
+}
+
+// CallTemplateMediator runs a sequence template with the given parameters
+type CallTemplateMediator struct {
+	Target    string
+	Arguments []TemplateArgument
+	Position  Position
+}
+
+func (ct CallTemplateMediator) Execute(msgContext *synctx.MsgContext, ctx context.Context) (bool, error) {
+	configContext, ok := ConfigFromContext(ctx)
+	if !ok {
+		return false, fmt.Errorf("config context not found in context at %s", ct.Position.Hierarchy)
+	}
+	template, exists := configContext.TemplateMap[ct.Target]
+	if !exists {
+		return false, fmt.Errorf("template not found with reference: %s at %s", ct.Target, ct.Position.Hierarchy)
+	}
+	if template.Sequence == nil {
+		return false, fmt.Errorf("template %s is not a sequence template at %s", ct.Target, ct.Position.Hierarchy)
+	}
+
+	if len(msgContext.FuncStack) >= maxTemplateDepth {
+		err := fmt.Errorf("template %s exceeds the maximum nesting depth of %d at %s", ct.Target, maxTemplateDepth, ct.Position.Hierarchy)
+		msgContext.Properties["ERROR_MESSAGE"] = err.Error()
+		return false, err
+	}
+
+	// Arguments are evaluated before the template's parameters become visible
+	supplied := make(map[string]string, len(ct.Arguments))
+	for _, argument := range ct.Arguments {
+		supplied[argument.Name] = argument.evaluate(msgContext)
+	}
+	params, err := template.bindParameters(supplied)
+	if err != nil {
+		return false, fmt.Errorf("%v at %s", err, ct.Position.Hierarchy)
+	}
+
+	msgContext.PushFuncParams(params)
+	defer msgContext.PopFuncParams()
+	return template.Sequence.Execute(msgContext, ctx), nil
+}
+
+func (ta TemplateArgument) evaluate(msgContext *synctx.MsgContext) string {
+	if ta.Expression == "" {
+		return ta.Value
+	}
+	return evaluateExpression(ta.Expression, msgContext)
+}
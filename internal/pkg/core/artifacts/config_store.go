@@ -0,0 +1,123 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package artifacts
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/apache/synapse-go/internal/pkg/core/utils"
+)
+
+var ErrConcurrentUpdate = errors.New("configuration was updated concurrently")
+
+// ConfigAccessor gives access to the current configuration snapshot
+type ConfigAccessor interface {
+	Snapshot() *ConfigContext
+}
+
+// ConfigStore holds the current ConfigContext. Snapshots are never modified
+// once they are committed: writers take a copy with Begin, change it and swap
+// it in with Commit, while readers keep using the snapshot they loaded.
+type ConfigStore struct {
+	current atomic.Pointer[ConfigContext]
+}
+
+func NewConfigStore() *ConfigStore {
+	s := &ConfigStore{}
+	s.current.Store(newConfigContext())
+	return s
+}
+
+// Snapshot returns the current configuration. It must not be modified.
+func (s *ConfigStore) Snapshot() *ConfigContext {
+	return s.current.Load()
+}
+
+// Begin returns a copy of the current configuration with the next version
+// number, to be changed and then committed
+func (s *ConfigStore) Begin() *ConfigContext {
+	next := s.current.Load().clone()
+	next.Version++
+	return next
+}
+
+// Commit makes next the current configuration. It fails if another snapshot
+// was committed since next was created with Begin.
+func (s *ConfigStore) Commit(next *ConfigContext) error {
+	previous := s.current.Load()
+	if next.Version != previous.Version+1 || !s.current.CompareAndSwap(previous, next) {
+		return ErrConcurrentUpdate
+	}
+	return nil
+}
+
+// Update applies fn to a copy of the current configuration and commits it
+func (s *ConfigStore) Update(fn func(next *ConfigContext)) (*ConfigContext, error) {
+	next := s.Begin()
+	fn(next)
+	if err := s.Commit(next); err != nil {
+		return nil, err
+	}
+	return next, nil
+}
+
+// ConfigFromContext returns the configuration for ctx: the snapshot pinned to
+// the message being mediated, or else the current one
+func ConfigFromContext(ctx context.Context) (*ConfigContext, bool) {
+	switch value := ctx.Value(utils.ConfigContextKey).(type) {
+	case *ConfigContext:
+		return value, true
+	case ConfigAccessor:
+		return value.Snapshot(), true
+	}
+	return nil, false
+}
+
+// ConfigStoreFromContext returns the store that holds the configuration. It
+// is not available from contexts with a pinned snapshot.
+func ConfigStoreFromContext(ctx context.Context) (*ConfigStore, bool) {
+	store, ok := ctx.Value(utils.ConfigContextKey).(*ConfigStore)
+	return store, ok
+}
+
+// WithSnapshot pins the current configuration to ctx, so that a message is
+// mediated against one snapshot even if the configuration changes meanwhile
+func WithSnapshot(ctx context.Context) context.Context {
+	configContext, ok := ConfigFromContext(ctx)
+	if !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, utils.ConfigContextKey, configContext)
+}
+
+var store *ConfigStore
+
+var once sync.Once
+
+// singleton instance of the ConfigStore
+func GetConfigStore() *ConfigStore {
+	once.Do(func() {
+		store = NewConfigStore()
+	})
+	return store
+}
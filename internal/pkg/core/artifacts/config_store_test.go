@@ -0,0 +1,96 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package artifacts
+
+import (
+	"context"
+	"testing"
+
+	"github.com/apache/synapse-go/internal/pkg/core/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigStore_Commit(t *testing.T) {
+	store := NewConfigStore()
+	initial := store.Snapshot()
+	assert.Equal(t, uint64(0), initial.Version)
+
+	next := store.Begin()
+	next.AddSequence(Sequence{Name: "main"})
+	assert.Equal(t, uint64(1), next.Version)
+	assert.NotContains(t, store.Snapshot().SequenceMap, "main", "drafts are not visible before commit")
+
+	require.NoError(t, store.Commit(next))
+	assert.Same(t, next, store.Snapshot())
+	assert.Contains(t, store.Snapshot().SequenceMap, "main")
+	assert.NotContains(t, initial.SequenceMap, "main", "committed snapshots are not changed")
+}
+
+func TestConfigStore_ConcurrentUpdate(t *testing.T) {
+	store := NewConfigStore()
+	first := store.Begin()
+	second := store.Begin()
+
+	require.NoError(t, store.Commit(first))
+	assert.ErrorIs(t, store.Commit(second), ErrConcurrentUpdate)
+	assert.Same(t, first, store.Snapshot())
+}
+
+func TestConfigStore_Update(t *testing.T) {
+	store := NewConfigStore()
+	updated, err := store.Update(func(next *ConfigContext) {
+		next.AddEndpoint(Endpoint{Name: "backend"})
+	})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), updated.Version)
+	assert.Contains(t, store.Snapshot().EndpointMap, "backend")
+}
+
+func TestConfigFromContext(t *testing.T) {
+	store := NewConfigStore()
+	ctx := context.WithValue(context.Background(), utils.ConfigContextKey, store)
+
+	configContext, ok := ConfigFromContext(ctx)
+	require.True(t, ok)
+	assert.Same(t, store.Snapshot(), configContext)
+	_, ok = ConfigStoreFromContext(ctx)
+	assert.True(t, ok)
+
+	// A pinned snapshot is kept while new contexts see the updated configuration
+	pinned := WithSnapshot(ctx)
+	_, err := store.Update(func(next *ConfigContext) {
+		next.AddSequence(Sequence{Name: "main"})
+	})
+	require.NoError(t, err)
+
+	configContext, ok = ConfigFromContext(pinned)
+	require.True(t, ok)
+	assert.Equal(t, uint64(0), configContext.Version)
+	_, ok = ConfigStoreFromContext(pinned)
+	assert.False(t, ok)
+
+	configContext, _ = ConfigFromContext(ctx)
+	assert.Equal(t, uint64(1), configContext.Version)
+	assert.Contains(t, configContext.SequenceMap, "main")
+
+	_, ok = ConfigFromContext(context.Background())
+	assert.False(t, ok)
+}
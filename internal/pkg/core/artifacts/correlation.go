@@ -0,0 +1,37 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package artifacts
+
+import "context"
+
+// DefaultCorrelationHeader is the HTTP header that carries correlation IDs
+// unless server.correlation_header is set in deployment.toml
+const DefaultCorrelationHeader = "X-Correlation-ID"
+
+// CorrelationHeader returns the name of the HTTP header that carries
+// correlation IDs from clients and to backends
+func CorrelationHeader(ctx context.Context) string {
+	if configContext, ok := ConfigFromContext(ctx); ok {
+		if serverConfig, ok := configContext.DeploymentConfig["server"].(map[string]string); ok && serverConfig["correlation_header"] != "" {
+			return serverConfig["correlation_header"]
+		}
+	}
+	return DefaultCorrelationHeader
+}
@@ -22,10 +22,16 @@ package artifacts
 type Endpoint struct {
 	Name        string
 	EndpointUrl EndpointUrl
+	// Template names the endpoint template this endpoint is created from, with
+	// the values for its parameters
+	Template           string
+	TemplateParameters map[string]string
 	Position    Position
 }
 
 type EndpointUrl struct {
 	Method 		string
 	URITemplate string
+	// Format is the message format sent to the endpoint, empty to send the message as is
+	Format      string
 }
@@ -0,0 +1,100 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package artifacts
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+
+	"github.com/apache/synapse-go/internal/pkg/core/synctx"
+)
+
+// Endpoint message formats
+const (
+	EndpointFormatSOAP11 = "soap11"
+	EndpointFormatSOAP12 = "soap12"
+	EndpointFormatPOX    = "pox"
+	EndpointFormatREST   = "rest"
+)
+
+// formatMessage converts the message to the endpoint format. It returns the
+// payload and content type to send.
+func formatMessage(message *synctx.Message, format string) ([]byte, string, error) {
+	switch format {
+	case "":
+		return message.RawPayload, message.ContentType, nil
+	case EndpointFormatSOAP11, EndpointFormatSOAP12:
+		version := synctx.SOAPVersion(format)
+		envelope := synctx.NewEnvelope(version, message.RawPayload)
+		if message.SOAPVersion() != "" {
+			current, err := message.Envelope()
+			if err != nil {
+				return nil, "", err
+			}
+			if current.Version == version {
+				return message.RawPayload, message.ContentType, nil
+			}
+			if current.Fault != nil {
+				envelope = synctx.NewFaultEnvelope(version, *current.Fault)
+			} else {
+				envelope.Body = current.Body
+				envelope.Namespaces = current.Namespaces
+				envelope.Prefix = current.Prefix
+			}
+			envelope.Header = current.Header
+		}
+		return envelope.Bytes(), synctx.ContentTypeOf(version), nil
+	case EndpointFormatPOX, EndpointFormatREST:
+		if message.SOAPVersion() == "" {
+			return message.RawPayload, message.ContentType, nil
+		}
+		envelope, err := message.Envelope()
+		if err != nil {
+			return nil, "", err
+		}
+		return envelope.PayloadBytes(), synctx.POXContentType, nil
+	}
+	return nil, "", fmt.Errorf("unsupported endpoint format: %s", format)
+}
+
+// setSOAPAction adds the SOAP action to a request, as the SOAPAction header
+// for SOAP 1.1 and the action content type parameter for SOAP 1.2
+func setSOAPAction(req *http.Request, contentType string, action string) {
+	if action == "" {
+		return
+	}
+	switch synctx.SOAPVersionOf(contentType) {
+	case synctx.SOAP11:
+		req.Header.Set("SOAPAction", `"`+action+`"`)
+	case synctx.SOAP12:
+		mediaType, params, err := mime.ParseMediaType(contentType)
+		if err != nil || params["action"] != "" {
+			return
+		}
+		params["action"] = action
+		req.Header.Set("Content-Type", mime.FormatMediaType(mediaType, params))
+	}
+}
+
+// bodylessMethod reports whether REST requests with the method are sent without a payload
+func bodylessMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodDelete
+}
@@ -0,0 +1,139 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package artifacts
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/apache/synapse-go/internal/pkg/core/synctx"
+	"github.com/apache/synapse-go/internal/pkg/core/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const soap11GetQuote = `<soapenv:Envelope xmlns:soapenv="http://schemas.xmlsoap.org/soap/envelope/" xmlns:ser="http://services.samples"><soapenv:Body><ser:getQuote/></soapenv:Body></soapenv:Envelope>`
+
+func TestFormatMessage(t *testing.T) {
+	soapMessage := &synctx.Message{RawPayload: []byte(soap11GetQuote), ContentType: "text/xml"}
+	poxMessage := &synctx.Message{RawPayload: []byte("<getQuote/>"), ContentType: "application/xml"}
+
+	// SOAP 1.1 to SOAP 1.2 moves the body into a new envelope
+	payload, contentType, err := formatMessage(soapMessage, EndpointFormatSOAP12)
+	require.NoError(t, err)
+	assert.Equal(t, synctx.SOAP12ContentType, contentType)
+	envelope, err := synctx.ParseEnvelope(payload)
+	require.NoError(t, err)
+	assert.Equal(t, synctx.SOAP12, envelope.Version)
+	assert.Equal(t, "<ser:getQuote/>", string(envelope.Body))
+
+	// The same version is sent unchanged
+	payload, contentType, err = formatMessage(soapMessage, EndpointFormatSOAP11)
+	require.NoError(t, err)
+	assert.Equal(t, soap11GetQuote, string(payload))
+	assert.Equal(t, "text/xml", contentType)
+
+	// SOAP to POX extracts the body
+	payload, contentType, err = formatMessage(soapMessage, EndpointFormatPOX)
+	require.NoError(t, err)
+	assert.Equal(t, `<ser:getQuote xmlns:ser="http://services.samples"/>`, string(payload))
+	assert.Equal(t, synctx.POXContentType, contentType)
+
+	// POX to SOAP wraps the payload
+	payload, contentType, err = formatMessage(poxMessage, EndpointFormatSOAP11)
+	require.NoError(t, err)
+	assert.Equal(t, synctx.SOAP11ContentType, contentType)
+	envelope, err = synctx.ParseEnvelope(payload)
+	require.NoError(t, err)
+	assert.Equal(t, "<getQuote/>", string(envelope.Body))
+
+	// POX stays as is for rest
+	payload, contentType, err = formatMessage(poxMessage, EndpointFormatREST)
+	require.NoError(t, err)
+	assert.Equal(t, "<getQuote/>", string(payload))
+	assert.Equal(t, "application/xml", contentType)
+
+	_, _, err = formatMessage(poxMessage, "binary")
+	assert.EqualError(t, err, "unsupported endpoint format: binary")
+}
+
+func TestCallMediator_SOAP(t *testing.T) {
+	var received *http.Request
+	var receivedBody string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ := io.ReadAll(r.Body)
+		receivedBody = string(body)
+		if r.URL.Path == "/fault" {
+			w.Header().Set("Content-Type", "text/xml")
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write(synctx.NewFaultEnvelope(synctx.SOAP11, synctx.Fault{Code: "Server", Reason: "Quote service down", Detail: "<retry>later</retry>"}).Bytes())
+			return
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write([]byte("<quote/>"))
+	}))
+	defer backend.Close()
+
+	configContext := &ConfigContext{EndpointMap: map[string]Endpoint{
+		"soap11":   {Name: "soap11", EndpointUrl: EndpointUrl{Method: "POST", URITemplate: backend.URL + "/soap", Format: EndpointFormatSOAP11}},
+		"soap12":   {Name: "soap12", EndpointUrl: EndpointUrl{Method: "POST", URITemplate: backend.URL + "/soap", Format: EndpointFormatSOAP12}},
+		"restGet":  {Name: "restGet", EndpointUrl: EndpointUrl{Method: "GET", URITemplate: backend.URL + "/rest", Format: EndpointFormatREST}},
+		"faulting": {Name: "faulting", EndpointUrl: EndpointUrl{Method: "POST", URITemplate: backend.URL + "/fault"}},
+	}}
+	ctx := context.WithValue(context.Background(), utils.ConfigContextKey, configContext)
+	newMessage := func() *synctx.MsgContext {
+		msgContext := synctx.CreateMsgContext()
+		msgContext.Message.RawPayload = []byte("<getQuote/>")
+		msgContext.Message.ContentType = "application/xml"
+		msgContext.Message.SOAPAction = "urn:getQuote"
+		return msgContext
+	}
+
+	result, err := CallMediator{EndpointRef: "soap11"}.Execute(newMessage(), ctx)
+	require.NoError(t, err)
+	assert.True(t, result)
+	assert.Equal(t, `"urn:getQuote"`, received.Header.Get("SOAPAction"))
+	assert.Equal(t, "text/xml", received.Header.Get("Content-Type"))
+
+	result, err = CallMediator{EndpointRef: "soap12"}.Execute(newMessage(), ctx)
+	require.NoError(t, err)
+	assert.True(t, result)
+	assert.Equal(t, `application/soap+xml; action="urn:getQuote"`, received.Header.Get("Content-Type"))
+	assert.Empty(t, received.Header.Get("SOAPAction"))
+
+	result, err = CallMediator{EndpointRef: "restGet"}.Execute(newMessage(), ctx)
+	require.NoError(t, err)
+	assert.True(t, result)
+	assert.Equal(t, http.MethodGet, received.Method)
+	assert.Empty(t, receivedBody)
+
+	msgContext := newMessage()
+	result, err = CallMediator{EndpointRef: "faulting", Position: Position{Hierarchy: "test.hierarchy"}}.Execute(msgContext, ctx)
+	assert.False(t, result)
+	assert.EqualError(t, err, "SOAP fault from endpoint faulting: Quote service down at test.hierarchy")
+	assert.Equal(t, "Server", msgContext.Properties["ERROR_CODE"])
+	assert.Equal(t, "Quote service down", msgContext.Properties["ERROR_MESSAGE"])
+	assert.Equal(t, "<retry>later</retry>", msgContext.Properties["ERROR_DETAIL"])
+	assert.Equal(t, http.StatusInternalServerError, msgContext.Properties["HTTP_SC"])
+}
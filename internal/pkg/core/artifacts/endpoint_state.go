@@ -0,0 +1,66 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package artifacts
+
+import (
+	"sync"
+)
+
+// EndpointStates holds the endpoints deactivated at runtime, by name. Calls
+// to a deactivated endpoint fail without reaching the backend. Endpoints stay
+// deactivated when they are redeployed, until they are activated again or
+// the server restarts.
+type EndpointStates struct {
+	mu       sync.RWMutex
+	inactive map[string]bool
+}
+
+var endpointStates *EndpointStates
+
+var endpointStatesOnce sync.Once
+
+// GetEndpointStates returns the endpoint states of the server
+func GetEndpointStates() *EndpointStates {
+	endpointStatesOnce.Do(func() {
+		endpointStates = &EndpointStates{inactive: make(map[string]bool)}
+	})
+	return endpointStates
+}
+
+// Activate lets calls reach the endpoint again
+func (s *EndpointStates) Activate(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.inactive, name)
+}
+
+// Deactivate fails the calls to the endpoint
+func (s *EndpointStates) Deactivate(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inactive[name] = true
+}
+
+// IsActive reports whether calls reach the endpoint
+func (s *EndpointStates) IsActive(name string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return !s.inactive[name]
+}
@@ -0,0 +1,52 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package artifacts
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/apache/synapse-go/internal/pkg/core/synctx"
+)
+
+// evaluateExpression returns the value of a $func:name template parameter,
+// a $ctx:name property or a $trp:name transport header, or "" if it is not
+// set
+func evaluateExpression(expression string, msgContext *synctx.MsgContext) string {
+	switch {
+	case strings.HasPrefix(expression, "$func:"):
+		value, _ := msgContext.FuncParam(strings.TrimPrefix(expression, "$func:"))
+		return value
+	case strings.HasPrefix(expression, "$ctx:"):
+		if value, ok := msgContext.Property(strings.TrimPrefix(expression, "$ctx:")); ok {
+			return fmt.Sprint(value)
+		}
+	case strings.HasPrefix(expression, "$trp:"):
+		name := strings.TrimPrefix(expression, "$trp:")
+		if headers, ok := msgContext.Properties["transportHeaders"].(map[string]string); ok {
+			for key, value := range headers {
+				if strings.EqualFold(key, name) {
+					return value
+				}
+			}
+		}
+	}
+	return ""
+}
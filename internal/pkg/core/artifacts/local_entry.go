@@ -0,0 +1,70 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package artifacts
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/apache/synapse-go/internal/pkg/core/registry"
+)
+
+// Files referenced by local entries are cached and read again when they change
+var localEntryFiles = registry.NewFileCache()
+
+// LocalEntry holds a resource under a key, either inline XML or text in Value
+// or a file or URL given by Src. Remote sources are fetched at deployment,
+// while files are read on lookup so edits are picked up.
+type LocalEntry struct {
+	Key      string
+	Value    string
+	Src      string
+	Path     string
+	Position Position
+}
+
+// Load resolves Src. Relative file paths are resolved against basePath.
+func (l *LocalEntry) Load(basePath string) error {
+	if l.Src == "" {
+		return nil
+	}
+	if isRemoteURI(l.Src) {
+		content, err := fetchURI(l.Src)
+		if err != nil {
+			return fmt.Errorf("failed to fetch local entry %s from %s: %w", l.Key, l.Src, err)
+		}
+		l.Value = string(content)
+		return nil
+	}
+	path := resolveFilePath(l.Src, basePath)
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("failed to read local entry %s from %s: %w", l.Key, l.Src, err)
+	}
+	l.Path = path
+	return nil
+}
+
+// Content returns the current content of the entry
+func (l *LocalEntry) Content() ([]byte, error) {
+	if l.Path != "" {
+		return localEntryFiles.Read(l.Path)
+	}
+	return []byte(l.Value), nil
+}
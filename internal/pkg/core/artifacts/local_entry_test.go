@@ -0,0 +1,86 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package artifacts
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/apache/synapse-go/internal/pkg/core/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalEntry_LoadAndContent(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "transform.xslt")
+	require.NoError(t, os.WriteFile(path, []byte("<v1/>"), 0o644))
+
+	entry := &LocalEntry{Key: "transform", Src: "file:transform.xslt"}
+	require.NoError(t, entry.Load(dir))
+	content, err := entry.Content()
+	require.NoError(t, err)
+	assert.Equal(t, "<v1/>", string(content))
+
+	// Changes to the file are picked up on the next lookup
+	require.NoError(t, os.WriteFile(path, []byte("<v2/>"), 0o644))
+	later := time.Now().Add(time.Second)
+	require.NoError(t, os.Chtimes(path, later, later))
+	content, err = entry.Content()
+	require.NoError(t, err)
+	assert.Equal(t, "<v2/>", string(content))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("remote"))
+	}))
+	defer server.Close()
+	entry = &LocalEntry{Key: "remote", Src: server.URL}
+	require.NoError(t, entry.Load(dir))
+	assert.Equal(t, "remote", entry.Value)
+
+	entry = &LocalEntry{Key: "missing", Src: "missing.txt"}
+	assert.ErrorContains(t, entry.Load(dir), "failed to read local entry missing")
+}
+
+func TestConfigContext_GetResource(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "conf"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "conf", "endpoints.txt"), []byte("from registry"), 0o644))
+
+	configContext := &ConfigContext{
+		LocalEntryMap: map[string]LocalEntry{"greeting": {Key: "greeting", Value: "hello"}},
+		Registry:      registry.New(root),
+	}
+
+	content, err := configContext.GetResource("greeting")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(content))
+
+	content, err = configContext.GetResource("conf:/endpoints.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "from registry", string(content))
+
+	_, err = configContext.GetResource("unknown")
+	assert.ErrorIs(t, err, registry.ErrResourceNotFound)
+}
@@ -22,55 +22,148 @@ package artifacts
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
 
 	"github.com/apache/synapse-go/internal/pkg/core/synctx"
+	"github.com/apache/synapse-go/internal/pkg/loggerfactory"
 )
 
+// Log levels of the log mediator, which select what is logged besides the
+// message ID and the custom properties
+const (
+	LogLevelSimple  = "simple"  // Content type and SOAP action
+	LogLevelHeaders = "headers" // Simple and the headers
+	LogLevelFull    = "full"    // Headers and the payload
+	LogLevelCustom  = "custom"  // Only the custom properties
+)
+
+// logMediatorLoggerName names the logger of messages that carry none of
+// their own
+const logMediatorLoggerName = "mediation"
+
+// DefaultLogSeparator separates the message and the custom properties in
+// the logged message
+const DefaultLogSeparator = ", "
+
+// logCategories maps the Synapse log categories to slog levels
+var logCategories = map[string]slog.Level{
+	"TRACE": slog.LevelDebug - 4,
+	"DEBUG": slog.LevelDebug,
+	"INFO":  slog.LevelInfo,
+	"WARN":  slog.LevelWarn,
+	"ERROR": slog.LevelError,
+	"FATAL": slog.LevelError + 4,
+}
+
+// IsLogCategory reports whether category is a Synapse log category
+func IsLogCategory(category string) bool {
+	_, ok := logCategories[strings.ToUpper(category)]
+	return ok
+}
+
+// LogProperty is a custom property of a log mediator, with a fixed value
+// or an expression evaluated against the message
+type LogProperty struct {
+	Name       string
+	Value      string
+	Expression string
+}
+
 type LogMediator struct {
-	Category string
-	Message  string
-	Position Position
+	Category   string
+	Level      string
+	Separator  string
+	Message    string
+	Properties []LogProperty
+	Position   Position
 }
 
 func (lm LogMediator) Execute(context *synctx.MsgContext, ctx context.Context) (bool, error) {
-	// Log the message
-	fmt.Println(lm.Category + " : " + lm.Message)
-
-	// Print the raw payload if available
-	if len(context.Message.RawPayload) > 0 {
-		fmt.Printf("%s : Raw Payload: %s\n", lm.Category, string(context.Message.RawPayload))
-	} else {
-		fmt.Printf("%s : No raw payload available\n", lm.Category)
+	// Template parameters can be used in the category and message
+	category := strings.ToUpper(context.ResolveFuncParams(lm.Category))
+	level, ok := logCategories[category]
+	if !ok {
+		level = slog.LevelInfo
 	}
 
-	// Check if pathparams exists in properties
-	if pathParamsObj, exists := context.Properties["uriParams"]; exists {
-		// Read the pathparams (map[string]string)
-		if pathParams, ok := pathParamsObj.(map[string]string); ok {
-			// Log the pathparams
-			for key, value := range pathParams {
-				fmt.Printf("%s : Pathparam %s: %s\n", lm.Category, key, value)
-			}
+	logger := context.Logger
+	if logger == nil {
+		logger = loggerfactory.GetLogger(logMediatorLoggerName, nil)
+	}
+	if !logger.Enabled(ctx, level) {
+		return true, nil
+	}
+	masker := loggerfactory.GetConfigManager().Masker()
+
+	separator := lm.Separator
+	if separator == "" {
+		separator = DefaultLogSeparator
+	}
+	var parts []string
+	if message := context.ResolveFuncParams(lm.Message); message != "" {
+		parts = append(parts, message)
+	}
+	properties := make([]any, 0, len(lm.Properties))
+	for _, property := range lm.Properties {
+		value := property.Value
+		if property.Expression != "" {
+			value = evaluateExpression(property.Expression, context)
 		} else {
-			fmt.Printf("%s : Error casting pathparams to map[string]string\n", lm.Category)
+			value = context.ResolveFuncParams(value)
 		}
-	} else {
-		fmt.Printf("%s : Pathparams not found in properties\n", lm.Category)
+		value = masker.MaskValue(property.Name, value)
+		parts = append(parts, property.Name+" = "+value)
+		properties = append(properties, slog.String(property.Name, value))
 	}
 
-	// Check if queryparams exists in properties
-	if queryParamsObj, exists := context.Properties["queryParams"]; exists {
-		// Read the queryparams (map[string]string)
-		if queryParams, ok := queryParamsObj.(map[string]string); ok {
-			// Log the queryparams
-			for key, value := range queryParams {
-				fmt.Printf("%s : Queryparam %s: %s\n", lm.Category, key, value)
-			}
-		} else {
-			fmt.Printf("%s : Error casting queryparams to map[string]string\n", lm.Category)
+	// The message and correlation IDs are added from ctx by the logger
+	var attrs []any
+	if artifactName, ok := context.Properties["ARTIFACT_NAME"]; ok {
+		attrs = append(attrs, slog.String("artifact", fmt.Sprint(artifactName)))
+	}
+	attrs = append(attrs, slog.String("hierarchy", lm.Position.Hierarchy))
+
+	logLevel := strings.ToLower(lm.Level)
+	if logLevel != LogLevelCustom {
+		if context.Message.ContentType != "" {
+			attrs = append(attrs, slog.String("content_type", context.Message.ContentType))
+		}
+		if context.Message.SOAPAction != "" {
+			attrs = append(attrs, slog.String("soap_action", context.Message.SOAPAction))
+		}
+	}
+	if logLevel == LogLevelHeaders || logLevel == LogLevelFull {
+		transportHeaders, _ := context.Properties["transportHeaders"].(map[string]string)
+		if len(transportHeaders) > 0 {
+			attrs = append(attrs, slog.Group("transport_headers", maskedAttrs(transportHeaders, masker)...))
+		}
+		if len(context.Headers) > 0 {
+			attrs = append(attrs, slog.Group("headers", maskedAttrs(context.Headers, masker)...))
 		}
-	} else {
-		fmt.Printf("%s : Queryparams not found in properties\n", lm.Category)
 	}
+	if logLevel == LogLevelFull && len(context.Message.RawPayload) > 0 {
+		attrs = append(attrs, slog.String("payload", masker.MaskPayload(string(context.Message.RawPayload))))
+	}
+	if len(properties) > 0 {
+		attrs = append(attrs, slog.Group("properties", properties...))
+	}
+
+	logger.Log(ctx, level, strings.Join(parts, separator), attrs...)
 	return true, nil
 }
+
+// maskedAttrs returns the headers as attributes in name order
+func maskedAttrs(headers map[string]string, masker *loggerfactory.Masker) []any {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	attrs := make([]any, 0, len(names))
+	for _, name := range names {
+		attrs = append(attrs, slog.String(name, masker.MaskValue(name, headers[name])))
+	}
+	return attrs
+}
@@ -20,10 +20,15 @@
 package artifacts
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"log/slog"
 	"testing"
 
 	"github.com/apache/synapse-go/internal/pkg/core/synctx"
+	"github.com/apache/synapse-go/internal/pkg/loggerfactory"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestLogMediator_Execute(t *testing.T) {
@@ -77,3 +82,118 @@ func TestLogMediator_Execute(t *testing.T) {
 		})
 	}
 }
+
+// logRecord runs the mediator and returns the JSON record it logged, or nil
+func logRecord(t *testing.T, lm LogMediator, msgContext *synctx.MsgContext, level slog.Level) map[string]interface{} {
+	t.Helper()
+	var buf bytes.Buffer
+	msgContext.Logger = slog.New(loggerfactory.NewLevelHandler(level, slog.NewJSONHandler(&buf, nil)))
+	ctx := loggerfactory.ContextWithAttrs(context.Background(), msgContext.LogAttrs()...)
+	ok, err := lm.Execute(msgContext, ctx)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	if buf.Len() == 0 {
+		return nil
+	}
+	var record map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	return record
+}
+
+func TestLogMediator_Levels(t *testing.T) {
+	newMsgContext := func() *synctx.MsgContext {
+		msgContext := synctx.CreateMsgContext()
+		msgContext.Properties["ARTIFACT_NAME"] = "OrdersAPI"
+		msgContext.Properties["transportHeaders"] = map[string]string{"Accept": "application/json"}
+		msgContext.Message.ContentType = "application/json"
+		msgContext.Message.RawPayload = []byte(`{"id": 1}`)
+		return msgContext
+	}
+	position := Position{Hierarchy: "OrdersAPI->/orders->inSequence->log"}
+
+	msgContext := newMsgContext()
+	record := logRecord(t, LogMediator{Level: LogLevelSimple, Message: "received", Position: position}, msgContext, slog.LevelInfo)
+	assert.Equal(t, "received", record["msg"])
+	assert.Equal(t, "INFO", record["level"])
+	assert.Equal(t, msgContext.MessageID, record["message_id"])
+	assert.Equal(t, msgContext.MessageID, record["correlation_id"])
+	assert.Equal(t, "OrdersAPI", record["artifact"])
+	assert.Equal(t, position.Hierarchy, record["hierarchy"])
+	assert.Equal(t, "application/json", record["content_type"])
+	assert.NotContains(t, record, "transport_headers")
+	assert.NotContains(t, record, "payload")
+
+	record = logRecord(t, LogMediator{Level: LogLevelHeaders}, newMsgContext(), slog.LevelInfo)
+	assert.Equal(t, map[string]interface{}{"Accept": "application/json"}, record["transport_headers"])
+	assert.NotContains(t, record, "payload")
+
+	record = logRecord(t, LogMediator{Level: LogLevelFull}, newMsgContext(), slog.LevelInfo)
+	assert.Contains(t, record, "transport_headers")
+	assert.Equal(t, `{"id": 1}`, record["payload"])
+
+	record = logRecord(t, LogMediator{Level: LogLevelCustom}, newMsgContext(), slog.LevelInfo)
+	assert.NotContains(t, record, "content_type")
+	assert.Contains(t, record, "message_id")
+
+	msgContext = newMsgContext()
+	msgContext.SetCorrelationID("order-42")
+	record = logRecord(t, LogMediator{Level: LogLevelSimple}, msgContext, slog.LevelInfo)
+	assert.Equal(t, "order-42", record["correlation_id"])
+}
+
+func TestLogMediator_Properties(t *testing.T) {
+	msgContext := synctx.CreateMsgContext()
+	msgContext.Properties["orderId"] = 42
+	msgContext.Properties["transportHeaders"] = map[string]string{"X-Customer": "bob"}
+	lm := LogMediator{
+		Level:     LogLevelCustom,
+		Separator: " | ",
+		Message:   "order",
+		Properties: []LogProperty{
+			{Name: "fixed", Value: "yes"},
+			{Name: "id", Expression: "$ctx:orderId"},
+			{Name: "customer", Expression: "$trp:x-customer"},
+		},
+	}
+	record := logRecord(t, lm, msgContext, slog.LevelInfo)
+	assert.Equal(t, "order | fixed = yes | id = 42 | customer = bob", record["msg"])
+	assert.Equal(t, map[string]interface{}{"fixed": "yes", "id": "42", "customer": "bob"}, record["properties"])
+}
+
+func TestLogMediator_Category(t *testing.T) {
+	record := logRecord(t, LogMediator{Category: "DEBUG", Message: "hidden"}, synctx.CreateMsgContext(), slog.LevelInfo)
+	assert.Nil(t, record)
+
+	record = logRecord(t, LogMediator{Category: "warn"}, synctx.CreateMsgContext(), slog.LevelInfo)
+	assert.Equal(t, "WARN", record["level"])
+
+	record = logRecord(t, LogMediator{Category: "FATAL"}, synctx.CreateMsgContext(), slog.LevelInfo)
+	assert.Equal(t, "ERROR+4", record["level"])
+
+	msgContext := synctx.CreateMsgContext()
+	msgContext.PushFuncParams(map[string]string{"category": "ERROR"})
+	record = logRecord(t, LogMediator{Category: "$func:category"}, msgContext, slog.LevelInfo)
+	assert.Equal(t, "ERROR", record["level"])
+}
+
+func TestLogMediator_Masking(t *testing.T) {
+	cm := loggerfactory.GetConfigManager()
+	assert.NoError(t, cm.SetMaskConfig(loggerfactory.MaskConfig{
+		Fields:   []string{"ssn", "Authorization"},
+		Patterns: []string{`\d{4}-\d{4}-\d{4}-\d{4}`},
+	}))
+	defer cm.SetMaskConfig(loggerfactory.MaskConfig{})
+
+	msgContext := synctx.CreateMsgContext()
+	msgContext.Properties["transportHeaders"] = map[string]string{"Authorization": "Bearer abc", "Accept": "*/*"}
+	msgContext.Properties["card"] = "card 1234-5678-9012-3456"
+	msgContext.Message.RawPayload = []byte(`{"name": "bob", "ssn": "123-45-6789"}<ssn>987</ssn>`)
+	lm := LogMediator{
+		Level:      LogLevelFull,
+		Properties: []LogProperty{{Name: "card", Expression: "$ctx:card"}, {Name: "ssn", Value: "1"}},
+	}
+	record := logRecord(t, lm, msgContext, slog.LevelInfo)
+	assert.Equal(t, `{"name": "bob", "ssn": "****"}<ssn>****</ssn>`, record["payload"])
+	assert.Equal(t, map[string]interface{}{"Authorization": "****", "Accept": "*/*"}, record["transport_headers"])
+	assert.Equal(t, map[string]interface{}{"card": "card ****", "ssn": "****"}, record["properties"])
+}
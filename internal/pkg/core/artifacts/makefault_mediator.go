@@ -0,0 +1,76 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package artifacts
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"net/http"
+
+	"github.com/apache/synapse-go/internal/pkg/core/synctx"
+)
+
+// MakeFaultMediator replaces the message with a SOAP or POX fault
+type MakeFaultMediator struct {
+	// Version is soap11, soap12 or pox. An empty version uses the SOAP version
+	// of the message, or SOAP 1.1 for messages that are not SOAP.
+	Version  string
+	Code     string
+	Reason   string
+	Role     string
+	Detail   string
+	Response bool
+	Position Position
+}
+
+func (mm MakeFaultMediator) Execute(msgContext *synctx.MsgContext, ctx context.Context) (bool, error) {
+	mm.Reason = msgContext.ResolveFuncParams(mm.Reason)
+	mm.Detail = msgContext.ResolveFuncParams(mm.Detail)
+	version := synctx.SOAPVersion(mm.Version)
+	if version == "" {
+		version = msgContext.Message.SOAPVersion()
+	}
+	if version == "" {
+		version = synctx.SOAP11
+	}
+
+	if version == synctx.POX {
+		payload := []byte(mm.Detail)
+		if mm.Detail == "" {
+			var buf bytes.Buffer
+			buf.WriteString("<Exception>")
+			xml.EscapeText(&buf, []byte(mm.Reason))
+			buf.WriteString("</Exception>")
+			payload = buf.Bytes()
+		}
+		msgContext.Message.RawPayload = payload
+		msgContext.Message.ContentType = synctx.POXContentType
+	} else {
+		fault := synctx.Fault{Code: mm.Code, Reason: mm.Reason, Role: mm.Role, Detail: mm.Detail}
+		msgContext.Message.SetEnvelope(synctx.NewFaultEnvelope(version, fault))
+	}
+	msgContext.Message.SOAPAction = ""
+	msgContext.Properties["HTTP_SC"] = http.StatusInternalServerError
+	if mm.Response {
+		msgContext.Headers["http-response"] = "true"
+	}
+	return true, nil
+}
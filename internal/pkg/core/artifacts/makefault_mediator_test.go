@@ -0,0 +1,81 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package artifacts
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/apache/synapse-go/internal/pkg/core/synctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMakeFaultMediator_Execute(t *testing.T) {
+	tests := []struct {
+		name        string
+		mediator    MakeFaultMediator
+		contentType string
+		wantVersion synctx.SOAPVersion
+		wantCode    string
+	}{
+		{"explicit soap12", MakeFaultMediator{Version: "soap12", Code: "Client", Reason: "Invalid order"}, "application/json", synctx.SOAP12, "Sender"},
+		{"version of the message", MakeFaultMediator{Code: "Server", Reason: "Invalid order"}, "application/soap+xml", synctx.SOAP12, "Receiver"},
+		{"default soap11", MakeFaultMediator{Code: "Receiver", Reason: "Invalid order"}, "application/json", synctx.SOAP11, "Server"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msgContext := synctx.CreateMsgContext()
+			msgContext.Message.RawPayload = []byte(`{"order":1}`)
+			msgContext.Message.ContentType = tt.contentType
+
+			result, err := tt.mediator.Execute(msgContext, context.Background())
+			require.NoError(t, err)
+			assert.True(t, result)
+			assert.Equal(t, tt.wantVersion, msgContext.Message.SOAPVersion())
+			assert.Equal(t, http.StatusInternalServerError, msgContext.Properties["HTTP_SC"])
+
+			envelope, err := msgContext.Message.Envelope()
+			require.NoError(t, err)
+			require.NotNil(t, envelope.Fault)
+			assert.Equal(t, tt.wantCode, envelope.Fault.Code)
+			assert.Equal(t, "Invalid order", envelope.Fault.Reason)
+		})
+	}
+}
+
+func TestMakeFaultMediator_POX(t *testing.T) {
+	msgContext := synctx.CreateMsgContext()
+	mediator := MakeFaultMediator{Version: "pox", Reason: "Stock <unknown>", Response: true}
+
+	result, err := mediator.Execute(msgContext, context.Background())
+	require.NoError(t, err)
+	assert.True(t, result)
+	assert.Equal(t, "<Exception>Stock &lt;unknown&gt;</Exception>", string(msgContext.Message.RawPayload))
+	assert.Equal(t, synctx.POXContentType, msgContext.Message.ContentType)
+	assert.Equal(t, "true", msgContext.Headers["http-response"])
+
+	mediator = MakeFaultMediator{Version: "pox", Detail: "<error>missing</error>"}
+	_, err = mediator.Execute(msgContext, context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "<error>missing</error>", string(msgContext.Message.RawPayload))
+}
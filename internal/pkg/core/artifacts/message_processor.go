@@ -0,0 +1,45 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package artifacts
+
+// Message processor types
+const (
+	MessageProcessorScheduledForwarding = "scheduled-forwarding"
+	MessageProcessorSampling            = "sampling"
+)
+
+type MessageProcessor struct {
+	Name           string
+	Type           string
+	MessageStore   string
+	TargetEndpoint string
+	Parameters     []Parameter
+	Position       Position
+}
+
+// Parameter returns the value of the named parameter, or an empty string
+func (mp *MessageProcessor) Parameter(name string) string {
+	for _, param := range mp.Parameters {
+		if param.Name == name {
+			return param.Value
+		}
+	}
+	return ""
+}
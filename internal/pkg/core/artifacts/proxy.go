@@ -0,0 +1,208 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package artifacts
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/apache/synapse-go/internal/pkg/core/synctx"
+	"github.com/apache/synapse-go/internal/pkg/loggerfactory"
+)
+
+// ProxySequence is either an inline sequence or a reference to a named one.
+// References are resolved when a message arrives.
+type ProxySequence struct {
+	Key    string
+	Inline *Sequence
+}
+
+type ProxyTarget struct {
+	InSequence    ProxySequence
+	OutSequence   ProxySequence
+	FaultSequence ProxySequence
+	// Endpoint references a named endpoint, InlineEndpoint holds one defined
+	// in the proxy. An inline endpoint without a method is an address
+	// endpoint, which forwards the incoming method and REST path.
+	Endpoint       string
+	InlineEndpoint *Endpoint
+	Position       Position
+}
+
+// PublishWSDL holds the WSDL served at /services/{name}?wsdl. Key or URI is
+// loaded at deployment into Content.
+type PublishWSDL struct {
+	Key     string
+	URI     string
+	Content string
+}
+
+type ProxyService struct {
+	Name        string
+	Transports  []string
+	StartOnLoad bool
+	Target      ProxyTarget
+	WSDL        *PublishWSDL
+	Parameters  []Parameter
+	Position    Position
+}
+
+// Exposed reports whether the proxy is served over HTTP
+func (p *ProxyService) Exposed() bool {
+	if !p.StartOnLoad {
+		return false
+	}
+	for _, transport := range p.Transports {
+		if transport == "http" || transport == "https" {
+			return true
+		}
+	}
+	return false
+}
+
+// Mediate runs the in sequence, calls the target endpoint and runs the out
+// sequence on the response. The fault sequence runs if any of them fail.
+func (p *ProxyService) Mediate(msgContext *synctx.MsgContext, ctx context.Context) bool {
+	if p.mediateTarget(msgContext, ctx) {
+		return true
+	}
+	faultSequence, err := p.Target.FaultSequence.resolve(ctx, p.Position)
+	if err != nil {
+		p.logError(msgContext, ctx, err)
+		return false
+	}
+	if faultSequence == nil {
+		return false
+	}
+	return faultSequence.Execute(msgContext, ctx)
+}
+
+func (p *ProxyService) mediateTarget(msgContext *synctx.MsgContext, ctx context.Context) bool {
+	inSequence, err := p.Target.InSequence.resolve(ctx, p.Position)
+	if err != nil {
+		p.logError(msgContext, ctx, err)
+		msgContext.Properties["ERROR_MESSAGE"] = err.Error()
+		return false
+	}
+	if inSequence != nil && !inSequence.Execute(msgContext, ctx) {
+		return false
+	}
+	// A respond mediator in the in sequence replies without calling the endpoint
+	if msgContext.Headers["http-response"] == "true" {
+		return true
+	}
+	if p.Target.Endpoint == "" && p.Target.InlineEndpoint == nil {
+		return true
+	}
+
+	callMediator := CallMediator{EndpointRef: p.Target.Endpoint, Position: p.Position}
+	if p.Target.InlineEndpoint != nil {
+		endpoint := *p.Target.InlineEndpoint
+		if endpoint.EndpointUrl.Method == "" {
+			// Address endpoints pass the request method and REST path through
+			endpoint.EndpointUrl.Method, _ = msgContext.Properties["HTTP_METHOD"].(string)
+			if postfix, ok := msgContext.Properties["REST_URL_POSTFIX"].(string); ok {
+				endpoint.EndpointUrl.URITemplate = strings.TrimSuffix(endpoint.EndpointUrl.URITemplate, "/") + postfix
+			}
+		}
+		callMediator.EndpointRef = endpoint.Name
+		callMediator.Endpoint = &endpoint
+	}
+	if ok, err := callMediator.Execute(msgContext, ctx); !ok {
+		if err != nil {
+			p.logError(msgContext, ctx, err)
+			msgContext.Properties["ERROR_MESSAGE"] = err.Error()
+		}
+		return false
+	}
+
+	outSequence, err := p.Target.OutSequence.resolve(ctx, p.Position)
+	if err != nil {
+		p.logError(msgContext, ctx, err)
+		msgContext.Properties["ERROR_MESSAGE"] = err.Error()
+		return false
+	}
+	if outSequence != nil && !outSequence.Execute(msgContext, ctx) {
+		return false
+	}
+	return true
+}
+
+// logError logs an error of the mediation with the logger of the proxy
+// service that received the message
+func (p *ProxyService) logError(msgContext *synctx.MsgContext, ctx context.Context, err error) {
+	logger := msgContext.Logger
+	if logger == nil {
+		logger = loggerfactory.GetLogger("proxy", nil)
+	}
+	logger.ErrorContext(ctx, "Error mediating message", "proxy", p.Name, "error", err.Error())
+}
+
+// resolve returns the sequence to run, or nil when none is configured
+func (s ProxySequence) resolve(ctx context.Context, position Position) (*Sequence, error) {
+	if s.Inline != nil {
+		return s.Inline, nil
+	}
+	if s.Key == "" {
+		return nil, nil
+	}
+	configContext, ok := ConfigFromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("config context not found in context at %s", position.Hierarchy)
+	}
+	sequence, exists := configContext.SequenceMap[s.Key]
+	if !exists {
+		return nil, fmt.Errorf("sequence not found with reference: %s at %s", s.Key, position.Hierarchy)
+	}
+	return &sequence, nil
+}
+
+// Load reads the WSDL from its registry key or URI. Relative file paths are
+// resolved against basePath.
+func (w *PublishWSDL) Load(basePath string, resources ResourceProvider) error {
+	if w.Key != "" {
+		content, err := resources.GetResource(w.Key)
+		if err != nil {
+			return fmt.Errorf("failed to load WSDL from %s: %w", w.Key, err)
+		}
+		w.Content = string(content)
+		return nil
+	}
+	if w.URI == "" {
+		return nil
+	}
+	if isRemoteURI(w.URI) {
+		content, err := fetchURI(w.URI)
+		if err != nil {
+			return fmt.Errorf("failed to fetch WSDL from %s: %w", w.URI, err)
+		}
+		w.Content = string(content)
+		return nil
+	}
+
+	content, err := os.ReadFile(resolveFilePath(w.URI, basePath))
+	if err != nil {
+		return fmt.Errorf("failed to read WSDL from %s: %w", w.URI, err)
+	}
+	w.Content = string(content)
+	return nil
+}
@@ -0,0 +1,237 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package artifacts
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/apache/synapse-go/internal/pkg/core/synctx"
+	"github.com/apache/synapse-go/internal/pkg/core/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// traceMediator appends its name to the "trace" property and optionally fails
+type traceMediator struct {
+	name string
+	fail bool
+}
+
+func (m traceMediator) Execute(msgContext *synctx.MsgContext, ctx context.Context) (bool, error) {
+	trace, _ := msgContext.Properties["trace"].(string)
+	msgContext.Properties["trace"] = trace + m.name + ";"
+	if m.fail {
+		return false, errors.New(m.name + " failed")
+	}
+	return true, nil
+}
+
+func traceSequence(name string, fail bool) *Sequence {
+	return &Sequence{Name: name, MediatorList: []Mediator{traceMediator{name: name, fail: fail}}}
+}
+
+func newProxyTestContext(backendURL string) context.Context {
+	configContext := &ConfigContext{
+		EndpointMap: map[string]Endpoint{
+			"backend": {Name: "backend", EndpointUrl: EndpointUrl{Method: "POST", URITemplate: backendURL}},
+		},
+		SequenceMap: map[string]Sequence{
+			"namedIn": *traceSequence("namedIn", false),
+		},
+	}
+	return context.WithValue(context.Background(), utils.ConfigContextKey, configContext)
+}
+
+func TestProxyService_Mediate(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, "<getQuote/>", string(body))
+		w.Header().Set("Content-Type", "text/xml")
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte("<quote/>"))
+	}))
+	defer backend.Close()
+	ctx := newProxyTestContext(backend.URL)
+
+	proxy := ProxyService{
+		Name: "StockQuoteProxy",
+		Target: ProxyTarget{
+			InSequence:    ProxySequence{Key: "namedIn"},
+			OutSequence:   ProxySequence{Inline: traceSequence("out", false)},
+			FaultSequence: ProxySequence{Inline: traceSequence("fault", false)},
+			Endpoint:      "backend",
+		},
+		Position: Position{Hierarchy: "StockQuoteProxy"},
+	}
+
+	msgContext := synctx.CreateMsgContext()
+	msgContext.Message.RawPayload = []byte("<getQuote/>")
+	assert.True(t, proxy.Mediate(msgContext, ctx))
+	assert.Equal(t, "namedIn;out;", msgContext.Properties["trace"])
+	assert.Equal(t, "<quote/>", string(msgContext.Message.RawPayload))
+	assert.Equal(t, http.StatusAccepted, msgContext.Properties["HTTP_SC"])
+}
+
+func TestProxyService_MediateInlineAddressEndpoint(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "/orders/42", r.URL.Path)
+		assert.Equal(t, "expand=true", r.URL.RawQuery)
+		w.Write([]byte("ok"))
+	}))
+	defer backend.Close()
+
+	proxy := ProxyService{
+		Name:   "RestProxy",
+		Target: ProxyTarget{InlineEndpoint: &Endpoint{Name: "RestProxy", EndpointUrl: EndpointUrl{URITemplate: backend.URL + "/orders/"}}},
+	}
+	msgContext := synctx.CreateMsgContext()
+	msgContext.Properties["HTTP_METHOD"] = http.MethodPut
+	msgContext.Properties["REST_URL_POSTFIX"] = "/42?expand=true"
+	assert.True(t, proxy.Mediate(msgContext, newProxyTestContext("")))
+	assert.Equal(t, "ok", string(msgContext.Message.RawPayload))
+}
+
+func TestProxyService_MediateFaults(t *testing.T) {
+	ctx := newProxyTestContext("http://127.0.0.1:0")
+
+	tests := []struct {
+		name          string
+		target        ProxyTarget
+		expected      bool
+		expectedTrace string
+	}{
+		{
+			name: "in sequence failure runs fault sequence",
+			target: ProxyTarget{
+				InSequence:    ProxySequence{Inline: traceSequence("in", true)},
+				FaultSequence: ProxySequence{Inline: traceSequence("fault", false)},
+				Endpoint:      "backend",
+			},
+			expected:      true,
+			expectedTrace: "in;fault;",
+		},
+		{
+			name: "endpoint failure runs fault sequence",
+			target: ProxyTarget{
+				OutSequence:   ProxySequence{Inline: traceSequence("out", false)},
+				FaultSequence: ProxySequence{Inline: traceSequence("fault", false)},
+				Endpoint:      "backend",
+			},
+			expected:      true,
+			expectedTrace: "fault;",
+		},
+		{
+			name: "failure without fault sequence",
+			target: ProxyTarget{
+				InSequence: ProxySequence{Key: "missing"},
+			},
+			expected: false,
+		},
+		{
+			name: "failing fault sequence",
+			target: ProxyTarget{
+				InSequence:    ProxySequence{Inline: traceSequence("in", true)},
+				FaultSequence: ProxySequence{Inline: traceSequence("fault", true)},
+			},
+			expected:      false,
+			expectedTrace: "in;fault;",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proxy := ProxyService{Name: "FaultyProxy", Target: tt.target, Position: Position{Hierarchy: "FaultyProxy"}}
+			msgContext := synctx.CreateMsgContext()
+			assert.Equal(t, tt.expected, proxy.Mediate(msgContext, ctx))
+			if tt.expectedTrace != "" {
+				assert.Equal(t, tt.expectedTrace, msgContext.Properties["trace"])
+			}
+		})
+	}
+}
+
+func TestProxyService_MediateLogsErrors(t *testing.T) {
+	ctx := newProxyTestContext("http://127.0.0.1:0")
+	var logs bytes.Buffer
+	proxy := ProxyService{Name: "FaultyProxy", Target: ProxyTarget{InSequence: ProxySequence{Key: "missing"}}, Position: Position{Hierarchy: "FaultyProxy"}}
+	msgContext := synctx.CreateMsgContext()
+	msgContext.Logger = slog.New(slog.NewTextHandler(&logs, nil))
+
+	assert.False(t, proxy.Mediate(msgContext, ctx))
+	assert.Contains(t, logs.String(), "level=ERROR msg=\"Error mediating message\" proxy=FaultyProxy")
+	assert.Contains(t, logs.String(), msgContext.Properties["ERROR_MESSAGE"])
+}
+
+func TestProxyService_RespondSkipsEndpoint(t *testing.T) {
+	proxy := ProxyService{
+		Name: "EchoProxy",
+		Target: ProxyTarget{
+			InSequence: ProxySequence{Inline: &Sequence{MediatorList: []Mediator{RespondMediator{}}}},
+			Endpoint:   "missing",
+		},
+	}
+	msgContext := synctx.CreateMsgContext()
+	assert.True(t, proxy.Mediate(msgContext, newProxyTestContext("")))
+}
+
+func TestProxyService_Exposed(t *testing.T) {
+	assert.True(t, (&ProxyService{Transports: []string{"https", "jms"}, StartOnLoad: true}).Exposed())
+	assert.False(t, (&ProxyService{Transports: []string{"jms"}, StartOnLoad: true}).Exposed())
+	assert.False(t, (&ProxyService{Transports: []string{"http"}, StartOnLoad: false}).Exposed())
+}
+
+func TestPublishWSDL_Load(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "service.wsdl"), []byte("<definitions/>"), 0o644))
+
+	resources := &ConfigContext{LocalEntryMap: map[string]LocalEntry{"ordersWSDL": {Key: "ordersWSDL", Value: "<orders/>"}}}
+
+	wsdl := &PublishWSDL{URI: "file:service.wsdl"}
+	require.NoError(t, wsdl.Load(dir, resources))
+	assert.Equal(t, "<definitions/>", wsdl.Content)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<remote/>"))
+	}))
+	defer server.Close()
+	wsdl = &PublishWSDL{URI: server.URL}
+	require.NoError(t, wsdl.Load(dir, resources))
+	assert.Equal(t, "<remote/>", wsdl.Content)
+
+	wsdl = &PublishWSDL{URI: "missing.wsdl"}
+	assert.ErrorContains(t, wsdl.Load(dir, resources), "failed to read WSDL from missing.wsdl")
+
+	wsdl = &PublishWSDL{Key: "ordersWSDL"}
+	require.NoError(t, wsdl.Load(dir, resources))
+	assert.Equal(t, "<orders/>", wsdl.Content)
+
+	wsdl = &PublishWSDL{Key: "conf:/wsdl/orders.wsdl"}
+	assert.ErrorContains(t, wsdl.Load(dir, resources), "registry is not configured")
+}
@@ -0,0 +1,171 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package artifacts
+
+import (
+	"strings"
+
+	"github.com/apache/synapse-go/internal/pkg/core/registry"
+)
+
+// Kinds of artifacts that can be referenced by name
+const (
+	ReferenceSequence     = "sequence"
+	ReferenceEndpoint     = "endpoint"
+	ReferenceTemplate     = "template"
+	ReferenceMessageStore = "message store"
+	ReferenceLocalEntry   = "local entry"
+)
+
+// Message processor parameters that name a sequence
+var processorSequenceParameters = []string{
+	"sequence",
+	"message.processor.reply.sequence",
+	"message.processor.fault.sequence",
+}
+
+// processorStoreParameters are the processor parameters that name a message
+// store
+var processorStoreParameters = []string{
+	"message.processor.dead.letter.store",
+}
+
+// Reference is a use of another artifact by its name. Position locates the
+// element that holds the reference.
+type Reference struct {
+	Kind     string
+	Key      string
+	Position Position
+}
+
+// Referrer is implemented by mediators that refer to other artifacts
+type Referrer interface {
+	References() []Reference
+}
+
+// appendReference adds a reference unless the key is empty or is only known
+// at runtime, such as a template parameter
+func appendReference(references []Reference, kind string, key string, position Position) []Reference {
+	if key == "" || strings.ContainsAny(key, "${") {
+		return references
+	}
+	return append(references, Reference{Kind: kind, Key: key, Position: position})
+}
+
+func (cm CallMediator) References() []Reference {
+	if cm.Endpoint != nil {
+		return nil
+	}
+	return appendReference(nil, ReferenceEndpoint, cm.EndpointRef, cm.Position)
+}
+
+func (sm StoreMediator) References() []Reference {
+	references := appendReference(nil, ReferenceMessageStore, sm.MessageStore, sm.Position)
+	return appendReference(references, ReferenceSequence, sm.Sequence, sm.Position)
+}
+
+func (ct CallTemplateMediator) References() []Reference {
+	return appendReference(nil, ReferenceTemplate, ct.Target, ct.Position)
+}
+
+func (tm ThrottleMediator) References() []Reference {
+	var references []Reference
+	for _, sequence := range []*Sequence{tm.OnAccept, tm.OnReject} {
+		if sequence != nil {
+			references = append(references, sequence.References()...)
+		}
+	}
+	return references
+}
+
+// References returns the references of the mediators in the sequence
+func (s *Sequence) References() []Reference {
+	var references []Reference
+	for _, mediator := range s.MediatorList {
+		if referrer, ok := mediator.(Referrer); ok {
+			references = append(references, referrer.References()...)
+		}
+	}
+	return references
+}
+
+func (a *API) References() []Reference {
+	var references []Reference
+	for _, resource := range a.Resources {
+		references = append(references, resource.InSequence.References()...)
+		references = append(references, resource.FaultSequence.References()...)
+	}
+	return references
+}
+
+func (p *ProxyService) References() []Reference {
+	var references []Reference
+	for _, sequence := range []ProxySequence{p.Target.InSequence, p.Target.OutSequence, p.Target.FaultSequence} {
+		if sequence.Inline != nil {
+			references = append(references, sequence.Inline.References()...)
+		} else {
+			references = appendReference(references, ReferenceSequence, sequence.Key, p.Target.Position)
+		}
+	}
+	if p.Target.InlineEndpoint == nil {
+		references = appendReference(references, ReferenceEndpoint, p.Target.Endpoint, p.Target.Position)
+	}
+	if p.WSDL != nil && !registry.IsRegistryKey(p.WSDL.Key) {
+		references = appendReference(references, ReferenceLocalEntry, p.WSDL.Key, p.Position)
+	}
+	return references
+}
+
+func (i *Inbound) References() []Reference {
+	return appendReference(nil, ReferenceSequence, i.Sequence, i.Position)
+}
+
+func (t *Task) References() []Reference {
+	return appendReference(nil, ReferenceSequence, t.Sequence, t.Position)
+}
+
+func (mp *MessageProcessor) References() []Reference {
+	references := appendReference(nil, ReferenceMessageStore, mp.MessageStore, mp.Position)
+	references = appendReference(references, ReferenceEndpoint, mp.TargetEndpoint, mp.Position)
+	for _, parameter := range mp.Parameters {
+		for _, name := range processorSequenceParameters {
+			if parameter.Name == name {
+				references = appendReference(references, ReferenceSequence, parameter.Value, mp.Position)
+			}
+		}
+		for _, name := range processorStoreParameters {
+			if parameter.Name == name {
+				references = appendReference(references, ReferenceMessageStore, parameter.Value, mp.Position)
+			}
+		}
+	}
+	return references
+}
+
+func (e *Endpoint) References() []Reference {
+	return appendReference(nil, ReferenceTemplate, e.Template, e.Position)
+}
+
+func (t *Template) References() []Reference {
+	if t.Sequence == nil {
+		return nil
+	}
+	return t.Sequence.References()
+}
@@ -0,0 +1,93 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package artifacts
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSequence_References(t *testing.T) {
+	callPosition := Position{FileName: "main.xml", LineNo: 3}
+	storePosition := Position{FileName: "main.xml", LineNo: 7}
+	sequence := Sequence{
+		Name: "main",
+		MediatorList: []Mediator{
+			CallMediator{EndpointRef: "backend", Position: callPosition},
+			// Inline endpoints and template parameters are not references
+			CallMediator{Endpoint: &Endpoint{Name: "inline"}},
+			CallMediator{EndpointRef: "$func:target"},
+			ThrottleMediator{OnReject: &Sequence{MediatorList: []Mediator{
+				StoreMediator{MessageStore: "rejected", Sequence: "onStore", Position: storePosition},
+			}}},
+			CallTemplateMediator{Target: "greet", Position: callPosition},
+			LogMediator{},
+		},
+	}
+
+	assert.Equal(t, []Reference{
+		{Kind: ReferenceEndpoint, Key: "backend", Position: callPosition},
+		{Kind: ReferenceMessageStore, Key: "rejected", Position: storePosition},
+		{Kind: ReferenceSequence, Key: "onStore", Position: storePosition},
+		{Kind: ReferenceTemplate, Key: "greet", Position: callPosition},
+	}, sequence.References())
+}
+
+func TestProxyService_References(t *testing.T) {
+	targetPosition := Position{FileName: "proxy.xml", LineNo: 2}
+	proxy := ProxyService{
+		Name: "OrderProxy",
+		Target: ProxyTarget{
+			InSequence:  ProxySequence{Key: "validate"},
+			OutSequence: ProxySequence{Inline: &Sequence{MediatorList: []Mediator{CallMediator{EndpointRef: "audit"}}}},
+			Endpoint:    "orders",
+			Position:    targetPosition,
+		},
+		WSDL: &PublishWSDL{Key: "conf:/wsdl/orders.wsdl"},
+	}
+
+	assert.Equal(t, []Reference{
+		{Kind: ReferenceSequence, Key: "validate", Position: targetPosition},
+		{Kind: ReferenceEndpoint, Key: "audit"},
+		{Kind: ReferenceEndpoint, Key: "orders", Position: targetPosition},
+	}, proxy.References())
+}
+
+func TestMessageProcessor_References(t *testing.T) {
+	position := Position{FileName: "forwarder.xml", LineNo: 1}
+	processor := MessageProcessor{
+		MessageStore:   "orders",
+		TargetEndpoint: "backend",
+		Parameters: []Parameter{
+			{Name: "interval", Value: "1000"},
+			{Name: "message.processor.fault.sequence", Value: "onFault"},
+			{Name: "message.processor.dead.letter.store", Value: "ordersDLQ"},
+		},
+		Position: position,
+	}
+
+	assert.Equal(t, []Reference{
+		{Kind: ReferenceMessageStore, Key: "orders", Position: position},
+		{Kind: ReferenceEndpoint, Key: "backend", Position: position},
+		{Kind: ReferenceSequence, Key: "onFault", Position: position},
+		{Kind: ReferenceMessageStore, Key: "ordersDLQ", Position: position},
+	}, processor.References())
+}
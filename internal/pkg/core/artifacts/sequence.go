@@ -33,8 +33,10 @@ type Sequence struct {
 }
 
 func (v *Sequence) Execute(context *synctx.MsgContext, ctx context.Context) bool {
+	ctx, span := startSequenceSpan(ctx, v)
+	defer span.End()
 	for _, mediator := range v.MediatorList {
-		result, err := mediator.Execute(context, ctx)
+		result, err := executeMediator(mediator, context, ctx)
 		if !result {
 			return false
 		}
@@ -0,0 +1,62 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package artifacts
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/apache/synapse-go/internal/pkg/core/synctx"
+)
+
+// StoreMediator serialises the message into a message store. If Sequence is
+// set, it runs on the message before it is stored.
+type StoreMediator struct {
+	MessageStore string
+	Sequence     string
+	Position     Position
+}
+
+func (sm StoreMediator) Execute(context *synctx.MsgContext, ctx context.Context) (bool, error) {
+	configContext, ok := ConfigFromContext(ctx)
+	if !ok {
+		return false, fmt.Errorf("config context not found in context at %s", sm.Position.Hierarchy)
+	}
+
+	messageStore, exists := configContext.MessageStoreMap[sm.MessageStore]
+	if !exists || messageStore.Store == nil {
+		return false, fmt.Errorf("message store not found with reference: %s at %s", sm.MessageStore, sm.Position.Hierarchy)
+	}
+
+	if sm.Sequence != "" {
+		sequence, exists := configContext.SequenceMap[sm.Sequence]
+		if !exists {
+			return false, fmt.Errorf("sequence not found with reference: %s at %s", sm.Sequence, sm.Position.Hierarchy)
+		}
+		if !sequence.Execute(context, ctx) {
+			return false, nil
+		}
+	}
+
+	if err := messageStore.Store.Store(context); err != nil {
+		return false, fmt.Errorf("failed to store message in %s at %s: %w", sm.MessageStore, sm.Position.Hierarchy, err)
+	}
+	return true, nil
+}
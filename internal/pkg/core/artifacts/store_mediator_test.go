@@ -0,0 +1,63 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package artifacts
+
+import (
+	"context"
+	"testing"
+
+	"github.com/apache/synapse-go/internal/pkg/core/messagestore"
+	"github.com/apache/synapse-go/internal/pkg/core/synctx"
+	"github.com/apache/synapse-go/internal/pkg/core/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStoreMediator_Execute(t *testing.T) {
+	store := messagestore.NewMemoryStore("OrdersStore")
+	configContext := &ConfigContext{
+		MessageStoreMap: map[string]MessageStore{
+			"OrdersStore": {Name: "OrdersStore", Store: store},
+		},
+		SequenceMap: map[string]Sequence{},
+	}
+	ctx := context.WithValue(context.Background(), utils.ConfigContextKey, configContext)
+
+	msgContext := synctx.CreateMsgContext()
+	msgContext.Message.RawPayload = []byte(`{"order":1}`)
+
+	mediator := StoreMediator{MessageStore: "OrdersStore", Position: Position{Hierarchy: "test.hierarchy"}}
+	result, err := mediator.Execute(msgContext, ctx)
+	assert.NoError(t, err)
+	assert.True(t, result)
+
+	entry, err := store.Peek()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"order":1}`, string(entry.Context.Message.RawPayload))
+
+	missing := StoreMediator{MessageStore: "Missing", Position: Position{Hierarchy: "test.hierarchy"}}
+	result, err = missing.Execute(msgContext, ctx)
+	assert.False(t, result)
+	assert.EqualError(t, err, "message store not found with reference: Missing at test.hierarchy")
+
+	withSequence := StoreMediator{MessageStore: "OrdersStore", Sequence: "Missing", Position: Position{Hierarchy: "test.hierarchy"}}
+	result, err = withSequence.Execute(msgContext, ctx)
+	assert.False(t, result)
+	assert.EqualError(t, err, "sequence not found with reference: Missing at test.hierarchy")
+}
@@ -0,0 +1,65 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package artifacts
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// TaskTrigger describes when a task runs. Exactly one of Interval and Cron is
+// set. A Count below 1 repeats the task forever.
+type TaskTrigger struct {
+	Interval time.Duration
+	Cron     string
+	Count    int
+}
+
+type Task struct {
+	Name        string
+	Sequence    string
+	Trigger     TaskTrigger
+	Payload     string
+	ContentType string
+	Position    Position
+}
+
+var cronParser = cron.NewParser(cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// ParseCronSchedule parses a cron expression with an optional seconds field.
+// Quartz expressions with a trailing year field are accepted as long as the
+// year is a wildcard.
+func ParseCronSchedule(expression string) (cron.Schedule, error) {
+	fields := strings.Fields(expression)
+	if len(fields) == 7 {
+		if fields[6] != "*" && fields[6] != "?" {
+			return nil, fmt.Errorf("unsupported year field in cron expression '%s'", expression)
+		}
+		fields = fields[:6]
+	}
+	schedule, err := cronParser.Parse(strings.Join(fields, " "))
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron expression '%s': %w", expression, err)
+	}
+	return schedule, nil
+}
@@ -0,0 +1,123 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package artifacts
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// templatePlaceholderPattern matches $name and {uri.var.name} placeholders in
+// endpoint templates
+var templatePlaceholderPattern = regexp.MustCompile(`\$([A-Za-z_][A-Za-z0-9_-]*)|\{uri\.var\.([A-Za-z_][A-Za-z0-9_-]*)\}`)
+
+type TemplateParameter struct {
+	Name         string
+	DefaultValue string
+	Mandatory    bool
+}
+
+// Template is a parameterised sequence or endpoint. Exactly one of Sequence
+// and Endpoint is set.
+type Template struct {
+	Name       string
+	Parameters []TemplateParameter
+	Sequence   *Sequence
+	Endpoint   *Endpoint
+	Position   Position
+}
+
+func (t *Template) declares(name string) bool {
+	for _, param := range t.Parameters {
+		if param.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// bindParameters applies default values to the supplied parameters and checks
+// that mandatory parameters are given and unknown ones are not
+func (t *Template) bindParameters(supplied map[string]string) (map[string]string, error) {
+	for name := range supplied {
+		if !t.declares(name) {
+			return nil, fmt.Errorf("parameter %s is not declared by template %s", name, t.Name)
+		}
+	}
+	params := make(map[string]string, len(t.Parameters))
+	for _, param := range t.Parameters {
+		value, ok := supplied[param.Name]
+		if !ok {
+			if param.Mandatory {
+				return nil, fmt.Errorf("mandatory parameter %s of template %s is not set", param.Name, t.Name)
+			}
+			value = param.DefaultValue
+		}
+		params[param.Name] = value
+	}
+	return params, nil
+}
+
+// ExpandEndpoint creates the endpoint described by ref, an endpoint that
+// refers to this template. The name and uri of ref are available as $name and
+// $uri even when the template does not declare them.
+func (t *Template) ExpandEndpoint(ref Endpoint) (Endpoint, error) {
+	if t.Endpoint == nil {
+		return Endpoint{}, fmt.Errorf("template %s is not an endpoint template", t.Name)
+	}
+	supplied := make(map[string]string, len(ref.TemplateParameters))
+	implicit := map[string]string{"name": ref.Name}
+	for name, value := range ref.TemplateParameters {
+		if (name == "name" || name == "uri") && !t.declares(name) {
+			implicit[name] = value
+			continue
+		}
+		supplied[name] = value
+	}
+	params, err := t.bindParameters(supplied)
+	if err != nil {
+		return Endpoint{}, err
+	}
+	for name, value := range implicit {
+		if _, ok := params[name]; !ok {
+			params[name] = value
+		}
+	}
+
+	expand := func(s string) string {
+		return templatePlaceholderPattern.ReplaceAllStringFunc(s, func(placeholder string) string {
+			name := strings.TrimPrefix(placeholder, "$")
+			if strings.HasPrefix(placeholder, "{") {
+				name = strings.TrimSuffix(strings.TrimPrefix(placeholder, "{uri.var."), "}")
+			}
+			if value, ok := params[name]; ok {
+				return value
+			}
+			return placeholder
+		})
+	}
+	endpoint := *t.Endpoint
+	endpoint.Name = ref.Name
+	endpoint.EndpointUrl.Method = expand(endpoint.EndpointUrl.Method)
+	endpoint.EndpointUrl.URITemplate = expand(endpoint.EndpointUrl.URITemplate)
+	endpoint.Position = ref.Position
+	return endpoint, nil
+}
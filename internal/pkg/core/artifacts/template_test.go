@@ -0,0 +1,165 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package artifacts
+
+import (
+	"context"
+	"testing"
+
+	"github.com/apache/synapse-go/internal/pkg/core/synctx"
+	"github.com/apache/synapse-go/internal/pkg/core/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// funcParamMediator appends text with template parameters resolved to the
+// "trace" property
+type funcParamMediator struct {
+	text string
+}
+
+func (m funcParamMediator) Execute(msgContext *synctx.MsgContext, ctx context.Context) (bool, error) {
+	trace, _ := msgContext.Properties["trace"].(string)
+	msgContext.Properties["trace"] = trace + msgContext.ResolveFuncParams(m.text) + ";"
+	return true, nil
+}
+
+func TestCallTemplateMediator_Execute(t *testing.T) {
+	configContext := &ConfigContext{
+		TemplateMap: map[string]Template{
+			"greet": {
+				Name: "greet",
+				Parameters: []TemplateParameter{
+					{Name: "name", Mandatory: true},
+					{Name: "greeting", DefaultValue: "Hello"},
+				},
+				Sequence: &Sequence{MediatorList: []Mediator{
+					funcParamMediator{text: "$func:greeting $func:name"},
+				}},
+			},
+			"outer": {
+				Name:       "outer",
+				Parameters: []TemplateParameter{{Name: "who"}},
+				Sequence: &Sequence{MediatorList: []Mediator{
+					CallTemplateMediator{Target: "greet", Arguments: []TemplateArgument{
+						{Name: "name", Expression: "$func:who"},
+						{Name: "greeting", Expression: "$ctx:greeting"},
+					}},
+					funcParamMediator{text: "after $func:who"},
+				}},
+			},
+			"ep": {Name: "ep", Endpoint: &Endpoint{}},
+			"loop": {
+				Name: "loop",
+				Sequence: &Sequence{MediatorList: []Mediator{
+					CallTemplateMediator{Target: "loop", Position: Position{Hierarchy: "loop->call-template"}},
+				}},
+			},
+		},
+	}
+	ctx := context.WithValue(context.Background(), utils.ConfigContextKey, configContext)
+
+	msgContext := synctx.CreateMsgContext()
+	mediator := CallTemplateMediator{Target: "greet", Arguments: []TemplateArgument{{Name: "name", Value: "Ada"}}}
+	result, err := mediator.Execute(msgContext, ctx)
+	require.NoError(t, err)
+	assert.True(t, result)
+	assert.Equal(t, "Hello Ada;", msgContext.Properties["trace"])
+	assert.Empty(t, msgContext.FuncStack)
+
+	msgContext = synctx.CreateMsgContext()
+	msgContext.Properties["greeting"] = "Hi"
+	mediator = CallTemplateMediator{Target: "outer", Arguments: []TemplateArgument{{Name: "who", Value: "Grace"}}}
+	result, err = mediator.Execute(msgContext, ctx)
+	require.NoError(t, err)
+	assert.True(t, result)
+	assert.Equal(t, "Hi Grace;after Grace;", msgContext.Properties["trace"])
+
+	// A recursive template fails the message instead of overflowing the stack
+	msgContext = synctx.CreateMsgContext()
+	result, err = CallTemplateMediator{Target: "loop"}.Execute(msgContext, ctx)
+	require.NoError(t, err)
+	assert.False(t, result)
+	assert.Equal(t, "template loop exceeds the maximum nesting depth of 64 at loop->call-template", msgContext.Properties["ERROR_MESSAGE"])
+	assert.Empty(t, msgContext.FuncStack)
+
+	tests := []struct {
+		name     string
+		mediator CallTemplateMediator
+		errMsg   string
+	}{
+		{"missing template", CallTemplateMediator{Target: "missing", Position: Position{Hierarchy: "api->sequence"}}, "template not found with reference: missing at api->sequence"},
+		{"endpoint template", CallTemplateMediator{Target: "ep"}, "template ep is not a sequence template"},
+		{"mandatory parameter", CallTemplateMediator{Target: "greet"}, "mandatory parameter name of template greet is not set"},
+		{"unknown parameter", CallTemplateMediator{Target: "greet", Arguments: []TemplateArgument{{Name: "name", Value: "a"}, {Name: "age", Value: "1"}}}, "parameter age is not declared by template greet"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.mediator.Execute(synctx.CreateMsgContext(), ctx)
+			assert.False(t, result)
+			assert.ErrorContains(t, err, tt.errMsg)
+		})
+	}
+}
+
+func TestTemplate_ExpandEndpoint(t *testing.T) {
+	template := Template{
+		Name: "backendTemplate",
+		Parameters: []TemplateParameter{
+			{Name: "method", DefaultValue: "GET"},
+			{Name: "host", Mandatory: true},
+		},
+		Endpoint: &Endpoint{EndpointUrl: EndpointUrl{
+			Method:      "$method",
+			URITemplate: "http://{uri.var.host}/orders/{uri.var.id}?src=$name",
+			Format:      EndpointFormatPOX,
+		}},
+	}
+
+	ref := Endpoint{
+		Name:               "OrdersEP",
+		Template:           "backendTemplate",
+		TemplateParameters: map[string]string{"host": "orders.local:8080"},
+		Position:           Position{FileName: "OrdersEP.xml", Hierarchy: "OrdersEP"},
+	}
+	endpoint, err := template.ExpandEndpoint(ref)
+	require.NoError(t, err)
+	assert.Equal(t, "OrdersEP", endpoint.Name)
+	assert.Equal(t, "GET", endpoint.EndpointUrl.Method)
+	assert.Equal(t, "http://orders.local:8080/orders/{uri.var.id}?src=OrdersEP", endpoint.EndpointUrl.URITemplate)
+	assert.Equal(t, EndpointFormatPOX, endpoint.EndpointUrl.Format)
+	assert.Equal(t, "OrdersEP.xml", endpoint.Position.FileName)
+	assert.Equal(t, "$method", template.Endpoint.EndpointUrl.Method, "the template is not modified")
+
+	uriTemplate := Template{
+		Name:     "uriTemplate",
+		Endpoint: &Endpoint{EndpointUrl: EndpointUrl{Method: "POST", URITemplate: "$uri"}},
+	}
+	endpoint, err = uriTemplate.ExpandEndpoint(Endpoint{Name: "UriEP", TemplateParameters: map[string]string{"uri": "http://backend/api"}})
+	require.NoError(t, err)
+	assert.Equal(t, "http://backend/api", endpoint.EndpointUrl.URITemplate)
+
+	_, err = template.ExpandEndpoint(Endpoint{Name: "NoHost"})
+	assert.ErrorContains(t, err, "mandatory parameter host of template backendTemplate is not set")
+
+	sequenceTemplate := Template{Name: "seq", Sequence: &Sequence{}}
+	_, err = sequenceTemplate.ExpandEndpoint(ref)
+	assert.ErrorContains(t, err, "template seq is not an endpoint template")
+}
@@ -0,0 +1,129 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package artifacts
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/apache/synapse-go/internal/pkg/core/synctx"
+	"github.com/apache/synapse-go/internal/pkg/core/throttle"
+)
+
+// Caller key types shared by the throttle mediator and API rate limits
+const (
+	ThrottleKeyIP       = "ip"
+	ThrottleKeyHeader   = "header"
+	ThrottleKeyProperty = "property"
+)
+
+// ThrottleMediator limits the rate and concurrency of messages per caller.
+// Accepted messages run OnAccept and rejected messages run OnReject. The
+// mediator returns the result of the branch it ran, so a rejected message
+// without an OnReject branch stops the flow.
+type ThrottleMediator struct {
+	ID            string
+	KeyType       string
+	KeyName       string
+	Policy        throttle.Policy
+	MaxConcurrent int
+	OnAccept      *Sequence
+	OnReject      *Sequence
+	Store         throttle.Store
+	Position      Position
+}
+
+func (tm ThrottleMediator) Execute(context *synctx.MsgContext, ctx context.Context) (bool, error) {
+	callerKey, err := tm.callerKey(context)
+	if err != nil {
+		return false, fmt.Errorf("%v at %s", err, tm.Position.Hierarchy)
+	}
+	counterKey := tm.ID + ":" + callerKey
+
+	store := tm.Store
+	if store == nil {
+		store = throttle.DefaultStore()
+	}
+
+	// The concurrency slot is reserved first, so that a message rejected
+	// for concurrency does not spend a rate token
+	if tm.MaxConcurrent > 0 {
+		if !store.Acquire(counterKey, tm.MaxConcurrent) {
+			return tm.reject(context, ctx, callerKey)
+		}
+	}
+
+	if tm.Policy.Limit > 0 {
+		decision := store.Take(counterKey, tm.Policy, time.Now())
+		if !decision.Allowed {
+			if tm.MaxConcurrent > 0 {
+				store.Release(counterKey)
+			}
+			context.Properties["throttleRetryAfter"] = decision.RetryAfter
+			return tm.reject(context, ctx, callerKey)
+		}
+	}
+
+	// The concurrency slot is held until the flow of the message completes,
+	// including the mediators after the throttle mediator
+	if tm.MaxConcurrent > 0 {
+		context.OnDone(func() { store.Release(counterKey) })
+	}
+
+	if tm.OnAccept == nil {
+		return true, nil
+	}
+	return tm.OnAccept.Execute(context, ctx), nil
+}
+
+func (tm ThrottleMediator) reject(context *synctx.MsgContext, ctx context.Context, callerKey string) (bool, error) {
+	if tm.OnReject == nil {
+		return false, fmt.Errorf("message from %s rejected by throttle %s at %s", callerKey, tm.ID, tm.Position.Hierarchy)
+	}
+	return tm.OnReject.Execute(context, ctx), nil
+}
+
+// callerKey identifies the caller a message is counted against
+func (tm ThrottleMediator) callerKey(context *synctx.MsgContext) (string, error) {
+	switch tm.KeyType {
+	case ThrottleKeyHeader:
+		if headers, ok := context.Properties["transportHeaders"].(map[string]string); ok {
+			if value, exists := headers[http.CanonicalHeaderKey(tm.KeyName)]; exists {
+				return value, nil
+			}
+		}
+		return "", fmt.Errorf("throttle header %s not found in message", tm.KeyName)
+	case ThrottleKeyProperty:
+		value, exists := context.Properties[tm.KeyName]
+		if !exists {
+			return "", fmt.Errorf("throttle property %s not found in message", tm.KeyName)
+		}
+		return fmt.Sprint(value), nil
+	default:
+		remoteAddr, _ := context.Properties["remoteAddr"].(string)
+		if host, _, err := net.SplitHostPort(remoteAddr); err == nil {
+			return host, nil
+		}
+		return remoteAddr, nil
+	}
+}
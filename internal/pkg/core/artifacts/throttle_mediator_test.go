@@ -0,0 +1,165 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package artifacts
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/apache/synapse-go/internal/pkg/core/synctx"
+	"github.com/apache/synapse-go/internal/pkg/core/throttle"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingMediator records how many times it was executed
+type countingMediator struct {
+	count *int
+}
+
+func (cm countingMediator) Execute(context *synctx.MsgContext, ctx context.Context) (bool, error) {
+	*cm.count++
+	return true, nil
+}
+
+func TestThrottleMediator_Execute(t *testing.T) {
+	accepted, rejected := 0, 0
+	mediator := ThrottleMediator{
+		ID:       "test",
+		KeyType:  ThrottleKeyIP,
+		Policy:   throttle.Policy{Algorithm: throttle.FixedWindow, Limit: 2, Window: time.Minute},
+		OnAccept: &Sequence{MediatorList: []Mediator{countingMediator{&accepted}}},
+		OnReject: &Sequence{MediatorList: []Mediator{countingMediator{&rejected}}},
+		Store:    throttle.NewMemoryStore(),
+		Position: Position{Hierarchy: "test.hierarchy"},
+	}
+
+	for i := 0; i < 3; i++ {
+		msgContext := synctx.CreateMsgContext()
+		msgContext.Properties["remoteAddr"] = "10.0.0.1:5000"
+		result, err := mediator.Execute(msgContext, context.Background())
+		assert.NoError(t, err)
+		assert.True(t, result)
+	}
+	assert.Equal(t, 2, accepted)
+	assert.Equal(t, 1, rejected)
+
+	// A different caller has its own counter
+	msgContext := synctx.CreateMsgContext()
+	msgContext.Properties["remoteAddr"] = "10.0.0.2:5000"
+	_, _ = mediator.Execute(msgContext, context.Background())
+	assert.Equal(t, 3, accepted)
+}
+
+func TestThrottleMediator_RejectWithoutOnReject(t *testing.T) {
+	mediator := ThrottleMediator{
+		ID:       "test",
+		KeyType:  ThrottleKeyProperty,
+		KeyName:  "tenant",
+		Policy:   throttle.Policy{Algorithm: throttle.FixedWindow, Limit: 1, Window: time.Minute},
+		Store:    throttle.NewMemoryStore(),
+		Position: Position{Hierarchy: "test.hierarchy"},
+	}
+	msgContext := synctx.CreateMsgContext()
+	msgContext.Properties["tenant"] = "acme"
+
+	result, err := mediator.Execute(msgContext, context.Background())
+	assert.True(t, result)
+	assert.NoError(t, err)
+
+	result, err = mediator.Execute(msgContext, context.Background())
+	assert.False(t, result)
+	assert.EqualError(t, err, "message from acme rejected by throttle test at test.hierarchy")
+	assert.Contains(t, msgContext.Properties, "throttleRetryAfter")
+}
+
+func TestThrottleMediator_MissingKey(t *testing.T) {
+	mediator := ThrottleMediator{
+		ID:            "test",
+		KeyType:       ThrottleKeyHeader,
+		KeyName:       "x-client-id",
+		MaxConcurrent: 1,
+		Store:         throttle.NewMemoryStore(),
+		Position:      Position{Hierarchy: "test.hierarchy"},
+	}
+	msgContext := synctx.CreateMsgContext()
+
+	result, err := mediator.Execute(msgContext, context.Background())
+	assert.False(t, result)
+	assert.EqualError(t, err, "throttle header x-client-id not found in message at test.hierarchy")
+
+	msgContext.Properties["transportHeaders"] = map[string]string{"X-Client-Id": "abc"}
+	result, err = mediator.Execute(msgContext, context.Background())
+	assert.True(t, result)
+	assert.NoError(t, err)
+}
+
+func TestThrottleMediator_ConcurrencyRejectKeepsRateToken(t *testing.T) {
+	store := throttle.NewMemoryStore()
+	mediator := ThrottleMediator{
+		ID:            "test",
+		Policy:        throttle.Policy{Algorithm: throttle.FixedWindow, Limit: 1, Window: time.Minute},
+		MaxConcurrent: 1,
+		Store:         store,
+		Position:      Position{Hierarchy: "test.hierarchy"},
+	}
+	msgContext := synctx.CreateMsgContext()
+	msgContext.Properties["remoteAddr"] = "10.0.0.1:5000"
+
+	// Another message from the caller holds the only concurrency slot
+	require.True(t, store.Acquire("test:10.0.0.1", 1))
+	result, _ := mediator.Execute(msgContext, context.Background())
+	assert.False(t, result)
+	store.Release("test:10.0.0.1")
+
+	result, err := mediator.Execute(msgContext, context.Background())
+	assert.True(t, result, "the rejected message did not spend the rate token")
+	assert.NoError(t, err)
+	msgContext.Done()
+	result, _ = mediator.Execute(msgContext, context.Background())
+	assert.False(t, result)
+
+	// A message rejected for its rate frees the concurrency slot
+	assert.True(t, store.Acquire("test:10.0.0.1", 1))
+}
+
+func TestThrottleMediator_SlotHeldUntilDone(t *testing.T) {
+	store := throttle.NewMemoryStore()
+	mediator := ThrottleMediator{ID: "test", MaxConcurrent: 1, Store: store, Position: Position{Hierarchy: "test.hierarchy"}}
+	first := synctx.CreateMsgContext()
+	first.Properties["remoteAddr"] = "10.0.0.1:5000"
+	second := synctx.CreateMsgContext()
+	second.Properties["remoteAddr"] = "10.0.0.1:5001"
+
+	result, err := mediator.Execute(first, context.Background())
+	require.True(t, result)
+	require.NoError(t, err)
+
+	// The first message still runs the mediators after the throttle mediator
+	result, err = mediator.Execute(second, context.Background())
+	assert.False(t, result)
+	assert.EqualError(t, err, "message from 10.0.0.1 rejected by throttle test at test.hierarchy")
+
+	first.Done()
+	result, err = mediator.Execute(second, context.Background())
+	assert.True(t, result)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,87 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package artifacts
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/apache/synapse-go/internal/pkg/core/synctx"
+	"github.com/apache/synapse-go/internal/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Positioned is implemented by mediators that know where they are defined,
+// which names their spans
+type Positioned interface {
+	GetPosition() Position
+}
+
+func (cm CallMediator) GetPosition() Position         { return cm.Position }
+func (ct CallTemplateMediator) GetPosition() Position { return ct.Position }
+func (lm LogMediator) GetPosition() Position          { return lm.Position }
+func (mm MakeFaultMediator) GetPosition() Position    { return mm.Position }
+func (rm RespondMediator) GetPosition() Position      { return rm.Position }
+func (sm StoreMediator) GetPosition() Position        { return sm.Position }
+func (tm ThrottleMediator) GetPosition() Position     { return tm.Position }
+
+// executeMediator executes a mediator in a span named after its hierarchy,
+// so that the spans of the mediators it calls, such as endpoint calls, are
+// its children
+func executeMediator(mediator Mediator, msgContext *synctx.MsgContext, ctx context.Context) (bool, error) {
+	mediatorType := strings.TrimPrefix(fmt.Sprintf("%T", mediator), "artifacts.")
+	name := mediatorType
+	attrs := []attribute.KeyValue{attribute.String("synapse.mediator", mediatorType)}
+	if positioned, ok := mediator.(Positioned); ok {
+		position := positioned.GetPosition()
+		if position.Hierarchy != "" {
+			name = position.Hierarchy
+		}
+		if position.FileName != "" {
+			attrs = append(attrs, semconv.CodeFilepath(position.FileName), semconv.CodeLineNumber(position.LineNo))
+		}
+	}
+	ctx, span := tracing.Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+	defer span.End()
+
+	result, err := mediator.Execute(msgContext, ctx)
+	if err != nil {
+		tracing.SetError(span, err)
+	}
+	// Mediation stops without an error when a mediator responds or drops the message
+	span.SetAttributes(attribute.Bool("synapse.mediation.continue", result))
+	return result, err
+}
+
+// startSequenceSpan starts the span of a sequence, named after its hierarchy
+// or, for named sequences, its name
+func startSequenceSpan(ctx context.Context, sequence *Sequence) (context.Context, trace.Span) {
+	name := sequence.Position.Hierarchy
+	if name == "" {
+		name = sequence.Name
+	}
+	if name == "" {
+		name = "sequence"
+	}
+	return tracing.Tracer().Start(ctx, name, trace.WithAttributes(attribute.String("synapse.sequence", sequence.Name)))
+}
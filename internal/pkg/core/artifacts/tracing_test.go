@@ -0,0 +1,135 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package artifacts
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/apache/synapse-go/internal/pkg/core/synctx"
+	"github.com/apache/synapse-go/internal/pkg/core/utils"
+	"github.com/apache/synapse-go/internal/pkg/vault"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestSequence_Spans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	sequence := &Sequence{
+		Name:     "OrdersSequence",
+		Position: Position{Hierarchy: "OrdersSequence"},
+		MediatorList: []Mediator{
+			LogMediator{Position: Position{Hierarchy: "OrdersSequence->log", FileName: "OrdersSequence.xml", LineNo: 3}},
+			CallMediator{
+				Endpoint: &Endpoint{EndpointUrl: EndpointUrl{Method: "GET", URITemplate: server.URL + "/orders"}},
+				Position: Position{Hierarchy: "OrdersSequence->call"},
+			},
+		},
+	}
+	ctx := context.WithValue(context.Background(), utils.ConfigContextKey, &ConfigContext{})
+	assert.True(t, sequence.Execute(synctx.CreateMsgContext(), ctx))
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	require.Len(t, spans, 4)
+	sequenceSpan := spans["OrdersSequence"]
+	logSpan := spans["OrdersSequence->log"]
+	callSpan := spans["OrdersSequence->call"]
+	clientSpan := spans["GET"]
+
+	assert.Equal(t, sequenceSpan.SpanContext().SpanID(), logSpan.Parent().SpanID())
+	assert.Equal(t, sequenceSpan.SpanContext().SpanID(), callSpan.Parent().SpanID())
+	assert.Equal(t, callSpan.SpanContext().SpanID(), clientSpan.Parent().SpanID())
+	assert.Equal(t, trace.SpanKindClient, clientSpan.SpanKind())
+	assert.Contains(t, logSpan.Attributes(), attribute.String("code.filepath", "OrdersSequence.xml"))
+	assert.Contains(t, logSpan.Attributes(), attribute.String("synapse.mediator", "LogMediator"))
+
+	// The backend continues the trace from the client span
+	assert.Equal(t, "00-"+clientSpan.SpanContext().TraceID().String()+"-"+clientSpan.SpanContext().SpanID().String()+"-01", traceparent)
+	assert.Equal(t, "404 Not Found", clientSpan.Status().Description)
+}
+
+// secrets is a vault provider backed by a map
+type secrets map[string]string
+
+func (s secrets) Secret(alias string) (string, error) {
+	if secret, ok := s[alias]; ok {
+		return secret, nil
+	}
+	return "", vault.ErrSecretNotFound
+}
+
+func TestCallMediator_SpanURL(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+
+	vault.GetVault().AddProvider(secrets{"orders_api_key": "s3cr3t-key"})
+	key, err := vault.GetVault().Secret("orders_api_key")
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	backend := strings.Replace(server.URL, "http://", "http://bob:hunter2@", 1)
+	ctx := context.WithValue(context.Background(), utils.ConfigContextKey, &ConfigContext{})
+
+	call := func(url string) sdktrace.ReadOnlySpan {
+		t.Helper()
+		recorder.Reset()
+		mediator := CallMediator{Endpoint: &Endpoint{EndpointUrl: EndpointUrl{Method: "GET", URITemplate: url}}}
+		mediator.Execute(synctx.CreateMsgContext(), ctx)
+		spans := recorder.Ended()
+		require.Len(t, spans, 1)
+		return spans[0]
+	}
+
+	// Credentials and secrets of the vault are not exported with the span
+	span := call(backend + "/orders/" + key + "?key=" + key)
+	assert.Contains(t, span.Attributes(), attribute.String("url.full", server.URL+"/orders/********?key=********"))
+
+	server.Close()
+	span = call(backend + "/orders?key=" + key)
+	assert.Contains(t, span.Attributes(), attribute.String("url.full", server.URL+"/orders?key=********"))
+	assert.NotContains(t, span.Status().Description, key)
+	assert.Contains(t, span.Status().Description, "key=********")
+}
@@ -0,0 +1,191 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+// Package carbonapp reads carbon application archives (.car or .zip), the
+// bundles that integration projects are built into. An archive holds an
+// artifacts.xml manifest naming the application and its dependencies, and a
+// folder per dependency with an artifact.xml that names its files:
+//
+//	artifacts.xml
+//	HealthcareAPI_1.0.0/
+//	├─ artifact.xml
+//	└─ HealthcareAPI.xml
+package carbonapp
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	ManifestName    = "artifacts.xml"
+	descriptorName  = "artifact.xml"
+	ApplicationType = "carbon/application"
+	// MaxEntrySize is the largest uncompressed size of a file in an archive,
+	// which bounds the memory that a compressed archive can expand into
+	MaxEntrySize = 32 << 20
+)
+
+var (
+	ErrManifestNotFound = errors.New(ManifestName + " not found in archive")
+	ErrEntryTooLarge    = fmt.Errorf("file is larger than %d bytes", MaxEntrySize)
+)
+
+// App is a carbon application and the artifacts it deploys
+type App struct {
+	Name      string
+	Version   string
+	Artifacts []Artifact
+}
+
+// Artifact is one file of a dependency of the application. Path is the
+// location of the file in the archive.
+type Artifact struct {
+	Name    string
+	Version string
+	Type    string
+	Path    string
+	Data    []byte
+}
+
+type descriptor struct {
+	Name         string       `xml:"name,attr"`
+	Version      string       `xml:"version,attr"`
+	Type         string       `xml:"type,attr"`
+	Files        []string     `xml:"file"`
+	Dependencies []dependency `xml:"dependency"`
+}
+
+type dependency struct {
+	Artifact string `xml:"artifact,attr"`
+	Version  string `xml:"version,attr"`
+	Include  string `xml:"include,attr"`
+}
+
+type manifest struct {
+	Artifacts []descriptor `xml:"artifact"`
+}
+
+// IsArchive reports whether fileName is a carbon application archive
+func IsArchive(fileName string) bool {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".car", ".zip":
+		return true
+	}
+	return false
+}
+
+// Read reads the application from the content of an archive
+func Read(data []byte) (*App, error) {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid archive: %w", err)
+	}
+	entries := make(map[string]*zip.File)
+	for _, file := range reader.File {
+		entries[path.Clean(file.Name)] = file
+	}
+
+	manifestFile, ok := entries[ManifestName]
+	if !ok {
+		return nil, ErrManifestNotFound
+	}
+	var m manifest
+	if err := unmarshalEntry(manifestFile, &m); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", ManifestName, err)
+	}
+	if len(m.Artifacts) != 1 || m.Artifacts[0].Type != ApplicationType {
+		return nil, fmt.Errorf("%s must describe one %s", ManifestName, ApplicationType)
+	}
+	application := m.Artifacts[0]
+	if application.Name == "" {
+		return nil, fmt.Errorf("application name is required in %s", ManifestName)
+	}
+
+	// Dependencies are found by the descriptors of the folders in the archive
+	descriptors := make(map[string]descriptor)
+	folders := make(map[string]string)
+	for name, file := range entries {
+		if path.Base(name) != descriptorName || path.Dir(name) == "." {
+			continue
+		}
+		var d descriptor
+		if err := unmarshalEntry(file, &d); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", name, err)
+		}
+		descriptors[d.Name+"_"+d.Version] = d
+		folders[d.Name+"_"+d.Version] = path.Dir(name)
+	}
+
+	app := &App{Name: application.Name, Version: application.Version}
+	for _, dep := range application.Dependencies {
+		if dep.Include == "false" {
+			continue
+		}
+		id := dep.Artifact + "_" + dep.Version
+		d, ok := descriptors[id]
+		if !ok {
+			return nil, fmt.Errorf("dependency %s %s of %s not found in archive", dep.Artifact, dep.Version, application.Name)
+		}
+		for _, fileName := range d.Files {
+			filePath := path.Join(folders[id], strings.TrimSpace(fileName))
+			file, ok := entries[filePath]
+			if !ok {
+				return nil, fmt.Errorf("file %s of %s not found in archive", filePath, d.Name)
+			}
+			data, err := readEntry(file)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", filePath, err)
+			}
+			app.Artifacts = append(app.Artifacts, Artifact{Name: d.Name, Version: d.Version, Type: d.Type, Path: filePath, Data: data})
+		}
+	}
+	return app, nil
+}
+
+func unmarshalEntry(file *zip.File, v interface{}) error {
+	data, err := readEntry(file)
+	if err != nil {
+		return err
+	}
+	return xml.Unmarshal(data, v)
+}
+
+func readEntry(file *zip.File) ([]byte, error) {
+	reader, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(io.LimitReader(reader, MaxEntrySize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxEntrySize {
+		return nil, ErrEntryTooLarge
+	}
+	return data, nil
+}
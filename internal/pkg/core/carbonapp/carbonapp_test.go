@@ -0,0 +1,139 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package carbonapp
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testManifest = `<?xml version="1.0" encoding="UTF-8"?>
+<artifacts>
+	<artifact name="OrdersApp" version="1.0.0" type="carbon/application">
+		<dependency artifact="OrdersAPI" version="1.0.0" include="true" serverRole="EnterpriseIntegrator"/>
+		<dependency artifact="OrdersEndpoint" version="1.0.0" include="true" serverRole="EnterpriseIntegrator"/>
+		<dependency artifact="Excluded" version="1.0.0" include="false" serverRole="EnterpriseIntegrator"/>
+	</artifact>
+</artifacts>`
+
+// newArchive builds a zip archive from file names and contents
+func newArchive(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := writer.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
+
+func TestRead(t *testing.T) {
+	data := newArchive(t, map[string]string{
+		"artifacts.xml":                           testManifest,
+		"OrdersAPI_1.0.0/artifact.xml":            `<artifact name="OrdersAPI" version="1.0.0" type="synapse/api"><file>OrdersAPI.xml</file></artifact>`,
+		"OrdersAPI_1.0.0/OrdersAPI.xml":           `<api name="OrdersAPI"/>`,
+		"OrdersEndpoint_1.0.0/artifact.xml":       `<artifact name="OrdersEndpoint" version="1.0.0" type="synapse/endpoint"><file>OrdersEndpoint.xml</file></artifact>`,
+		"OrdersEndpoint_1.0.0/OrdersEndpoint.xml": `<endpoint name="OrdersEndpoint"/>`,
+	})
+
+	app, err := Read(data)
+	require.NoError(t, err)
+	assert.Equal(t, "OrdersApp", app.Name)
+	assert.Equal(t, "1.0.0", app.Version)
+	require.Len(t, app.Artifacts, 2)
+	assert.Equal(t, Artifact{
+		Name:    "OrdersAPI",
+		Version: "1.0.0",
+		Type:    "synapse/api",
+		Path:    "OrdersAPI_1.0.0/OrdersAPI.xml",
+		Data:    []byte(`<api name="OrdersAPI"/>`),
+	}, app.Artifacts[0])
+	assert.Equal(t, "synapse/endpoint", app.Artifacts[1].Type)
+}
+
+func TestRead_Errors(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          []byte
+		expectedError string
+	}{
+		{
+			name:          "not a zip archive",
+			data:          []byte("not a zip"),
+			expectedError: "invalid archive",
+		},
+		{
+			name:          "missing manifest",
+			data:          newArchive(t, map[string]string{"OrdersAPI.xml": "<api/>"}),
+			expectedError: "artifacts.xml not found in archive",
+		},
+		{
+			name:          "manifest without application",
+			data:          newArchive(t, map[string]string{"artifacts.xml": `<artifacts><artifact name="OrdersAPI" type="synapse/api"/></artifacts>`}),
+			expectedError: "artifacts.xml must describe one carbon/application",
+		},
+		{
+			name:          "missing dependency",
+			data:          newArchive(t, map[string]string{"artifacts.xml": testManifest}),
+			expectedError: "dependency OrdersAPI 1.0.0 of OrdersApp not found in archive",
+		},
+		{
+			name: "missing file",
+			data: newArchive(t, map[string]string{
+				"artifacts.xml":                     testManifest,
+				"OrdersAPI_1.0.0/artifact.xml":      `<artifact name="OrdersAPI" version="1.0.0" type="synapse/api"><file>OrdersAPI.xml</file></artifact>`,
+				"OrdersEndpoint_1.0.0/artifact.xml": `<artifact name="OrdersEndpoint" version="1.0.0" type="synapse/endpoint"/>`,
+			}),
+			expectedError: "file OrdersAPI_1.0.0/OrdersAPI.xml of OrdersAPI not found in archive",
+		},
+		{
+			name: "file larger than the limit",
+			data: newArchive(t, map[string]string{
+				"artifacts.xml":                           testManifest,
+				"OrdersAPI_1.0.0/artifact.xml":            `<artifact name="OrdersAPI" version="1.0.0" type="synapse/api"><file>OrdersAPI.xml</file></artifact>`,
+				"OrdersAPI_1.0.0/OrdersAPI.xml":           strings.Repeat(" ", MaxEntrySize+1),
+				"OrdersEndpoint_1.0.0/artifact.xml":       `<artifact name="OrdersEndpoint" version="1.0.0" type="synapse/endpoint"><file>OrdersEndpoint.xml</file></artifact>`,
+				"OrdersEndpoint_1.0.0/OrdersEndpoint.xml": `<endpoint name="OrdersEndpoint"/>`,
+			}),
+			expectedError: "failed to read OrdersAPI_1.0.0/OrdersAPI.xml: file is larger than 33554432 bytes",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Read(tt.data)
+			assert.ErrorContains(t, err, tt.expectedError)
+		})
+	}
+}
+
+func TestIsArchive(t *testing.T) {
+	assert.True(t, IsArchive("OrdersApp_1.0.0.car"))
+	assert.True(t, IsArchive("orders.ZIP"))
+	assert.False(t, IsArchive("orders.xml"))
+}
@@ -0,0 +1,274 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package deployers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/apache/synapse-go/internal/pkg/core/carbonapp"
+	"github.com/apache/synapse-go/internal/pkg/core/placeholder"
+)
+
+// Artifact folders that carbon application artifact types deploy as
+var archiveArtifactTypes = map[string]string{
+	"synapse/local-entry":        "LocalEntries",
+	"synapse/template":           "Templates",
+	"synapse/sequenceTemplate":   "Templates",
+	"synapse/endpointTemplate":   "Templates",
+	"synapse/message-store":      "MessageStores",
+	"synapse/endpoint":           "Endpoints",
+	"synapse/sequence":           "Sequences",
+	"synapse/api":                "APIs",
+	"synapse/proxy-service":      "ProxyServices",
+	"synapse/inbound-endpoint":   "Inbounds",
+	"synapse/message-processors": "MessageProcessors",
+	"synapse/task":               "Tasks",
+}
+
+// deployedArchive is a carbon application that is deployed as a unit. files
+// holds the artifact files of the version that is deployed, keyed like the
+// other files with the archive path in the file name.
+type deployedArchive struct {
+	hash    string
+	name    string
+	version string
+	files   map[string]artifactFile
+}
+
+// scannedArchive is an archive as found in a folder, or the error reading it
+type scannedArchive struct {
+	deployedArchive
+	err error
+}
+
+// scanArchives reads the archives in folderPath, keyed by the folder name and
+// the archive name, such as carbonapps/OrdersApp_1.0.0.car
+func (d *Deployer) scanArchives(folderPath string, archives map[string]*scannedArchive) error {
+	entries, err := os.ReadDir(folderPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !carbonapp.IsArchive(entry.Name()) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(folderPath, entry.Name()))
+		if err != nil {
+			d.logger.Error("Error reading file:", "error", err)
+			continue
+		}
+		id := filepath.Base(folderPath) + "/" + entry.Name()
+		archives[id] = readArchive(id, data)
+	}
+	return nil
+}
+
+func readArchive(id string, data []byte) *scannedArchive {
+	hash := sha256.Sum256(data)
+	archive := &scannedArchive{deployedArchive: deployedArchive{hash: hex.EncodeToString(hash[:])}}
+	app, err := carbonapp.Read(data)
+	if err != nil {
+		archive.err = err
+		return archive
+	}
+	archive.name = app.Name
+	archive.version = app.Version
+	archive.files = make(map[string]artifactFile)
+	for _, artifact := range app.Artifacts {
+		artifactType, ok := archiveArtifactTypes[artifact.Type]
+		if !ok {
+			archive.err = fmt.Errorf("unsupported artifact type %s of %s", artifact.Type, artifact.Name)
+			return archive
+		}
+		fileName := id + "!" + artifact.Path
+		archive.files[artifactType+"/"+fileName] = artifactFile{artifactType: artifactType, name: fileName, data: string(artifact.Data)}
+	}
+	return archive
+}
+
+// archiveOf returns the archive that the file at key belongs to, if any
+func archiveOf(key string) string {
+	_, fileName, _ := strings.Cut(key, "/")
+	id, _, found := strings.Cut(fileName, "!")
+	if !found {
+		return ""
+	}
+	return id
+}
+
+// resolveArchives adds the files of the archives to deploy to files. A new or
+// changed archive is only used if all of its placeholders resolve and all of
+// its artifacts parse, otherwise the version that is deployed is kept. It
+// returns the previous version of every archive that changed, nil for new
+// ones.
+func (d *Deployer) resolveArchives(files map[string]artifactFile, scanned map[string]*scannedArchive, resolver *placeholder.Resolver) map[string]*deployedArchive {
+	for _, id := range slices.Sorted(maps.Keys(d.archives)) {
+		if _, exists := scanned[id]; !exists {
+			delete(d.archives, id)
+			d.health.Remove("CarbonApps/" + id)
+			d.logger.Info("Undeploying archive: " + id)
+		}
+	}
+
+	changed := make(map[string]*deployedArchive)
+	for _, id := range slices.Sorted(maps.Keys(scanned)) {
+		archive := scanned[id]
+		previous := d.archives[id]
+		if previous != nil && previous.hash == archive.hash {
+			maps.Copy(files, previous.files)
+			continue
+		}
+		err := archive.err
+		if err == nil {
+			err = resolvePlaceholders(archive.files, resolver)
+		}
+		if err == nil {
+			err = validateArchive(archive.files)
+		}
+		if err != nil {
+			d.logger.Error("Error deploying archive "+id+":", "error", err)
+			d.archiveFailed(id, previous, err.Error())
+			d.archives[id] = keep(previous, archive.hash)
+			maps.Copy(files, d.archives[id].files)
+			continue
+		}
+		maps.Copy(files, archive.files)
+		deployed := archive.deployedArchive
+		d.archives[id] = &deployed
+		changed[id] = previous
+	}
+	return changed
+}
+
+// validateArchive parses every artifact of an archive
+func validateArchive(files map[string]artifactFile) error {
+	for _, key := range sortedFileKeys(files) {
+		if _, _, err := parseReferences(files[key]); err != nil {
+			return fmt.Errorf("%s: %w", files[key].name, err)
+		}
+	}
+	return nil
+}
+
+// rejectArchive replaces the files of a changed archive with those of its
+// previous version before anything is deployed
+func (d *Deployer) rejectArchive(id string, previous *deployedArchive, files map[string]artifactFile, err error) {
+	d.logger.Error("Error deploying archive "+id+":", "error", err)
+	d.archiveFailed(id, previous, err.Error())
+	archive := d.archives[id]
+	for key := range archive.files {
+		delete(files, key)
+	}
+	d.archives[id] = keep(previous, archive.hash)
+	maps.Copy(files, d.archives[id].files)
+}
+
+// settleArchives rolls back every changed archive of which an artifact failed
+// to deploy or kept its previous version
+func (d *Deployer) settleArchives(ctx context.Context, changed map[string]*deployedArchive) {
+	for _, id := range slices.Sorted(maps.Keys(changed)) {
+		archive := d.archives[id]
+		failed := ""
+		for _, key := range sortedFileKeys(archive.files) {
+			// A file that failed to redeploy may have its previous version restored
+			if deployed, ok := d.deployed[key]; !ok || deployed.data != archive.files[key].data {
+				failed = key
+				break
+			}
+		}
+		if failed == "" {
+			d.logger.Info("Deployed archive: "+id, "application", archive.name, "version", archive.version)
+			d.health.Deployed("CarbonApps/"+id, archive.name)
+			continue
+		}
+		d.logger.Error("Error deploying archive "+id+", rolling back:", "error", "failed to deploy "+failed)
+		d.archiveFailed(id, changed[id], "failed to deploy "+failed+": "+d.health.Reason(failed))
+		d.rollbackArchive(ctx, id, changed[id])
+	}
+}
+
+// rollbackArchive undeploys the artifacts of an archive and deploys those of
+// its previous version again
+func (d *Deployer) rollbackArchive(ctx context.Context, id string, previous *deployedArchive) {
+	archive := d.archives[id]
+	var previousFiles map[string]artifactFile
+	if previous != nil {
+		previousFiles = previous.files
+	}
+
+	keys := sortedFileKeys(archive.files)
+	for i := len(keys) - 1; i >= 0; i-- {
+		key := keys[i]
+		deployed, ok := d.deployed[key]
+		if file, kept := previousFiles[key]; kept && ok && deployed.data == file.data {
+			d.seen[key] = file.data
+			continue
+		}
+		if ok {
+			d.undeploy(ctx, deployed, false)
+			delete(d.deployed, key)
+		}
+		delete(d.seen, key)
+		d.health.Remove(key)
+	}
+	for _, key := range sortedFileKeys(previousFiles) {
+		file := previousFiles[key]
+		if deployed, ok := d.deployed[key]; ok && deployed.data == file.data {
+			continue
+		}
+		d.seen[key] = file.data
+		d.deployFile(ctx, file.artifactType, file.name, file.data)
+	}
+
+	d.archives[id] = keep(previous, archive.hash)
+}
+
+// archiveFailed reports an archive that failed to deploy, as deployed with
+// the reason if its previous version is kept
+func (d *Deployer) archiveFailed(id string, previous *deployedArchive, reason string) {
+	key := "CarbonApps/" + id
+	if previous != nil && len(previous.files) > 0 {
+		d.health.Kept(key, reason)
+		return
+	}
+	d.health.Failed(key, reason)
+}
+
+// keep returns the previous version of an archive, or an empty one if there
+// is none, with the hash of the version that was rejected so that it is not
+// tried again until the archive changes
+func keep(previous *deployedArchive, hash string) *deployedArchive {
+	kept := &deployedArchive{hash: hash}
+	if previous != nil {
+		kept.name, kept.version, kept.files = previous.name, previous.version, previous.files
+	}
+	return kept
+}
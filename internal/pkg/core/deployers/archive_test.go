@@ -0,0 +1,145 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package deployers
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/apache/synapse-go/internal/pkg/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// archiveArtifact is an artifact of a test carbon application
+type archiveArtifact struct {
+	name         string
+	artifactType string
+	data         string
+}
+
+// writeArchive writes a carbon application with the artifacts to the
+// carbonapps folder next to basePath
+func writeArchive(t *testing.T, basePath string, fileName string, version string, artifacts ...archiveArtifact) {
+	t.Helper()
+	var manifest strings.Builder
+	manifest.WriteString(`<artifacts><artifact name="OrdersApp" version="` + version + `" type="carbon/application">`)
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	write := func(name string, content string) {
+		w, err := writer.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	for _, artifact := range artifacts {
+		folder := artifact.name + "_1.0.0"
+		fmt.Fprintf(&manifest, `<dependency artifact="%s" version="1.0.0" include="true"/>`, artifact.name)
+		write(folder+"/artifact.xml", fmt.Sprintf(`<artifact name="%s" version="1.0.0" type="%s"><file>%s.xml</file></artifact>`,
+			artifact.name, artifact.artifactType, artifact.name))
+		write(folder+"/"+artifact.name+".xml", artifact.data)
+	}
+	manifest.WriteString(`</artifact></artifacts>`)
+	write("artifacts.xml", manifest.String())
+	require.NoError(t, writer.Close())
+
+	folderPath := filepath.Join(basePath, "..", "carbonapps")
+	require.NoError(t, os.MkdirAll(folderPath, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(folderPath, fileName), buf.Bytes(), 0o644))
+}
+
+// archiveStatus returns the health of the archive with the file name
+func archiveStatus(d *Deployer, fileName string) health.ArtifactStatus {
+	for _, status := range d.health.Artifacts() {
+		if status.Type == "CarbonApps" && status.File == "carbonapps/"+fileName {
+			return status
+		}
+	}
+	return health.ArtifactStatus{}
+}
+
+func TestDeploy_ArchiveRollback(t *testing.T) {
+	d, ctx, basePath := newTestDeployer(t)
+	writeArtifact(t, basePath, "APIs", "stock.xml", apiXML("StockAPI", "/stock", "/list"))
+	writeArchive(t, basePath, "OrdersApp.car", "1.0.0",
+		archiveArtifact{"OrdersAPI", "synapse/api", apiXML("OrdersAPI", "/orders", "/list")},
+		archiveArtifact{"backend", "synapse/endpoint", backendXML})
+	require.NoError(t, d.Deploy(ctx))
+	assert.Equal(t, http.StatusOK, get(d, "/orders/list"))
+	assert.Equal(t, health.ArtifactStatus{Type: "CarbonApps", File: "carbonapps/OrdersApp.car", Name: "OrdersApp", State: health.StateDeployed},
+		archiveStatus(d, "OrdersApp.car"))
+
+	// The API of the new version conflicts with StockAPI, so the whole
+	// archive is rolled back, including the endpoint that did deploy
+	writeArchive(t, basePath, "OrdersApp.car", "2.0.0",
+		archiveArtifact{"OrdersAPI", "synapse/api", apiXML("OrdersAPI", "/stock", "/list")},
+		archiveArtifact{"quotes", "synapse/endpoint", strings.Replace(backendXML, `name="backend"`, `name="quotes"`, 1)})
+	require.NoError(t, d.Deploy(ctx))
+	assert.Equal(t, http.StatusOK, get(d, "/orders/list"))
+	configContext := snapshot(ctx)
+	assert.Equal(t, "/orders", configContext.ApiMap["OrdersAPI"].Context)
+	assert.Contains(t, configContext.EndpointMap, "backend")
+	assert.NotContains(t, configContext.EndpointMap, "quotes")
+	status := archiveStatus(d, "OrdersApp.car")
+	assert.Equal(t, health.StateDeployed, status.State)
+	assert.Contains(t, status.Reason, "failed to deploy APIs/carbonapps/OrdersApp.car!OrdersAPI_1.0.0/OrdersAPI.xml")
+
+	// The rejected version is not tried again until the archive changes
+	version := configContext.Version
+	require.NoError(t, d.Deploy(ctx))
+	assert.Equal(t, version, snapshot(ctx).Version)
+
+	require.NoError(t, os.Remove(filepath.Join(basePath, "..", "carbonapps", "OrdersApp.car")))
+	require.NoError(t, d.Deploy(ctx))
+	assert.Equal(t, http.StatusNotFound, get(d, "/orders/list"))
+	assert.NotContains(t, snapshot(ctx).EndpointMap, "backend")
+	assert.Empty(t, archiveStatus(d, "OrdersApp.car").State)
+}
+
+func TestDeploy_NewArchiveFailsAlone(t *testing.T) {
+	d, ctx, basePath := newTestDeployer(t)
+	writeArtifact(t, basePath, "APIs", "stock.xml", apiXML("StockAPI", "/stock", "/list"))
+
+	// An archive that refers to an endpoint that no file defines is rejected
+	// before anything is deployed, and the loose files still deploy
+	writeArchive(t, basePath, "OrdersApp.car", "1.0.0",
+		archiveArtifact{"main", "synapse/sequence", sequenceXML("main", `<call><endpoint key="missing"/></call>`)})
+	require.NoError(t, d.Deploy(ctx))
+	assert.Equal(t, http.StatusOK, get(d, "/stock/list"))
+	assert.NotContains(t, snapshot(ctx).SequenceMap, "main")
+	status := archiveStatus(d, "OrdersApp.car")
+	assert.Equal(t, health.StateFailed, status.State)
+	assert.Contains(t, status.Reason, "endpoint not found with reference: missing")
+
+	// An archive that does not read fails without affecting the others
+	require.NoError(t, os.WriteFile(filepath.Join(basePath, "..", "carbonapps", "Broken.car"), []byte("not a zip"), 0o644))
+	writeArchive(t, basePath, "OrdersApp.car", "1.0.1",
+		archiveArtifact{"main", "synapse/sequence", sequenceXML("main", "")})
+	require.NoError(t, d.Deploy(ctx))
+	assert.Contains(t, snapshot(ctx).SequenceMap, "main")
+	assert.Equal(t, health.StateDeployed, archiveStatus(d, "OrdersApp.car").State)
+	assert.Equal(t, health.StateFailed, archiveStatus(d, "Broken.car").State)
+}
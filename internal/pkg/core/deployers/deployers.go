@@ -21,47 +21,120 @@ package deployers
 
 import (
 	"context"
-	"io"
+	"errors"
+	"fmt"
 	"log/slog"
+	"maps"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 
 	"github.com/apache/synapse-go/internal/app/adapters/inbound"
+	"github.com/apache/synapse-go/internal/app/adapters/processor"
+	"github.com/apache/synapse-go/internal/app/adapters/task"
 	"github.com/apache/synapse-go/internal/app/core/domain"
 	"github.com/apache/synapse-go/internal/app/core/ports"
 	"github.com/apache/synapse-go/internal/pkg/core/artifacts"
 	"github.com/apache/synapse-go/internal/pkg/core/deployers/types"
+	"github.com/apache/synapse-go/internal/pkg/core/messagestore"
+	"github.com/apache/synapse-go/internal/pkg/core/placeholder"
 	"github.com/apache/synapse-go/internal/pkg/core/router"
 	"github.com/apache/synapse-go/internal/pkg/core/utils"
+	"github.com/apache/synapse-go/internal/pkg/health"
 	"github.com/apache/synapse-go/internal/pkg/loggerfactory"
+	"github.com/apache/synapse-go/internal/pkg/vault"
 )
 
 const (
 	componentName = "deployers"
 )
 
+// Artifact folders in deployment order, among artifacts that do not refer
+// to each other
+var artifactTypes = []string{"LocalEntries", "Templates", "MessageStores", "Endpoints", "Sequences", "APIs", "ProxyServices", "Inbounds", "MessageProcessors", "Tasks"}
+
 type Deployer struct {
 	inboundMediator ports.InboundMessageMediator
 	routerService   *router.RouterService
 	basePath        string
+	carbonAppsPath  string
 	logger 			*slog.Logger
+	// health reports the state of every artifact file to the readiness endpoint
+	health          *health.Registry
+	mu              sync.Mutex
+	// deployed holds the deployed artifacts and seen the last content of
+	// every file, both keyed by artifact type and file name
+	deployed map[string]*deployedArtifact
+	seen     map[string]string
+	// archives holds the carbon applications, keyed by folder and file name
+	archives map[string]*deployedArchive
+	// draft is the configuration being changed by a deployment. It is
+	// committed as a new snapshot when the deployment finishes, and only then
+	// are the undeployed artifacts in stopping stopped and released, and the
+	// runnables in pending started. The message stores in opened are closed
+	// if the draft is not committed.
+	draft    *artifacts.ConfigContext
+	stopping []func()
+	pending  []func()
+	opened   []messagestore.Store
+	changed  bool
+}
+
+// deployerState is the state of the deployer before a deployment, restored
+// when the deployment cannot be committed
+type deployerState struct {
+	deployed map[string]*deployedArtifact
+	seen     map[string]string
+	archives map[string]*deployedArchive
+}
+
+// Artifact kinds that artifact loggers are named after, by artifact folder
+var loggerKinds = map[string]string{"APIs": "api", "ProxyServices": "proxy", "Inbounds": "inbound"}
+
+// maxDeployAttempts bounds how often a deployment is tried again when another
+// change to the configuration is committed first
+const maxDeployAttempts = 3
+
+type deployedArtifact struct {
+	artifactType string
+	fileName     string
+	name         string
+	data         string
+	// stop stops an inbound endpoint, message processor or task
+	stop func()
+	// processor is set for message processors that can be activated and
+	// deactivated by hand
+	processor switchable
 }
 
 // Synapse/
 // ├─ bin/
 // │  └─ synapse           (the compiled binary)
-// └─ artifacts/
+// ├─ carbonapps/          (.car and .zip archives)
+// └─ artifacts/           (archives can also be placed here)
+//    ├─ LocalEntries/
+//    |─ Templates/
 //    ├─ APIs/
+//    |─ ProxyServices/
 //    |─ Endpoints/
 //    |─ Sequences/
-//    └─ Inbounds/
+//    |─ Inbounds/
+//    |─ MessageStores/
+//    |─ MessageProcessors/
+//    └─ Tasks/
 
 func NewDeployer(basePath string, inboundMediator ports.InboundMessageMediator, routerService *router.RouterService) *Deployer {
 	d := &Deployer{
 		basePath:        basePath,
+		carbonAppsPath:  filepath.Join(basePath, "..", "carbonapps"),
 		inboundMediator: inboundMediator,
 		routerService:   routerService,
+		deployed:        make(map[string]*deployedArtifact),
+		seen:            make(map[string]string),
+		archives:        make(map[string]*deployedArchive),
+		health:          health.GetRegistry(),
 	}
 	d.logger = loggerfactory.GetLogger(componentName, d)
 	return d
@@ -71,59 +144,632 @@ func (d *Deployer) UpdateLogger() {
 	d.logger = loggerfactory.GetLogger(componentName,d)
 }
 
+// Deploy deploys every artifact under the base path, each after the artifacts
+// it refers to. Running it again deploys new files, redeploys changed ones and
+// undeploys removed ones. All changes are committed together as one new
+// configuration snapshot. Nothing is changed if an artifact refers to one
+// that is not defined or has a placeholder that cannot be resolved. Carbon
+// application archives are deployed as a unit: if any of their artifacts
+// fails, the previous version of the archive is kept.
 func (d *Deployer) Deploy(ctx context.Context) error {
-	files, err := os.ReadDir(d.basePath)
+	err := d.deploy(ctx)
+	for attempt := 1; errors.Is(err, artifacts.ErrConcurrentUpdate) && attempt < maxDeployAttempts; attempt++ {
+		d.logger.Warn("Configuration was updated during deployment, deploying again")
+		err = d.deploy(ctx)
+	}
+	d.health.DeploymentDone(err)
+	return err
+}
+
+func (d *Deployer) deploy(ctx context.Context) error {
+	configStore, ok := artifacts.ConfigStoreFromContext(ctx)
+	if !ok {
+		return errors.New("config store not found in context")
+	}
+	files, archives, err := d.scan()
 	if err != nil {
 		return err
 	}
-	if len(files) == 0 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	saved := d.save()
+	resolver := d.resolver(configStore.Snapshot().DeploymentConfig)
+	if err := resolvePlaceholders(files, resolver); err != nil {
+		return err
+	}
+	changedArchives := d.resolveArchives(files, archives, resolver)
+	ordered, err := d.order(files)
+	for err != nil {
+		// A new archive that refers to a missing artifact is rejected alone
+		var refErr *referenceError
+		if !errors.As(err, &refErr) {
+			return err
+		}
+		id := archiveOf(refErr.key)
+		previous, changed := changedArchives[id]
+		if !changed {
+			return err
+		}
+		d.rejectArchive(id, previous, files, err)
+		delete(changedArchives, id)
+		ordered, err = d.order(files)
+	}
+
+	d.draft = configStore.Begin()
+	d.routerService.Hold()
+	defer func() {
+		d.draft = nil
+		d.stopping = nil
+		d.pending = nil
+		d.opened = nil
+		d.changed = false
+	}()
+
+	// Undeploy removed files, dependents first
+	for i := len(artifactTypes) - 1; i >= 0; i-- {
+		for _, key := range d.sortedKeys(artifactTypes[i]) {
+			if _, exists := files[key]; exists {
+				continue
+			}
+			if deployed, ok := d.deployed[key]; ok {
+				d.undeploy(ctx, deployed, false)
+				delete(d.deployed, key)
+				d.logger.Info("Undeployed " + key)
+			}
+			delete(d.seen, key)
+			d.health.Remove(key)
+		}
+	}
+
+	for _, key := range ordered {
+		file := files[key]
+		if seen, ok := d.seen[key]; ok && seen == file.data {
+			continue
+		}
+		d.seen[key] = file.data
+		deployed, exists := d.deployed[key]
+		switch {
+		case !exists:
+			d.deployFile(ctx, file.artifactType, file.name, file.data)
+		case file.artifactType == "MessageStores":
+			d.logger.Warn("Message store changes take effect after a restart: " + key)
+		default:
+			d.redeploy(ctx, deployed, file.data)
+		}
+	}
+	d.settleArchives(ctx, changedArchives)
+	return d.commit(configStore, saved)
+}
+
+// commit swaps in the draft configuration along with the routes of the
+// deployment, stops the runnables that were undeployed and starts the ones
+// deployed with it. If the draft cannot be committed, the deployer and its
+// routes are restored to saved and nothing is stopped or started.
+func (d *Deployer) commit(configStore *artifacts.ConfigStore, saved deployerState) error {
+	if !d.changed {
+		d.routerService.Release(true)
 		return nil
 	}
-	for _, artifactType := range []string{"Sequences", "APIs", "Inbounds","Endpoints"} {
-		folderPath := filepath.Join(d.basePath, artifactType)
-		files, err := os.ReadDir(folderPath)
+	if err := configStore.Commit(d.draft); err != nil {
+		d.logger.Error("Error committing configuration:", "version", d.draft.Version, "error", err)
+		d.routerService.Release(false)
+		d.restore(saved)
+		return err
+	}
+	d.routerService.Release(true)
+	d.logger.Info("Deployed configuration", "version", d.draft.Version)
+	for _, stop := range d.stopping {
+		stop()
+	}
+	for _, launch := range d.pending {
+		launch()
+	}
+	return nil
+}
+
+// save returns the state of the deployer before a deployment
+func (d *Deployer) save() deployerState {
+	return deployerState{
+		deployed: maps.Clone(d.deployed),
+		seen:     maps.Clone(d.seen),
+		archives: maps.Clone(d.archives),
+	}
+}
+
+// restore puts back the state of the deployer before a deployment. The
+// runnables created by the deployment were never started and are discarded,
+// and the message stores it opened are closed, so that the next deployment
+// can open them again.
+func (d *Deployer) restore(saved deployerState) {
+	for key, deployed := range d.deployed {
+		if saved.deployed[key] != deployed && deployed.stop != nil {
+			deployed.stop()
+		}
+	}
+	for _, store := range d.opened {
+		if err := store.Close(); err != nil {
+			d.logger.Error("Error closing message store "+store.Name()+":", "error", err)
+		}
+	}
+	d.deployed, d.seen, d.archives = saved.deployed, saved.seen, saved.archives
+}
+
+type artifactFile struct {
+	artifactType string
+	name         string
+	data         string
+}
+
+// resolver resolves placeholders against the deployment configuration,
+// relative file paths against the Synapse home folder and secret references
+// against the vault
+func (d *Deployer) resolver(deploymentConfig map[string]interface{}) *placeholder.Resolver {
+	return &placeholder.Resolver{
+		DeploymentConfig: deploymentConfig,
+		BasePath:         filepath.Join(d.basePath, ".."),
+		Secrets:          vault.GetVault(),
+	}
+}
+
+// resolvePlaceholders replaces the placeholders in the data of files. It stops
+// at the first placeholder that cannot be resolved.
+func resolvePlaceholders(files map[string]artifactFile, resolver *placeholder.Resolver) error {
+	for _, key := range sortedFileKeys(files) {
+		file := files[key]
+		data, err := resolver.Resolve(file.name, file.data)
 		if err != nil {
 			return err
 		}
-		for _, file := range files {
-			if file.IsDir() || filepath.Ext(file.Name()) != ".xml" {
+		file.data = data
+		files[key] = file
+	}
+	return nil
+}
+
+// scan reads the artifact files, keyed by artifact type and file name, and
+// the archives
+func (d *Deployer) scan() (map[string]artifactFile, map[string]*scannedArchive, error) {
+	if _, err := os.Stat(d.basePath); err != nil {
+		return nil, nil, err
+	}
+	archives := make(map[string]*scannedArchive)
+	for _, folderPath := range []string{d.basePath, d.carbonAppsPath} {
+		if err := d.scanArchives(folderPath, archives); err != nil {
+			return nil, nil, err
+		}
+	}
+	files := make(map[string]artifactFile)
+	for _, artifactType := range artifactTypes {
+		folderPath := filepath.Join(d.basePath, artifactType)
+		entries, err := os.ReadDir(folderPath)
+		if os.IsNotExist(err) {
+			// Artifact folders are optional
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || filepath.Ext(entry.Name()) != ".xml" {
 				continue
 			}
-			xmlFile, err := os.Open(filepath.Join(folderPath, file.Name()))
-			if err != nil {
-				return err
-			}
-			defer xmlFile.Close()
-			data, err := io.ReadAll(xmlFile)
+			data, err := os.ReadFile(filepath.Join(folderPath, entry.Name()))
 			if err != nil {
 				d.logger.Error("Error reading file:", "error", err)
 				continue
 			}
-			switch artifactType {
-			case "APIs":
-				d.DeployAPIs(ctx, file.Name(), string(data))
-			case "Sequences":
-				d.DeploySequences(ctx, file.Name(), string(data))
-			case "Inbounds":
-				d.DeployInbounds(ctx, file.Name(), string(data))
-			case "Endpoints":
-				d.DeployEndpoints(ctx, file.Name(), string(data))
+			files[artifactType+"/"+entry.Name()] = artifactFile{artifactType: artifactType, name: entry.Name(), data: string(data)}
+		}
+	}
+	return files, archives, nil
+}
+
+func (d *Deployer) deployFile(ctx context.Context, artifactType string, fileName string, data string) {
+	switch artifactType {
+	case "LocalEntries":
+		d.DeployLocalEntries(ctx, fileName, data)
+	case "Templates":
+		d.DeployTemplates(ctx, fileName, data)
+	case "APIs":
+		d.DeployAPIs(ctx, fileName, data)
+	case "Sequences":
+		d.DeploySequences(ctx, fileName, data)
+	case "ProxyServices":
+		d.DeployProxyServices(ctx, fileName, data)
+	case "Inbounds":
+		d.DeployInbounds(ctx, fileName, data)
+	case "Endpoints":
+		d.DeployEndpoints(ctx, fileName, data)
+	case "MessageStores":
+		d.DeployMessageStores(ctx, fileName, data)
+	case "MessageProcessors":
+		d.DeployMessageProcessors(ctx, fileName, data)
+	case "Tasks":
+		d.DeployTasks(ctx, fileName, data)
+	}
+}
+
+// redeploy replaces a deployed artifact with a new version of its file. APIs
+// and proxy services keep serving the old version until the new one is
+// registered. If the new version fails to deploy, the old one is restored.
+func (d *Deployer) redeploy(ctx context.Context, deployed *deployedArtifact, data string) {
+	key := deployed.artifactType + "/" + deployed.fileName
+	d.undeploy(ctx, deployed, true)
+	delete(d.deployed, key)
+
+	d.deployFile(ctx, deployed.artifactType, deployed.fileName, data)
+	redeployed, ok := d.deployed[key]
+	if !ok {
+		d.logger.Error("Error redeploying " + key + ", restoring the previous version")
+		reason := d.health.Reason(key)
+		d.deployFile(ctx, deployed.artifactType, deployed.fileName, deployed.data)
+		if _, restored := d.deployed[key]; !restored {
+			d.unregisterRoutes(deployed)
+			return
+		}
+		d.health.Kept(key, reason)
+		return
+	}
+	if redeployed.name != deployed.name {
+		d.unregisterRoutes(deployed)
+	}
+	d.logger.Info("Redeployed " + key)
+}
+
+// undeploy removes an artifact from the config context and stops it if it
+// runs in the background. keepRoutes leaves HTTP routes in place so that a
+// redeployment can replace them without a gap.
+func (d *Deployer) undeploy(ctx context.Context, deployed *deployedArtifact, keepRoutes bool) {
+	configContext := d.draft
+	d.changed = true
+	if deployed.stop != nil {
+		d.stopping = append(d.stopping, deployed.stop)
+	}
+	if kind, ok := loggerKinds[deployed.artifactType]; ok {
+		d.stopping = append(d.stopping, func() {
+			// A file that was renamed deploys the artifact again
+			if _, _, err := d.find(deployed.artifactType, deployed.name); err != nil {
+				loggerfactory.GetConfigManager().ForgetArtifact(kind, deployed.name)
 			}
+		})
+	}
+	switch deployed.artifactType {
+	case "LocalEntries":
+		delete(configContext.LocalEntryMap, deployed.name)
+	case "Templates":
+		delete(configContext.TemplateMap, deployed.name)
+	case "APIs":
+		delete(configContext.ApiMap, deployed.name)
+	case "Sequences":
+		delete(configContext.SequenceMap, deployed.name)
+	case "ProxyServices":
+		delete(configContext.ProxyServiceMap, deployed.name)
+	case "Inbounds":
+		delete(configContext.InboundMap, deployed.name)
+	case "Endpoints":
+		delete(configContext.EndpointMap, deployed.name)
+	case "MessageStores":
+		d.logger.Warn("Message stores are not undeployed until a restart: " + deployed.name)
+		return
+	case "MessageProcessors":
+		delete(configContext.MessageProcessorMap, deployed.name)
+	case "Tasks":
+		delete(configContext.TaskMap, deployed.name)
+	}
+	if !keepRoutes {
+		d.unregisterRoutes(deployed)
+	}
+}
+
+func (d *Deployer) unregisterRoutes(deployed *deployedArtifact) {
+	var err error
+	switch deployed.artifactType {
+	case "APIs":
+		err = d.routerService.UnregisterAPI(deployed.name)
+	case "ProxyServices":
+		err = d.routerService.UnregisterProxy(deployed.name)
+	}
+	if err != nil {
+		d.logger.Error("Error unregistering routes of "+deployed.name+":", "error", err)
+	}
+}
+
+// record tracks a deployed artifact so that it can be redeployed or undeployed
+// when its file changes
+func (d *Deployer) record(artifactType string, fileName string, data string, name string, stop func()) {
+	d.changed = true
+	d.deployed[artifactType+"/"+fileName] = &deployedArtifact{
+		artifactType: artifactType,
+		fileName:     fileName,
+		name:         name,
+		data:         data,
+		stop:         stop,
+	}
+	d.health.Deployed(artifactType+"/"+fileName, name)
+}
+
+// fail logs why an artifact failed to deploy and reports it as failed
+func (d *Deployer) fail(artifactType string, fileName string, msg string, err any) {
+	d.logger.Error(msg, "error", err)
+	d.health.Failed(artifactType+"/"+fileName, strings.TrimSuffix(msg, ":")+": "+fmt.Sprint(err))
+}
+
+func (d *Deployer) sortedKeys(artifactType string) []string {
+	var keys []string
+	for key, deployed := range d.deployed {
+		if deployed.artifactType == artifactType {
+			keys = append(keys, key)
+		}
+	}
+	for key := range d.seen {
+		if _, ok := d.deployed[key]; !ok && strings.HasPrefix(key, artifactType+"/") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ErrArtifactNotFound is returned when no artifact of a type is deployed
+// with a name
+var ErrArtifactNotFound = errors.New("artifact not found")
+
+// SuspendInbound stops an inbound endpoint until it is resumed, restarted or
+// redeployed
+func (d *Deployer) SuspendInbound(ctx context.Context, name string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	key, deployed, err := d.find("Inbounds", name)
+	if err != nil {
+		return err
+	}
+	if deployed.stop != nil {
+		deployed.stop()
+		deployed.stop = nil
+	}
+	d.health.Suspended(key)
+	d.logger.Info("Suspended inbound: " + name)
+	return nil
+}
+
+// ResumeInbound starts an inbound endpoint that is suspended, including one
+// deployed with suspend="true". ctx is the server context.
+func (d *Deployer) ResumeInbound(ctx context.Context, name string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	key, deployed, err := d.find("Inbounds", name)
+	if err != nil {
+		return err
+	}
+	if deployed.stop != nil {
+		return nil
+	}
+	if err := d.launchInbound(ctx, key, deployed); err != nil {
+		return err
+	}
+	d.logger.Info("Resumed inbound: " + name)
+	return nil
+}
+
+// RestartInbound stops an inbound endpoint, if it runs, and starts a new
+// instance of it. ctx is the server context.
+func (d *Deployer) RestartInbound(ctx context.Context, name string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	key, deployed, err := d.find("Inbounds", name)
+	if err != nil {
+		return err
+	}
+	if deployed.stop != nil {
+		deployed.stop()
+		deployed.stop = nil
+	}
+	if err := d.launchInbound(ctx, key, deployed); err != nil {
+		return err
+	}
+	d.logger.Info("Restarted inbound: " + name)
+	return nil
+}
+
+// ErrNotSwitchable is returned when a message processor that cannot be
+// deactivated, such as a sampling processor, is activated or deactivated
+var ErrNotSwitchable = errors.New("message processor cannot be activated or deactivated")
+
+// ActivateProcessor resumes a message processor that was deactivated by hand
+// or after its maximum delivery attempts
+func (d *Deployer) ActivateProcessor(ctx context.Context, name string) error {
+	return d.setProcessorActive(name, true)
+}
+
+// DeactivateProcessor pauses a message processor, leaving its messages in
+// the store until it is activated or redeployed
+func (d *Deployer) DeactivateProcessor(ctx context.Context, name string) error {
+	return d.setProcessorActive(name, false)
+}
+
+func (d *Deployer) setProcessorActive(name string, active bool) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	_, deployed, err := d.find("MessageProcessors", name)
+	if err != nil {
+		return err
+	}
+	if deployed.processor == nil {
+		return fmt.Errorf("%w: %s", ErrNotSwitchable, name)
+	}
+	if active {
+		deployed.processor.Activate()
+		d.logger.Info("Activated message processor: " + name)
+	} else {
+		deployed.processor.Deactivate()
+		d.logger.Info("Deactivated message processor: " + name)
+	}
+	return nil
+}
+
+// find returns the deployed artifact of a type with a name
+func (d *Deployer) find(artifactType string, name string) (string, *deployedArtifact, error) {
+	for _, key := range d.sortedKeys(artifactType) {
+		if deployed, ok := d.deployed[key]; ok && deployed.name == name {
+			return key, deployed, nil
 		}
 	}
+	return "", nil, fmt.Errorf("%w: %s", ErrArtifactNotFound, name)
+}
+
+// launchInbound starts a new instance of a deployed inbound endpoint right
+// away, outside of a deployment
+func (d *Deployer) launchInbound(ctx context.Context, key string, deployed *deployedArtifact) error {
+	configStore, ok := artifacts.ConfigStoreFromContext(ctx)
+	if !ok {
+		return errors.New("config store not found in context")
+	}
+	definition, ok := configStore.Snapshot().InboundMap[deployed.name]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrArtifactNotFound, deployed.name)
+	}
+	inboundEndpoint, err := newInboundEndpoint(definition)
+	if err != nil {
+		d.health.Failed(key, "Error creating inbound endpoint: "+err.Error())
+		return err
+	}
+	d.health.Deployed(key, deployed.name)
+	deployed.stop = d.start(ctx, "inbound endpoint", key, inboundEndpoint)
+	for _, launch := range d.pending {
+		launch()
+	}
+	d.pending = nil
 	return nil
 }
 
+// runnable is an inbound endpoint, message processor or task
+type runnable interface {
+	Start(ctx context.Context, mediator ports.InboundMessageMediator) error
+	Stop(ctx context.Context) error
+}
+
+// starter is a runnable that reports when it has started, such as an inbound
+// endpoint once it listens on its port. Other runnables count as started
+// when they are launched.
+type starter interface {
+	Started() <-chan struct{}
+}
+
+// activity is a runnable that can be deactivated, such as a message processor
+type activity interface {
+	IsActive() bool
+}
+
+// switchable is an activity that can be activated and deactivated by hand
+type switchable interface {
+	activity
+	Activate()
+	Deactivate()
+}
+
+// start runs r in the background under the server WaitGroup once the
+// deployment is committed, and reports its state under key. The returned
+// function stops r and waits for it to finish.
+func (d *Deployer) start(ctx context.Context, kind string, key string, r runnable) func() {
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	launched := false
+	d.pending = append(d.pending, func() {
+		if runCtx.Err() != nil {
+			return
+		}
+		launched = true
+		if a, ok := r.(activity); ok {
+			d.health.Watch(key, a.IsActive)
+		}
+		if s, ok := r.(starter); ok {
+			d.health.Starting(key)
+			go func() {
+				select {
+				case <-s.Started():
+					d.health.Started(key)
+				case <-done:
+				}
+			}()
+		}
+		wg := ctx.Value(utils.WaitGroupKey).(*sync.WaitGroup)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(done)
+			// Runnables stopped by an undeployment or a pause did not fail
+			if err := r.Start(runCtx, d.inboundMediator); err != nil && runCtx.Err() == nil {
+				d.logger.Error("Error starting "+kind+":", "error", err)
+				d.health.Failed(key, "Error starting "+kind+": "+err.Error())
+			}
+		}()
+	})
+	return func() {
+		cancel()
+		if !launched {
+			return
+		}
+		// Runnables return once their context is cancelled. Stop runs after
+		// that, as some of them call it themselves when they return.
+		<-done
+		if err := r.Stop(runCtx); err != nil {
+			d.logger.Error("Error stopping "+kind+":", "error", err)
+		}
+	}
+}
+
+func (d *Deployer) DeployLocalEntries(ctx context.Context, fileName string, xmlData string) {
+	position := artifacts.Position{FileName: fileName}
+	localEntry := types.LocalEntry{}
+	newLocalEntry, err := localEntry.Unmarshal(xmlData, position)
+	if err != nil {
+		d.fail("LocalEntries", fileName, "Error unmarshalling local entry:", err)
+		return
+	}
+	configContext := d.draft
+	if _, exists := configContext.LocalEntryMap[newLocalEntry.Key]; exists {
+		d.fail("LocalEntries", fileName, "Error deploying local entry:", "duplicate local entry key "+newLocalEntry.Key)
+		return
+	}
+	if err := newLocalEntry.Load(d.basePath); err != nil {
+		d.fail("LocalEntries", fileName, "Error loading local entry:", err)
+		return
+	}
+	configContext.AddLocalEntry(newLocalEntry)
+	d.record("LocalEntries", fileName, xmlData, newLocalEntry.Key, nil)
+	d.logger.Info("Deployed local entry: " + newLocalEntry.Key)
+}
+
+func (d *Deployer) DeployTemplates(ctx context.Context, fileName string, xmlData string) {
+	position := artifacts.Position{FileName: fileName}
+	template := types.Template{}
+	newTemplate, err := template.Unmarshal(xmlData, position)
+	if err != nil {
+		d.fail("Templates", fileName, "Error unmarshalling template:", err)
+		return
+	}
+	configContext := d.draft
+	if _, exists := configContext.TemplateMap[newTemplate.Name]; exists {
+		d.fail("Templates", fileName, "Error deploying template:", "duplicate template name "+newTemplate.Name)
+		return
+	}
+	configContext.AddTemplate(newTemplate)
+	d.record("Templates", fileName, xmlData, newTemplate.Name, nil)
+	d.logger.Info("Deployed template: " + newTemplate.Name)
+}
+
 func (d *Deployer) DeploySequences(ctx context.Context, fileName string, xmlData string) {
 	position := artifacts.Position{FileName: fileName}
 	sequence := types.Sequence{}
 	newSeq, err := sequence.Unmarshal(xmlData, position)
 	if err != nil {
-		d.logger.Error("Error unmarshalling sequence:", "error", err)
+		d.fail("Sequences", fileName, "Error unmarshalling sequence:", err)
 		return
 	}
-	configContext := ctx.Value(utils.ConfigContextKey).(*artifacts.ConfigContext)
+	configContext := d.draft
 	configContext.AddSequence(newSeq)
+	d.record("Sequences", fileName, xmlData, newSeq.Name, nil)
 	d.logger.Info("Deployed sequence: " + newSeq.Name)
 }
 
@@ -132,19 +778,61 @@ func (d *Deployer) DeployAPIs(ctx context.Context, fileName string, xmlData stri
 	api := types.API{}
 	newApi, err := api.Unmarshal(xmlData, position)
 	if err != nil {
-		d.logger.Error("Error unmarshalling api:", "error", err)
+		d.fail("APIs", fileName, "Error unmarshalling api:", err)
 		return
 	}
-	configContext := ctx.Value(utils.ConfigContextKey).(*artifacts.ConfigContext)
+	configContext := d.draft
 	configContext.AddAPI(newApi)
 
 	d.logger.Info("Deployed API: " + newApi.Name)
 
 	// Register the API with the router service
 	if err := d.routerService.RegisterAPI(ctx, newApi); err != nil {
-		d.logger.Error("Error registering API with router service:", "error", err)
+		d.fail("APIs", fileName, "Error registering API with router service:", err)
+		delete(configContext.ApiMap, newApi.Name)
 		return
 	}
+	d.record("APIs", fileName, xmlData, newApi.Name, nil)
+}
+
+func (d *Deployer) DeployProxyServices(ctx context.Context, fileName string, xmlData string) {
+	position := artifacts.Position{FileName: fileName}
+	proxy := types.ProxyService{}
+	newProxy, err := proxy.Unmarshal(xmlData, position)
+	if err != nil {
+		d.fail("ProxyServices", fileName, "Error unmarshalling proxy service:", err)
+		return
+	}
+	configContext := d.draft
+	if _, exists := configContext.ProxyServiceMap[newProxy.Name]; exists {
+		d.fail("ProxyServices", fileName, "Error deploying proxy service:", "duplicate proxy service name "+newProxy.Name)
+		return
+	}
+	if newProxy.WSDL != nil {
+		if err := newProxy.WSDL.Load(d.basePath, configContext); err != nil {
+			d.fail("ProxyServices", fileName, "Error loading WSDL for proxy service:", err)
+			return
+		}
+	}
+	configContext.AddProxyService(newProxy)
+	d.logger.Info("Deployed proxy service: " + newProxy.Name)
+
+	if !newProxy.Exposed() {
+		d.logger.Info("Proxy service is not exposed over HTTP: " + newProxy.Name)
+		// Remove the routes of a previous version that was exposed
+		if err := d.routerService.UnregisterProxy(newProxy.Name); err != nil {
+			d.logger.Error("Error unregistering proxy service:", "error", err)
+		}
+		d.record("ProxyServices", fileName, xmlData, newProxy.Name, nil)
+		return
+	}
+	// Register the proxy service with the router service
+	if err := d.routerService.RegisterProxy(ctx, newProxy); err != nil {
+		d.fail("ProxyServices", fileName, "Error registering proxy service with router service:", err)
+		delete(configContext.ProxyServiceMap, newProxy.Name)
+		return
+	}
+	d.record("ProxyServices", fileName, xmlData, newProxy.Name, nil)
 }
 
 func (d *Deployer) DeployInbounds(ctx context.Context, fileName string, xmlData string) {
@@ -152,37 +840,44 @@ func (d *Deployer) DeployInbounds(ctx context.Context, fileName string, xmlData
 	inboundEp := types.Inbound{}
 	newInbound, err := inboundEp.Unmarshal(xmlData, position)
 	if err != nil {
-		d.logger.Error("Error unmarshalling inbound:", "error", err)
+		d.fail("Inbounds", fileName, "Error unmarshalling inbound:", err)
 		return
 	}
-	configContext := ctx.Value(utils.ConfigContextKey).(*artifacts.ConfigContext)
+	configContext := d.draft
 	configContext.AddInbound(newInbound)
 	d.logger.Info("Deployed inbound: " + newInbound.Name)
 
+	// A suspended inbound endpoint is deployed without being started
+	if strings.EqualFold(newInbound.Suspend, "true") {
+		d.record("Inbounds", fileName, xmlData, newInbound.Name, nil)
+		d.health.Suspended("Inbounds/" + fileName)
+		d.logger.Info("Inbound endpoint is suspended: " + newInbound.Name)
+		return
+	}
+
 	// Start the inbound endpoint
+	inboundEndpoint, err := newInboundEndpoint(newInbound)
+	if err != nil {
+		d.fail("Inbounds", fileName, "Error creating inbound endpoint:", err)
+		return
+	}
+
+	stop := d.start(ctx, "inbound endpoint", "Inbounds/"+fileName, inboundEndpoint)
+	d.record("Inbounds", fileName, xmlData, newInbound.Name, stop)
+}
+
+// newInboundEndpoint creates the inbound endpoint of an inbound definition
+func newInboundEndpoint(definition artifacts.Inbound) (ports.InboundEndpoint, error) {
 	parametersMap := make(map[string]string)
-	for _, param := range newInbound.Parameters {
+	for _, param := range definition.Parameters {
 		parametersMap[param.Name] = param.Value
 	}
-	inboundEndpoint, err := inbound.NewInbound(domain.InboundConfig{
-		SequenceName: newInbound.Sequence,
-		Name:         newInbound.Name,
-		Protocol:     newInbound.Protocol,
+	return inbound.NewInbound(domain.InboundConfig{
+		SequenceName: definition.Sequence,
+		Name:         definition.Name,
+		Protocol:     definition.Protocol,
 		Parameters:   parametersMap,
 	})
-	if err != nil {
-		d.logger.Error("Error creating inbound endpoint:", "error", err)
-		return
-	}
-
-	wg := ctx.Value(utils.WaitGroupKey).(*sync.WaitGroup)
-	wg.Add(1)
-	go func(endpoint ports.InboundEndpoint) {
-		defer wg.Done()
-		if err := endpoint.Start(ctx, d.inboundMediator); err != nil {
-			d.logger.Error("Error starting inbound endpoint:", "error", err)
-		}
-	}(inboundEndpoint)
 }
 
 func (d *Deployer) DeployEndpoints(ctx context.Context, fileName string, xmlData string) {
@@ -190,10 +885,121 @@ func (d *Deployer) DeployEndpoints(ctx context.Context, fileName string, xmlData
 	endpoint := types.Endpoint{}
 	newEndpoint, err := endpoint.Unmarshal(xmlData, position)
 	if err != nil {
-		d.logger.Error("Error unmarshalling endpoint:", "error", err)
+		d.fail("Endpoints", fileName, "Error unmarshalling endpoint:", err)
 		return
 	}
-	configContext := ctx.Value(utils.ConfigContextKey).(*artifacts.ConfigContext)
+	configContext := d.draft
+	if newEndpoint.Template != "" {
+		template, exists := configContext.TemplateMap[newEndpoint.Template]
+		if !exists {
+			d.fail("Endpoints", fileName, "Error deploying endpoint:", "template not found: "+newEndpoint.Template)
+			return
+		}
+		if newEndpoint, err = template.ExpandEndpoint(newEndpoint); err != nil {
+			d.fail("Endpoints", fileName, "Error expanding endpoint template:", err)
+			return
+		}
+	}
 	configContext.AddEndpoint(newEndpoint)
+	d.record("Endpoints", fileName, xmlData, newEndpoint.Name, nil)
+	name := newEndpoint.Name
+	d.health.Watch("Endpoints/"+fileName, func() bool {
+		return artifacts.GetEndpointStates().IsActive(name)
+	})
 	d.logger.Info("Deployed endpoint: " + newEndpoint.Name)
 }
+
+func (d *Deployer) DeployMessageStores(ctx context.Context, fileName string, xmlData string) {
+	position := artifacts.Position{FileName: fileName}
+	messageStore := types.MessageStore{}
+	newMessageStore, err := messageStore.Unmarshal(xmlData, position)
+	if err != nil {
+		d.fail("MessageStores", fileName, "Error unmarshalling message store:", err)
+		return
+	}
+
+	parametersMap := make(map[string]string)
+	for _, param := range newMessageStore.Parameters {
+		parametersMap[param.Name] = param.Value
+	}
+	// Relative paths are relative to the Synapse home folder, like other
+	// file paths in artifacts, rather than to the working directory
+	if path := parametersMap["store.bbolt.path"]; path != "" && !filepath.IsAbs(path) {
+		parametersMap["store.bbolt.path"] = filepath.Join(d.basePath, "..", path)
+	}
+	store, err := messagestore.New(newMessageStore.Name, newMessageStore.Type, parametersMap)
+	if err != nil {
+		d.fail("MessageStores", fileName, "Error creating message store:", err)
+		return
+	}
+	newMessageStore.Store = store
+	d.opened = append(d.opened, store)
+
+	configContext := d.draft
+	configContext.AddMessageStore(newMessageStore)
+	d.record("MessageStores", fileName, xmlData, newMessageStore.Name, nil)
+	d.logger.Info("Deployed message store: " + newMessageStore.Name)
+}
+
+func (d *Deployer) DeployMessageProcessors(ctx context.Context, fileName string, xmlData string) {
+	position := artifacts.Position{FileName: fileName}
+	messageProcessor := types.MessageProcessor{}
+	newMessageProcessor, err := messageProcessor.Unmarshal(xmlData, position)
+	if err != nil {
+		d.fail("MessageProcessors", fileName, "Error unmarshalling message processor:", err)
+		return
+	}
+	configContext := d.draft
+	configContext.AddMessageProcessor(newMessageProcessor)
+	d.logger.Info("Deployed message processor: " + newMessageProcessor.Name)
+
+	// Start the message processor
+	parametersMap := make(map[string]string)
+	for _, param := range newMessageProcessor.Parameters {
+		parametersMap[param.Name] = param.Value
+	}
+	messageProcessorEndpoint, err := processor.NewMessageProcessor(domain.MessageProcessorConfig{
+		Name:           newMessageProcessor.Name,
+		Type:           newMessageProcessor.Type,
+		MessageStore:   newMessageProcessor.MessageStore,
+		TargetEndpoint: newMessageProcessor.TargetEndpoint,
+		Parameters:     parametersMap,
+	})
+	if err != nil {
+		d.fail("MessageProcessors", fileName, "Error creating message processor:", err)
+		return
+	}
+
+	stop := d.start(ctx, "message processor", "MessageProcessors/"+fileName, messageProcessorEndpoint)
+	d.record("MessageProcessors", fileName, xmlData, newMessageProcessor.Name, stop)
+	if s, ok := messageProcessorEndpoint.(switchable); ok {
+		d.deployed["MessageProcessors/"+fileName].processor = s
+	}
+}
+
+func (d *Deployer) DeployTasks(ctx context.Context, fileName string, xmlData string) {
+	position := artifacts.Position{FileName: fileName}
+	taskElem := types.Task{}
+	newTask, err := taskElem.Unmarshal(xmlData, position)
+	if err != nil {
+		d.fail("Tasks", fileName, "Error unmarshalling task:", err)
+		return
+	}
+	configContext := d.draft
+	configContext.AddTask(newTask)
+	d.logger.Info("Deployed task: " + newTask.Name)
+
+	// Start the task
+	scheduledTask := task.NewScheduledTask(domain.TaskConfig{
+		Name:         newTask.Name,
+		SequenceName: newTask.Sequence,
+		Interval:     newTask.Trigger.Interval,
+		Cron:         newTask.Trigger.Cron,
+		Count:        newTask.Trigger.Count,
+		Payload:      newTask.Payload,
+		ContentType:  newTask.ContentType,
+	})
+
+	stop := d.start(ctx, "task", "Tasks/"+fileName, scheduledTask)
+	d.record("Tasks", fileName, xmlData, newTask.Name, stop)
+}
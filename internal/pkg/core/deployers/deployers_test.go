@@ -0,0 +1,254 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package deployers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/apache/synapse-go/internal/pkg/config"
+	"github.com/apache/synapse-go/internal/pkg/core/artifacts"
+	"github.com/apache/synapse-go/internal/pkg/core/router"
+	"github.com/apache/synapse-go/internal/pkg/core/utils"
+	"github.com/apache/synapse-go/internal/pkg/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestDeployer returns a deployer for an empty artifacts folder in a
+// temporary directory, and a server context holding a new config store
+func newTestDeployer(t *testing.T) (*Deployer, context.Context, string) {
+	t.Helper()
+	basePath := filepath.Join(t.TempDir(), "artifacts")
+	require.NoError(t, os.MkdirAll(basePath, 0o755))
+
+	var wg sync.WaitGroup
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx = context.WithValue(ctx, utils.WaitGroupKey, &wg)
+	ctx = context.WithValue(ctx, utils.ConfigContextKey, artifacts.NewConfigStore())
+	t.Cleanup(func() {
+		cancel()
+		wg.Wait()
+	})
+
+	d := NewDeployer(basePath, nil, router.NewRouterService(":0", "localhost"))
+	d.health = health.NewRegistry()
+	return d, ctx, basePath
+}
+
+func writeArtifact(t *testing.T, basePath string, artifactType string, fileName string, data string) {
+	t.Helper()
+	folderPath := filepath.Join(basePath, artifactType)
+	require.NoError(t, os.MkdirAll(folderPath, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(folderPath, fileName), []byte(data), 0o644))
+}
+
+func apiXML(name string, context string, uriTemplate string) string {
+	return fmt.Sprintf(`<api xmlns="http://ws.apache.org/ns/synapse" name="%s" context="%s">
+	<resource methods="GET" uri-template="%s"><inSequence><respond/></inSequence></resource>
+</api>`, name, context, uriTemplate)
+}
+
+// get returns the status of a GET request served by the router of d
+func get(d *Deployer, path string) int {
+	w := httptest.NewRecorder()
+	d.routerService.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w.Code
+}
+
+func snapshot(ctx context.Context) *artifacts.ConfigContext {
+	configContext, _ := artifacts.ConfigFromContext(ctx)
+	return configContext
+}
+
+func TestDeploy_HotDeployAndUndeploy(t *testing.T) {
+	d, ctx, basePath := newTestDeployer(t)
+	require.NoError(t, d.Deploy(ctx))
+	assert.Equal(t, http.StatusNotFound, get(d, "/orders/list"))
+
+	writeArtifact(t, basePath, "APIs", "orders.xml", apiXML("OrdersAPI", "/orders", "/list"))
+	require.NoError(t, d.Deploy(ctx))
+	assert.Equal(t, http.StatusOK, get(d, "/orders/list"))
+	assert.Contains(t, snapshot(ctx).ApiMap, "OrdersAPI")
+	assert.Equal(t, []health.ArtifactStatus{{Type: "APIs", File: "orders.xml", Name: "OrdersAPI", State: health.StateDeployed}}, d.health.Artifacts())
+
+	// Deploying unchanged files does not create a new snapshot
+	version := snapshot(ctx).Version
+	require.NoError(t, d.Deploy(ctx))
+	assert.Equal(t, version, snapshot(ctx).Version)
+
+	require.NoError(t, os.Remove(filepath.Join(basePath, "APIs", "orders.xml")))
+	require.NoError(t, d.Deploy(ctx))
+	assert.Equal(t, http.StatusNotFound, get(d, "/orders/list"))
+	assert.NotContains(t, snapshot(ctx).ApiMap, "OrdersAPI")
+	assert.Empty(t, d.health.Artifacts())
+}
+
+func TestDeploy_Redeploy(t *testing.T) {
+	d, ctx, basePath := newTestDeployer(t)
+	writeArtifact(t, basePath, "APIs", "orders.xml", apiXML("OrdersAPI", "/orders", "/list"))
+	require.NoError(t, d.Deploy(ctx))
+	version := snapshot(ctx).Version
+
+	writeArtifact(t, basePath, "APIs", "orders.xml", apiXML("OrdersAPI", "/orders", "/all"))
+	require.NoError(t, d.Deploy(ctx))
+	assert.Equal(t, version+1, snapshot(ctx).Version)
+	assert.Equal(t, http.StatusNotFound, get(d, "/orders/list"))
+	assert.Equal(t, http.StatusOK, get(d, "/orders/all"))
+
+	// A renamed API replaces the routes of the old name
+	writeArtifact(t, basePath, "APIs", "orders.xml", apiXML("OrdersV2API", "/v2/orders", "/all"))
+	require.NoError(t, d.Deploy(ctx))
+	assert.Equal(t, http.StatusNotFound, get(d, "/orders/all"))
+	assert.Equal(t, http.StatusOK, get(d, "/v2/orders/all"))
+	assert.NotContains(t, snapshot(ctx).ApiMap, "OrdersAPI")
+	assert.Contains(t, snapshot(ctx).ApiMap, "OrdersV2API")
+}
+
+func TestDeploy_ConflictingRoutesKeepPreviousVersion(t *testing.T) {
+	d, ctx, basePath := newTestDeployer(t)
+	writeArtifact(t, basePath, "APIs", "orders.xml", apiXML("OrdersAPI", "/orders", "/list"))
+	writeArtifact(t, basePath, "APIs", "stock.xml", apiXML("StockAPI", "/stock", "/list"))
+	require.NoError(t, d.Deploy(ctx))
+
+	// The new version of StockAPI takes the context of OrdersAPI
+	writeArtifact(t, basePath, "APIs", "stock.xml", apiXML("StockAPI", "/orders", "/list"))
+	require.NoError(t, d.Deploy(ctx))
+	assert.Equal(t, http.StatusOK, get(d, "/orders/list"))
+	assert.Equal(t, http.StatusOK, get(d, "/stock/list"), "the previous version keeps serving")
+	assert.Equal(t, "/stock", snapshot(ctx).ApiMap["StockAPI"].Context)
+
+	statuses := d.health.Artifacts()
+	require.Len(t, statuses, 2)
+	assert.Equal(t, "stock.xml", statuses[1].File)
+	assert.Equal(t, health.StateDeployed, statuses[1].State)
+	assert.Contains(t, statuses[1].Reason, "conflicting routes")
+
+	// A new API with conflicting routes fails alone
+	writeArtifact(t, basePath, "APIs", "zorders.xml", apiXML("OtherOrdersAPI", "/orders", "/list"))
+	require.NoError(t, d.Deploy(ctx))
+	assert.Equal(t, http.StatusOK, get(d, "/orders/list"))
+	assert.NotContains(t, snapshot(ctx).ApiMap, "OtherOrdersAPI")
+	assert.Equal(t, health.StateFailed, d.health.Artifacts()[2].State)
+}
+
+func TestDeploy_CommitFailureRestoresLiveState(t *testing.T) {
+	d, ctx, basePath := newTestDeployer(t)
+	writeArtifact(t, basePath, "APIs", "orders.xml", apiXML("OrdersAPI", "/orders", "/list"))
+	require.NoError(t, d.Deploy(ctx))
+	configStore, _ := artifacts.ConfigStoreFromContext(ctx)
+
+	// Redeploy by hand so that another change can be committed before the draft
+	changed := apiXML("OrdersAPI", "/orders", "/all")
+	saved := d.save()
+	d.draft = configStore.Begin()
+	d.routerService.Hold()
+	d.seen["APIs/orders.xml"] = changed
+	d.redeploy(ctx, d.deployed["APIs/orders.xml"], changed)
+	assert.Equal(t, http.StatusOK, get(d, "/orders/list"), "routes are held back until the commit")
+	assert.Equal(t, http.StatusNotFound, get(d, "/orders/all"))
+
+	_, err := configStore.Update(func(next *artifacts.ConfigContext) {})
+	require.NoError(t, err)
+	require.ErrorIs(t, d.commit(configStore, saved), artifacts.ErrConcurrentUpdate)
+	d.draft, d.stopping, d.pending, d.changed = nil, nil, nil, false
+
+	assert.Equal(t, http.StatusOK, get(d, "/orders/list"))
+	assert.Equal(t, http.StatusNotFound, get(d, "/orders/all"))
+	assert.NotEqual(t, changed, d.deployed["APIs/orders.xml"].data)
+	assert.NotEqual(t, changed, d.seen["APIs/orders.xml"])
+
+	// The change is deployed by the next deployment
+	writeArtifact(t, basePath, "APIs", "orders.xml", changed)
+	require.NoError(t, d.Deploy(ctx))
+	assert.Equal(t, http.StatusNotFound, get(d, "/orders/list"))
+	assert.Equal(t, http.StatusOK, get(d, "/orders/all"))
+}
+
+func TestDeploy_MessageStoreClosedWhenNotCommitted(t *testing.T) {
+	d, ctx, basePath := newTestDeployer(t)
+	storeXML := `<messageStore xmlns="http://ws.apache.org/ns/synapse" name="OrdersStore" class="bbolt">
+	<parameter name="store.bbolt.path">stores/orders.db</parameter>
+</messageStore>`
+	writeArtifact(t, basePath, "MessageStores", "orders.xml", storeXML)
+	configStore, _ := artifacts.ConfigStoreFromContext(ctx)
+
+	// Deploy by hand so that another change can be committed before the draft
+	saved := d.save()
+	d.draft = configStore.Begin()
+	d.routerService.Hold()
+	d.seen["MessageStores/orders.xml"] = storeXML
+	d.deployFile(ctx, "MessageStores", "orders.xml", storeXML)
+	store := d.draft.MessageStoreMap["OrdersStore"].Store
+	require.NotNil(t, store)
+
+	_, err := configStore.Update(func(next *artifacts.ConfigContext) {})
+	require.NoError(t, err)
+	require.ErrorIs(t, d.commit(configStore, saved), artifacts.ErrConcurrentUpdate)
+	d.draft, d.stopping, d.pending, d.opened, d.changed = nil, nil, nil, nil, false
+	_, err = store.Size()
+	assert.Error(t, err, "the store of the draft is closed")
+
+	// The next deployment opens the store again, relative to the home folder
+	require.NoError(t, d.Deploy(ctx))
+	deployed := snapshot(ctx).MessageStoreMap["OrdersStore"].Store
+	require.NotNil(t, deployed)
+	t.Cleanup(func() { deployed.Close() })
+	assert.FileExists(t, filepath.Join(basePath, "..", "stores", "orders.db"))
+	size, err := deployed.Size()
+	assert.NoError(t, err)
+	assert.Zero(t, size)
+}
+
+func TestDeploy_ConfigPlaceholders(t *testing.T) {
+	d, ctx, basePath := newTestDeployer(t)
+	confPath := filepath.Join(basePath, "..", "conf")
+	require.NoError(t, os.MkdirAll(confPath, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(confPath, "deployment.toml"), []byte(`
+[server]
+hostname = "localhost"
+
+[order_service]
+base_url = "http://orders.local"
+
+[order_service.retry]
+count = 3
+`), 0o644))
+	t.Setenv("SYNAPSE_ORDER_SERVICE__BASE_URL", "http://orders.prod")
+	deploymentConfig, err := config.ReadDeploymentFile(filepath.Join(confPath, "deployment.toml"))
+	require.NoError(t, err)
+	configStore, _ := artifacts.ConfigStoreFromContext(ctx)
+	_, err = configStore.Update(func(next *artifacts.ConfigContext) {
+		next.AddDeploymentConfig(deploymentConfig)
+	})
+	require.NoError(t, err)
+
+	writeArtifact(t, basePath, "Endpoints", "orders.xml", `<endpoint xmlns="http://ws.apache.org/ns/synapse" name="orders">
+	<http method="GET" uri-template="${config:order_service.base_url}/orders?retries=${config:order_service.retry.count}"/>
+</endpoint>`)
+	require.NoError(t, d.Deploy(ctx))
+	assert.Equal(t, "http://orders.prod/orders?retries=3", snapshot(ctx).EndpointMap["orders"].EndpointUrl.URITemplate)
+}
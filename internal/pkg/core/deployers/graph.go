@@ -0,0 +1,279 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package deployers
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/apache/synapse-go/internal/pkg/core/artifacts"
+	"github.com/apache/synapse-go/internal/pkg/core/deployers/types"
+)
+
+// Artifact folders that hold each kind of referenced artifact
+var referenceTypes = map[string]string{
+	artifacts.ReferenceSequence:     "Sequences",
+	artifacts.ReferenceEndpoint:     "Endpoints",
+	artifacts.ReferenceTemplate:     "Templates",
+	artifacts.ReferenceMessageStore: "MessageStores",
+	artifacts.ReferenceLocalEntry:   "LocalEntries",
+}
+
+// referenceError is a reference from the file at key to an artifact that no
+// file defines. unparsed is a file of the referenced type that does not parse
+// and so may be the one meant to define it, and err is why.
+type referenceError struct {
+	key       string
+	reference artifacts.Reference
+	unparsed  string
+	err       error
+}
+
+func (e *referenceError) Error() string {
+	msg := fmt.Sprintf("%s not found with reference: %s in %s at line %d",
+		e.reference.Kind, e.reference.Key, e.reference.Position.FileName, e.reference.Position.LineNo)
+	if e.err != nil {
+		msg += fmt.Sprintf(", %s does not parse: %v", e.unparsed, e.err)
+	}
+	return msg
+}
+
+func (e *referenceError) Unwrap() error {
+	return e.err
+}
+
+// cycleError lists the files that cannot be deployed because they refer to
+// each other, or to themselves
+type cycleError struct {
+	keys []string
+}
+
+func (e *cycleError) Error() string {
+	if len(e.keys) == 1 {
+		return "circular reference from " + e.keys[0] + " to itself"
+	}
+	return "circular reference between " + strings.Join(e.keys, ", ")
+}
+
+// artifactNode is an artifact file in the dependency graph
+type artifactNode struct {
+	key        string
+	name       string
+	references []artifacts.Reference
+	// dependencies holds the keys of the files that the artifact refers to
+	dependencies []string
+}
+
+// artifactGraph holds the artifact files that parse and the references
+// between them
+type artifactGraph struct {
+	keys  []string
+	nodes map[string]*artifactNode
+	// providers maps an artifact type and name to the file that defines it
+	providers map[string]string
+	// errors holds the files that do not parse, and duplicates the files
+	// that define an artifact that an earlier file already defines
+	errors     map[string]error
+	duplicates []string
+}
+
+// order returns the keys of files in the order they have to be deployed:
+// every artifact after the ones it refers to. It fails on the first reference
+// to an artifact that is not defined by any file, and on circular references.
+func (d *Deployer) order(files map[string]artifactFile) ([]string, error) {
+	graph := d.newGraph(files)
+	if missing := graph.link(); len(missing) > 0 {
+		return nil, missing[0]
+	}
+	return graph.sort()
+}
+
+func (d *Deployer) newGraph(files map[string]artifactFile) *artifactGraph {
+	graph := &artifactGraph{
+		keys:      sortedFileKeys(files),
+		nodes:     make(map[string]*artifactNode),
+		providers: make(map[string]string),
+		errors:    make(map[string]error),
+	}
+	for _, key := range graph.keys {
+		node, err := d.parseNode(key, files[key])
+		if err != nil {
+			// The file fails to deploy and reports the error itself
+			graph.errors[key] = err
+			continue
+		}
+		graph.nodes[key] = node
+		provider := files[key].artifactType + "/" + node.name
+		if _, exists := graph.providers[provider]; exists {
+			graph.duplicates = append(graph.duplicates, key)
+			continue
+		}
+		graph.providers[provider] = key
+	}
+	return graph
+}
+
+// link resolves the references of every node to the files that define them
+// and returns the references that no file defines
+func (g *artifactGraph) link() []*referenceError {
+	var missing []*referenceError
+	for _, key := range g.keys {
+		node, ok := g.nodes[key]
+		if !ok {
+			continue
+		}
+		for _, reference := range node.references {
+			provider, exists := g.providers[referenceTypes[reference.Kind]+"/"+reference.Key]
+			if !exists {
+				unparsed := g.unparsed(reference)
+				missing = append(missing, &referenceError{key: key, reference: reference, unparsed: unparsed, err: g.errors[unparsed]})
+				continue
+			}
+			// A file that refers to itself depends on itself, so that sorting
+			// reports it as a cycle
+			if !slices.Contains(node.dependencies, provider) {
+				node.dependencies = append(node.dependencies, provider)
+			}
+		}
+	}
+	return missing
+}
+
+// unparsed returns the file that does not parse and may define the artifact
+// of a reference: the one named after the artifact, or else the first in its
+// folder. It returns an empty key when every file of the folder parses.
+func (g *artifactGraph) unparsed(reference artifacts.Reference) string {
+	folder := referenceTypes[reference.Kind] + "/"
+	first := ""
+	for _, key := range g.keys {
+		if _, failed := g.errors[key]; !failed || !strings.HasPrefix(key, folder) {
+			continue
+		}
+		if strings.TrimSuffix(path.Base(key), ".xml") == reference.Key {
+			return key
+		}
+		if first == "" {
+			first = key
+		}
+	}
+	return first
+}
+
+// sort returns the keys with every file after the files it depends on
+func (g *artifactGraph) sort() ([]string, error) {
+	// Repeatedly take the first file in folder order whose dependencies are
+	// all deployed, so that the order is stable
+	var ordered []string
+	deployed := make(map[string]bool)
+	pending := slices.Clone(g.keys)
+	for len(pending) > 0 {
+		next := -1
+		for i, key := range pending {
+			node, ok := g.nodes[key]
+			if !ok || allDeployed(node.dependencies, deployed) {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			return nil, &cycleError{keys: pending}
+		}
+		deployed[pending[next]] = true
+		ordered = append(ordered, pending[next])
+		pending = slices.Delete(pending, next, next+1)
+	}
+	return ordered, nil
+}
+
+// parseNode reads the name and the references of an artifact file. A file
+// that no longer parses still provides the version that is deployed.
+func (d *Deployer) parseNode(key string, file artifactFile) (*artifactNode, error) {
+	name, references, err := parseReferences(file)
+	if err != nil {
+		if deployed, ok := d.deployed[key]; ok {
+			return &artifactNode{key: key, name: deployed.name}, nil
+		}
+		return nil, err
+	}
+	return &artifactNode{key: key, name: name, references: references}, nil
+}
+
+func parseReferences(file artifactFile) (string, []artifacts.Reference, error) {
+	position := artifacts.Position{FileName: file.name}
+	switch file.artifactType {
+	case "LocalEntries":
+		localEntry, err := (&types.LocalEntry{}).Unmarshal(file.data, position)
+		return localEntry.Key, nil, err
+	case "Templates":
+		template, err := (&types.Template{}).Unmarshal(file.data, position)
+		return template.Name, template.References(), err
+	case "MessageStores":
+		messageStore, err := (&types.MessageStore{}).Unmarshal(file.data, position)
+		return messageStore.Name, nil, err
+	case "Sequences":
+		sequence, err := (&types.Sequence{}).Unmarshal(file.data, position)
+		return sequence.Name, sequence.References(), err
+	case "APIs":
+		api, err := (&types.API{}).Unmarshal(file.data, position)
+		return api.Name, api.References(), err
+	case "ProxyServices":
+		proxy, err := (&types.ProxyService{}).Unmarshal(file.data, position)
+		return proxy.Name, proxy.References(), err
+	case "Inbounds":
+		inbound, err := (&types.Inbound{}).Unmarshal(file.data, position)
+		return inbound.Name, inbound.References(), err
+	case "Endpoints":
+		endpoint, err := (&types.Endpoint{}).Unmarshal(file.data, position)
+		return endpoint.Name, endpoint.References(), err
+	case "MessageProcessors":
+		messageProcessor, err := (&types.MessageProcessor{}).Unmarshal(file.data, position)
+		return messageProcessor.Name, messageProcessor.References(), err
+	case "Tasks":
+		task, err := (&types.Task{}).Unmarshal(file.data, position)
+		return task.Name, task.References(), err
+	}
+	return "", nil, fmt.Errorf("unknown artifact type: %s", file.artifactType)
+}
+
+func allDeployed(keys []string, deployed map[string]bool) bool {
+	for _, key := range keys {
+		if !deployed[key] {
+			return false
+		}
+	}
+	return true
+}
+
+// sortedFileKeys returns the keys of files in folder order, then by name
+func sortedFileKeys(files map[string]artifactFile) []string {
+	var keys []string
+	for _, artifactType := range artifactTypes {
+		start := len(keys)
+		for key, file := range files {
+			if file.artifactType == artifactType {
+				keys = append(keys, key)
+			}
+		}
+		slices.Sort(keys[start:])
+	}
+	return keys
+}
@@ -0,0 +1,133 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package deployers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sequenceXML(name string, body string) string {
+	return `<sequence xmlns="http://ws.apache.org/ns/synapse" name="` + name + `">` + body + `</sequence>`
+}
+
+const (
+	backendXML = `<endpoint xmlns="http://ws.apache.org/ns/synapse" name="backend"><http method="GET" uri-template="http://localhost:9000/"/></endpoint>`
+	storeXML   = `<messageStore xmlns="http://ws.apache.org/ns/synapse" name="OrdersStore" class="memory"/>`
+)
+
+func TestOrder(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "references first",
+			files: map[string]string{
+				"APIs/orders.xml":         apiXML("OrdersAPI", "/orders", "/list"),
+				"Sequences/main.xml":      sequenceXML("main", `<call><endpoint key="backend"/></call>`),
+				"Sequences/audit.xml":     sequenceXML("audit", `<store messageStore="OrdersStore" sequence="main"/>`),
+				"Endpoints/backend.xml":   backendXML,
+				"MessageStores/store.xml": storeXML,
+			},
+			want: []string{"MessageStores/store.xml", "Endpoints/backend.xml", "Sequences/main.xml", "Sequences/audit.xml", "APIs/orders.xml"},
+		},
+		{
+			name: "folder order without references",
+			files: map[string]string{
+				"APIs/orders.xml":       apiXML("OrdersAPI", "/orders", "/list"),
+				"Sequences/b.xml":       sequenceXML("b", ""),
+				"Sequences/a.xml":       sequenceXML("a", ""),
+				"Endpoints/backend.xml": backendXML,
+			},
+			want: []string{"Endpoints/backend.xml", "Sequences/a.xml", "Sequences/b.xml", "APIs/orders.xml"},
+		},
+		{
+			name: "cycle",
+			files: map[string]string{
+				"MessageStores/store.xml": storeXML,
+				"Sequences/a.xml":         sequenceXML("a", `<store messageStore="OrdersStore" sequence="b"/>`),
+				"Sequences/b.xml":         sequenceXML("b", `<store messageStore="OrdersStore" sequence="a"/>`),
+			},
+			wantErr: "circular reference between Sequences/a.xml, Sequences/b.xml",
+		},
+		{
+			name: "reference to itself",
+			files: map[string]string{
+				"MessageStores/store.xml": storeXML,
+				"Sequences/a.xml":         sequenceXML("a", `<store messageStore="OrdersStore" sequence="a"/>`),
+			},
+			wantErr: "circular reference from Sequences/a.xml to itself",
+		},
+		{
+			name: "template calling itself",
+			files: map[string]string{
+				"Templates/loop.xml": `<template xmlns="http://ws.apache.org/ns/synapse" name="loop"><sequence><call-template target="loop"/></sequence></template>`,
+			},
+			wantErr: "circular reference from Templates/loop.xml to itself",
+		},
+		{
+			name: "missing reference",
+			files: map[string]string{
+				"Sequences/main.xml": sequenceXML("main", "\n<call><endpoint key=\"backend\"/></call>"),
+			},
+			wantErr: "endpoint not found with reference: backend in main.xml at line 2",
+		},
+		{
+			name: "referenced file does not parse",
+			files: map[string]string{
+				"Sequences/main.xml":    sequenceXML("main", `<call><endpoint key="backend"/></call>`),
+				"Endpoints/backend.xml": `<endpoint xmlns="http://ws.apache.org/ns/synapse"><http method="GET" uri-template="http://localhost:9000/"/></endpoint>`,
+			},
+			wantErr: "endpoint not found with reference: backend in main.xml at line 1, Endpoints/backend.xml does not parse: ",
+		},
+		{
+			name: "file of the referenced type does not parse",
+			files: map[string]string{
+				"Sequences/main.xml":   sequenceXML("main", `<call><endpoint key="backend"/></call>`),
+				"Endpoints/quotes.xml": `<endpoint xmlns="http://ws.apache.org/ns/synapse"/>`,
+			},
+			wantErr: "endpoint not found with reference: backend in main.xml at line 1, Endpoints/quotes.xml does not parse: ",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, _, _ := newTestDeployer(t)
+			files := make(map[string]artifactFile)
+			for key, data := range tt.files {
+				artifactType, name, _ := strings.Cut(key, "/")
+				files[key] = artifactFile{artifactType: artifactType, name: name, data: data}
+			}
+			ordered, err := d.order(files)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, ordered)
+		})
+	}
+}
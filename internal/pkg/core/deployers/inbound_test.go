@@ -0,0 +1,148 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package deployers
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/apache/synapse-go/internal/pkg/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// freePort returns a port that nothing listens on
+func freePort(t *testing.T) int {
+	t.Helper()
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func inboundXML(name string, port int, suspend bool) string {
+	return fmt.Sprintf(`<inboundEndpoint xmlns="http://ws.apache.org/ns/synapse" name="%s" sequence="orders" protocol="http" suspend="%t">
+	<parameters><parameter name="inbound.http.port">%d</parameter></parameters>
+</inboundEndpoint>`, name, suspend, port)
+}
+
+// deployInbound deploys an HTTP inbound endpoint named OrdersInbound on a
+// free port, and returns the port
+func deployInbound(t *testing.T, suspend bool) (*Deployer, context.Context, string, int) {
+	t.Helper()
+	d, ctx, basePath := newTestDeployer(t)
+	port := freePort(t)
+	writeArtifact(t, basePath, "Sequences", "orders.xml", sequenceXML("orders", "<drop/>"))
+	writeArtifact(t, basePath, "Inbounds", "orders.xml", inboundXML("OrdersInbound", port, suspend))
+	require.NoError(t, d.Deploy(ctx))
+	return d, ctx, basePath, port
+}
+
+// listening reports whether an inbound endpoint accepts connections on port
+func listening(port int) bool {
+	conn, err := net.DialTimeout("tcp", "localhost:"+strconv.Itoa(port), time.Second)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// inboundState returns the state of the deployed inbound endpoint
+func inboundState(t *testing.T, d *Deployer) string {
+	t.Helper()
+	for _, status := range d.health.Artifacts() {
+		if status.Type == "Inbounds" {
+			return status.State
+		}
+	}
+	return ""
+}
+
+func TestSuspendAndResumeInbound(t *testing.T) {
+	d, ctx, _, port := deployInbound(t, false)
+	require.Eventually(t, func() bool { return listening(port) }, 5*time.Second, 10*time.Millisecond)
+	assert.Eventually(t, func() bool { return inboundState(t, d) == health.StateDeployed }, 5*time.Second, 10*time.Millisecond)
+
+	// Suspending waits for the endpoint to stop
+	require.NoError(t, d.SuspendInbound(ctx, "OrdersInbound"))
+	assert.False(t, listening(port))
+	assert.Equal(t, health.StateSuspended, inboundState(t, d))
+	require.NoError(t, d.SuspendInbound(ctx, "OrdersInbound"), "suspending twice is not an error")
+
+	require.NoError(t, d.ResumeInbound(ctx, "OrdersInbound"))
+	require.Eventually(t, func() bool { return listening(port) }, 5*time.Second, 10*time.Millisecond)
+	assert.Eventually(t, func() bool { return inboundState(t, d) == health.StateDeployed }, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, d.ResumeInbound(ctx, "OrdersInbound"), "resuming a running endpoint does nothing")
+	assert.True(t, listening(port))
+
+	// Deploying unchanged files keeps the endpoint running
+	require.NoError(t, d.Deploy(ctx))
+	assert.True(t, listening(port))
+}
+
+func TestResumeInbound_DeployedSuspended(t *testing.T) {
+	d, ctx, basePath, port := deployInbound(t, true)
+	assert.Contains(t, snapshot(ctx).InboundMap, "OrdersInbound")
+	assert.Equal(t, health.StateSuspended, inboundState(t, d))
+	assert.False(t, listening(port))
+
+	require.NoError(t, d.ResumeInbound(ctx, "OrdersInbound"))
+	require.Eventually(t, func() bool { return listening(port) }, 5*time.Second, 10*time.Millisecond)
+
+	// Undeploying stops the resumed endpoint
+	require.NoError(t, os.Remove(filepath.Join(basePath, "Inbounds", "orders.xml")))
+	require.NoError(t, d.Deploy(ctx))
+	assert.False(t, listening(port))
+	assert.NotContains(t, snapshot(ctx).InboundMap, "OrdersInbound")
+}
+
+func TestRestartInbound(t *testing.T) {
+	d, ctx, _, port := deployInbound(t, true)
+
+	// Restarting a suspended endpoint starts it
+	require.NoError(t, d.RestartInbound(ctx, "OrdersInbound"))
+	require.Eventually(t, func() bool { return listening(port) }, 5*time.Second, 10*time.Millisecond)
+
+	// Restarting a running endpoint stops it first, which frees its port
+	require.NoError(t, d.RestartInbound(ctx, "OrdersInbound"))
+	require.Eventually(t, func() bool { return listening(port) }, 5*time.Second, 10*time.Millisecond)
+	assert.Eventually(t, func() bool { return inboundState(t, d) == health.StateDeployed }, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, d.SuspendInbound(ctx, "OrdersInbound"))
+	assert.False(t, listening(port))
+}
+
+func TestInboundControl_NotFound(t *testing.T) {
+	d, ctx, basePath := newTestDeployer(t)
+	writeArtifact(t, basePath, "APIs", "orders.xml", apiXML("OrdersAPI", "/orders", "/list"))
+	require.NoError(t, d.Deploy(ctx))
+
+	// Only inbound endpoints can be controlled
+	assert.ErrorIs(t, d.SuspendInbound(ctx, "OrdersAPI"), ErrArtifactNotFound)
+	assert.ErrorIs(t, d.ResumeInbound(ctx, "OrdersInbound"), ErrArtifactNotFound)
+	assert.EqualError(t, d.RestartInbound(ctx, "OrdersInbound"), "artifact not found: OrdersInbound")
+}
@@ -0,0 +1,64 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package deployers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/synapse-go/internal/pkg/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// processorState returns the state of a deployed message processor
+func processorState(d *Deployer, name string) string {
+	for _, status := range d.health.Artifacts() {
+		if status.Type == "MessageProcessors" && status.Name == name {
+			return status.State
+		}
+	}
+	return ""
+}
+
+func TestProcessorActivation(t *testing.T) {
+	d, ctx, basePath := newTestDeployer(t)
+	writeArtifact(t, basePath, "Endpoints", "backend.xml", backendXML)
+	writeArtifact(t, basePath, "MessageStores", "orders.xml", storeXML)
+	writeArtifact(t, basePath, "MessageProcessors", "forwarder.xml", `<messageProcessor xmlns="http://ws.apache.org/ns/synapse" name="OrdersForwarder"
+	class="scheduled-forwarding" messageStore="OrdersStore" targetEndpoint="backend"/>`)
+	writeArtifact(t, basePath, "Sequences", "process.xml", sequenceXML("process", "<drop/>"))
+	writeArtifact(t, basePath, "MessageProcessors", "sampler.xml", `<messageProcessor xmlns="http://ws.apache.org/ns/synapse" name="OrdersSampler"
+	class="sampling" messageStore="OrdersStore"><parameter name="sequence">process</parameter></messageProcessor>`)
+	require.NoError(t, d.Deploy(ctx))
+
+	// The processor is suspended until it has read its configuration
+	require.Eventually(t, func() bool {
+		return processorState(d, "OrdersForwarder") == health.StateDeployed
+	}, 2*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, d.DeactivateProcessor(ctx, "OrdersForwarder"))
+	assert.Equal(t, health.StateSuspended, processorState(d, "OrdersForwarder"))
+	require.NoError(t, d.ActivateProcessor(ctx, "OrdersForwarder"))
+	assert.Equal(t, health.StateDeployed, processorState(d, "OrdersForwarder"))
+
+	assert.ErrorIs(t, d.DeactivateProcessor(ctx, "OrdersSampler"), ErrNotSwitchable)
+	assert.ErrorIs(t, d.ActivateProcessor(ctx, "PaymentsForwarder"), ErrArtifactNotFound)
+}
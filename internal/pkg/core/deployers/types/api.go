@@ -60,6 +60,43 @@ type CORSElement struct {
 	MaxAge           string `xml:"max-age,attr,omitempty"`
 }
 
+// RateLimitElement represents the XML structure for API and resource rate limits
+type RateLimitElement struct {
+	Algorithm string `xml:"algorithm,attr,omitempty"`
+	MaxCount  string `xml:"maxCount,attr"`
+	UnitTime  string `xml:"unitTime,attr"`
+	Key       string `xml:"key,attr,omitempty"`
+	KeyName   string `xml:"keyName,attr,omitempty"`
+}
+
+func (r *RateLimitElement) toConfig() (artifacts.RateLimitConfig, error) {
+	policy, err := parseThrottlePolicy(r.Algorithm, r.MaxCount, r.UnitTime)
+	if err != nil {
+		return artifacts.RateLimitConfig{}, fmt.Errorf("invalid rate limit: %w", err)
+	}
+	if err := validateThrottleKey(r.Key, r.KeyName, false); err != nil {
+		return artifacts.RateLimitConfig{}, fmt.Errorf("invalid rate limit: %w", err)
+	}
+	keyType := r.Key
+	if keyType == "" {
+		keyType = artifacts.ThrottleKeyIP
+	}
+	return artifacts.RateLimitConfig{
+		Enabled: true,
+		Policy:  policy,
+		KeyType: keyType,
+		KeyName: r.KeyName,
+	}, nil
+}
+
+func decodeRateLimit(decoder *xml.Decoder, elem xml.StartElement) (artifacts.RateLimitConfig, error) {
+	rateLimitElem := &RateLimitElement{}
+	if err := decoder.DecodeElement(rateLimitElem, &elem); err != nil {
+		return artifacts.RateLimitConfig{}, fmt.Errorf("error decoding rateLimit element: %w", err)
+	}
+	return rateLimitElem.toConfig()
+}
+
 func (api *API) Unmarshal(xmlData string, position artifacts.Position) (artifacts.API, error) {
 	decoder := xml.NewDecoder(strings.NewReader(xmlData))
 	newAPI := artifacts.API{}
@@ -150,6 +187,12 @@ func (api *API) Unmarshal(xmlData string, position artifacts.Position) (artifact
 				}
 
 				newAPI.CORSConfig = cors
+			case "rateLimit":
+				rateLimit, err := decodeRateLimit(decoder, elem)
+				if err != nil {
+					return artifacts.API{}, err
+				}
+				newAPI.RateLimit = rateLimit
 			case "resource":
 				var resource = Resource{}
 				res, err := resource.Unmarshal(decoder, elem, newAPI.Position)
@@ -251,6 +294,12 @@ parsingLoop:
 				} else {
 					res.FaultSequence = seq
 				}
+			case "rateLimit":
+				rateLimit, err := decodeRateLimit(decoder, elem)
+				if err != nil {
+					return artifacts.Resource{}, err
+				}
+				res.RateLimit = rateLimit
 			default:
 				// Skip unknown elements
 				if err := decoder.Skip(); err != nil {
@@ -300,20 +349,11 @@ func (r *Resource) decodeSequence(decoder *xml.Decoder, position artifacts.Posit
 				}
 
 				// Process the first element we found
-				switch startElem.Name.Local {
-				case "log":
-					logMediator := LogMediator{}
-					mediator, err := logMediator.Unmarshal(decoder, startElem, position)
-					if err != nil {
-						return artifacts.Sequence{}, err
-					}
-					mediatorList = append(mediatorList, mediator)
-				case "call":
-					callMediator := CallMediator{}
-					mediator, err := callMediator.Unmarshal(decoder, startElem, position)
-					if err != nil {
-						return artifacts.Sequence{}, err
-					}
+				mediator, err := unmarshalMediator(decoder, startElem, position)
+				if err != nil {
+					return artifacts.Sequence{}, err
+				}
+				if mediator != nil {
 					mediatorList = append(mediatorList, mediator)
 				}
 				// Continue processing other elements
@@ -327,20 +367,11 @@ func (r *Resource) decodeSequence(decoder *xml.Decoder, position artifacts.Posit
 					position := artifacts.Position{LineNo: line, FileName: position.FileName, Hierarchy: position.Hierarchy}
 					switch element := token.(type) {
 					case xml.StartElement:
-						switch element.Name.Local {
-						case "log":
-							logMediator := LogMediator{}
-							mediator, err := logMediator.Unmarshal(decoder, element, position)
-							if err != nil {
-								return artifacts.Sequence{}, err
-							}
-							mediatorList = append(mediatorList, mediator)
-						case "call":
-							callMediator := CallMediator{}
-							mediator, err := callMediator.Unmarshal(decoder, element, position)
-							if err != nil {
-								return artifacts.Sequence{}, err
-							}
+						mediator, err := unmarshalMediator(decoder, element, position)
+						if err != nil {
+							return artifacts.Sequence{}, err
+						}
+						if mediator != nil {
 							mediatorList = append(mediatorList, mediator)
 						}
 					case xml.EndElement:
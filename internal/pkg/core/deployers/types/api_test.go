@@ -36,7 +36,7 @@ func TestAPI_Unmarshal(t *testing.T) {
 			</inSequence>
 			<faultSequence>
 				<sequence>
-					<log level="error"/>
+					<log category="ERROR"/>
 				</sequence>
 			</faultSequence>
 		</resource>
@@ -199,7 +199,7 @@ func TestAPI_UnmarshalWithNestedSequence(t *testing.T) {
 				</sequence>
 			</inSequence>
 			<faultSequence>
-				<log level="error"/>
+				<log category="ERROR"/>
 			</faultSequence>
 		</resource>
 	</api>`
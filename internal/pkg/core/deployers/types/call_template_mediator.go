@@ -0,0 +1,71 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package types
+
+import (
+	"encoding/xml"
+	"errors"
+	"regexp"
+	"strconv"
+
+	"github.com/apache/synapse-go/internal/pkg/core/artifacts"
+)
+
+// withParamExpressionPattern matches the expressions supported in with-param
+var withParamExpressionPattern = regexp.MustCompile(`^\$(func|ctx):[A-Za-z_][A-Za-z0-9_.-]*$`)
+
+type CallTemplateMediator struct {
+	XMLName    xml.Name    `xml:"call-template"`
+	Target     string      `xml:"target,attr"`
+	WithParams []WithParam `xml:"with-param"`
+}
+
+type WithParam struct {
+	Name       string `xml:"name,attr"`
+	Value      string `xml:"value,attr"`
+	Expression string `xml:"expression,attr"`
+}
+
+func (callTemplateMediator CallTemplateMediator) Unmarshal(d *xml.Decoder, start xml.StartElement, position artifacts.Position) (artifacts.Mediator, error) {
+	location := position.FileName + " at line " + strconv.Itoa(position.LineNo)
+	if err := d.DecodeElement(&callTemplateMediator, &start); err != nil {
+		return nil, errors.New("error in unmarshalling call-template mediator in " + location)
+	}
+	if callTemplateMediator.Target == "" {
+		return nil, errors.New("target attribute is required for call-template mediator in " + location)
+	}
+	position.Hierarchy = position.Hierarchy + "->call-template"
+
+	mediator := artifacts.CallTemplateMediator{Target: callTemplateMediator.Target, Position: position}
+	for _, withParam := range callTemplateMediator.WithParams {
+		if withParam.Name == "" {
+			return nil, errors.New("with-param name is required in call-template mediator in " + location)
+		}
+		if withParam.Expression != "" && !withParamExpressionPattern.MatchString(withParam.Expression) {
+			return nil, errors.New("unsupported with-param expression '" + withParam.Expression + "', only $func:name and $ctx:name are supported in " + location)
+		}
+		mediator.Arguments = append(mediator.Arguments, artifacts.TemplateArgument{
+			Name:       withParam.Name,
+			Value:      withParam.Value,
+			Expression: withParam.Expression,
+		})
+	}
+	return mediator, nil
+}
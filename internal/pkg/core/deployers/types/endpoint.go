@@ -33,12 +33,31 @@ type Endpoint struct {
 	Position    artifacts.Position
 }
 
+// TemplateEndpointParameter is a parameter value passed to an endpoint template
+type TemplateEndpointParameter struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
 type EndpointUrl struct {
 	Method       string  `xml:"method,attr"`
 	URITemplate   string `xml:"uri-template,attr"`
 }
 
 func (endpoint *Endpoint) Unmarshal(xmlData string, position artifacts.Position) (artifacts.Endpoint, error) {
+	newEndpoint, err := endpoint.unmarshal(xmlData, position)
+	if err != nil {
+		return artifacts.Endpoint{}, err
+	}
+	if newEndpoint.Name == "" {
+		return artifacts.Endpoint{}, fmt.Errorf("Endpoint name is required")
+	}
+	return newEndpoint, nil
+}
+
+// unmarshal decodes an endpoint without requiring a name, as endpoints in
+// templates take the name of the endpoint that uses the template
+func (endpoint *Endpoint) unmarshal(xmlData string, position artifacts.Position) (artifacts.Endpoint, error) {
 	decoder := xml.NewDecoder(strings.NewReader(xmlData))
 	newEndpoint := artifacts.Endpoint{}
 	newEndpoint.Position = position
@@ -51,11 +70,18 @@ func (endpoint *Endpoint) Unmarshal(xmlData string, position artifacts.Position)
 		case xml.StartElement:
 			switch elem.Name.Local {
 			case "endpoint":
+				if newEndpoint.Position.LineNo == 0 {
+					newEndpoint.Position.LineNo, _ = decoder.InputPos()
+				}
 				for _, attr := range elem.Attr {
 					switch attr.Name.Local {
 					case "name":
 						newEndpoint.Name = attr.Value
 						newEndpoint.Position.Hierarchy = attr.Value
+					case "template":
+						newEndpoint.Template = attr.Value
+					case "uri":
+						setTemplateParameter(&newEndpoint, "uri", attr.Value)
 					}
 				}
 			case "http":
@@ -65,6 +91,12 @@ func (endpoint *Endpoint) Unmarshal(xmlData string, position artifacts.Position)
 					return artifacts.Endpoint{}, err
 				}
 				newEndpoint.EndpointUrl = res
+			case "parameter":
+				var parameter TemplateEndpointParameter
+				if err := decoder.DecodeElement(&parameter, &elem); err != nil {
+					return artifacts.Endpoint{}, err
+				}
+				setTemplateParameter(&newEndpoint, parameter.Name, parameter.Value)
 			default:
 				// Skip unknown elements
 				if err := decoder.Skip(); err != nil {
@@ -77,10 +109,9 @@ func (endpoint *Endpoint) Unmarshal(xmlData string, position artifacts.Position)
 		}
 	}
 
-	if newEndpoint.Name == "" {
-		return artifacts.Endpoint{}, fmt.Errorf("Endpoint name is required")
+	if newEndpoint.Template == "" && newEndpoint.TemplateParameters != nil {
+		return artifacts.Endpoint{}, fmt.Errorf("uri and parameters are only supported on template endpoints in %s", newEndpoint.Position.Hierarchy)
 	}
-
 	return newEndpoint, nil
 }
 
@@ -97,9 +128,29 @@ func (endpointUrl *EndpointUrl) Unmarshal(decoder *xml.Decoder, start xml.StartE
 			method= attr.Value
 		case "uri-template":
 			uriTemplate = attr.Value
+		case "format":
+			res.Format = attr.Value
 		}
 	}
+	if err := validateEndpointFormat(res.Format); err != nil {
+		return artifacts.EndpointUrl{}, err
+	}
 	res.Method = method
 	res.URITemplate = uriTemplate
 	return res, nil
 }
+
+func setTemplateParameter(endpoint *artifacts.Endpoint, name string, value string) {
+	if endpoint.TemplateParameters == nil {
+		endpoint.TemplateParameters = make(map[string]string)
+	}
+	endpoint.TemplateParameters[name] = value
+}
+
+func validateEndpointFormat(format string) error {
+	switch format {
+	case "", artifacts.EndpointFormatSOAP11, artifacts.EndpointFormatSOAP12, artifacts.EndpointFormatPOX, artifacts.EndpointFormatREST:
+		return nil
+	}
+	return fmt.Errorf("unsupported endpoint format '%s': must be soap11, soap12, pox or rest", format)
+}
@@ -189,3 +189,20 @@ func TestEndpoint_Unmarshal_MultipleElements(t *testing.T) {
 	assert.Equal(t, "PUT", result.EndpointUrl.Method)
 	assert.Equal(t, "https://api.example.com/resource", result.EndpointUrl.URITemplate)
 }
+
+func TestEndpoint_Unmarshal_Format(t *testing.T) {
+	xmlData := `<endpoint name="soapEndpoint">
+		<http method="POST" uri-template="https://api.example.com/services/quotes" format="soap12"/>
+	</endpoint>`
+
+	endpoint := &Endpoint{}
+	result, err := endpoint.Unmarshal(xmlData, artifacts.Position{})
+	assert.NoError(t, err)
+	assert.Equal(t, artifacts.EndpointFormatSOAP12, result.EndpointUrl.Format)
+
+	decoder := xml.NewDecoder(strings.NewReader(`<http method="POST" uri-template="https://api.example.com" format="mtom"/>`))
+	token, _ := decoder.Token()
+	endpointUrl := &EndpointUrl{}
+	_, err = endpointUrl.Unmarshal(decoder, token.(xml.StartElement), artifacts.Position{})
+	assert.ErrorContains(t, err, "unsupported endpoint format 'mtom'")
+}
@@ -55,5 +55,6 @@ func (inbound *Inbound) Unmarshal(xmlData string, position artifacts.Position) (
 		newInbound.Parameters = append(newInbound.Parameters, artifacts.Parameter{Name: parameter.Name, Value: parameter.Value})
 	}
 	newInbound.Position.Hierarchy = position.Hierarchy
+	newInbound.Position.LineNo = rootLine(xmlData)
 	return newInbound, nil
 }
@@ -0,0 +1,65 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package types
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/apache/synapse-go/internal/pkg/core/artifacts"
+)
+
+type LocalEntry struct {
+	Key      string `xml:"key,attr"`
+	Src      string `xml:"src,attr"`
+	Text     string `xml:",chardata"`
+	InnerXML string `xml:",innerxml"`
+}
+
+func (localEntry *LocalEntry) Unmarshal(xmlData string, position artifacts.Position) (artifacts.LocalEntry, error) {
+	if err := xml.Unmarshal([]byte(xmlData), localEntry); err != nil {
+		return artifacts.LocalEntry{}, err
+	}
+	if localEntry.Key == "" {
+		return artifacts.LocalEntry{}, fmt.Errorf("local entry key is required")
+	}
+
+	// Inline XML is kept as written, plain text and CDATA are unescaped
+	value := strings.TrimSpace(localEntry.InnerXML)
+	if !strings.HasPrefix(value, "<") || strings.HasPrefix(value, "<![CDATA[") {
+		value = strings.TrimSpace(localEntry.Text)
+	}
+	if localEntry.Src != "" && value != "" {
+		return artifacts.LocalEntry{}, fmt.Errorf("local entry %s must have either a src or inline content, not both", localEntry.Key)
+	}
+	if localEntry.Src == "" && value == "" {
+		return artifacts.LocalEntry{}, fmt.Errorf("local entry %s requires a src or inline content", localEntry.Key)
+	}
+
+	newLocalEntry := artifacts.LocalEntry{
+		Key:      localEntry.Key,
+		Value:    value,
+		Src:      localEntry.Src,
+		Position: position,
+	}
+	newLocalEntry.Position.Hierarchy = localEntry.Key
+	return newLocalEntry, nil
+}
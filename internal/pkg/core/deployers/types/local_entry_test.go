@@ -0,0 +1,74 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package types
+
+import (
+	"testing"
+
+	"github.com/apache/synapse-go/internal/pkg/core/artifacts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalEntry_Unmarshal(t *testing.T) {
+	tests := []struct {
+		name    string
+		xmlData string
+		value   string
+		src     string
+	}{
+		{"text", `<localEntry key="greeting">Hello &amp; welcome</localEntry>`, "Hello & welcome", ""},
+		{"cdata", `<localEntry key="script"><![CDATA[a < b]]></localEntry>`, "a < b", ""},
+		{"xml", `<localEntry key="config"><settings xmlns="urn:cfg"><retry>3</retry></settings></localEntry>`, `<settings xmlns="urn:cfg"><retry>3</retry></settings>`, ""},
+		{"src", `<localEntry key="transform" src="file:resources/transform.xslt"/>`, "", "file:resources/transform.xslt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			localEntry := &LocalEntry{}
+			result, err := localEntry.Unmarshal(tt.xmlData, artifacts.Position{FileName: "entry.xml"})
+			require.NoError(t, err)
+			assert.Equal(t, tt.value, result.Value)
+			assert.Equal(t, tt.src, result.Src)
+			assert.Equal(t, "entry.xml", result.Position.FileName)
+			assert.Equal(t, result.Key, result.Position.Hierarchy)
+		})
+	}
+}
+
+func TestLocalEntry_Unmarshal_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		xmlData string
+		errMsg  string
+	}{
+		{"missing key", `<localEntry>text</localEntry>`, "local entry key is required"},
+		{"empty", `<localEntry key="e"/>`, "requires a src or inline content"},
+		{"src and content", `<localEntry key="e" src="file:a.txt">text</localEntry>`, "either a src or inline content"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			localEntry := &LocalEntry{}
+			_, err := localEntry.Unmarshal(tt.xmlData, artifacts.Position{})
+			assert.ErrorContains(t, err, tt.errMsg)
+		})
+	}
+}
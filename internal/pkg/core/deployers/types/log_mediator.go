@@ -22,25 +22,69 @@ package types
 import (
 	"encoding/xml"
 	"errors"
+	"regexp"
 	"strconv"
+	"strings"
 
 	"github.com/apache/synapse-go/internal/pkg/core/artifacts"
 )
 
+// logPropertyExpressionPattern matches the expressions supported in log properties
+var logPropertyExpressionPattern = regexp.MustCompile(`^\$(func|ctx|trp):[A-Za-z_][A-Za-z0-9_.-]*$`)
+
 type LogMediator struct {
-	XMLName  xml.Name `xml:"log"`
-	Category string   `xml:"category,attr"`
-	Message  string   `xml:"message"`
+	XMLName    xml.Name      `xml:"log"`
+	Level      string        `xml:"level,attr"`
+	Category   string        `xml:"category,attr"`
+	Separator  string        `xml:"separator,attr"`
+	Message    string        `xml:"message"`
+	Properties []LogProperty `xml:"property"`
+}
+
+type LogProperty struct {
+	Name       string `xml:"name,attr"`
+	Value      string `xml:"value,attr"`
+	Expression string `xml:"expression,attr"`
 }
 
 func (logMediator LogMediator) Unmarshal(d *xml.Decoder, start xml.StartElement, position artifacts.Position) (artifacts.Mediator, error) {
+	location := position.FileName + " at line " + strconv.Itoa(position.LineNo)
 	if err := d.DecodeElement(&logMediator, &start); err != nil {
-		return artifacts.LogMediator{}, errors.New("error in unmarshalling log mediator in " + position.FileName + " at line " + strconv.Itoa(position.LineNo))
+		return artifacts.LogMediator{}, errors.New("error in unmarshalling log mediator in " + location)
+	}
+	level := strings.ToLower(logMediator.Level)
+	switch level {
+	case "":
+		level = artifacts.LogLevelSimple
+	case artifacts.LogLevelSimple, artifacts.LogLevelHeaders, artifacts.LogLevelFull, artifacts.LogLevelCustom:
+	default:
+		return nil, errors.New("invalid log level '" + logMediator.Level + "', expected simple, headers, full or custom in " + location)
+	}
+	// Categories holding template parameters are resolved when the mediator runs
+	if logMediator.Category != "" && !strings.Contains(logMediator.Category, "$func:") && !artifacts.IsLogCategory(logMediator.Category) {
+		return nil, errors.New("invalid log category '" + logMediator.Category + "', expected TRACE, DEBUG, INFO, WARN, ERROR or FATAL in " + location)
 	}
 	position.Hierarchy = position.Hierarchy + "->log"
-	return artifacts.LogMediator{
-		Category: logMediator.Category,
-		Message:  logMediator.Message,
-		Position: position,
-	}, nil
+
+	mediator := artifacts.LogMediator{
+		Category:  logMediator.Category,
+		Level:     level,
+		Separator: logMediator.Separator,
+		Message:   logMediator.Message,
+		Position:  position,
+	}
+	for _, property := range logMediator.Properties {
+		if property.Name == "" {
+			return nil, errors.New("property name is required in log mediator in " + location)
+		}
+		if property.Expression != "" && !logPropertyExpressionPattern.MatchString(property.Expression) {
+			return nil, errors.New("unsupported property expression '" + property.Expression + "', only $func:name, $ctx:name and $trp:name are supported in " + location)
+		}
+		mediator.Properties = append(mediator.Properties, artifacts.LogProperty{
+			Name:       property.Name,
+			Value:      property.Value,
+			Expression: property.Expression,
+		})
+	}
+	return mediator, nil
 }
@@ -65,3 +65,58 @@ func TestLogMediator_Unmarshal(t *testing.T) {
 		})
 	}
 }
+
+func TestLogMediator_UnmarshalProperties(t *testing.T) {
+	xmlData := `<log level="custom" category="WARN" separator=" | ">
+		<message>Order received</message>
+		<property name="fixed" value="yes"/>
+		<property name="id" expression="$ctx:orderId"/>
+		<property name="customer" expression="$trp:X-Customer"/>
+	</log>`
+	decoder := xml.NewDecoder(strings.NewReader(xmlData))
+	token, _ := decoder.Token()
+	mediator, err := LogMediator{}.Unmarshal(decoder, token.(xml.StartElement), artifacts.Position{})
+	assert.NoError(t, err)
+	logMediator := mediator.(artifacts.LogMediator)
+	assert.Equal(t, artifacts.LogLevelCustom, logMediator.Level)
+	assert.Equal(t, "WARN", logMediator.Category)
+	assert.Equal(t, " | ", logMediator.Separator)
+	assert.Equal(t, "Order received", logMediator.Message)
+	assert.Equal(t, []artifacts.LogProperty{
+		{Name: "fixed", Value: "yes"},
+		{Name: "id", Expression: "$ctx:orderId"},
+		{Name: "customer", Expression: "$trp:X-Customer"},
+	}, logMediator.Properties)
+}
+
+func TestLogMediator_UnmarshalDefaultLevel(t *testing.T) {
+	decoder := xml.NewDecoder(strings.NewReader(`<log/>`))
+	token, _ := decoder.Token()
+	mediator, err := LogMediator{}.Unmarshal(decoder, token.(xml.StartElement), artifacts.Position{})
+	assert.NoError(t, err)
+	assert.Equal(t, artifacts.LogLevelSimple, mediator.(artifacts.LogMediator).Level)
+}
+
+func TestLogMediator_UnmarshalErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		xmlData string
+		wantErr string
+	}{
+		{"Invalid level", `<log level="verbose"/>`, "invalid log level 'verbose'"},
+		{"Invalid category", `<log category="LOUD"/>`, "invalid log category 'LOUD'"},
+		{"Property without name", `<log><property value="x"/></log>`, "property name is required"},
+		{"Unsupported expression", `<log><property name="x" expression="json-eval($.id)"/></log>`, "unsupported property expression 'json-eval($.id)'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoder := xml.NewDecoder(strings.NewReader(tt.xmlData))
+			token, _ := decoder.Token()
+			_, err := LogMediator{}.Unmarshal(decoder, token.(xml.StartElement), artifacts.Position{FileName: "test.xml", LineNo: 3})
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.wantErr)
+				assert.Contains(t, err.Error(), "in test.xml at line 3")
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package types
+
+import (
+	"encoding/xml"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/apache/synapse-go/internal/pkg/core/artifacts"
+)
+
+// SOAP 1.1 and 1.2 fault codes. Codes of one version are mapped onto the
+// other when the fault is built.
+var soapFaultCodes = map[string]bool{
+	"VersionMismatch":     true,
+	"MustUnderstand":      true,
+	"DataEncodingUnknown": true,
+	"Client":              true,
+	"Server":              true,
+	"Sender":              true,
+	"Receiver":            true,
+}
+
+type MakeFaultMediator struct {
+	XMLName  xml.Name         `xml:"makefault"`
+	Version  string           `xml:"version,attr"`
+	Response string           `xml:"response,attr"`
+	Code     *FaultValue      `xml:"code"`
+	Reason   *FaultValue      `xml:"reason"`
+	Role     string           `xml:"role"`
+	Detail   *FaultDetailElem `xml:"detail"`
+}
+
+type FaultValue struct {
+	Value      string `xml:"value,attr"`
+	Expression string `xml:"expression,attr"`
+}
+
+type FaultDetailElem struct {
+	InnerXML string `xml:",innerxml"`
+}
+
+func (makeFaultMediator MakeFaultMediator) Unmarshal(d *xml.Decoder, start xml.StartElement, position artifacts.Position) (artifacts.Mediator, error) {
+	location := position.FileName + " at line " + strconv.Itoa(position.LineNo)
+	if err := d.DecodeElement(&makeFaultMediator, &start); err != nil {
+		return nil, errors.New("error in unmarshalling makefault mediator in " + location)
+	}
+
+	switch makeFaultMediator.Version {
+	case "", artifacts.EndpointFormatSOAP11, artifacts.EndpointFormatSOAP12, artifacts.EndpointFormatPOX:
+	default:
+		return nil, errors.New("invalid version '" + makeFaultMediator.Version + "' for makefault mediator in " + location + ", must be soap11, soap12 or pox")
+	}
+	for _, value := range []*FaultValue{makeFaultMediator.Code, makeFaultMediator.Reason} {
+		if value != nil && value.Expression != "" {
+			return nil, errors.New("expressions are not supported in makefault mediator in " + location)
+		}
+	}
+
+	mediator := artifacts.MakeFaultMediator{
+		Version:  makeFaultMediator.Version,
+		Role:     strings.TrimSpace(makeFaultMediator.Role),
+		Response: makeFaultMediator.Response == "true",
+	}
+	if makeFaultMediator.Code != nil {
+		mediator.Code = makeFaultMediator.Code.Value
+		if i := strings.LastIndex(mediator.Code, ":"); i >= 0 {
+			mediator.Code = mediator.Code[i+1:]
+		}
+	}
+	if makeFaultMediator.Reason != nil {
+		mediator.Reason = makeFaultMediator.Reason.Value
+	}
+	if makeFaultMediator.Detail != nil {
+		mediator.Detail = strings.TrimSpace(makeFaultMediator.Detail.InnerXML)
+	}
+
+	if mediator.Version != artifacts.EndpointFormatPOX {
+		if !soapFaultCodes[mediator.Code] {
+			return nil, errors.New("invalid or missing fault code '" + mediator.Code + "' for makefault mediator in " + location)
+		}
+		if mediator.Reason == "" {
+			return nil, errors.New("reason is required for makefault mediator in " + location)
+		}
+	} else if mediator.Reason == "" && mediator.Detail == "" {
+		return nil, errors.New("reason or detail is required for pox makefault mediator in " + location)
+	}
+
+	position.Hierarchy = position.Hierarchy + "->makefault"
+	mediator.Position = position
+	return mediator, nil
+}
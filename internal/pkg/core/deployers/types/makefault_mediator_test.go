@@ -0,0 +1,80 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package types
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/apache/synapse-go/internal/pkg/core/artifacts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func unmarshalMakeFault(t *testing.T, xmlData string) (artifacts.Mediator, error) {
+	decoder := xml.NewDecoder(strings.NewReader(xmlData))
+	token, err := decoder.Token()
+	require.NoError(t, err)
+	start := token.(xml.StartElement)
+	return MakeFaultMediator{}.Unmarshal(decoder, start, artifacts.Position{FileName: "test.xml", LineNo: 3, Hierarchy: "seq"})
+}
+
+func TestMakeFaultMediator_Unmarshal(t *testing.T) {
+	xmlData := `<makefault version="soap11" response="true">
+		<code value="soap11Env:Client" xmlns:soap11Env="http://schemas.xmlsoap.org/soap/envelope/"/>
+		<reason value="Invalid symbol"/>
+		<role>urn:quotes</role>
+		<detail><symbol>XYZ</symbol></detail>
+	</makefault>`
+
+	mediator, err := unmarshalMakeFault(t, xmlData)
+	require.NoError(t, err)
+	makeFault := mediator.(artifacts.MakeFaultMediator)
+	assert.Equal(t, "soap11", makeFault.Version)
+	assert.Equal(t, "Client", makeFault.Code)
+	assert.Equal(t, "Invalid symbol", makeFault.Reason)
+	assert.Equal(t, "urn:quotes", makeFault.Role)
+	assert.Equal(t, "<symbol>XYZ</symbol>", makeFault.Detail)
+	assert.True(t, makeFault.Response)
+	assert.Equal(t, "seq->makefault", makeFault.Position.Hierarchy)
+}
+
+func TestMakeFaultMediator_Unmarshal_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		xmlData string
+		wantErr string
+	}{
+		{"bad version", `<makefault version="soap13"><code value="Server"/><reason value="r"/></makefault>`, "invalid version 'soap13'"},
+		{"missing code", `<makefault><reason value="r"/></makefault>`, "invalid or missing fault code ''"},
+		{"unknown code", `<makefault><code value="Busy"/><reason value="r"/></makefault>`, "invalid or missing fault code 'Busy'"},
+		{"missing reason", `<makefault><code value="Server"/></makefault>`, "reason is required for makefault mediator in test.xml at line 3"},
+		{"empty pox", `<makefault version="pox"/>`, "reason or detail is required"},
+		{"expression", `<makefault><code value="Server"/><reason expression="//error"/></makefault>`, "expressions are not supported"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := unmarshalMakeFault(t, tt.xmlData)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
@@ -21,6 +21,7 @@ package types
 
 import (
 	"encoding/xml"
+	"strings"
 
 	"github.com/apache/synapse-go/internal/pkg/core/artifacts"
 )
@@ -28,3 +29,71 @@ import (
 type Mediator interface {
 	Unmarshal(d *xml.Decoder, start xml.StartElement, position artifacts.Position) (artifacts.Mediator, error)
 }
+
+// unmarshalMediator decodes the mediator that starts at element. It returns a
+// nil mediator when the element is not a known mediator.
+func unmarshalMediator(decoder *xml.Decoder, element xml.StartElement, position artifacts.Position) (artifacts.Mediator, error) {
+	var mediator Mediator
+	switch element.Name.Local {
+	case "log":
+		mediator = LogMediator{}
+	case "call":
+		mediator = CallMediator{}
+	case "respond":
+		mediator = RespondMediator{}
+	case "throttle":
+		mediator = ThrottleMediator{}
+	case "store":
+		mediator = StoreMediator{}
+	case "makefault":
+		mediator = MakeFaultMediator{}
+	case "call-template":
+		mediator = CallTemplateMediator{}
+	default:
+		return nil, nil
+	}
+	return mediator.Unmarshal(decoder, element, position)
+}
+
+// unmarshalMediatorList decodes mediators until the end element named endTag
+func unmarshalMediatorList(decoder *xml.Decoder, endTag string, position artifacts.Position) ([]artifacts.Mediator, error) {
+	var mediatorList []artifacts.Mediator
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return mediatorList, err
+		}
+		line, _ := decoder.InputPos()
+		position := artifacts.Position{LineNo: line, FileName: position.FileName, Hierarchy: position.Hierarchy}
+		switch element := token.(type) {
+		case xml.StartElement:
+			mediator, err := unmarshalMediator(decoder, element, position)
+			if err != nil {
+				return nil, err
+			}
+			if mediator != nil {
+				mediatorList = append(mediatorList, mediator)
+			}
+		case xml.EndElement:
+			if element.Name.Local == endTag {
+				return mediatorList, nil
+			}
+		}
+	}
+}
+
+// rootLine returns the line of the root element of xmlData, so that errors
+// about attributes of an artifact can point at it
+func rootLine(xmlData string) int {
+	decoder := xml.NewDecoder(strings.NewReader(xmlData))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return 0
+		}
+		if _, ok := token.(xml.StartElement); ok {
+			line, _ := decoder.InputPos()
+			return line
+		}
+	}
+}
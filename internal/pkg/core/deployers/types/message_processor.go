@@ -0,0 +1,79 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package types
+
+import (
+	"encoding/xml"
+	"fmt"
+
+	"github.com/apache/synapse-go/internal/pkg/core/artifacts"
+)
+
+// Apache Synapse class names that map onto the built-in processor types
+var messageProcessorClasses = map[string]string{
+	artifacts.MessageProcessorScheduledForwarding:                                             artifacts.MessageProcessorScheduledForwarding,
+	artifacts.MessageProcessorSampling:                                                        artifacts.MessageProcessorSampling,
+	"org.apache.synapse.message.processor.impl.forwarder.ScheduledMessageForwardingProcessor": artifacts.MessageProcessorScheduledForwarding,
+	"org.apache.synapse.message.processor.impl.sampler.SamplingProcessor":                     artifacts.MessageProcessorSampling,
+}
+
+type MessageProcessor struct {
+	Name           string      `xml:"name,attr"`
+	Class          string      `xml:"class,attr"`
+	MessageStore   string      `xml:"messageStore,attr"`
+	TargetEndpoint string      `xml:"targetEndpoint,attr"`
+	Parameters     []Parameter `xml:"parameter"`
+}
+
+func (messageProcessor *MessageProcessor) Unmarshal(xmlData string, position artifacts.Position) (artifacts.MessageProcessor, error) {
+	if err := xml.Unmarshal([]byte(xmlData), messageProcessor); err != nil {
+		return artifacts.MessageProcessor{}, err
+	}
+	if messageProcessor.Name == "" {
+		return artifacts.MessageProcessor{}, fmt.Errorf("message processor name is required")
+	}
+	processorType, ok := messageProcessorClasses[messageProcessor.Class]
+	if !ok {
+		return artifacts.MessageProcessor{}, fmt.Errorf("unsupported message processor class: %s", messageProcessor.Class)
+	}
+	if messageProcessor.MessageStore == "" {
+		return artifacts.MessageProcessor{}, fmt.Errorf("messageStore is required for message processor %s", messageProcessor.Name)
+	}
+	if processorType == artifacts.MessageProcessorScheduledForwarding && messageProcessor.TargetEndpoint == "" {
+		return artifacts.MessageProcessor{}, fmt.Errorf("targetEndpoint is required for message processor %s", messageProcessor.Name)
+	}
+
+	newMessageProcessor := artifacts.MessageProcessor{
+		Name:           messageProcessor.Name,
+		Type:           processorType,
+		MessageStore:   messageProcessor.MessageStore,
+		TargetEndpoint: messageProcessor.TargetEndpoint,
+		Position:       position,
+	}
+	newMessageProcessor.Position.Hierarchy = messageProcessor.Name
+	newMessageProcessor.Position.LineNo = rootLine(xmlData)
+	for _, parameter := range messageProcessor.Parameters {
+		newMessageProcessor.Parameters = append(newMessageProcessor.Parameters, artifacts.Parameter{Name: parameter.Name, Value: parameter.Value})
+	}
+	if processorType == artifacts.MessageProcessorSampling && newMessageProcessor.Parameter("sequence") == "" {
+		return artifacts.MessageProcessor{}, fmt.Errorf("sequence parameter is required for message processor %s", messageProcessor.Name)
+	}
+	return newMessageProcessor, nil
+}
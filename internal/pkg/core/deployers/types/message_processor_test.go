@@ -0,0 +1,82 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package types
+
+import (
+	"testing"
+
+	"github.com/apache/synapse-go/internal/pkg/core/artifacts"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessageProcessor_Unmarshal_Forwarding(t *testing.T) {
+	xmlData := `<messageProcessor name="OrdersForwarder" class="org.apache.synapse.message.processor.impl.forwarder.ScheduledMessageForwardingProcessor"
+			messageStore="OrdersStore" targetEndpoint="OrdersEndpoint" xmlns="http://ws.apache.org/ns/synapse">
+		<parameter name="interval">1000</parameter>
+		<parameter name="max.delivery.attempts">3</parameter>
+		<parameter name="message.processor.dead.letter.store">OrdersDLQ</parameter>
+	</messageProcessor>`
+
+	messageProcessor := &MessageProcessor{}
+	result, err := messageProcessor.Unmarshal(xmlData, artifacts.Position{FileName: "OrdersForwarder.xml"})
+	assert.NoError(t, err)
+	assert.Equal(t, "OrdersForwarder", result.Name)
+	assert.Equal(t, artifacts.MessageProcessorScheduledForwarding, result.Type)
+	assert.Equal(t, "OrdersStore", result.MessageStore)
+	assert.Equal(t, "OrdersEndpoint", result.TargetEndpoint)
+	assert.Equal(t, "OrdersForwarder", result.Position.Hierarchy)
+	assert.Len(t, result.Parameters, 3)
+	assert.Equal(t, "OrdersDLQ", result.Parameter("message.processor.dead.letter.store"))
+}
+
+func TestMessageProcessor_Unmarshal_Sampling(t *testing.T) {
+	xmlData := `<messageProcessor name="Sampler" class="sampling" messageStore="OrdersStore">
+		<parameter name="sequence">processOrder</parameter>
+		<parameter name="concurrency">5</parameter>
+	</messageProcessor>`
+
+	messageProcessor := &MessageProcessor{}
+	result, err := messageProcessor.Unmarshal(xmlData, artifacts.Position{})
+	assert.NoError(t, err)
+	assert.Equal(t, artifacts.MessageProcessorSampling, result.Type)
+	assert.Equal(t, "processOrder", result.Parameter("sequence"))
+}
+
+func TestMessageProcessor_Unmarshal_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		xmlData string
+		wantErr string
+	}{
+		{"missing name", `<messageProcessor class="sampling" messageStore="s"/>`, "message processor name is required"},
+		{"unknown class", `<messageProcessor name="p" class="resequencing" messageStore="s"/>`, "unsupported message processor class"},
+		{"missing store", `<messageProcessor name="p" class="sampling"/>`, "messageStore is required"},
+		{"missing endpoint", `<messageProcessor name="p" class="scheduled-forwarding" messageStore="s"/>`, "targetEndpoint is required"},
+		{"missing sequence", `<messageProcessor name="p" class="sampling" messageStore="s"/>`, "sequence parameter is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messageProcessor := &MessageProcessor{}
+			_, err := messageProcessor.Unmarshal(tt.xmlData, artifacts.Position{})
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,66 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package types
+
+import (
+	"encoding/xml"
+	"fmt"
+
+	"github.com/apache/synapse-go/internal/pkg/core/artifacts"
+	"github.com/apache/synapse-go/internal/pkg/core/messagestore"
+)
+
+// Apache Synapse class names that map onto the built-in store types
+var messageStoreClasses = map[string]string{
+	"":                      messagestore.TypeMemory,
+	messagestore.TypeMemory: messagestore.TypeMemory,
+	messagestore.TypeBbolt:  messagestore.TypeBbolt,
+	"org.apache.synapse.message.store.impl.memory.InMemoryStore": messagestore.TypeMemory,
+}
+
+type MessageStore struct {
+	Name       string      `xml:"name,attr"`
+	Class      string      `xml:"class,attr"`
+	Parameters []Parameter `xml:"parameter"`
+}
+
+func (messageStore *MessageStore) Unmarshal(xmlData string, position artifacts.Position) (artifacts.MessageStore, error) {
+	if err := xml.Unmarshal([]byte(xmlData), messageStore); err != nil {
+		return artifacts.MessageStore{}, err
+	}
+	if messageStore.Name == "" {
+		return artifacts.MessageStore{}, fmt.Errorf("message store name is required")
+	}
+	storeType, ok := messageStoreClasses[messageStore.Class]
+	if !ok {
+		return artifacts.MessageStore{}, fmt.Errorf("unsupported message store class: %s", messageStore.Class)
+	}
+
+	newMessageStore := artifacts.MessageStore{
+		Name:     messageStore.Name,
+		Type:     storeType,
+		Position: position,
+	}
+	newMessageStore.Position.Hierarchy = messageStore.Name
+	for _, parameter := range messageStore.Parameters {
+		newMessageStore.Parameters = append(newMessageStore.Parameters, artifacts.Parameter{Name: parameter.Name, Value: parameter.Value})
+	}
+	return newMessageStore, nil
+}
@@ -0,0 +1,75 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package types
+
+import (
+	"testing"
+
+	"github.com/apache/synapse-go/internal/pkg/core/artifacts"
+	"github.com/apache/synapse-go/internal/pkg/core/messagestore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessageStore_Unmarshal(t *testing.T) {
+	xmlData := `<messageStore name="OrdersStore" class="bbolt" xmlns="http://ws.apache.org/ns/synapse">
+		<parameter name="store.bbolt.path">/var/synapse/orders.db</parameter>
+	</messageStore>`
+
+	messageStore := &MessageStore{}
+	result, err := messageStore.Unmarshal(xmlData, artifacts.Position{FileName: "OrdersStore.xml"})
+	assert.NoError(t, err)
+	assert.Equal(t, "OrdersStore", result.Name)
+	assert.Equal(t, messagestore.TypeBbolt, result.Type)
+	assert.Equal(t, "OrdersStore", result.Position.Hierarchy)
+	assert.Equal(t, "OrdersStore.xml", result.Position.FileName)
+	assert.Equal(t, []artifacts.Parameter{{Name: "store.bbolt.path", Value: "/var/synapse/orders.db"}}, result.Parameters)
+}
+
+func TestMessageStore_Unmarshal_Classes(t *testing.T) {
+	testCases := []struct {
+		class    string
+		wantType string
+		wantErr  bool
+	}{
+		{"", messagestore.TypeMemory, false},
+		{"memory", messagestore.TypeMemory, false},
+		{"org.apache.synapse.message.store.impl.memory.InMemoryStore", messagestore.TypeMemory, false},
+		{"org.apache.synapse.message.store.impl.jms.JmsStore", "", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.class, func(t *testing.T) {
+			messageStore := &MessageStore{}
+			result, err := messageStore.Unmarshal(`<messageStore name="s" class="`+tc.class+`"/>`, artifacts.Position{})
+			if tc.wantErr {
+				assert.ErrorContains(t, err, "unsupported message store class")
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantType, result.Type)
+		})
+	}
+}
+
+func TestMessageStore_Unmarshal_MissingName(t *testing.T) {
+	messageStore := &MessageStore{}
+	_, err := messageStore.Unmarshal(`<messageStore class="memory"/>`, artifacts.Position{})
+	assert.ErrorContains(t, err, "message store name is required")
+}
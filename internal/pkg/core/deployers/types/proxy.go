@@ -0,0 +1,253 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package types
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/apache/synapse-go/internal/pkg/core/artifacts"
+)
+
+type ProxyService struct {
+	Name string
+}
+
+type PublishWSDLElement struct {
+	URI      string `xml:"uri,attr"`
+	Key      string `xml:"key,attr"`
+	InnerXML string `xml:",innerxml"`
+}
+
+func (proxy *ProxyService) Unmarshal(xmlData string, position artifacts.Position) (artifacts.ProxyService, error) {
+	decoder := xml.NewDecoder(strings.NewReader(xmlData))
+	newProxy := artifacts.ProxyService{
+		Transports:  []string{"http", "https"},
+		StartOnLoad: true,
+		Position:    position,
+	}
+
+	// Find the <proxy> element and read its attributes
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return artifacts.ProxyService{}, fmt.Errorf("proxy element not found: %w", err)
+		}
+		if elem, ok := token.(xml.StartElement); ok {
+			if elem.Name.Local != "proxy" {
+				return artifacts.ProxyService{}, fmt.Errorf("expected proxy element, got: %s", elem.Name.Local)
+			}
+			newProxy.Position.LineNo, _ = decoder.InputPos()
+			for _, attr := range elem.Attr {
+				switch attr.Name.Local {
+				case "name":
+					proxy.Name = attr.Value
+				case "transports":
+					newProxy.Transports = strings.FieldsFunc(attr.Value, func(r rune) bool { return r == ' ' || r == ',' })
+				case "startOnLoad":
+					newProxy.StartOnLoad = attr.Value != "false"
+				}
+			}
+			break
+		}
+	}
+	if proxy.Name == "" {
+		return artifacts.ProxyService{}, fmt.Errorf("proxy name is required")
+	}
+	// The name becomes a path segment of /services/{name}
+	if strings.ContainsAny(proxy.Name, "/{}?# ") {
+		return artifacts.ProxyService{}, fmt.Errorf("invalid proxy name '%s': must be a single URL path segment", proxy.Name)
+	}
+	newProxy.Name = proxy.Name
+	newProxy.Position.Hierarchy = proxy.Name
+
+	hasTarget := false
+parsingLoop:
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		switch elem := token.(type) {
+		case xml.StartElement:
+			switch elem.Name.Local {
+			case "target":
+				target, err := proxy.decodeTarget(decoder, elem, newProxy.Position)
+				if err != nil {
+					return artifacts.ProxyService{}, err
+				}
+				newProxy.Target = target
+				hasTarget = true
+			case "publishWSDL":
+				wsdl, err := proxy.decodeWSDL(decoder, elem)
+				if err != nil {
+					return artifacts.ProxyService{}, err
+				}
+				newProxy.WSDL = wsdl
+			case "parameter":
+				parameter := Parameter{}
+				if err := decoder.DecodeElement(&parameter, &elem); err != nil {
+					return artifacts.ProxyService{}, fmt.Errorf("error decoding parameter in proxy %s: %w", proxy.Name, err)
+				}
+				newProxy.Parameters = append(newProxy.Parameters, artifacts.Parameter{Name: parameter.Name, Value: strings.TrimSpace(parameter.Value)})
+			default:
+				// Skip unknown elements such as <description>
+				if err := decoder.Skip(); err != nil {
+					return artifacts.ProxyService{}, err
+				}
+			}
+		case xml.EndElement:
+			// Stop when the </proxy> tag is encountered
+			if elem.Name.Local == "proxy" {
+				break parsingLoop
+			}
+		}
+	}
+
+	if !hasTarget {
+		return artifacts.ProxyService{}, fmt.Errorf("target is required for proxy %s", proxy.Name)
+	}
+	return newProxy, nil
+}
+
+func (proxy *ProxyService) decodeTarget(decoder *xml.Decoder, start xml.StartElement, position artifacts.Position) (artifacts.ProxyTarget, error) {
+	target := artifacts.ProxyTarget{Position: position}
+	target.Position.LineNo, _ = decoder.InputPos()
+	for _, attr := range start.Attr {
+		switch attr.Name.Local {
+		case "inSequence":
+			target.InSequence.Key = attr.Value
+		case "outSequence":
+			target.OutSequence.Key = attr.Value
+		case "faultSequence":
+			target.FaultSequence.Key = attr.Value
+		case "endpoint":
+			target.Endpoint = attr.Value
+		}
+	}
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return artifacts.ProxyTarget{}, err
+		}
+		switch elem := token.(type) {
+		case xml.StartElement:
+			switch elem.Name.Local {
+			case "inSequence", "outSequence", "faultSequence":
+				line, _ := decoder.InputPos()
+				seqPosition := artifacts.Position{FileName: position.FileName, LineNo: line, Hierarchy: position.Hierarchy + "->" + elem.Name.Local}
+				mediatorList, err := unmarshalMediatorList(decoder, elem.Name.Local, seqPosition)
+				if err != nil {
+					return artifacts.ProxyTarget{}, err
+				}
+				sequence := &artifacts.Sequence{MediatorList: mediatorList, Position: seqPosition}
+				switch elem.Name.Local {
+				case "inSequence":
+					target.InSequence = artifacts.ProxySequence{Inline: sequence}
+				case "outSequence":
+					target.OutSequence = artifacts.ProxySequence{Inline: sequence}
+				default:
+					target.FaultSequence = artifacts.ProxySequence{Inline: sequence}
+				}
+			case "endpoint":
+				if err := proxy.decodeEndpoint(decoder, elem, position, &target); err != nil {
+					return artifacts.ProxyTarget{}, err
+				}
+			default:
+				if err := decoder.Skip(); err != nil {
+					return artifacts.ProxyTarget{}, err
+				}
+			}
+		case xml.EndElement:
+			if elem.Name.Local == "target" {
+				return target, nil
+			}
+		}
+	}
+}
+
+// decodeEndpoint reads an endpoint reference or an inline http or address endpoint
+func (proxy *ProxyService) decodeEndpoint(decoder *xml.Decoder, start xml.StartElement, position artifacts.Position, target *artifacts.ProxyTarget) error {
+	for _, attr := range start.Attr {
+		if attr.Name.Local == "key" {
+			target.Endpoint = attr.Value
+			return decoder.Skip()
+		}
+	}
+
+	endpoint := &artifacts.Endpoint{Name: proxy.Name, Position: position}
+	endpoint.Position.Hierarchy = position.Hierarchy + "->endpoint"
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch elem := token.(type) {
+		case xml.StartElement:
+			switch elem.Name.Local {
+			case "http":
+				endpointUrl := EndpointUrl{}
+				res, err := endpointUrl.Unmarshal(decoder, elem, position)
+				if err != nil {
+					return err
+				}
+				endpoint.EndpointUrl = res
+			case "address":
+				// Address endpoints forward the incoming request method
+				for _, attr := range elem.Attr {
+					switch attr.Name.Local {
+					case "uri":
+						endpoint.EndpointUrl.URITemplate = attr.Value
+					case "format":
+						endpoint.EndpointUrl.Format = attr.Value
+					}
+				}
+				if err := validateEndpointFormat(endpoint.EndpointUrl.Format); err != nil {
+					return err
+				}
+			}
+			if err := decoder.Skip(); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			if elem.Name.Local == "endpoint" {
+				if endpoint.EndpointUrl.URITemplate == "" {
+					return fmt.Errorf("inline endpoint in proxy %s requires an http or address uri", proxy.Name)
+				}
+				target.InlineEndpoint = endpoint
+				return nil
+			}
+		}
+	}
+}
+
+func (proxy *ProxyService) decodeWSDL(decoder *xml.Decoder, start xml.StartElement) (*artifacts.PublishWSDL, error) {
+	wsdlElem := &PublishWSDLElement{}
+	if err := decoder.DecodeElement(wsdlElem, &start); err != nil {
+		return nil, fmt.Errorf("error decoding publishWSDL in proxy %s: %w", proxy.Name, err)
+	}
+	wsdl := &artifacts.PublishWSDL{Key: wsdlElem.Key, URI: wsdlElem.URI, Content: strings.TrimSpace(wsdlElem.InnerXML)}
+	if wsdl.Key == "" && wsdl.URI == "" && wsdl.Content == "" {
+		return nil, fmt.Errorf("publishWSDL requires a key, uri or inline WSDL in proxy %s", proxy.Name)
+	}
+	return wsdl, nil
+}
@@ -0,0 +1,144 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package types
+
+import (
+	"testing"
+
+	"github.com/apache/synapse-go/internal/pkg/core/artifacts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProxyService_Unmarshal(t *testing.T) {
+	xmlData := `<proxy name="StockQuoteProxy" transports="https http" startOnLoad="true" xmlns="http://ws.apache.org/ns/synapse">
+		<description>Legacy stock quote service</description>
+		<target faultSequence="errorHandler">
+			<inSequence>
+				<log level="full"/>
+			</inSequence>
+			<outSequence>
+				<respond/>
+			</outSequence>
+			<endpoint>
+				<address uri="http://localhost:9000/services/SimpleStockQuoteService"/>
+			</endpoint>
+		</target>
+		<publishWSDL uri="file:resources/sample_proxy_1.wsdl"/>
+		<parameter name="serviceType">soap</parameter>
+	</proxy>`
+
+	proxy := &ProxyService{}
+	result, err := proxy.Unmarshal(xmlData, artifacts.Position{FileName: "StockQuoteProxy.xml"})
+	require.NoError(t, err)
+	assert.Equal(t, "StockQuoteProxy", result.Name)
+	assert.Equal(t, []string{"https", "http"}, result.Transports)
+	assert.True(t, result.Exposed())
+	assert.Equal(t, "StockQuoteProxy", result.Position.Hierarchy)
+	assert.Equal(t, 1, result.Position.LineNo)
+	assert.Equal(t, 3, result.Target.Position.LineNo)
+
+	require.NotNil(t, result.Target.InSequence.Inline)
+	assert.Len(t, result.Target.InSequence.Inline.MediatorList, 1)
+	assert.Equal(t, "StockQuoteProxy->inSequence", result.Target.InSequence.Inline.Position.Hierarchy)
+	require.NotNil(t, result.Target.OutSequence.Inline)
+	assert.Len(t, result.Target.OutSequence.Inline.MediatorList, 1)
+	assert.Equal(t, "errorHandler", result.Target.FaultSequence.Key)
+
+	require.NotNil(t, result.Target.InlineEndpoint)
+	assert.Equal(t, "http://localhost:9000/services/SimpleStockQuoteService", result.Target.InlineEndpoint.EndpointUrl.URITemplate)
+	assert.Equal(t, "", result.Target.InlineEndpoint.EndpointUrl.Method)
+
+	require.NotNil(t, result.WSDL)
+	assert.Equal(t, "file:resources/sample_proxy_1.wsdl", result.WSDL.URI)
+	assert.Equal(t, []artifacts.Parameter{{Name: "serviceType", Value: "soap"}}, result.Parameters)
+}
+
+func TestProxyService_Unmarshal_References(t *testing.T) {
+	xmlData := `<proxy name="OrderProxy" xmlns="http://ws.apache.org/ns/synapse">
+		<target inSequence="orderIn" outSequence="orderOut" endpoint="OrderEndpoint"/>
+		<publishWSDL>
+			<wsdl:definitions xmlns:wsdl="http://schemas.xmlsoap.org/wsdl/" name="Orders"/>
+		</publishWSDL>
+	</proxy>`
+
+	proxy := &ProxyService{}
+	result, err := proxy.Unmarshal(xmlData, artifacts.Position{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"http", "https"}, result.Transports)
+	assert.Equal(t, "orderIn", result.Target.InSequence.Key)
+	assert.Equal(t, "orderOut", result.Target.OutSequence.Key)
+	assert.Equal(t, "OrderEndpoint", result.Target.Endpoint)
+	assert.Nil(t, result.Target.InlineEndpoint)
+	require.NotNil(t, result.WSDL)
+	assert.Contains(t, result.WSDL.Content, `name="Orders"`)
+}
+
+func TestProxyService_Unmarshal_WSDLKey(t *testing.T) {
+	xmlData := `<proxy name="RegistryProxy"><target/><publishWSDL key="conf:/wsdl/orders.wsdl"/></proxy>`
+
+	proxy := &ProxyService{}
+	result, err := proxy.Unmarshal(xmlData, artifacts.Position{})
+	require.NoError(t, err)
+	require.NotNil(t, result.WSDL)
+	assert.Equal(t, "conf:/wsdl/orders.wsdl", result.WSDL.Key)
+	assert.Empty(t, result.WSDL.Content)
+}
+
+func TestProxyService_Unmarshal_EndpointKeyAndHTTP(t *testing.T) {
+	withKey := `<proxy name="KeyProxy"><target><endpoint key="OrderEndpoint"/></target></proxy>`
+	proxy := &ProxyService{}
+	result, err := proxy.Unmarshal(withKey, artifacts.Position{})
+	require.NoError(t, err)
+	assert.Equal(t, "OrderEndpoint", result.Target.Endpoint)
+
+	withHTTP := `<proxy name="HttpProxy" transports="jms"><target><endpoint>
+		<http method="GET" uri-template="http://localhost:8080/orders"/>
+	</endpoint></target></proxy>`
+	proxy = &ProxyService{}
+	result, err = proxy.Unmarshal(withHTTP, artifacts.Position{})
+	require.NoError(t, err)
+	require.NotNil(t, result.Target.InlineEndpoint)
+	assert.Equal(t, "GET", result.Target.InlineEndpoint.EndpointUrl.Method)
+	assert.False(t, result.Exposed())
+}
+
+func TestProxyService_Unmarshal_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		xmlData string
+		wantErr string
+	}{
+		{"missing name", `<proxy><target/></proxy>`, "proxy name is required"},
+		{"invalid name", `<proxy name="a/b"><target/></proxy>`, "must be a single URL path segment"},
+		{"missing target", `<proxy name="p"/>`, "target is required"},
+		{"not a proxy", `<api name="p"/>`, "expected proxy element"},
+		{"empty inline endpoint", `<proxy name="p"><target><endpoint><loadbalance/></endpoint></target></proxy>`, "requires an http or address uri"},
+		{"empty wsdl", `<proxy name="p"><target/><publishWSDL/></proxy>`, "publishWSDL requires a key, uri or inline WSDL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proxy := &ProxyService{}
+			_, err := proxy.Unmarshal(tt.xmlData, artifacts.Position{})
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
@@ -21,6 +21,7 @@ package types
 
 import (
 	"encoding/xml"
+	"io"
 	"strings"
 
 	"github.com/apache/synapse-go/internal/pkg/core/artifacts"
@@ -41,27 +42,21 @@ func (seq *Sequence) unmarshal(decoder *xml.Decoder, position artifacts.Position
 OuterLoop:
 	for {
 		token, err := decoder.Token()
-		if err != nil {
+		if err == io.EOF {
 			break
 		}
+		if err != nil {
+			return artifacts.Sequence{}, err
+		}
 		line, _ := decoder.InputPos()
 		position := artifacts.Position{LineNo: line, FileName: position.FileName, Hierarchy: position.Hierarchy}
 		switch element := token.(type) {
 		case xml.StartElement:
-			switch element.Name.Local {
-			case "log":
-				logMediator := LogMediator{}
-				mediator, err := logMediator.Unmarshal(decoder, element, position)
-				if err != nil {
-					return artifacts.Sequence{}, err
-				}
-				mediatorList = append(mediatorList, mediator)
-			case "respond":
-				respondMediator := RespondMediator{}
-				mediator, err := respondMediator.Unmarshal(decoder, element, position)
-				if err != nil {
-					return artifacts.Sequence{}, err
-				}
+			mediator, err := unmarshalMediator(decoder, element, position)
+			if err != nil {
+				return artifacts.Sequence{}, err
+			}
+			if mediator != nil {
 				mediatorList = append(mediatorList, mediator)
 			}
 		case xml.EndElement:
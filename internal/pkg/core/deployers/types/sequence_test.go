@@ -83,3 +83,12 @@ func TestUnmarshalMediatorsWithInvalidXML(t *testing.T) {
 	_, err := sequence.unmarshal(decoder, position)
 	assert.NotNil(t, err)
 }
+
+func TestUnmarshalSequenceWithTruncatedXML(t *testing.T) {
+	xmlData := `<sequence name="truncated"><log level="full"/>`
+
+	sequence := &Sequence{}
+	_, err := sequence.Unmarshal(xmlData, artifacts.Position{FileName: "truncated.xml"})
+	var syntaxErr *xml.SyntaxError
+	assert.ErrorAs(t, err, &syntaxErr)
+}
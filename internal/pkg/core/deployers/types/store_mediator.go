@@ -0,0 +1,49 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package types
+
+import (
+	"encoding/xml"
+	"errors"
+	"strconv"
+
+	"github.com/apache/synapse-go/internal/pkg/core/artifacts"
+)
+
+type StoreMediator struct {
+	XMLName      xml.Name `xml:"store"`
+	MessageStore string   `xml:"messageStore,attr"`
+	Sequence     string   `xml:"sequence,attr"`
+}
+
+func (storeMediator StoreMediator) Unmarshal(d *xml.Decoder, start xml.StartElement, position artifacts.Position) (artifacts.Mediator, error) {
+	if err := d.DecodeElement(&storeMediator, &start); err != nil {
+		return nil, errors.New("error in unmarshalling store mediator in " + position.FileName + " at line " + strconv.Itoa(position.LineNo))
+	}
+	if storeMediator.MessageStore == "" {
+		return nil, errors.New("messageStore attribute is required for store mediator in " + position.FileName + " at line " + strconv.Itoa(position.LineNo))
+	}
+	position.Hierarchy = position.Hierarchy + "->store"
+	return artifacts.StoreMediator{
+		MessageStore: storeMediator.MessageStore,
+		Sequence:     storeMediator.Sequence,
+		Position:     position,
+	}, nil
+}
@@ -0,0 +1,58 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package types
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/apache/synapse-go/internal/pkg/core/artifacts"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStoreMediator_Unmarshal(t *testing.T) {
+	xmlData := `<sequence>
+		<store messageStore="OrdersStore" sequence="beforeStore"/>
+	</sequence>`
+
+	decoder := xml.NewDecoder(strings.NewReader(xmlData))
+	decoder.Token()
+
+	mediatorList, err := unmarshalMediatorList(decoder, "sequence", artifacts.Position{FileName: "test.xml", Hierarchy: "seq"})
+	assert.NoError(t, err)
+	assert.Len(t, mediatorList, 1)
+
+	storeMediator, ok := mediatorList[0].(artifacts.StoreMediator)
+	if !ok {
+		t.Fatalf("Expected artifacts.StoreMediator but got %T", mediatorList[0])
+	}
+	assert.Equal(t, "OrdersStore", storeMediator.MessageStore)
+	assert.Equal(t, "beforeStore", storeMediator.Sequence)
+	assert.Equal(t, "seq->store", storeMediator.Position.Hierarchy)
+	assert.Equal(t, 2, storeMediator.Position.LineNo)
+}
+
+func TestStoreMediator_Unmarshal_MissingStore(t *testing.T) {
+	decoder := xml.NewDecoder(strings.NewReader(`<store/>`))
+	token, _ := decoder.Token()
+	_, err := StoreMediator{}.Unmarshal(decoder, token.(xml.StartElement), artifacts.Position{FileName: "test.xml", LineNo: 3})
+	assert.EqualError(t, err, "messageStore attribute is required for store mediator in test.xml at line 3")
+}
@@ -0,0 +1,185 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package types
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/synapse-go/internal/pkg/core/artifacts"
+)
+
+// Task classes that inject a message into a sequence
+var taskClasses = map[string]bool{
+	"":                 true,
+	"message-injector": true,
+	"org.apache.synapse.startup.tasks.MessageInjector": true,
+}
+
+// Content types for the Apache Synapse message injector formats
+var taskFormats = map[string]string{
+	"json":   "application/json",
+	"pox":    "application/xml",
+	"soap11": "text/xml",
+	"soap12": "application/soap+xml",
+	"text":   "text/plain",
+}
+
+type Task struct {
+	Name       string         `xml:"name,attr"`
+	Class      string         `xml:"class,attr"`
+	Sequence   string         `xml:"sequence,attr"`
+	Trigger    *TaskTrigger   `xml:"trigger"`
+	Properties []TaskProperty `xml:"property"`
+}
+
+type TaskTrigger struct {
+	Interval string `xml:"interval,attr"`
+	Cron     string `xml:"cron,attr"`
+	Count    string `xml:"count,attr"`
+	Once     string `xml:"once,attr"`
+}
+
+// TaskProperty holds either a value attribute or inline XML content
+type TaskProperty struct {
+	Name     string `xml:"name,attr"`
+	Value    string `xml:"value,attr"`
+	InnerXML string `xml:",innerxml"`
+}
+
+func (task *Task) Unmarshal(xmlData string, position artifacts.Position) (artifacts.Task, error) {
+	if err := xml.Unmarshal([]byte(xmlData), task); err != nil {
+		return artifacts.Task{}, err
+	}
+	if task.Name == "" {
+		return artifacts.Task{}, fmt.Errorf("task name is required")
+	}
+	if !taskClasses[task.Class] {
+		return artifacts.Task{}, fmt.Errorf("unsupported task class: %s", task.Class)
+	}
+
+	newTask := artifacts.Task{
+		Name:     task.Name,
+		Sequence: task.Sequence,
+		Position: position,
+	}
+	newTask.Position.Hierarchy = task.Name
+	newTask.Position.LineNo = rootLine(xmlData)
+
+	inline := false
+	format := ""
+	for _, property := range task.Properties {
+		switch property.Name {
+		case "injectTo":
+			if property.Value != "sequence" {
+				return artifacts.Task{}, fmt.Errorf("unsupported injectTo value '%s' in task %s", property.Value, task.Name)
+			}
+		case "sequenceName":
+			if newTask.Sequence == "" {
+				newTask.Sequence = property.Value
+			}
+		case "message":
+			if property.Value != "" {
+				newTask.Payload = property.Value
+			} else {
+				newTask.Payload = strings.TrimSpace(property.InnerXML)
+				inline = newTask.Payload != ""
+			}
+		case "format":
+			format = property.Value
+		}
+	}
+	if newTask.Sequence == "" {
+		return artifacts.Task{}, fmt.Errorf("sequence is required for task %s", task.Name)
+	}
+	contentType, err := taskContentType(format, newTask.Payload, inline)
+	if err != nil {
+		return artifacts.Task{}, fmt.Errorf("%w in task %s", err, task.Name)
+	}
+	newTask.ContentType = contentType
+
+	trigger, err := task.Trigger.toTrigger()
+	if err != nil {
+		return artifacts.Task{}, fmt.Errorf("%w in task %s", err, task.Name)
+	}
+	newTask.Trigger = trigger
+	return newTask, nil
+}
+
+func (trigger *TaskTrigger) toTrigger() (artifacts.TaskTrigger, error) {
+	if trigger == nil {
+		return artifacts.TaskTrigger{}, fmt.Errorf("trigger is required")
+	}
+	if (trigger.Interval == "") == (trigger.Cron == "") {
+		return artifacts.TaskTrigger{}, fmt.Errorf("trigger requires exactly one of interval or cron")
+	}
+
+	newTrigger := artifacts.TaskTrigger{Cron: trigger.Cron, Count: -1}
+	if trigger.Interval != "" {
+		seconds, err := strconv.Atoi(trigger.Interval)
+		if err != nil || seconds <= 0 {
+			return artifacts.TaskTrigger{}, fmt.Errorf("invalid trigger interval '%s': must be a positive number of seconds", trigger.Interval)
+		}
+		newTrigger.Interval = time.Duration(seconds) * time.Second
+	} else if _, err := artifacts.ParseCronSchedule(trigger.Cron); err != nil {
+		return artifacts.TaskTrigger{}, err
+	}
+
+	if trigger.Count != "" {
+		count, err := strconv.Atoi(trigger.Count)
+		if err != nil || count == 0 || count < -1 {
+			return artifacts.TaskTrigger{}, fmt.Errorf("invalid trigger count '%s': must be -1 or a positive integer", trigger.Count)
+		}
+		newTrigger.Count = count
+	}
+	if trigger.Once == "true" {
+		newTrigger.Count = 1
+	}
+	return newTrigger, nil
+}
+
+// taskContentType resolves the content type of the injected message. Without
+// an explicit format inline XML is sent as XML, and values as JSON or text.
+func taskContentType(format string, payload string, inline bool) (string, error) {
+	if format != "" {
+		if strings.Contains(format, "/") {
+			return format, nil
+		}
+		contentType, ok := taskFormats[format]
+		if !ok {
+			return "", fmt.Errorf("unsupported message format '%s'", format)
+		}
+		return contentType, nil
+	}
+	switch {
+	case payload == "":
+		return "", nil
+	case inline:
+		return taskFormats["pox"], nil
+	case json.Valid([]byte(payload)):
+		return taskFormats["json"], nil
+	default:
+		return taskFormats["text"], nil
+	}
+}
@@ -0,0 +1,108 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/synapse-go/internal/pkg/core/artifacts"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTask_Unmarshal_Interval(t *testing.T) {
+	xmlData := `<task name="Heartbeat" sequence="heartbeatSeq" xmlns="http://ws.apache.org/ns/synapse">
+		<trigger interval="30" count="5"/>
+		<property name="message" value='{"ping":true}'/>
+	</task>`
+
+	task := &Task{}
+	result, err := task.Unmarshal(xmlData, artifacts.Position{FileName: "Heartbeat.xml"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Heartbeat", result.Name)
+	assert.Equal(t, "heartbeatSeq", result.Sequence)
+	assert.Equal(t, 30*time.Second, result.Trigger.Interval)
+	assert.Equal(t, 5, result.Trigger.Count)
+	assert.Equal(t, `{"ping":true}`, result.Payload)
+	assert.Equal(t, "application/json", result.ContentType)
+	assert.Equal(t, "Heartbeat", result.Position.Hierarchy)
+	assert.Equal(t, 1, result.Position.LineNo)
+}
+
+func TestTask_Unmarshal_MessageInjector(t *testing.T) {
+	xmlData := `<task name="NightlyBatch" class="org.apache.synapse.startup.tasks.MessageInjector" xmlns="http://ws.apache.org/ns/synapse">
+		<trigger cron="0 0 2 * * ? *"/>
+		<property name="injectTo" value="sequence"/>
+		<property name="sequenceName" value="batchSeq"/>
+		<property name="message">
+			<batch xmlns=""><run>nightly</run></batch>
+		</property>
+	</task>`
+
+	task := &Task{}
+	result, err := task.Unmarshal(xmlData, artifacts.Position{})
+	assert.NoError(t, err)
+	assert.Equal(t, "batchSeq", result.Sequence)
+	assert.Equal(t, "0 0 2 * * ? *", result.Trigger.Cron)
+	assert.Equal(t, -1, result.Trigger.Count)
+	assert.Equal(t, `<batch xmlns=""><run>nightly</run></batch>`, result.Payload)
+	assert.Equal(t, "application/xml", result.ContentType)
+}
+
+func TestTask_Unmarshal_Once(t *testing.T) {
+	xmlData := `<task name="Warmup" sequence="warmupSeq">
+		<trigger interval="1" once="true"/>
+		<property name="message" value="warmup"/>
+		<property name="format" value="text"/>
+	</task>`
+
+	task := &Task{}
+	result, err := task.Unmarshal(xmlData, artifacts.Position{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Trigger.Count)
+	assert.Equal(t, "text/plain", result.ContentType)
+}
+
+func TestTask_Unmarshal_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		xmlData string
+		wantErr string
+	}{
+		{"missing name", `<task sequence="s"><trigger interval="1"/></task>`, "task name is required"},
+		{"unknown class", `<task name="t" class="com.example.Job" sequence="s"><trigger interval="1"/></task>`, "unsupported task class"},
+		{"missing sequence", `<task name="t"><trigger interval="1"/></task>`, "sequence is required"},
+		{"missing trigger", `<task name="t" sequence="s"/>`, "trigger is required"},
+		{"both triggers", `<task name="t" sequence="s"><trigger interval="1" cron="* * * * *"/></task>`, "exactly one of interval or cron"},
+		{"bad interval", `<task name="t" sequence="s"><trigger interval="0"/></task>`, "invalid trigger interval"},
+		{"bad cron", `<task name="t" sequence="s"><trigger cron="61 * * * *"/></task>`, "invalid cron expression"},
+		{"bad count", `<task name="t" sequence="s"><trigger interval="1" count="0"/></task>`, "invalid trigger count"},
+		{"proxy injection", `<task name="t" sequence="s"><trigger interval="1"/><property name="injectTo" value="proxy"/></task>`, "unsupported injectTo value"},
+		{"bad format", `<task name="t" sequence="s"><trigger interval="1"/><property name="format" value="csv"/></task>`, "unsupported message format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &Task{}
+			_, err := task.Unmarshal(tt.xmlData, artifacts.Position{})
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
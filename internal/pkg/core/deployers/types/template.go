@@ -0,0 +1,156 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package types
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/apache/synapse-go/internal/pkg/core/artifacts"
+)
+
+type Template struct {
+	Name string
+}
+
+type TemplateParameterElement struct {
+	Name         string `xml:"name,attr"`
+	IsMandatory  string `xml:"isMandatory,attr"`
+	DefaultValue string `xml:"defaultValue,attr"`
+}
+
+type endpointElement struct {
+	InnerXML string `xml:",innerxml"`
+}
+
+func (template *Template) Unmarshal(xmlData string, position artifacts.Position) (artifacts.Template, error) {
+	decoder := xml.NewDecoder(strings.NewReader(xmlData))
+	newTemplate := artifacts.Template{Position: position}
+
+	// Find the <template> element and read its name
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return artifacts.Template{}, fmt.Errorf("template element not found: %w", err)
+		}
+		if elem, ok := token.(xml.StartElement); ok {
+			if elem.Name.Local != "template" {
+				return artifacts.Template{}, fmt.Errorf("expected template element, got: %s", elem.Name.Local)
+			}
+			for _, attr := range elem.Attr {
+				if attr.Name.Local == "name" {
+					template.Name = attr.Value
+				}
+			}
+			break
+		}
+	}
+	if template.Name == "" {
+		return artifacts.Template{}, fmt.Errorf("template name is required")
+	}
+	newTemplate.Name = template.Name
+	newTemplate.Position.Hierarchy = template.Name
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		elem, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch elem.Name.Local {
+		case "parameter":
+			param, err := template.decodeParameter(decoder, elem)
+			if err != nil {
+				return artifacts.Template{}, err
+			}
+			newTemplate.Parameters = append(newTemplate.Parameters, param)
+		case "sequence":
+			if newTemplate.Sequence != nil || newTemplate.Endpoint != nil {
+				return artifacts.Template{}, fmt.Errorf("template %s must have exactly one sequence or endpoint", template.Name)
+			}
+			seq := Sequence{}
+			sequence, err := seq.unmarshal(decoder, newTemplate.Position)
+			if err != nil {
+				return artifacts.Template{}, err
+			}
+			sequence.Name = template.Name
+			newTemplate.Sequence = &sequence
+		case "endpoint":
+			if newTemplate.Sequence != nil || newTemplate.Endpoint != nil {
+				return artifacts.Template{}, fmt.Errorf("template %s must have exactly one sequence or endpoint", template.Name)
+			}
+			endpoint, err := template.decodeEndpoint(decoder, elem, newTemplate.Position)
+			if err != nil {
+				return artifacts.Template{}, err
+			}
+			newTemplate.Endpoint = endpoint
+		default:
+			if err := decoder.Skip(); err != nil {
+				return artifacts.Template{}, err
+			}
+		}
+	}
+
+	if newTemplate.Sequence == nil && newTemplate.Endpoint == nil {
+		return artifacts.Template{}, fmt.Errorf("template %s requires a sequence or an endpoint", template.Name)
+	}
+	return newTemplate, nil
+}
+
+func (template *Template) decodeParameter(decoder *xml.Decoder, start xml.StartElement) (artifacts.TemplateParameter, error) {
+	paramElem := &TemplateParameterElement{}
+	if err := decoder.DecodeElement(paramElem, &start); err != nil {
+		return artifacts.TemplateParameter{}, fmt.Errorf("error decoding parameter in template %s: %w", template.Name, err)
+	}
+	if paramElem.Name == "" {
+		return artifacts.TemplateParameter{}, fmt.Errorf("parameter name is required in template %s", template.Name)
+	}
+	param := artifacts.TemplateParameter{Name: paramElem.Name, DefaultValue: paramElem.DefaultValue}
+	if paramElem.IsMandatory != "" {
+		mandatory, err := strconv.ParseBool(paramElem.IsMandatory)
+		if err != nil {
+			return artifacts.TemplateParameter{}, fmt.Errorf("invalid isMandatory value '%s' for parameter %s in template %s", paramElem.IsMandatory, paramElem.Name, template.Name)
+		}
+		param.Mandatory = mandatory
+	}
+	return param, nil
+}
+
+// decodeEndpoint decodes the endpoint of an endpoint template. Its attributes
+// are ignored, endpoints created from the template take their own name.
+func (template *Template) decodeEndpoint(decoder *xml.Decoder, start xml.StartElement, position artifacts.Position) (*artifacts.Endpoint, error) {
+	elem := &endpointElement{}
+	if err := decoder.DecodeElement(elem, &start); err != nil {
+		return nil, fmt.Errorf("error decoding endpoint in template %s: %w", template.Name, err)
+	}
+	xmlData := "<endpoint>" + elem.InnerXML + "</endpoint>"
+	endpoint := Endpoint{}
+	newEndpoint, err := endpoint.unmarshal(xmlData, position)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding endpoint in template %s: %w", template.Name, err)
+	}
+	newEndpoint.Position.Hierarchy = template.Name + "->endpoint"
+	return &newEndpoint, nil
+}
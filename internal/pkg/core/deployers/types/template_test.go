@@ -0,0 +1,146 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package types
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/apache/synapse-go/internal/pkg/core/artifacts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplate_Unmarshal_Sequence(t *testing.T) {
+	xmlData := `<template name="logAndFault" xmlns="http://ws.apache.org/ns/synapse">
+		<parameter name="message" isMandatory="true"/>
+		<parameter name="code" defaultValue="Server"/>
+		<sequence>
+			<log category="INFO"><message>$func:message</message></log>
+			<call-template target="nested">
+				<with-param name="text" expression="$func:message"/>
+				<with-param name="status" value="failed"/>
+			</call-template>
+		</sequence>
+	</template>`
+
+	template := &Template{}
+	result, err := template.Unmarshal(xmlData, artifacts.Position{FileName: "logAndFault.xml"})
+	require.NoError(t, err)
+	assert.Equal(t, "logAndFault", result.Name)
+	assert.Equal(t, []artifacts.TemplateParameter{
+		{Name: "message", Mandatory: true},
+		{Name: "code", DefaultValue: "Server"},
+	}, result.Parameters)
+	assert.Nil(t, result.Endpoint)
+	require.NotNil(t, result.Sequence)
+	require.Len(t, result.Sequence.MediatorList, 2)
+
+	callTemplate, ok := result.Sequence.MediatorList[1].(artifacts.CallTemplateMediator)
+	require.True(t, ok)
+	assert.Equal(t, "nested", callTemplate.Target)
+	assert.Equal(t, []artifacts.TemplateArgument{
+		{Name: "text", Expression: "$func:message"},
+		{Name: "status", Value: "failed"},
+	}, callTemplate.Arguments)
+	assert.Equal(t, "logAndFault->sequence->call-template", callTemplate.Position.Hierarchy)
+}
+
+func TestTemplate_Unmarshal_Endpoint(t *testing.T) {
+	xmlData := `<template name="backendTemplate" xmlns="http://ws.apache.org/ns/synapse">
+		<parameter name="host"/>
+		<endpoint name="$name">
+			<http method="POST" uri-template="http://{uri.var.host}/orders" format="pox"/>
+		</endpoint>
+	</template>`
+
+	template := &Template{}
+	result, err := template.Unmarshal(xmlData, artifacts.Position{})
+	require.NoError(t, err)
+	assert.Nil(t, result.Sequence)
+	require.NotNil(t, result.Endpoint)
+	assert.Equal(t, "", result.Endpoint.Name)
+	assert.Equal(t, "http://{uri.var.host}/orders", result.Endpoint.EndpointUrl.URITemplate)
+	assert.Equal(t, artifacts.EndpointFormatPOX, result.Endpoint.EndpointUrl.Format)
+	assert.Equal(t, "backendTemplate->endpoint", result.Endpoint.Position.Hierarchy)
+}
+
+func TestTemplate_Unmarshal_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		xmlData string
+		errMsg  string
+	}{
+		{"missing name", `<template><sequence/></template>`, "template name is required"},
+		{"wrong element", `<sequence name="s"/>`, "expected template element"},
+		{"empty", `<template name="t"><parameter name="p"/></template>`, "template t requires a sequence or an endpoint"},
+		{"both", `<template name="t"><sequence/><endpoint><http method="GET" uri-template="http://a"/></endpoint></template>`, "must have exactly one sequence or endpoint"},
+		{"parameter name", `<template name="t"><parameter/><sequence/></template>`, "parameter name is required in template t"},
+		{"mandatory", `<template name="t"><parameter name="p" isMandatory="maybe"/><sequence/></template>`, "invalid isMandatory value 'maybe'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template := &Template{}
+			_, err := template.Unmarshal(tt.xmlData, artifacts.Position{})
+			assert.ErrorContains(t, err, tt.errMsg)
+		})
+	}
+}
+
+func TestEndpoint_Unmarshal_TemplateReference(t *testing.T) {
+	xmlData := `<endpoint name="OrdersEP" template="backendTemplate" uri="http://orders.local/api">
+		<parameter name="host" value="orders.local:8080"/>
+	</endpoint>`
+
+	endpoint := &Endpoint{}
+	result, err := endpoint.Unmarshal(xmlData, artifacts.Position{})
+	require.NoError(t, err)
+	assert.Equal(t, "OrdersEP", result.Name)
+	assert.Equal(t, "backendTemplate", result.Template)
+	assert.Equal(t, map[string]string{"uri": "http://orders.local/api", "host": "orders.local:8080"}, result.TemplateParameters)
+
+	_, err = endpoint.Unmarshal(`<endpoint name="Plain" uri="http://a"><http method="GET" uri-template="http://a"/></endpoint>`, artifacts.Position{})
+	assert.ErrorContains(t, err, "uri and parameters are only supported on template endpoints")
+}
+
+func TestCallTemplateMediator_Unmarshal_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		xmlData string
+		errMsg  string
+	}{
+		{"missing target", `<call-template/>`, "target attribute is required for call-template mediator"},
+		{"missing param name", `<call-template target="t"><with-param value="v"/></call-template>`, "with-param name is required"},
+		{"unsupported expression", `<call-template target="t"><with-param name="p" expression="json-eval($.id)"/></call-template>`, "unsupported with-param expression 'json-eval($.id)'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoder := xml.NewDecoder(strings.NewReader(tt.xmlData))
+			token, err := decoder.Token()
+			require.NoError(t, err)
+			start := token.(xml.StartElement)
+			_, err = CallTemplateMediator{}.Unmarshal(decoder, start, artifacts.Position{FileName: "test.xml", LineNo: 3})
+			assert.ErrorContains(t, err, tt.errMsg)
+		})
+	}
+}
@@ -0,0 +1,161 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package types
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/apache/synapse-go/internal/pkg/core/artifacts"
+	"github.com/apache/synapse-go/internal/pkg/core/throttle"
+)
+
+type ThrottleMediator struct {
+	XMLName xml.Name `xml:"throttle"`
+}
+
+// Unmarshal decodes a throttle mediator:
+//
+//	<throttle id="..." key="ip|header|property" keyName="..." algorithm="fixed-window"
+//	          maxCount="10" unitTime="60000" maxConcurrent="5">
+//	    <onAccept>...</onAccept>
+//	    <onReject>...</onReject>
+//	</throttle>
+func (throttleMediator ThrottleMediator) Unmarshal(d *xml.Decoder, start xml.StartElement, position artifacts.Position) (artifacts.Mediator, error) {
+	position.Hierarchy = position.Hierarchy + "->throttle"
+	attrs := make(map[string]string)
+	for _, attr := range start.Attr {
+		attrs[attr.Name.Local] = attr.Value
+	}
+
+	mediator := artifacts.ThrottleMediator{
+		ID:       attrs["id"],
+		KeyType:  attrs["key"],
+		KeyName:  attrs["keyName"],
+		Store:    throttle.DefaultStore(),
+		Position: position,
+	}
+	if mediator.ID == "" {
+		mediator.ID = position.FileName + ":" + strconv.Itoa(position.LineNo)
+	}
+	if err := validateThrottleKey(mediator.KeyType, mediator.KeyName, true); err != nil {
+		return nil, fmt.Errorf("%v in throttle mediator in %s at line %d", err, position.FileName, position.LineNo)
+	}
+
+	if attrs["maxCount"] != "" {
+		policy, err := parseThrottlePolicy(attrs["algorithm"], attrs["maxCount"], attrs["unitTime"])
+		if err != nil {
+			return nil, fmt.Errorf("%v in throttle mediator in %s at line %d", err, position.FileName, position.LineNo)
+		}
+		mediator.Policy = policy
+	}
+	if attrs["maxConcurrent"] != "" {
+		maxConcurrent, err := strconv.Atoi(attrs["maxConcurrent"])
+		if err != nil || maxConcurrent <= 0 {
+			return nil, fmt.Errorf("invalid maxConcurrent value '%s' in throttle mediator in %s at line %d", attrs["maxConcurrent"], position.FileName, position.LineNo)
+		}
+		mediator.MaxConcurrent = maxConcurrent
+	}
+	if mediator.Policy.Limit == 0 && mediator.MaxConcurrent == 0 {
+		return nil, fmt.Errorf("throttle mediator requires maxCount or maxConcurrent in %s at line %d", position.FileName, position.LineNo)
+	}
+
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return nil, fmt.Errorf("error in unmarshalling throttle mediator in %s at line %d: %w", position.FileName, position.LineNo, err)
+		}
+		switch elem := token.(type) {
+		case xml.StartElement:
+			switch elem.Name.Local {
+			case "onAccept", "onReject":
+				line, _ := d.InputPos()
+				branchPosition := artifacts.Position{
+					FileName:  position.FileName,
+					LineNo:    line,
+					Hierarchy: position.Hierarchy + "->" + elem.Name.Local,
+				}
+				mediatorList, err := unmarshalMediatorList(d, elem.Name.Local, branchPosition)
+				if err != nil {
+					return nil, err
+				}
+				branch := &artifacts.Sequence{MediatorList: mediatorList, Position: branchPosition}
+				if elem.Name.Local == "onAccept" {
+					mediator.OnAccept = branch
+				} else {
+					mediator.OnReject = branch
+				}
+			default:
+				if err := d.Skip(); err != nil {
+					return nil, err
+				}
+			}
+		case xml.EndElement:
+			if elem.Name.Local == start.Name.Local {
+				return mediator, nil
+			}
+		}
+	}
+}
+
+// parseThrottlePolicy builds a throttle policy from its XML attributes.
+// unitTime is in milliseconds.
+func parseThrottlePolicy(algorithm string, maxCount string, unitTime string) (throttle.Policy, error) {
+	alg, err := throttle.ParseAlgorithm(algorithm)
+	if err != nil {
+		return throttle.Policy{}, err
+	}
+	limit, err := strconv.Atoi(maxCount)
+	if err != nil {
+		return throttle.Policy{}, fmt.Errorf("invalid maxCount value: %s", maxCount)
+	}
+	window, err := strconv.Atoi(unitTime)
+	if err != nil {
+		return throttle.Policy{}, fmt.Errorf("invalid unitTime value: %s", unitTime)
+	}
+	policy := throttle.Policy{
+		Algorithm: alg,
+		Limit:     limit,
+		Window:    time.Duration(window) * time.Millisecond,
+	}
+	return policy, policy.Validate()
+}
+
+// validateThrottleKey checks the caller key type. Properties are only
+// available inside the mediation flow, not to API rate limits.
+func validateThrottleKey(keyType string, keyName string, allowProperty bool) error {
+	switch keyType {
+	case "", artifacts.ThrottleKeyIP:
+		return nil
+	case artifacts.ThrottleKeyHeader:
+	case artifacts.ThrottleKeyProperty:
+		if !allowProperty {
+			return fmt.Errorf("unsupported throttle key: %s", keyType)
+		}
+	default:
+		return fmt.Errorf("unsupported throttle key: %s", keyType)
+	}
+	if keyName == "" {
+		return fmt.Errorf("keyName is required for throttle key: %s", keyType)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package types
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/synapse-go/internal/pkg/core/artifacts"
+	"github.com/apache/synapse-go/internal/pkg/core/throttle"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThrottleMediator_Unmarshal(t *testing.T) {
+	xmlData := `<throttle id="perClient" key="header" keyName="X-Client-Id" algorithm="token-bucket" maxCount="10" unitTime="60000" maxConcurrent="2">
+		<onAccept>
+			<call><endpoint key="backend"/></call>
+		</onAccept>
+		<onReject>
+			<log category="WARN"><message>throttled</message></log>
+			<respond/>
+		</onReject>
+	</throttle>`
+
+	decoder := xml.NewDecoder(strings.NewReader(xmlData))
+	token, _ := decoder.Token()
+	start := token.(xml.StartElement)
+	position := artifacts.Position{FileName: "test.xml", LineNo: 1, Hierarchy: "sequence"}
+
+	mediator, err := ThrottleMediator{}.Unmarshal(decoder, start, position)
+	assert.NoError(t, err)
+
+	throttleMediator, ok := mediator.(artifacts.ThrottleMediator)
+	if !ok {
+		t.Fatalf("Expected artifacts.ThrottleMediator but got %T", mediator)
+	}
+	assert.Equal(t, "perClient", throttleMediator.ID)
+	assert.Equal(t, artifacts.ThrottleKeyHeader, throttleMediator.KeyType)
+	assert.Equal(t, "X-Client-Id", throttleMediator.KeyName)
+	assert.Equal(t, throttle.Policy{Algorithm: throttle.TokenBucket, Limit: 10, Window: time.Minute}, throttleMediator.Policy)
+	assert.Equal(t, 2, throttleMediator.MaxConcurrent)
+	assert.Equal(t, "sequence->throttle", throttleMediator.Position.Hierarchy)
+
+	assert.Len(t, throttleMediator.OnAccept.MediatorList, 1)
+	assert.Equal(t, "sequence->throttle->onAccept->call", throttleMediator.OnAccept.MediatorList[0].(artifacts.CallMediator).Position.Hierarchy)
+	assert.Len(t, throttleMediator.OnReject.MediatorList, 2)
+}
+
+func TestThrottleMediator_Unmarshal_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		xmlData string
+		wantErr string
+	}{
+		{"no limits", `<throttle id="a"/>`, "requires maxCount or maxConcurrent"},
+		{"bad algorithm", `<throttle maxCount="1" unitTime="1000" algorithm="leaky"/>`, "unknown throttle algorithm"},
+		{"bad unit time", `<throttle maxCount="1" unitTime="soon"/>`, "invalid unitTime value"},
+		{"missing key name", `<throttle key="header" maxCount="1" unitTime="1000"/>`, "keyName is required"},
+		{"bad key", `<throttle key="cookie" maxCount="1" unitTime="1000"/>`, "unsupported throttle key"},
+		{"bad concurrency", `<throttle maxConcurrent="-1"/>`, "invalid maxConcurrent value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoder := xml.NewDecoder(strings.NewReader(tt.xmlData))
+			token, _ := decoder.Token()
+			_, err := ThrottleMediator{}.Unmarshal(decoder, token.(xml.StartElement), artifacts.Position{FileName: "test.xml"})
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestAPI_Unmarshal_WithRateLimit(t *testing.T) {
+	xmlData := `<api context="/orders" name="OrdersAPI">
+		<rateLimit maxCount="100" unitTime="60000" algorithm="sliding-window"/>
+		<resource methods="POST" uri-template="/submit">
+			<rateLimit maxCount="5" unitTime="1000" key="header" keyName="X-API-Key"/>
+			<inSequence><respond/></inSequence>
+		</resource>
+	</api>`
+
+	api := &API{}
+	result, err := api.Unmarshal(xmlData, artifacts.Position{FileName: "orders.xml"})
+	assert.NoError(t, err)
+
+	assert.True(t, result.RateLimit.Enabled)
+	assert.Equal(t, artifacts.ThrottleKeyIP, result.RateLimit.KeyType)
+	assert.Equal(t, throttle.Policy{Algorithm: throttle.SlidingWindow, Limit: 100, Window: time.Minute}, result.RateLimit.Policy)
+
+	resourceLimit := result.Resources[0].RateLimit
+	assert.True(t, resourceLimit.Enabled)
+	assert.Equal(t, artifacts.ThrottleKeyHeader, resourceLimit.KeyType)
+	assert.Equal(t, "X-API-Key", resourceLimit.KeyName)
+	assert.Equal(t, throttle.FixedWindow, resourceLimit.Policy.Algorithm)
+
+	_, err = api.Unmarshal(`<api context="/a" name="A"><rateLimit maxCount="1" unitTime="1000" key="property" keyName="p"/></api>`, artifacts.Position{})
+	assert.ErrorContains(t, err, "unsupported throttle key")
+}
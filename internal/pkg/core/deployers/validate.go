@@ -0,0 +1,140 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package deployers
+
+import (
+	"encoding/xml"
+	"errors"
+	"maps"
+	"slices"
+
+	"github.com/apache/synapse-go/internal/pkg/core/artifacts"
+	"github.com/apache/synapse-go/internal/pkg/core/placeholder"
+)
+
+// Problem is an error found in an artifact file by Validate
+type Problem struct {
+	// File is the path of the file relative to the artifacts folder, or the
+	// archive that the file belongs to
+	File     string
+	Position artifacts.Position
+	Message  string
+}
+
+// ValidationReport lists the files checked by Validate and the problems found
+type ValidationReport struct {
+	Files    []string
+	Problems []Problem
+}
+
+// Valid reports whether no problems were found
+func (r *ValidationReport) Valid() bool {
+	return len(r.Problems) == 0
+}
+
+// Validate checks the artifacts under the base path without deploying them:
+// every file and archive must parse, artifact names must be unique per type
+// and every reference must resolve to an artifact that is defined.
+// Placeholders are resolved against deploymentConfig and the environment.
+// Unlike Deploy, it reports every problem instead of stopping at the first
+// one.
+func (d *Deployer) Validate(deploymentConfig map[string]interface{}) (*ValidationReport, error) {
+	files, archives, err := d.scan()
+	if err != nil {
+		return nil, err
+	}
+	report := &ValidationReport{}
+	for _, id := range slices.Sorted(maps.Keys(archives)) {
+		report.Files = append(report.Files, id)
+		if archive := archives[id]; archive.err != nil {
+			report.add(id, artifacts.Position{}, archive.err)
+			continue
+		}
+		maps.Copy(files, archives[id].files)
+	}
+
+	resolver := d.resolver(deploymentConfig)
+	for _, key := range sortedFileKeys(files) {
+		file := files[key]
+		data, err := resolver.Resolve(file.name, file.data)
+		if err != nil {
+			// The file is still checked as it is
+			position := artifacts.Position{FileName: file.name}
+			var placeholderErr *placeholder.Error
+			if errors.As(err, &placeholderErr) {
+				position = placeholderErr.Position
+			}
+			report.add(filePath(key, file), position, err)
+			continue
+		}
+		file.data = data
+		files[key] = file
+	}
+
+	graph := d.newGraph(files)
+	for _, key := range graph.keys {
+		report.Files = append(report.Files, filePath(key, files[key]))
+		if err, failed := graph.errors[key]; failed {
+			report.add(filePath(key, files[key]), artifacts.Position{FileName: files[key].name, LineNo: syntaxErrorLine(err)}, err)
+		}
+	}
+	for _, key := range graph.duplicates {
+		file := files[key]
+		other := graph.providers[file.artifactType+"/"+graph.nodes[key].name]
+		report.add(filePath(key, file), artifacts.Position{FileName: file.name},
+			errors.New("duplicate name "+graph.nodes[key].name+", also defined in "+filePath(other, files[other])))
+	}
+	for _, missing := range graph.link() {
+		report.add(filePath(missing.key, files[missing.key]), missing.reference.Position, missing)
+	}
+	if _, err := graph.sort(); err != nil {
+		var cycle *cycleError
+		if errors.As(err, &cycle) {
+			report.add(filePath(cycle.keys[0], files[cycle.keys[0]]), artifacts.Position{FileName: files[cycle.keys[0]].name}, err)
+		}
+	}
+	// List the problems in file order
+	slices.SortStableFunc(report.Problems, func(a, b Problem) int {
+		return slices.Index(report.Files, a.File) - slices.Index(report.Files, b.File)
+	})
+	return report, nil
+}
+
+func (r *ValidationReport) add(file string, position artifacts.Position, err error) {
+	r.Problems = append(r.Problems, Problem{File: file, Position: position, Message: err.Error()})
+}
+
+// filePath returns the path of a loose file relative to the artifacts folder,
+// such as APIs/OrdersAPI.xml, and the name of a file in an archive
+func filePath(key string, file artifactFile) string {
+	if archiveOf(key) != "" {
+		return file.name
+	}
+	return key
+}
+
+// syntaxErrorLine returns the line of an XML syntax error, or 0
+func syntaxErrorLine(err error) int {
+	var syntaxErr *xml.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return syntaxErr.Line
+	}
+	return 0
+}
@@ -0,0 +1,124 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package deployers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/apache/synapse-go/internal/pkg/core/utils"
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultWatchDelay is how long the watcher waits for file events to settle
+// before redeploying, so that an editor saving a file deploys it once
+const DefaultWatchDelay = 500 * time.Millisecond
+
+// Watch redeploys artifacts when files under the base path change. It returns
+// once the watcher is set up and stops when ctx is cancelled.
+func (d *Deployer) Watch(ctx context.Context, delay time.Duration) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	if err := watcher.Add(d.basePath); err != nil {
+		watcher.Close()
+		return err
+	}
+	// The parent of the carbon apps folder is watched for the folder to be
+	// created, or removed and created again, after startup
+	folderPaths := []string{filepath.Dir(d.carbonAppsPath), d.carbonAppsPath}
+	for _, artifactType := range artifactTypes {
+		folderPaths = append(folderPaths, filepath.Join(d.basePath, artifactType))
+	}
+	for _, folderPath := range folderPaths {
+		if info, err := os.Stat(folderPath); err == nil && info.IsDir() {
+			if err := watcher.Add(folderPath); err != nil {
+				watcher.Close()
+				return err
+			}
+		}
+	}
+	d.logger.Info("Watching artifacts for changes", "path", d.basePath)
+
+	wg := ctx.Value(utils.WaitGroupKey).(*sync.WaitGroup)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer watcher.Close()
+		var settled <-chan time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if d.watchNewFolder(watcher, event) {
+					settled = time.After(delay)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				d.logger.Error("Error watching artifacts:", "error", err)
+			case <-settled:
+				settled = nil
+				if err := d.Deploy(ctx); err != nil {
+					d.logger.Error("Error redeploying artifacts:", "error", err)
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// watchNewFolder starts watching an artifact folder or the carbon apps folder
+// created after startup. It reports whether the event is about artifacts,
+// which the other files next to the carbon apps folder are not.
+func (d *Deployer) watchNewFolder(watcher *fsnotify.Watcher, event fsnotify.Event) bool {
+	carbonAppsPath := filepath.Clean(d.carbonAppsPath)
+	folder := filepath.Dir(event.Name)
+	switch {
+	case filepath.Clean(event.Name) == carbonAppsPath:
+	case folder == filepath.Clean(d.basePath):
+		if !slices.Contains(artifactTypes, filepath.Base(event.Name)) {
+			return true
+		}
+	case folder == filepath.Dir(carbonAppsPath):
+		return false
+	default:
+		return true
+	}
+	if !event.Has(fsnotify.Create) {
+		return true
+	}
+	if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+		if err := watcher.Add(event.Name); err != nil {
+			d.logger.Error("Error watching artifact folder:", "error", err)
+		}
+	}
+	return true
+}
@@ -0,0 +1,56 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package deployers
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatch_CarbonAppsCreatedLater(t *testing.T) {
+	d, ctx, basePath := newTestDeployer(t)
+	require.NoError(t, d.Deploy(ctx))
+	require.NoError(t, d.Watch(ctx, 10*time.Millisecond))
+
+	// Files next to the carbon apps folder do not redeploy
+	version := snapshot(ctx).Version
+	require.NoError(t, os.WriteFile(filepath.Join(basePath, "..", "synapse.pid"), []byte("42"), 0o644))
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, version, snapshot(ctx).Version)
+
+	writeArchive(t, basePath, "OrdersApp.car", "1.0.0",
+		archiveArtifact{"OrdersAPI", "synapse/api", apiXML("OrdersAPI", "/orders", "/list")})
+	assert.Eventually(t, func() bool {
+		return get(d, "/orders/list") == http.StatusOK
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// Archives added later to the new folder are deployed too
+	writeArchive(t, basePath, "StockApp.car", "1.0.0",
+		archiveArtifact{"StockAPI", "synapse/api", apiXML("StockAPI", "/stock", "/list")})
+	assert.Eventually(t, func() bool {
+		return get(d, "/stock/list") == http.StatusOK
+	}, 2*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,130 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package messagestore
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/apache/synapse-go/internal/pkg/core/synctx"
+	bolt "go.etcd.io/bbolt"
+)
+
+var messagesBucket = []byte("messages")
+
+// BboltStore keeps messages in an embedded bbolt database, so that they
+// survive restarts. Keys are big-endian sequence numbers, which keeps the
+// bucket in insertion order.
+type BboltStore struct {
+	name string
+	db   *bolt.DB
+}
+
+func NewBboltStore(name string, path string) (*BboltStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create directory for message store %s: %w", name, err)
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open message store %s at %s: %w", name, path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(messagesBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialise message store %s: %w", name, err)
+	}
+	return &BboltStore{name: name, db: db}, nil
+}
+
+func (s *BboltStore) Name() string {
+	return s.name
+}
+
+func (s *BboltStore) Store(msg *synctx.MsgContext) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(messagesBucket)
+		seq, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+		data, err := encode(strconv.FormatUint(seq, 10), time.Now(), msg)
+		if err != nil {
+			return err
+		}
+		return bucket.Put(sequenceKey(seq), data)
+	})
+}
+
+func (s *BboltStore) Peek() (*Entry, error) {
+	var entry *Entry
+	err := s.db.View(func(tx *bolt.Tx) error {
+		_, data := tx.Bucket(messagesBucket).Cursor().First()
+		if data == nil {
+			return nil
+		}
+		var err error
+		entry, err = decode(data)
+		return err
+	})
+	if err == bolt.ErrDatabaseNotOpen {
+		return nil, ErrStoreClosed
+	}
+	return entry, err
+}
+
+func (s *BboltStore) Remove(id string) error {
+	seq, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return ErrMessageNotFound
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(messagesBucket)
+		if bucket.Get(sequenceKey(seq)) == nil {
+			return ErrMessageNotFound
+		}
+		return bucket.Delete(sequenceKey(seq))
+	})
+}
+
+func (s *BboltStore) Size() (int, error) {
+	var size int
+	err := s.db.View(func(tx *bolt.Tx) error {
+		size = tx.Bucket(messagesBucket).Stats().KeyN
+		return nil
+	})
+	return size, err
+}
+
+func (s *BboltStore) Close() error {
+	return s.db.Close()
+}
+
+func sequenceKey(seq uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, seq)
+	return key
+}
@@ -0,0 +1,100 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package messagestore
+
+import (
+	"bytes"
+	"encoding/gob"
+	"time"
+
+	"github.com/apache/synapse-go/internal/pkg/core/synctx"
+)
+
+func init() {
+	// Property value types set by the router and inbounds
+	gob.Register(map[string]string{})
+	gob.Register(map[string]interface{}{})
+	gob.Register([]interface{}{})
+}
+
+// storedMessage is the serialised form of a message context
+type storedMessage struct {
+	ID          string
+	StoredAt    time.Time
+	MessageID   string
+	Properties  map[string]interface{}
+	Headers     map[string]string
+	RawPayload  []byte
+	ContentType string
+	SOAPAction  string
+}
+
+// encode serialises the payload, headers and properties of msg. Properties
+// holding values that cannot be serialised, such as open request bodies, are
+// left out.
+func encode(id string, storedAt time.Time, msg *synctx.MsgContext) ([]byte, error) {
+	stored := storedMessage{
+		ID:          id,
+		StoredAt:    storedAt,
+		MessageID:   msg.MessageID,
+		Properties:  make(map[string]interface{}),
+		Headers:     msg.Headers,
+		RawPayload:  msg.Message.RawPayload,
+		ContentType: msg.Message.ContentType,
+		SOAPAction:  msg.Message.SOAPAction,
+	}
+	for key, value := range msg.Properties {
+		if isEncodable(value) {
+			stored.Properties[key] = value
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&stored); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func isEncodable(value interface{}) bool {
+	return gob.NewEncoder(&bytes.Buffer{}).Encode(&value) == nil
+}
+
+func decode(data []byte) (*Entry, error) {
+	var stored storedMessage
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&stored); err != nil {
+		return nil, err
+	}
+	msg := synctx.CreateMsgContext()
+	// Messages stored before message IDs were stored get a new one
+	if stored.MessageID != "" {
+		msg.MessageID = stored.MessageID
+	}
+	for key, value := range stored.Properties {
+		msg.Properties[key] = value
+	}
+	for key, value := range stored.Headers {
+		msg.Headers[key] = value
+	}
+	msg.Message.RawPayload = stored.RawPayload
+	msg.Message.ContentType = stored.ContentType
+	msg.Message.SOAPAction = stored.SOAPAction
+	return &Entry{ID: stored.ID, StoredAt: stored.StoredAt, Context: msg}, nil
+}
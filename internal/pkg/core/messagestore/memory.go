@@ -0,0 +1,107 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package messagestore
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/apache/synapse-go/internal/pkg/core/synctx"
+)
+
+// MemoryStore keeps messages in memory. Messages are lost when the server stops.
+type MemoryStore struct {
+	name    string
+	mu      sync.Mutex
+	nextID  uint64
+	entries [][]byte
+	ids     []string
+	closed  bool
+}
+
+func NewMemoryStore(name string) *MemoryStore {
+	return &MemoryStore{name: name}
+}
+
+func (s *MemoryStore) Name() string {
+	return s.name
+}
+
+func (s *MemoryStore) Store(msg *synctx.MsgContext) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrStoreClosed
+	}
+	s.nextID++
+	id := strconv.FormatUint(s.nextID, 10)
+	// Store an encoded copy so that later changes to msg are not visible
+	data, err := encode(id, time.Now(), msg)
+	if err != nil {
+		return err
+	}
+	s.entries = append(s.entries, data)
+	s.ids = append(s.ids, id)
+	return nil
+}
+
+func (s *MemoryStore) Peek() (*Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil, ErrStoreClosed
+	}
+	if len(s.entries) == 0 {
+		return nil, nil
+	}
+	return decode(s.entries[0])
+}
+
+func (s *MemoryStore) Remove(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrStoreClosed
+	}
+	for i, storedID := range s.ids {
+		if storedID == id {
+			s.entries = append(s.entries[:i], s.entries[i+1:]...)
+			s.ids = append(s.ids[:i], s.ids[i+1:]...)
+			return nil
+		}
+	}
+	return ErrMessageNotFound
+}
+
+func (s *MemoryStore) Size() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries), nil
+}
+
+func (s *MemoryStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	s.entries = nil
+	s.ids = nil
+	return nil
+}
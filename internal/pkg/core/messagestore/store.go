@@ -0,0 +1,84 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+// Package messagestore persists message contexts so that they can be
+// delivered later, for example by a message processor once a backend is
+// reachable again.
+package messagestore
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/apache/synapse-go/internal/pkg/core/synctx"
+)
+
+// Store types accepted in the class attribute of a message store
+const (
+	TypeMemory = "memory"
+	TypeBbolt  = "bbolt"
+)
+
+var (
+	// ErrStoreClosed is returned when a closed store is used
+	ErrStoreClosed = errors.New("message store is closed")
+	// ErrMessageNotFound is returned when removing a message that is not in the store
+	ErrMessageNotFound = errors.New("message not found in store")
+)
+
+// Entry is a message held in a store
+type Entry struct {
+	ID       string
+	StoredAt time.Time
+	Context  *synctx.MsgContext
+}
+
+// Store is a FIFO queue of messages
+type Store interface {
+	// Name returns the name of the message store artifact
+	Name() string
+	// Store appends a copy of the message to the queue
+	Store(msg *synctx.MsgContext) error
+	// Peek returns the oldest message without removing it, or nil when the store is empty
+	Peek() (*Entry, error)
+	// Remove deletes the message with the given id
+	Remove(id string) error
+	// Size returns the number of messages in the store
+	Size() (int, error)
+	// Close releases resources held by the store
+	Close() error
+}
+
+// New creates a store of the given type. Persistent stores read their
+// location from parameters.
+func New(name string, storeType string, parameters map[string]string) (Store, error) {
+	switch storeType {
+	case TypeMemory:
+		return NewMemoryStore(name), nil
+	case TypeBbolt:
+		path := parameters["store.bbolt.path"]
+		if path == "" {
+			return nil, fmt.Errorf("missing required parameter 'store.bbolt.path' for message store %s", name)
+		}
+		return NewBboltStore(name, path)
+	default:
+		return nil, fmt.Errorf("unsupported message store type: %s", storeType)
+	}
+}
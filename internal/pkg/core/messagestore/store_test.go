@@ -0,0 +1,117 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package messagestore
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/apache/synapse-go/internal/pkg/core/synctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestMessage(payload string) *synctx.MsgContext {
+	msg := synctx.CreateMsgContext()
+	msg.Message.RawPayload = []byte(payload)
+	msg.Message.ContentType = "application/json"
+	msg.Headers["FILE_NAME"] = "order.json"
+	msg.Properties["uriParams"] = map[string]string{"id": "42"}
+	msg.Properties["retries"] = 3
+	msg.SetCorrelationID("order-" + payload)
+	// Request bodies cannot be serialised and are dropped
+	msg.Properties["http_request_body"] = strings.NewReader("body")
+	return msg
+}
+
+func testStore(t *testing.T, store Store) {
+	size, err := store.Size()
+	require.NoError(t, err)
+	assert.Equal(t, 0, size)
+
+	entry, err := store.Peek()
+	require.NoError(t, err)
+	assert.Nil(t, entry)
+
+	first := newTestMessage(`{"order":1}`)
+	require.NoError(t, store.Store(first))
+	require.NoError(t, store.Store(newTestMessage(`{"order":2}`)))
+
+	size, err = store.Size()
+	require.NoError(t, err)
+	assert.Equal(t, 2, size)
+
+	entry, err = store.Peek()
+	require.NoError(t, err)
+	require.NotNil(t, entry)
+	assert.Equal(t, `{"order":1}`, string(entry.Context.Message.RawPayload))
+	assert.Equal(t, "application/json", entry.Context.Message.ContentType)
+	assert.Equal(t, "order.json", entry.Context.Headers["FILE_NAME"])
+	assert.Equal(t, map[string]string{"id": "42"}, entry.Context.Properties["uriParams"])
+	assert.Equal(t, 3, entry.Context.Properties["retries"])
+	assert.NotContains(t, entry.Context.Properties, "http_request_body")
+	assert.Equal(t, first.MessageID, entry.Context.MessageID)
+	assert.Equal(t, `order-{"order":1}`, entry.Context.CorrelationID())
+	assert.False(t, entry.StoredAt.IsZero())
+
+	require.NoError(t, store.Remove(entry.ID))
+	assert.ErrorIs(t, store.Remove(entry.ID), ErrMessageNotFound)
+
+	entry, err = store.Peek()
+	require.NoError(t, err)
+	assert.Equal(t, `{"order":2}`, string(entry.Context.Message.RawPayload))
+}
+
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore("memoryStore")
+	testStore(t, store)
+
+	require.NoError(t, store.Close())
+	assert.ErrorIs(t, store.Store(newTestMessage("{}")), ErrStoreClosed)
+}
+
+func TestBboltStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stores", "orders.db")
+	store, err := NewBboltStore("bboltStore", path)
+	require.NoError(t, err)
+	testStore(t, store)
+	require.NoError(t, store.Close())
+
+	// Messages survive reopening the store
+	reopened, err := NewBboltStore("bboltStore", path)
+	require.NoError(t, err)
+	defer reopened.Close()
+	size, err := reopened.Size()
+	require.NoError(t, err)
+	assert.Equal(t, 1, size)
+}
+
+func TestNew(t *testing.T) {
+	store, err := New("a", TypeMemory, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "a", store.Name())
+
+	_, err = New("b", TypeBbolt, map[string]string{})
+	assert.ErrorContains(t, err, "store.bbolt.path")
+
+	_, err = New("c", "jdbc", nil)
+	assert.ErrorContains(t, err, "unsupported message store type")
+}
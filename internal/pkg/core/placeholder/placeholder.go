@@ -0,0 +1,266 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+// Package placeholder resolves the ${env:VAR}, ${config:section.key} and
+// ${file:path} placeholders and the {secret:alias} references of artifact
+// files, so that the same artifacts can be promoted through environments.
+package placeholder
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/apache/synapse-go/internal/pkg/core/artifacts"
+	"github.com/apache/synapse-go/internal/pkg/vault"
+)
+
+// Sources of placeholder values
+const (
+	SourceEnv    = "env"
+	SourceConfig = "config"
+	SourceFile   = "file"
+)
+
+// defaultSeparator separates the name of a placeholder from its default
+// value, as in ${env:HOST:-localhost}
+const defaultSeparator = ":-"
+
+// Error is a placeholder that cannot be resolved
+type Error struct {
+	Placeholder string
+	Position    artifacts.Position
+	// Err is the error reading the value, nil if it is not defined
+	Err error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("cannot resolve placeholder %s in %s at line %d: %v", e.Placeholder, e.Position.FileName, e.Position.LineNo, e.Err)
+	}
+	return fmt.Sprintf("unresolved placeholder %s in %s at line %d", e.Placeholder, e.Position.FileName, e.Position.LineNo)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Resolver replaces placeholders with values from the environment, the
+// deployment configuration and files
+type Resolver struct {
+	// DeploymentConfig holds the sections of deployment.toml
+	DeploymentConfig map[string]interface{}
+	// BasePath is the folder that relative ${file:path} placeholders are
+	// read from
+	BasePath string
+	// LookupEnv reads environment variables, os.LookupEnv if nil
+	LookupEnv func(string) (string, bool)
+	// Secrets resolves {secret:alias} and $secret{alias} references after
+	// the placeholders, if not nil
+	Secrets *vault.Vault
+}
+
+// Resolve replaces every placeholder and secret reference in the XML data of
+// fileName. Values are XML-escaped, so they can be used in both attributes and
+// text, and secrets in URIs are URL-escaped as well. A placeholder without a value and without a default fails the whole
+// file. XML comments are left as they are, so that commented out
+// configuration does not need its placeholders to resolve.
+func (r *Resolver) Resolve(fileName string, data string) (string, error) {
+	var resolved strings.Builder
+	line := 1
+	for data != "" {
+		text, comment := data, ""
+		if start := strings.Index(data, "<!--"); start >= 0 {
+			text, comment = data[:start], data[start:]
+			if end := strings.Index(comment, "-->"); end >= 0 {
+				comment = comment[:end+len("-->")]
+			}
+		}
+		value, err := r.resolveText(fileName, text, line)
+		if err != nil {
+			return "", err
+		}
+		resolved.WriteString(value)
+		resolved.WriteString(comment)
+		line += strings.Count(text, "\n") + strings.Count(comment, "\n")
+		data = data[len(text)+len(comment):]
+	}
+	return resolved.String(), nil
+}
+
+// resolveText resolves the placeholders and secret references of text that
+// starts at line of fileName
+func (r *Resolver) resolveText(fileName string, text string, line int) (string, error) {
+	resolved, err := r.resolvePlaceholders(fileName, text, line)
+	if err != nil || r.Secrets == nil {
+		return resolved, err
+	}
+	// Escaped values hold no line breaks, so lines are unchanged
+	expanded, err := r.expandSecrets(resolved)
+	var refErr *vault.ReferenceError
+	if errors.As(err, &refErr) {
+		position := artifacts.Position{FileName: fileName, LineNo: line + strings.Count(resolved[:refErr.Offset], "\n")}
+		placeholderErr := &Error{Placeholder: refErr.Reference, Position: position, Err: refErr.Err}
+		if errors.Is(refErr.Err, vault.ErrSecretNotFound) {
+			placeholderErr.Err = nil
+		}
+		return "", placeholderErr
+	}
+	return expanded, err
+}
+
+func (r *Resolver) resolvePlaceholders(fileName string, data string, line int) (string, error) {
+	var resolved strings.Builder
+	rest := data
+	for {
+		start := strings.Index(rest, "${")
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			break
+		}
+		placeholder := rest[start : start+end+1]
+		line += strings.Count(rest[:start], "\n")
+		source, name, ok := strings.Cut(placeholder[2:len(placeholder)-1], ":")
+		if !ok || (source != SourceEnv && source != SourceConfig && source != SourceFile) {
+			// Not a placeholder, such as a Synapse expression
+			resolved.WriteString(rest[:start+2])
+			rest = rest[start+2:]
+			continue
+		}
+
+		value, err := r.lookup(source, name)
+		if err != nil || value == "" {
+			if _, defaultValue, hasDefault := strings.Cut(name, defaultSeparator); hasDefault && err == nil {
+				value = defaultValue
+			} else {
+				position := artifacts.Position{FileName: fileName, LineNo: line}
+				return "", &Error{Placeholder: placeholder, Position: position, Err: err}
+			}
+		}
+		resolved.WriteString(rest[:start])
+		resolved.WriteString(escape(value))
+		line += strings.Count(placeholder, "\n")
+		rest = rest[start+end+1:]
+	}
+	resolved.WriteString(rest)
+	return resolved.String(), nil
+}
+
+// lookup returns the value of a placeholder, or an empty string if it is not
+// defined
+func (r *Resolver) lookup(source string, name string) (string, error) {
+	name, _, _ = strings.Cut(name, defaultSeparator)
+	switch source {
+	case SourceEnv:
+		lookupEnv := r.LookupEnv
+		if lookupEnv == nil {
+			lookupEnv = os.LookupEnv
+		}
+		value, _ := lookupEnv(name)
+		return value, nil
+	case SourceConfig:
+		section, key, _ := strings.Cut(name, ".")
+		settings, _ := r.DeploymentConfig[section].(map[string]string)
+		return settings[key], nil
+	default:
+		path := name
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(r.BasePath, path)
+		}
+		data, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", nil
+		}
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+}
+
+// valueBoundaries end the attribute values and the words of text that a
+// secret reference can be part of
+const valueBoundaries = "\"'<> \t\r\n"
+
+// expandSecrets expands the secret references of text. A secret that follows
+// the scheme of a URI in the same attribute value or word, such as the
+// password in ftp://user:{secret:ftp-password}@host, is also URL-escaped, so
+// that characters such as @ and / in it do not change the URI.
+func (r *Resolver) expandSecrets(text string) (string, error) {
+	var expanded strings.Builder
+	offset := 0
+	expand := func(s string, escape func(string) string) error {
+		value, err := r.Secrets.Expand(s, escape)
+		var refErr *vault.ReferenceError
+		if errors.As(err, &refErr) {
+			// Offsets are in text, before the secrets of s are expanded
+			refErr.Offset = offset + strings.Index(s, refErr.Reference)
+		}
+		if err != nil {
+			return err
+		}
+		expanded.WriteString(value)
+		offset += len(s)
+		return nil
+	}
+	for text != "" {
+		end := strings.IndexAny(text, valueBoundaries) + 1
+		if end == 0 {
+			end = len(text)
+		}
+		value := text[:end]
+		text = text[end:]
+		scheme := strings.Index(value, "://")
+		if scheme < 0 {
+			if err := expand(value, escape); err != nil {
+				return "", err
+			}
+			continue
+		}
+		if err := expand(value[:scheme+len("://")], escape); err != nil {
+			return "", err
+		}
+		if err := expand(value[scheme+len("://"):], escapeURIComponent); err != nil {
+			return "", err
+		}
+	}
+	return expanded.String(), nil
+}
+
+// escapeURIComponent escapes a secret that is part of a URI, and then for
+// use in XML, which the escaped value needs no more escaping for
+func escapeURIComponent(value string) string {
+	return strings.ReplaceAll(url.QueryEscape(value), "+", "%20")
+}
+
+// escape escapes a value for use in XML attributes and text
+func escape(value string) string {
+	var escaped strings.Builder
+	// Writing to a strings.Builder does not fail
+	_ = xml.EscapeText(&escaped, []byte(value))
+	return escaped.String()
+}
@@ -0,0 +1,134 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+// Package registry implements the file-backed registry that holds shared
+// resources such as XSLTs, schemas and WSDLs. Keys with the conf: prefix
+// resolve under <root>/conf and keys with the gov: prefix under <root>/gov.
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Registry key prefixes
+const (
+	ConfPrefix = "conf:"
+	GovPrefix  = "gov:"
+)
+
+var ErrResourceNotFound = errors.New("resource not found")
+
+type Registry struct {
+	root  string
+	cache *FileCache
+}
+
+func New(root string) *Registry {
+	return &Registry{root: root, cache: NewFileCache()}
+}
+
+// IsRegistryKey reports whether key refers to the registry rather than a local entry
+func IsRegistryKey(key string) bool {
+	return strings.HasPrefix(key, ConfPrefix) || strings.HasPrefix(key, GovPrefix)
+}
+
+// Get returns the content of the resource at key, such as conf:/xslt/order.xslt
+func (r *Registry) Get(key string) ([]byte, error) {
+	path, err := r.Path(key)
+	if err != nil {
+		return nil, err
+	}
+	content, err := r.cache.Read(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrResourceNotFound, key)
+	}
+	return content, err
+}
+
+// Path returns the file that backs key
+func (r *Registry) Path(key string) (string, error) {
+	var collection, resource string
+	switch {
+	case strings.HasPrefix(key, ConfPrefix):
+		collection, resource = "conf", strings.TrimPrefix(key, ConfPrefix)
+	case strings.HasPrefix(key, GovPrefix):
+		collection, resource = "gov", strings.TrimPrefix(key, GovPrefix)
+	default:
+		return "", fmt.Errorf("invalid registry key %s: must start with %s or %s", key, ConfPrefix, GovPrefix)
+	}
+
+	// Cleaning the path as an absolute one keeps it inside the collection
+	resource = filepath.Clean("/" + filepath.FromSlash(resource))
+	if resource == string(filepath.Separator) {
+		return "", fmt.Errorf("invalid registry key %s: resource path is empty", key)
+	}
+	return filepath.Join(r.root, collection, resource), nil
+}
+
+type cachedFile struct {
+	content []byte
+	modTime time.Time
+	size    int64
+}
+
+// FileCache caches file contents. A file is read again when its modification
+// time or size changes.
+type FileCache struct {
+	mu    sync.Mutex
+	files map[string]cachedFile
+}
+
+func NewFileCache() *FileCache {
+	return &FileCache{files: make(map[string]cachedFile)}
+}
+
+func (c *FileCache) Read(path string) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		c.mu.Lock()
+		delete(c.files, path)
+		c.mu.Unlock()
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("%s is a directory", path)
+	}
+
+	c.mu.Lock()
+	cached, ok := c.files[path]
+	c.mu.Unlock()
+	if ok && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+		return cached.content, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.files[path] = cachedFile{content: content, modTime: info.ModTime(), size: info.Size()}
+	c.mu.Unlock()
+	return content, nil
+}
@@ -0,0 +1,84 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_Get(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "conf", "xslt"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "gov", "schemas"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "conf", "xslt", "order.xslt"), []byte("<xsl/>"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "gov", "schemas", "order.xsd"), []byte("<xs/>"), 0o644))
+
+	reg := New(root)
+	content, err := reg.Get("conf:/xslt/order.xslt")
+	require.NoError(t, err)
+	assert.Equal(t, "<xsl/>", string(content))
+
+	content, err = reg.Get("gov:schemas/order.xsd")
+	require.NoError(t, err)
+	assert.Equal(t, "<xs/>", string(content))
+
+	_, err = reg.Get("conf:/xslt/missing.xslt")
+	assert.ErrorIs(t, err, ErrResourceNotFound)
+
+	_, err = reg.Get("xslt/order.xslt")
+	assert.ErrorContains(t, err, "invalid registry key")
+}
+
+func TestRegistry_PathStaysInCollection(t *testing.T) {
+	reg := New("/opt/synapse/registry")
+	path, err := reg.Path("conf:/../../etc/passwd")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join("/opt/synapse/registry", "conf", "etc", "passwd"), path)
+
+	_, err = reg.Path("gov:/")
+	assert.ErrorContains(t, err, "resource path is empty")
+}
+
+func TestFileCache_ReloadsChangedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "entry.txt")
+	require.NoError(t, os.WriteFile(path, []byte("v1"), 0o644))
+
+	cache := NewFileCache()
+	content, err := cache.Read(path)
+	require.NoError(t, err)
+	assert.Equal(t, "v1", string(content))
+
+	require.NoError(t, os.WriteFile(path, []byte("v2-updated"), 0o644))
+	later := time.Now().Add(time.Second)
+	require.NoError(t, os.Chtimes(path, later, later))
+	content, err = cache.Read(path)
+	require.NoError(t, err)
+	assert.Equal(t, "v2-updated", string(content))
+
+	require.NoError(t, os.Remove(path))
+	_, err = cache.Read(path)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
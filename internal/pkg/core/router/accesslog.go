@@ -0,0 +1,146 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package router
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/apache/synapse-go/internal/pkg/core/artifacts"
+	"github.com/apache/synapse-go/internal/pkg/core/synctx"
+	"github.com/apache/synapse-go/internal/pkg/loggerfactory"
+)
+
+const (
+	accessLoggerName = "access"
+	// Longer correlation IDs from clients are replaced
+	maxCorrelationIDLength = 128
+)
+
+// AccessLogMiddleware logs every request and its response in the Combined
+// Log Format to the access logger. It also makes sure the request carries a
+// correlation ID in the header named by server.correlation_header, creating
+// one if the client sent none, and returns it to the client.
+func AccessLogMiddleware(next http.Handler, ctx context.Context) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		header := artifacts.CorrelationHeader(ctx)
+		correlationID := r.Header.Get(header)
+		if !validCorrelationID(correlationID) {
+			correlationID = synctx.NewUUID()
+			r.Header.Set(header, correlationID)
+		}
+		w.Header().Set(header, correlationID)
+
+		recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		logger := loggerfactory.GetLogger(accessLoggerName, nil)
+		if !logger.Enabled(r.Context(), slog.LevelInfo) {
+			return
+		}
+		logger.LogAttrs(r.Context(), slog.LevelInfo, combinedLogLine(r, recorder, start),
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.String("protocol", r.Proto),
+			slog.Int("status", recorder.status),
+			slog.Int64("bytes", recorder.bytes),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("remote_addr", r.RemoteAddr),
+			slog.String("user_agent", r.UserAgent()),
+			slog.String("referer", r.Referer()),
+			slog.String("correlation_id", correlationID))
+	})
+}
+
+// validCorrelationID reports whether a correlation ID from a client can be
+// used, which keeps control characters and large values out of the logs
+func validCorrelationID(id string) bool {
+	if id == "" || len(id) > maxCorrelationIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// combinedLogLine formats a request in the Combined Log Format:
+// host ident user [time] "request line" status bytes "referer" "user agent"
+func combinedLogLine(r *http.Request, recorder *responseRecorder, start time.Time) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	bytes := "-"
+	if recorder.bytes > 0 {
+		bytes = strconv.FormatInt(recorder.bytes, 10)
+	}
+	return fmt.Sprintf("%s - - [%s] %q %d %s %q %q",
+		host,
+		start.Format("02/Jan/2006:15:04:05 -0700"),
+		r.Method+" "+r.URL.RequestURI()+" "+r.Proto,
+		recorder.status,
+		bytes,
+		orDash(r.Referer()),
+		orDash(r.UserAgent()))
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// responseRecorder records the status code and size of a response
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(p []byte) (int, error) {
+	r.wroteHeader = true
+	n, err := r.ResponseWriter.Write(p)
+	r.bytes += int64(n)
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,134 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/apache/synapse-go/internal/pkg/core/artifacts"
+	"github.com/apache/synapse-go/internal/pkg/core/utils"
+	"github.com/apache/synapse-go/internal/pkg/loggerfactory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// accessRecord serves r through the access log middleware and returns the
+// response and the record logged for it, read from the recent logs
+func accessRecord(t *testing.T, ctx context.Context, r *http.Request) (*httptest.ResponseRecorder, map[string]interface{}) {
+	t.Helper()
+	recent := loggerfactory.GetRecentLogs()
+	var after uint64
+	if lines := recent.Since(0, 1); len(lines) > 0 {
+		after = lines[0].Seq
+	}
+	handler := AccessLogMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("created"))
+	}), ctx)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	lines := recent.Since(after, 0)
+	require.NotEmpty(t, lines)
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[len(lines)-1].Line), &record))
+	return w, record
+}
+
+func TestAccessLog_CorrelationID(t *testing.T) {
+	ctx := context.Background()
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+	tests := []struct {
+		name string
+		id   string
+		keep bool
+	}{
+		{"sent by the client", "order-42", true},
+		{"longest", strings.Repeat("a", maxCorrelationIDLength), true},
+		{"none", "", false},
+		{"too long", strings.Repeat("a", maxCorrelationIDLength+1), false},
+		{"control character", "order\t42", false},
+		{"space", "order 42", false},
+		{"not ASCII", "bestellung-ä", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/orders", nil)
+			if tt.id != "" {
+				r.Header.Set(artifacts.DefaultCorrelationHeader, tt.id)
+			}
+			w, record := accessRecord(t, ctx, r)
+
+			id := w.Header().Get(artifacts.DefaultCorrelationHeader)
+			if tt.keep {
+				assert.Equal(t, tt.id, id)
+			} else {
+				assert.Regexp(t, uuid, id)
+			}
+			// Mediation sees the ID returned to the client
+			assert.Equal(t, id, r.Header.Get(artifacts.DefaultCorrelationHeader))
+			assert.Equal(t, id, record["correlation_id"])
+		})
+	}
+}
+
+func TestAccessLog_CorrelationHeader(t *testing.T) {
+	configContext := &artifacts.ConfigContext{DeploymentConfig: map[string]interface{}{
+		"server": map[string]string{"correlation_header": "X-Request-ID"},
+	}}
+	ctx := context.WithValue(context.Background(), utils.ConfigContextKey, configContext)
+
+	r := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	r.Header.Set("X-Request-ID", "order-42")
+	w, record := accessRecord(t, ctx, r)
+
+	assert.Equal(t, "order-42", w.Header().Get("X-Request-ID"))
+	assert.Empty(t, w.Header().Get(artifacts.DefaultCorrelationHeader))
+	assert.Equal(t, "order-42", record["correlation_id"])
+}
+
+func TestAccessLog_CombinedLogFormat(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/orders?id=42", strings.NewReader("{}"))
+	r.RemoteAddr = "192.0.2.7:51234"
+	r.Header.Set("User-Agent", "curl/8.5.0")
+	r.Header.Set("Referer", "http://example.com/")
+	w, record := accessRecord(t, context.Background(), r)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Regexp(t, `^192\.0\.2\.7 - - \[\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} [-+]\d{4}\] "POST /orders\?id=42 HTTP/1\.1" 201 7 "http://example\.com/" "curl/8\.5\.0"$`, record["msg"])
+	assert.Equal(t, "POST", record["method"])
+	assert.Equal(t, "/orders", record["path"])
+	assert.Equal(t, float64(http.StatusCreated), record["status"])
+	assert.Equal(t, float64(7), record["bytes"])
+	assert.Equal(t, "192.0.2.7:51234", record["remote_addr"])
+
+	// Requests without a body, referer or user agent log dashes
+	r = httptest.NewRequest(http.MethodGet, "/livez", nil)
+	r.RemoteAddr = "192.0.2.7"
+	_, record = accessRecord(t, context.Background(), r)
+	assert.Regexp(t, `^192\.0\.2\.7 - - \[.*\] "GET /livez HTTP/1\.1" 201 7 "-" "-"$`, record["msg"])
+}
@@ -0,0 +1,96 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"github.com/apache/synapse-go/internal/pkg/core/artifacts"
+	"github.com/apache/synapse-go/internal/pkg/health"
+)
+
+// registerHealthEndpoints registers /readyz, which reports whether the
+// server is ready to mediate messages with the state of every artifact, and
+// /healthz, which aggregates it into one check per aspect. Both respond 503
+// when the server is not ready.
+func (rs *RouterService) registerHealthEndpoints(ctx context.Context) {
+	readiness := func(r *http.Request) health.Report {
+		return health.GetRegistry().Readiness(checkEndpoints(ctx, r.Context()))
+	}
+	if err := rs.setRoutes("health", func(mux *http.ServeMux) {
+		mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) {
+			report := readiness(r)
+			writeJSON(w, healthStatusCode(report), report)
+		})
+		mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+			report := readiness(r)
+			writeJSON(w, healthStatusCode(report), report.Summary())
+		})
+	}); err != nil {
+		rs.logger.Error("Error registering health endpoints", "error", err.Error())
+	}
+}
+
+func healthStatusCode(report health.Report) int {
+	if report.Ready() {
+		return http.StatusOK
+	}
+	return http.StatusServiceUnavailable
+}
+
+// checkEndpoints checks the backends of the endpoints selected in the
+// [health] section of deployment.toml. Selected endpoints that are not
+// deployed are reported as down.
+func checkEndpoints(ctx context.Context, requestCtx context.Context) []health.EndpointStatus {
+	configContext, ok := artifacts.ConfigFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	settings, _ := configContext.DeploymentConfig["health"].(map[string]string)
+	// The section is validated when the configuration is loaded
+	config, _ := health.ParseConfig(settings)
+	if len(config.Endpoints) == 0 {
+		return nil
+	}
+
+	urls := make(map[string]string)
+	for name, endpoint := range configContext.EndpointMap {
+		if config.Checks(name) {
+			urls[name] = endpoint.EndpointUrl.URITemplate
+		}
+	}
+	statuses := health.CheckEndpoints(requestCtx, urls, config.Timeout)
+	for _, name := range config.Endpoints {
+		if _, deployed := configContext.EndpointMap[name]; !deployed && name != "*" {
+			statuses = append(statuses, health.EndpointStatus{Name: name, Status: health.StatusDown, Error: "endpoint is not deployed"})
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
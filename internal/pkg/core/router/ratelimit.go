@@ -0,0 +1,72 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package router
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/apache/synapse-go/internal/pkg/core/artifacts"
+	"github.com/apache/synapse-go/internal/pkg/core/throttle"
+)
+
+// RateLimitMiddleware rejects requests exceeding the configured rate with
+// 429 Too Many Requests and a Retry-After header. Counters are kept under
+// name, so each API and resource is limited independently.
+func RateLimitMiddleware(handler http.Handler, name string, config artifacts.RateLimitConfig, store throttle.Store) http.Handler {
+	// Skip rate limiting if disabled
+	if !config.Enabled {
+		return handler
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Callers without the header are counted by address, so that they do
+		// not share one counter
+		var callerKey string
+		if config.KeyType == artifacts.ThrottleKeyHeader {
+			callerKey = r.Header.Get(config.KeyName)
+		}
+		if callerKey == "" {
+			callerKey = remoteHost(r)
+		}
+
+		decision := store.Take(name+":"+callerKey, config.Policy, time.Now())
+		w.Header().Set("X-RateLimit-Limit", strconv.Itoa(config.Policy.Limit))
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+		if !decision.Allowed {
+			retryAfter := int(math.Ceil(decision.RetryAfter.Seconds()))
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			http.Error(w, "Too many requests", http.StatusTooManyRequests)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// remoteHost is the address of the caller without its port
+func remoteHost(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
@@ -0,0 +1,82 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/apache/synapse-go/internal/pkg/core/artifacts"
+	"github.com/apache/synapse-go/internal/pkg/core/throttle"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	config := artifacts.RateLimitConfig{
+		Enabled: true,
+		Policy:  throttle.Policy{Algorithm: throttle.FixedWindow, Limit: 1, Window: time.Minute},
+		KeyType: artifacts.ThrottleKeyHeader,
+		KeyName: "X-API-Key",
+	}
+	handler := RateLimitMiddleware(ok, "OrdersAPI", config, throttle.NewMemoryStore())
+
+	request := func(remoteAddr string, apiKey string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/orders", nil)
+		r.RemoteAddr = remoteAddr
+		if apiKey != "" {
+			r.Header.Set("X-API-Key", apiKey)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	w := request("10.0.0.1:5000", "key-a")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "1", w.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+
+	w = request("10.0.0.2:5000", "key-a")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code, "callers with the same key share a counter")
+	assert.Equal(t, "60", w.Header().Get("Retry-After"))
+
+	assert.Equal(t, http.StatusOK, request("10.0.0.1:5000", "key-b").Code)
+
+	// Callers without the header are counted by address
+	assert.Equal(t, http.StatusOK, request("10.0.0.3:5000", "").Code)
+	assert.Equal(t, http.StatusOK, request("10.0.0.4:5000", "").Code)
+	assert.Equal(t, http.StatusTooManyRequests, request("10.0.0.3:6000", "").Code)
+}
+
+func TestRateLimitMiddlewareDisabled(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	handler := RateLimitMiddleware(ok, "OrdersAPI", artifacts.RateLimitConfig{}, throttle.NewMemoryStore())
+	for i := 0; i < 3; i++ {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/orders", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("X-RateLimit-Limit"))
+	}
+}
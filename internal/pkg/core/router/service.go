@@ -24,6 +24,7 @@
 // - HTTP server lifecycle management with automatic start/stop
 // - Request handling with conversion to/from Synapse message contexts
 // - Method-based routing for RESTful APIs
+// - Route replacement and removal for hot deployment
 
 package router
 
@@ -34,24 +35,42 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"encoding/json"
 
 	"github.com/apache/synapse-go/internal/pkg/core/artifacts"
 	"github.com/apache/synapse-go/internal/pkg/core/synctx"
+	"github.com/apache/synapse-go/internal/pkg/core/throttle"
 	"github.com/apache/synapse-go/internal/pkg/loggerfactory"
+	"github.com/apache/synapse-go/internal/pkg/metrics"
+	"github.com/apache/synapse-go/internal/pkg/tracing"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 )
 
 const (
 	componentName = "router"
 )
 
-// RouterService manages API routing and server lifecycle
+// routeRegistration adds the routes of one API, proxy service or endpoint to a mux
+type routeRegistration func(mux *http.ServeMux)
+
+// RouterService manages API routing and server lifecycle. http.ServeMux cannot
+// remove routes, so every change builds a new mux from the registrations and
+// swaps it in. Requests in flight finish on the mux they started on.
 type RouterService struct {
 	server   *http.Server
-	router   *http.ServeMux
+	router   atomic.Pointer[http.ServeMux]
+	mu       sync.Mutex
+	routes   map[string]routeRegistration
+	// held is the routes when Hold was called. While it is set, route
+	// changes are checked for conflicts but held back in next.
+	held     map[string]routeRegistration
+	next     *http.ServeMux
 	port     string // :8290
 	hostname string
 	logger   *slog.Logger
@@ -60,18 +79,107 @@ type RouterService struct {
 // NewRouterService creates a new router service with the given port and hostname
 func NewRouterService(port string, hostname string) *RouterService {
 	rs := &RouterService{
-		router:   http.NewServeMux(),
+		routes:   make(map[string]routeRegistration),
 		hostname: hostname,
 		port:     port,
 	}
+	rs.router.Store(http.NewServeMux())
 	rs.logger = loggerfactory.GetLogger(componentName, rs)
 	return rs
 }
 
+// ServeHTTP dispatches the request to the current mux
+func (rs *RouterService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rs.router.Load().ServeHTTP(w, r)
+}
+
+// setRoutes adds, replaces or, with a nil registration, removes the routes
+// under key. The current mux is kept when the new routes conflict.
+func (rs *RouterService) setRoutes(key string, registration routeRegistration) error {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	routes := make(map[string]routeRegistration, len(rs.routes)+1)
+	for k, r := range rs.routes {
+		routes[k] = r
+	}
+	if registration == nil {
+		delete(routes, key)
+	} else {
+		routes[key] = registration
+	}
+
+	mux, err := buildMux(routes)
+	if err != nil {
+		return err
+	}
+	rs.routes = routes
+	if rs.held != nil {
+		rs.next = mux
+		return nil
+	}
+	rs.router.Store(mux)
+	return nil
+}
+
+// Hold holds back route changes until Release, so that the routes of a
+// deployment go live together with the configuration they belong to
+func (rs *RouterService) Hold() {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	rs.held = rs.routes
+}
+
+// Release ends Hold. With apply, the routes changed since Hold are served,
+// otherwise they are discarded.
+func (rs *RouterService) Release(apply bool) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if rs.held == nil {
+		return
+	}
+	if !apply {
+		rs.routes = rs.held
+	} else if rs.next != nil {
+		rs.router.Store(rs.next)
+	}
+	rs.held, rs.next = nil, nil
+}
+
+// buildMux registers the routes in key order, turning the panic that
+// http.ServeMux raises for conflicting patterns into an error
+func buildMux(routes map[string]routeRegistration) (mux *http.ServeMux, err error) {
+	keys := make([]string, 0, len(routes))
+	for key := range routes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	mux = http.NewServeMux()
+	var current string
+	defer func() {
+		if r := recover(); r != nil {
+			mux, err = nil, fmt.Errorf("conflicting routes for %s: %v", current, r)
+		}
+	}()
+	for _, key := range keys {
+		current = key
+		routes[key](mux)
+	}
+	return mux, nil
+}
+
 func (rs *RouterService) UpdateLogger() {
 	rs.logger = loggerfactory.GetLogger(componentName, rs)
 }
 
+// artifactLogger returns the logger for records about an API or proxy
+// service, named api.<name> or proxy.<name>, which also go to its own log
+// file when that is configured
+func (rs *RouterService) artifactLogger(kind string, name string) *slog.Logger {
+	return loggerfactory.GetArtifactLogger(kind, kind, name, nil)
+}
+
 // RegisterAPI registers a new API with the router service
 func (rs *RouterService) RegisterAPI(ctx context.Context, api artifacts.API) error {
 	// Determine base path based on context and version
@@ -103,7 +211,7 @@ func (rs *RouterService) RegisterAPI(ctx context.Context, api artifacts.API) err
 		swaggerBasePath = swaggerBasePath + ":" + api.Version
 	}
 
-	rs.router.HandleFunc(swaggerBasePath, func(w http.ResponseWriter, r *http.Request) {
+	swaggerHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Get the query parameters from the URL
 		query := r.URL.Query()
 			
@@ -130,42 +238,72 @@ func (rs *RouterService) RegisterAPI(ctx context.Context, api artifacts.API) err
 			// Construct the full pattern: "METHOD /path/to/resource"
 			pattern := method + " " + resource.URITemplate.PathTemplate
 			// Create a wrapper handler that checks query parameters before forwarding to the resource handler
-			queryParamHandler := rs.createQueryParamMiddleware(resource, rs.createResourceHandler(resource, ctx))
-			apiHandler.HandleFunc(pattern, queryParamHandler)
-			rs.logger.Info("Registered route for API",
-				slog.String("api_name", api.Name),
+			queryParamHandler := rs.createQueryParamMiddleware(resource, rs.createResourceHandler(api.Name, resource, ctx))
+			resourceName := api.Name + ":" + method + ":" + resource.URITemplate.FullTemplate
+			apiHandler.Handle(pattern, RateLimitMiddleware(queryParamHandler, resourceName, resource.RateLimit, throttle.DefaultStore()))
+			rs.artifactLogger("api", api.Name).Info("Registered route for API",
 				slog.String("pattern", pattern))
 			// No need to register explicit OPTIONS handlers when using rs/cors package
 			// The CORSMiddleware already handles OPTIONS preflight requests automatically
 		}
 	}
 
-	// Apply CORS middleware to the entire API subrouter if enabled
-	var handler http.Handler = apiHandler
+	// Apply the API level rate limit, then CORS so that rejected requests still carry CORS headers
+	var handler http.Handler = RateLimitMiddleware(apiHandler, api.Name, api.RateLimit, throttle.DefaultStore())
 	if api.CORSConfig.Enabled {
 		handler = CORSMiddleware(handler, api.CORSConfig)
 	}
 
-	// Register the API handler with the main router
-	rs.router.Handle(basePath+"/", http.StripPrefix(basePath, apiHandler))
-	return nil
+	// Register the API handler with the main router, replacing an earlier
+	// version of the API
+	return rs.setRoutes("api:"+api.Name, func(mux *http.ServeMux) {
+		mux.Handle(swaggerBasePath, swaggerHandler)
+		mux.Handle(basePath+"/", http.StripPrefix(basePath, handler))
+	})
+}
+
+// UnregisterAPI removes the routes of the named API
+func (rs *RouterService) UnregisterAPI(name string) error {
+	return rs.setRoutes("api:"+name, nil)
 }
 
 // createHandlerFunc creates an HTTP handler function for the given API resource
-func (rs *RouterService) createResourceHandler(resource artifacts.Resource, ctx context.Context) http.HandlerFunc {
+func (rs *RouterService) createResourceHandler(apiName string, resource artifacts.Resource, ctx context.Context) http.HandlerFunc {
 	handler := func(w http.ResponseWriter, r *http.Request) {
 		// Create message context
 		msgContext := synctx.CreateMsgContext()
+		defer msgContext.Done()
+		msgContext.Properties["ARTIFACT_NAME"] = apiName
+		msgContext.Logger = rs.artifactLogger("api", apiName)
+		msgContext.SetCorrelationID(r.Header.Get(artifacts.CorrelationHeader(ctx)))
+		mediationCtx, span := tracing.StartServerSpan(artifacts.WithSnapshot(ctx), r, "api "+apiName,
+			append(tracing.MessageAttributes(msgContext.MessageID, msgContext.CorrelationID()), semconv.HTTPRoute(resource.URITemplate.FullTemplate))...)
+		defer span.End()
+		defer metrics.TrackInFlight(metrics.KindAPI, apiName)()
+		start := time.Now()
+		status := http.StatusOK
+		defer func() {
+			tracing.SetStatusCode(span, status)
+			metrics.ObserveAPIRequest(apiName, resource.URITemplate.FullTemplate, r.Method, status, time.Since(start))
+		}()
+		// Records logged during mediation carry the IDs of the message and
+		// the version of the configuration it is mediated with
+		mediationCtx = loggerfactory.ContextWithAttrs(mediationCtx, messageLogAttrs(mediationCtx, msgContext)...)
 
 		bodyBytes, err := io.ReadAll(r.Body)
 		if err != nil {
+		  rs.artifactLogger("api", apiName).WarnContext(mediationCtx, "Error reading request body", "error", err.Error())
 		  http.Error(w, "Error reading request body", http.StatusBadRequest)
+		  status = http.StatusBadRequest
 		  return
 		}
 		r.Body.Close() // Properly close the body
 		msgContext.Message.RawPayload = bodyBytes
 
 		msgContext.Message.ContentType = r.Header.Get("Content-Type")
+		msgContext.Message.SOAPAction = synctx.ParseSOAPAction(r.Header.Get("SOAPAction"), msgContext.Message.ContentType)
+
+		setTransportProperties(msgContext, r)
 
 		// Set path parameters into message context properties
 		pathParamsMap := make(map[string]string)
@@ -195,24 +333,163 @@ func (rs *RouterService) createResourceHandler(resource artifacts.Resource, ctx
 			msgContext.Properties["queryParams"] = queryVarMap
 		}
 
-		// Process through mediation pipeline
-		success := resource.Mediate(msgContext, ctx)
+		// Process through mediation pipeline against the current configuration
+		success := resource.Mediate(msgContext, mediationCtx)
 
 		// Write response
 		if success {
-			for name, value := range msgContext.Headers {
-				w.Header().Set(name, value)
-			}
-			if msgContext.Message.RawPayload != nil {
-				w.Write(msgContext.Message.RawPayload)
-			}
+			writeResponse(w, msgContext)
+			status = responseStatus(msgContext)
 		} else {
+			rs.artifactLogger("api", apiName).ErrorContext(mediationCtx, "Mediation failed",
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path))
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			status = http.StatusInternalServerError
 		}
 	}
 	return handler
 }
 
+// setTransportProperties sets the caller address and request headers into message context properties
+// messageLogAttrs returns the attributes of the records logged about a
+// message: its IDs and the version of the snapshot pinned to ctx
+func messageLogAttrs(ctx context.Context, msgContext *synctx.MsgContext) []slog.Attr {
+	attrs := msgContext.LogAttrs()
+	if configContext, ok := artifacts.ConfigFromContext(ctx); ok {
+		attrs = append(attrs, slog.Uint64("config_version", configContext.Version))
+	}
+	return attrs
+}
+
+func setTransportProperties(msgContext *synctx.MsgContext, r *http.Request) {
+	msgContext.Properties["remoteAddr"] = r.RemoteAddr
+	transportHeaders := make(map[string]string)
+	for name := range r.Header {
+		transportHeaders[name] = r.Header.Get(name)
+	}
+	msgContext.Properties["transportHeaders"] = transportHeaders
+}
+
+// RegisterProxy exposes a proxy service at /services/{name}. Requests to
+// sub-paths are accepted too, so that REST clients can address the proxy.
+func (rs *RouterService) RegisterProxy(ctx context.Context, proxy artifacts.ProxyService) error {
+	basePath := "/services/" + proxy.Name
+	handler := rs.createProxyHandler(proxy, basePath, ctx)
+	err := rs.setRoutes("proxy:"+proxy.Name, func(mux *http.ServeMux) {
+		mux.Handle(basePath, handler)
+		mux.Handle(basePath+"/", handler)
+	})
+	if err != nil {
+		return err
+	}
+	rs.artifactLogger("proxy", proxy.Name).Info("Registered proxy service",
+		slog.String("path", basePath))
+	return nil
+}
+
+// UnregisterProxy removes the routes of the named proxy service
+func (rs *RouterService) UnregisterProxy(name string) error {
+	return rs.setRoutes("proxy:"+name, nil)
+}
+
+// createProxyHandler creates an HTTP handler that serves the published WSDL and mediates SOAP and REST/POX requests
+func (rs *RouterService) createProxyHandler(proxy artifacts.ProxyService, basePath string, ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if r.Method == http.MethodGet && query.Has("wsdl") {
+			if proxy.WSDL == nil || proxy.WSDL.Content == "" {
+				http.NotFound(w, r)
+				return
+			}
+			w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+			w.Write([]byte(proxy.WSDL.Content))
+			return
+		}
+
+		msgContext := synctx.CreateMsgContext()
+		defer msgContext.Done()
+		msgContext.Properties["ARTIFACT_NAME"] = proxy.Name
+		msgContext.Logger = rs.artifactLogger("proxy", proxy.Name)
+		msgContext.SetCorrelationID(r.Header.Get(artifacts.CorrelationHeader(ctx)))
+		mediationCtx, span := tracing.StartServerSpan(artifacts.WithSnapshot(ctx), r, "proxy "+proxy.Name,
+			tracing.MessageAttributes(msgContext.MessageID, msgContext.CorrelationID())...)
+		defer span.End()
+		defer metrics.TrackInFlight(metrics.KindProxy, proxy.Name)()
+		start := time.Now()
+		status := http.StatusOK
+		defer func() {
+			tracing.SetStatusCode(span, status)
+			metrics.ObserveProxyRequest(proxy.Name, r.Method, status, time.Since(start))
+		}()
+		mediationCtx = loggerfactory.ContextWithAttrs(mediationCtx, messageLogAttrs(mediationCtx, msgContext)...)
+		bodyBytes, err := io.ReadAll(r.Body)
+		if err != nil {
+			rs.artifactLogger("proxy", proxy.Name).WarnContext(mediationCtx, "Error reading request body", "error", err.Error())
+			http.Error(w, "Error reading request body", http.StatusBadRequest)
+			status = http.StatusBadRequest
+			return
+		}
+		r.Body.Close()
+		msgContext.Message.RawPayload = bodyBytes
+		msgContext.Message.ContentType = r.Header.Get("Content-Type")
+		msgContext.Message.SOAPAction = synctx.ParseSOAPAction(r.Header.Get("SOAPAction"), msgContext.Message.ContentType)
+
+		setTransportProperties(msgContext, r)
+		msgContext.Properties["HTTP_METHOD"] = r.Method
+		restURLPostfix := strings.TrimPrefix(r.URL.Path, basePath)
+		if r.URL.RawQuery != "" {
+			restURLPostfix += "?" + r.URL.RawQuery
+		}
+		msgContext.Properties["REST_URL_POSTFIX"] = restURLPostfix
+		queryParams := make(map[string]string)
+		for name := range query {
+			queryParams[name] = query.Get(name)
+		}
+		msgContext.Properties["queryParams"] = queryParams
+
+		if !proxy.Mediate(msgContext, mediationCtx) {
+			rs.artifactLogger("proxy", proxy.Name).ErrorContext(mediationCtx, "Mediation failed",
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path))
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			status = http.StatusInternalServerError
+			return
+		}
+
+		writeResponse(w, msgContext)
+		status = responseStatus(msgContext)
+	}
+}
+
+// writeResponse writes the mediated message to the client, with the status
+// code from the HTTP_SC property when it is set
+func writeResponse(w http.ResponseWriter, msgContext *synctx.MsgContext) {
+	for name, value := range msgContext.Headers {
+		// http-response is an internal flag set by the respond mediator
+		if name == "http-response" {
+			continue
+		}
+		w.Header().Set(name, value)
+	}
+	if msgContext.Message.ContentType != "" {
+		w.Header().Set("Content-Type", msgContext.Message.ContentType)
+	}
+	w.WriteHeader(responseStatus(msgContext))
+	if msgContext.Message.RawPayload != nil {
+		w.Write(msgContext.Message.RawPayload)
+	}
+}
+
+// responseStatus returns the status code of the response to the client, which
+// is the HTTP_SC property when it is set
+func responseStatus(msgContext *synctx.MsgContext) int {
+	if statusCode, ok := msgContext.Properties["HTTP_SC"].(int); ok && statusCode > 0 {
+		return statusCode
+	}
+	return http.StatusOK
+}
+
 // createQueryParamMiddleware creates a middleware that validates query parameters against predefined parameters
 func (rs *RouterService) createQueryParamMiddleware(resource artifacts.Resource, next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -255,12 +532,14 @@ func (rs *RouterService) StartServer(ctx context.Context) {
 	addr := rs.hostname + rs.port
 	rs.server = &http.Server{
 		Addr:    addr,
-		Handler: rs.router,
+		Handler: AccessLogMiddleware(rs, ctx),
 	}
 
 	// Register health/liveness endpoints
 	rs.registerLivelinessEndpoint()
 	rs.logger.Info("liveness endpoint registered")
+	rs.registerHealthEndpoints(ctx)
+	rs.registerMetricsEndpoint()
 
 	// Start the server in a goroutine
 	go func() {
@@ -287,7 +566,7 @@ func (rs *RouterService) StopServer() {
 // registerHealthEndpoints registers health and liveness endpoints
 func (rs *RouterService) registerLivelinessEndpoint() {
 	// liveliness probe endpoint
-	rs.router.HandleFunc("/livez", func(w http.ResponseWriter, r *http.Request) {
+	livenessHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
 		json.NewEncoder(w).Encode(map[string]interface{}{
@@ -295,4 +574,18 @@ func (rs *RouterService) registerLivelinessEndpoint() {
 			"timestamp": time.Now().Format(time.RFC3339),
 		})
 	})
-}
\ No newline at end of file
+	if err := rs.setRoutes("livez", func(mux *http.ServeMux) {
+		mux.Handle("/livez", livenessHandler)
+	}); err != nil {
+		rs.logger.Error("Error registering liveness endpoint", "error", err.Error())
+	}
+}
+
+// registerMetricsEndpoint serves the metrics in the Prometheus exposition format
+func (rs *RouterService) registerMetricsEndpoint() {
+	if err := rs.setRoutes("metrics", func(mux *http.ServeMux) {
+		mux.Handle("GET /metrics", metrics.Handler())
+	}); err != nil {
+		rs.logger.Error("Error registering metrics endpoint", "error", err.Error())
+	}
+}
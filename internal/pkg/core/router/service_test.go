@@ -0,0 +1,290 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package router
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/apache/synapse-go/internal/pkg/core/artifacts"
+	"github.com/apache/synapse-go/internal/pkg/core/synctx"
+	"github.com/apache/synapse-go/internal/pkg/core/throttle"
+	"github.com/apache/synapse-go/internal/pkg/core/utils"
+	"github.com/apache/synapse-go/internal/pkg/loggerfactory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mediatorFunc adapts a function to the artifacts.Mediator interface
+type mediatorFunc func(msgContext *synctx.MsgContext)
+
+func (f mediatorFunc) Execute(msgContext *synctx.MsgContext, ctx context.Context) (bool, error) {
+	f(msgContext)
+	return true, nil
+}
+
+// attrsMediator records the log attributes of the mediation context
+type attrsMediator struct {
+	attrs chan []slog.Attr
+}
+
+func (m attrsMediator) Execute(msgContext *synctx.MsgContext, ctx context.Context) (bool, error) {
+	m.attrs <- loggerfactory.AttrsFromContext(ctx)
+	return true, nil
+}
+
+func TestRegisterAPI_LogAttrs(t *testing.T) {
+	store := artifacts.NewConfigStore()
+	_, err := store.Update(func(next *artifacts.ConfigContext) {})
+	require.NoError(t, err)
+	ctx := context.WithValue(context.Background(), utils.ConfigContextKey, store)
+
+	mediator := attrsMediator{attrs: make(chan []slog.Attr, 2)}
+	sequence := artifacts.Sequence{MediatorList: []artifacts.Mediator{mediator}}
+	rs := NewRouterService(":0", "localhost")
+	require.NoError(t, rs.RegisterAPI(ctx, artifacts.API{
+		Name:    "OrdersAPI",
+		Context: "/orders",
+		Resources: []artifacts.Resource{{
+			Methods:     []string{http.MethodGet},
+			URITemplate: artifacts.URITemplateInfo{FullTemplate: "/", PathTemplate: "/"},
+			InSequence:  sequence,
+		}},
+	}))
+	require.NoError(t, rs.RegisterProxy(ctx, artifacts.ProxyService{Name: "OrdersProxy", StartOnLoad: true,
+		Target: artifacts.ProxyTarget{InSequence: artifacts.ProxySequence{Inline: &sequence}}}))
+
+	for _, path := range []string{"/orders/", "/services/OrdersProxy"} {
+		serve(rs, path)
+		attrs := map[string]slog.Value{}
+		for _, attr := range <-mediator.attrs {
+			attrs[attr.Key] = attr.Value
+		}
+		assert.Equal(t, uint64(1), attrs["config_version"].Uint64(), path)
+		assert.Contains(t, attrs, "message_id", path)
+	}
+}
+
+func TestRegisterAPI_WritesResponse(t *testing.T) {
+	respond := mediatorFunc(func(msgContext *synctx.MsgContext) {
+		msgContext.Message.RawPayload = []byte(`{"status":"created"}`)
+		msgContext.Message.ContentType = "application/json"
+		msgContext.Headers["X-Order"] = "42"
+		msgContext.Headers["http-response"] = "true"
+		msgContext.Properties["HTTP_SC"] = http.StatusCreated
+	})
+	api := artifacts.API{
+		Name:    "OrdersAPI",
+		Context: "/orders",
+		Resources: []artifacts.Resource{{
+			Methods:     []string{http.MethodPost},
+			URITemplate: artifacts.URITemplateInfo{FullTemplate: "/", PathTemplate: "/"},
+			InSequence:  artifacts.Sequence{MediatorList: []artifacts.Mediator{respond}},
+		}},
+	}
+	rs := NewRouterService(":0", "localhost")
+	require.NoError(t, rs.RegisterAPI(context.Background(), api))
+
+	w := httptest.NewRecorder()
+	rs.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/orders/", nil))
+
+	// API responses carry the status, content type and headers set during
+	// mediation, like proxy service responses
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.Equal(t, "42", w.Header().Get("X-Order"))
+	assert.Empty(t, w.Header().Get("http-response"))
+	assert.Equal(t, `{"status":"created"}`, w.Body.String())
+}
+
+func TestRegisterAPI_DefaultsToOK(t *testing.T) {
+	api := artifacts.API{
+		Name:    "PingAPI",
+		Context: "/ping",
+		Resources: []artifacts.Resource{{
+			Methods:     []string{http.MethodGet},
+			URITemplate: artifacts.URITemplateInfo{FullTemplate: "/", PathTemplate: "/"},
+		}},
+	}
+	rs := NewRouterService(":0", "localhost")
+	require.NoError(t, rs.RegisterAPI(context.Background(), api))
+
+	w := httptest.NewRecorder()
+	rs.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ping/", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Body.String())
+}
+
+// handle returns a registration serving pattern with the given status
+func handle(pattern string, status int) routeRegistration {
+	return func(mux *http.ServeMux) {
+		mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+		})
+	}
+}
+
+func serve(rs *RouterService, path string) int {
+	w := httptest.NewRecorder()
+	rs.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w.Code
+}
+
+func TestSetRoutes(t *testing.T) {
+	rs := NewRouterService(":0", "localhost")
+	require.NoError(t, rs.setRoutes("api:Orders", handle("/orders/", http.StatusOK)))
+	require.NoError(t, rs.setRoutes("api:Stock", handle("/stock/", http.StatusAccepted)))
+	assert.Equal(t, http.StatusOK, serve(rs, "/orders/1"))
+	assert.Equal(t, http.StatusAccepted, serve(rs, "/stock/1"))
+
+	// Replacing the routes of a key drops its old patterns
+	require.NoError(t, rs.setRoutes("api:Orders", handle("/v2/orders/", http.StatusOK)))
+	assert.Equal(t, http.StatusNotFound, serve(rs, "/orders/1"))
+	assert.Equal(t, http.StatusOK, serve(rs, "/v2/orders/1"))
+
+	// Conflicting routes are rejected and the current mux keeps serving
+	err := rs.setRoutes("api:Other", handle("/stock/", http.StatusTeapot))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `conflicting routes for api:Stock: pattern "/stock/"`)
+	assert.Equal(t, http.StatusAccepted, serve(rs, "/stock/1"))
+	assert.NotContains(t, rs.routes, "api:Other")
+
+	require.NoError(t, rs.setRoutes("api:Stock", nil))
+	assert.Equal(t, http.StatusNotFound, serve(rs, "/stock/1"))
+	assert.Equal(t, http.StatusOK, serve(rs, "/v2/orders/1"))
+}
+
+func TestBuildMux(t *testing.T) {
+	mux, err := buildMux(map[string]routeRegistration{
+		"api:Orders": handle("/orders/", http.StatusOK),
+		"proxy:Quotes": func(mux *http.ServeMux) {
+			mux.HandleFunc("/services/Quotes", func(w http.ResponseWriter, r *http.Request) {})
+			mux.HandleFunc("/services/Quotes/", func(w http.ResponseWriter, r *http.Request) {})
+		},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, mux)
+
+	// Keys register in order, so the later key is reported for a conflict
+	_, err = buildMux(map[string]routeRegistration{
+		"api:B": handle("/orders/", http.StatusOK),
+		"api:A": handle("/orders/", http.StatusOK),
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "conflicting routes for api:B")
+}
+
+func TestHoldAndRelease(t *testing.T) {
+	rs := NewRouterService(":0", "localhost")
+	require.NoError(t, rs.setRoutes("api:Orders", handle("/orders/", http.StatusOK)))
+
+	rs.Hold()
+	require.NoError(t, rs.setRoutes("api:Stock", handle("/stock/", http.StatusOK)))
+	require.Error(t, rs.setRoutes("api:Other", handle("/stock/", http.StatusOK)), "held routes are checked for conflicts")
+	assert.Equal(t, http.StatusNotFound, serve(rs, "/stock/1"))
+	rs.Release(true)
+	assert.Equal(t, http.StatusOK, serve(rs, "/stock/1"))
+
+	rs.Hold()
+	require.NoError(t, rs.setRoutes("api:Orders", nil))
+	rs.Release(false)
+	assert.Equal(t, http.StatusOK, serve(rs, "/orders/1"))
+	assert.Contains(t, rs.routes, "api:Orders")
+
+	// Discarded routes are not served by the next change either
+	require.NoError(t, rs.setRoutes("api:Quotes", handle("/quotes/", http.StatusOK)))
+	assert.Equal(t, http.StatusOK, serve(rs, "/orders/1"))
+}
+
+func TestTrafficPort_NoManagementPaths(t *testing.T) {
+	ctx := context.Background()
+	rs := NewRouterService(":0", "localhost")
+	rs.registerLivelinessEndpoint()
+	rs.registerHealthEndpoints(ctx)
+	rs.registerMetricsEndpoint()
+	handler := AccessLogMiddleware(rs, ctx)
+
+	// The management API is served on its own port only
+	for _, method := range []string{http.MethodGet, http.MethodPut} {
+		for _, path := range []string{"/management/loggers", "/management/loggers/router", "/management/apis"} {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+			assert.Equal(t, http.StatusNotFound, w.Code, "%s %s", method, path)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+// stopFunc adapts a function to a mediator that ends the flow of the message
+type stopFunc func(msgContext *synctx.MsgContext)
+
+func (f stopFunc) Execute(msgContext *synctx.MsgContext, ctx context.Context) (bool, error) {
+	f(msgContext)
+	return false, nil
+}
+
+func TestRegisterAPI_ThrottleHoldsSlotUntilResponse(t *testing.T) {
+	entered := make(chan struct{})
+	unblock := make(chan struct{})
+	var calls atomic.Int32
+	// The backend call after the throttle mediator blocks until unblocked
+	backend := mediatorFunc(func(msgContext *synctx.MsgContext) {
+		if calls.Add(1) == 1 {
+			close(entered)
+			<-unblock
+		}
+	})
+	tooMany := stopFunc(func(msgContext *synctx.MsgContext) {
+		msgContext.Properties["HTTP_SC"] = http.StatusTooManyRequests
+	})
+	throttleMediator := artifacts.ThrottleMediator{ID: "orders", MaxConcurrent: 1, Store: throttle.NewMemoryStore(),
+		OnReject: &artifacts.Sequence{MediatorList: []artifacts.Mediator{tooMany}}}
+	api := artifacts.API{
+		Name:    "OrdersAPI",
+		Context: "/orders",
+		Resources: []artifacts.Resource{{
+			Methods:     []string{http.MethodGet},
+			URITemplate: artifacts.URITemplateInfo{FullTemplate: "/", PathTemplate: "/"},
+			InSequence:  artifacts.Sequence{MediatorList: []artifacts.Mediator{throttleMediator, backend}},
+		}},
+	}
+	rs := NewRouterService(":0", "localhost")
+	require.NoError(t, rs.RegisterAPI(context.Background(), api))
+
+	first := make(chan int)
+	go func() { first <- serve(rs, "/orders/") }()
+	<-entered
+
+	// The first request holds the only slot while it waits for the backend
+	assert.Equal(t, http.StatusTooManyRequests, serve(rs, "/orders/"))
+	assert.Equal(t, int32(1), calls.Load())
+
+	close(unblock)
+	assert.Equal(t, http.StatusOK, <-first)
+	assert.Equal(t, http.StatusOK, serve(rs, "/orders/"))
+	assert.Equal(t, int32(2), calls.Load())
+}
@@ -0,0 +1,63 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package synctx
+
+import (
+	"regexp"
+)
+
+// funcParamPattern matches $func:name references to template parameters
+var funcParamPattern = regexp.MustCompile(`\$func:([A-Za-z_][A-Za-z0-9_.-]*)`)
+
+// PushFuncParams makes params the parameters of the template being executed
+func (m *MsgContext) PushFuncParams(params map[string]string) {
+	m.FuncStack = append(m.FuncStack, params)
+}
+
+// PopFuncParams restores the parameters of the calling template
+func (m *MsgContext) PopFuncParams() {
+	if len(m.FuncStack) > 0 {
+		m.FuncStack = m.FuncStack[:len(m.FuncStack)-1]
+	}
+}
+
+// FuncParam returns a parameter of the template being executed. Parameters of
+// calling templates are not visible.
+func (m *MsgContext) FuncParam(name string) (string, bool) {
+	if len(m.FuncStack) == 0 {
+		return "", false
+	}
+	value, ok := m.FuncStack[len(m.FuncStack)-1][name]
+	return value, ok
+}
+
+// ResolveFuncParams replaces $func:name references in s with the parameters
+// of the template being executed. Unknown references are left as they are.
+func (m *MsgContext) ResolveFuncParams(s string) string {
+	if len(m.FuncStack) == 0 {
+		return s
+	}
+	return funcParamPattern.ReplaceAllStringFunc(s, func(ref string) string {
+		if value, ok := m.FuncParam(ref[len("$func:"):]); ok {
+			return value
+		}
+		return ref
+	})
+}
@@ -0,0 +1,47 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package synctx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMsgContext_FuncParams(t *testing.T) {
+	msgContext := CreateMsgContext()
+	assert.Equal(t, "$func:name", msgContext.ResolveFuncParams("$func:name"))
+
+	msgContext.PushFuncParams(map[string]string{"name": "outer", "level": "1"})
+	msgContext.PushFuncParams(map[string]string{"name": "inner"})
+	assert.Equal(t, "hello inner at $func:level", msgContext.ResolveFuncParams("hello $func:name at $func:level"))
+
+	_, ok := msgContext.FuncParam("level")
+	assert.False(t, ok, "parameters of calling templates are not visible")
+
+	msgContext.PopFuncParams()
+	value, ok := msgContext.FuncParam("level")
+	assert.True(t, ok)
+	assert.Equal(t, "1", value)
+
+	msgContext.PopFuncParams()
+	msgContext.PopFuncParams()
+	assert.Empty(t, msgContext.FuncStack)
+}
@@ -0,0 +1,398 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package synctx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"mime"
+	"strings"
+)
+
+type SOAPVersion string
+
+// SOAP versions and the pox format of a message
+const (
+	SOAP11 SOAPVersion = "soap11"
+	SOAP12 SOAPVersion = "soap12"
+	POX    SOAPVersion = "pox"
+)
+
+const (
+	SOAP11Namespace   = "http://schemas.xmlsoap.org/soap/envelope/"
+	SOAP12Namespace   = "http://www.w3.org/2003/05/soap-envelope"
+	SOAP11ContentType = "text/xml"
+	SOAP12ContentType = "application/soap+xml"
+	POXContentType    = "application/xml"
+)
+
+var ErrNotSOAPEnvelope = errors.New("payload is not a SOAP envelope")
+
+// Envelope is a parsed SOAP envelope. Header and Body hold the inner XML of
+// the header and body elements.
+type Envelope struct {
+	Version SOAPVersion
+	Header  []byte
+	Body    []byte
+	Fault   *Fault
+	// Prefix is the namespace prefix of the envelope elements. It is empty
+	// when the SOAP namespace is the default namespace of the envelope.
+	Prefix string
+	// Namespaces holds namespace declarations on the envelope element other
+	// than the SOAP one, so that the body can be moved into a new envelope.
+	// A default namespace declaration has an empty Name.Space.
+	Namespaces []xml.Attr
+}
+
+// defaultPrefix is the prefix of envelopes built by Synapse
+const defaultPrefix = "soapenv"
+
+// Fault is a SOAP fault. Code is the local part of the fault code, such as
+// Server or Receiver.
+type Fault struct {
+	Code   string
+	Reason string
+	Role   string
+	Detail string
+}
+
+type envelopeXML struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Header  *innerXML  `xml:"Header"`
+	Body    *innerXML  `xml:"Body"`
+}
+
+type innerXML struct {
+	Content []byte `xml:",innerxml"`
+}
+
+type fault11XML struct {
+	Code   string    `xml:"faultcode"`
+	Reason string    `xml:"faultstring"`
+	Actor  string    `xml:"faultactor"`
+	Detail *innerXML `xml:"detail"`
+}
+
+type fault12XML struct {
+	Code   string    `xml:"Code>Value"`
+	Reason string    `xml:"Reason>Text"`
+	Role   string    `xml:"Role"`
+	Detail *innerXML `xml:"Detail"`
+}
+
+// SOAPVersionOf returns the SOAP version for a content type, or an empty
+// version for content that is not SOAP
+func SOAPVersionOf(contentType string) SOAPVersion {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	switch mediaType {
+	case SOAP11ContentType:
+		return SOAP11
+	case SOAP12ContentType:
+		return SOAP12
+	}
+	return ""
+}
+
+// ContentTypeOf returns the content type used to send a message in the given format
+func ContentTypeOf(version SOAPVersion) string {
+	switch version {
+	case SOAP11:
+		return SOAP11ContentType
+	case SOAP12:
+		return SOAP12ContentType
+	}
+	return POXContentType
+}
+
+// ParseSOAPAction returns the action of a request from the SOAPAction header
+// used by SOAP 1.1, or the action parameter of a SOAP 1.2 content type
+func ParseSOAPAction(soapActionHeader string, contentType string) string {
+	if action := strings.Trim(soapActionHeader, `"`); action != "" {
+		return action
+	}
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	return params["action"]
+}
+
+// SOAPVersion returns the SOAP version of the message, or an empty version
+// when the message is not SOAP
+func (m *Message) SOAPVersion() SOAPVersion {
+	return SOAPVersionOf(m.ContentType)
+}
+
+// Envelope parses the payload of a SOAP message
+func (m *Message) Envelope() (*Envelope, error) {
+	if m.SOAPVersion() == "" {
+		return nil, ErrNotSOAPEnvelope
+	}
+	return ParseEnvelope(m.RawPayload)
+}
+
+// SetEnvelope replaces the payload with the envelope and sets the matching content type
+func (m *Message) SetEnvelope(envelope *Envelope) {
+	m.RawPayload = envelope.Bytes()
+	m.ContentType = ContentTypeOf(envelope.Version)
+}
+
+// ParseEnvelope parses a SOAP 1.1 or 1.2 envelope
+func ParseEnvelope(payload []byte) (*Envelope, error) {
+	var parsed envelopeXML
+	if err := xml.Unmarshal(payload, &parsed); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotSOAPEnvelope, err)
+	}
+	if parsed.XMLName.Local != "Envelope" || parsed.Body == nil {
+		return nil, ErrNotSOAPEnvelope
+	}
+
+	envelope := &Envelope{Body: bytes.TrimSpace(parsed.Body.Content), Prefix: envelopePrefix(payload)}
+	switch parsed.XMLName.Space {
+	case SOAP11Namespace:
+		envelope.Version = SOAP11
+	case SOAP12Namespace:
+		envelope.Version = SOAP12
+	default:
+		return nil, fmt.Errorf("%w: unknown envelope namespace %s", ErrNotSOAPEnvelope, parsed.XMLName.Space)
+	}
+	if parsed.Header != nil {
+		envelope.Header = bytes.TrimSpace(parsed.Header.Content)
+	}
+	for _, attr := range parsed.Attrs {
+		isDeclaration := attr.Name.Space == "xmlns" || (attr.Name.Space == "" && attr.Name.Local == "xmlns")
+		if isDeclaration && attr.Value != SOAP11Namespace && attr.Value != SOAP12Namespace {
+			envelope.Namespaces = append(envelope.Namespaces, attr)
+		}
+	}
+
+	fault, err := parseFault(envelope.Version, payload)
+	if err != nil {
+		return nil, err
+	}
+	envelope.Fault = fault
+	return envelope, nil
+}
+
+// envelopePrefix returns the prefix the envelope element is written with
+func envelopePrefix(payload []byte) string {
+	decoder := xml.NewDecoder(bytes.NewReader(payload))
+	for {
+		token, err := decoder.RawToken()
+		if err != nil {
+			return defaultPrefix
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Space
+		}
+	}
+}
+
+// parseFault returns the fault when the envelope body holds one. The whole
+// envelope is decoded so that prefixes declared on it resolve.
+func parseFault(version SOAPVersion, payload []byte) (*Fault, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(payload))
+	inBody := false
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			// An empty body holds no fault
+			return nil, nil
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if !inBody {
+			inBody = start.Name.Local == "Body" && (start.Name.Space == SOAP11Namespace || start.Name.Space == SOAP12Namespace)
+			continue
+		}
+		if start.Name.Local != "Fault" || (start.Name.Space != SOAP11Namespace && start.Name.Space != SOAP12Namespace) {
+			return nil, nil
+		}
+
+		fault := &Fault{}
+		var detail *innerXML
+		if version == SOAP11 {
+			var parsed fault11XML
+			if err := decoder.DecodeElement(&parsed, &start); err != nil {
+				return nil, fmt.Errorf("invalid SOAP fault: %w", err)
+			}
+			fault.Code, fault.Reason, fault.Role, detail = parsed.Code, parsed.Reason, parsed.Actor, parsed.Detail
+		} else {
+			var parsed fault12XML
+			if err := decoder.DecodeElement(&parsed, &start); err != nil {
+				return nil, fmt.Errorf("invalid SOAP fault: %w", err)
+			}
+			fault.Code, fault.Reason, fault.Role, detail = parsed.Code, parsed.Reason, parsed.Role, parsed.Detail
+		}
+		fault.Code = strings.TrimSpace(fault.Code)
+		if i := strings.LastIndex(fault.Code, ":"); i >= 0 {
+			fault.Code = fault.Code[i+1:]
+		}
+		fault.Reason = strings.TrimSpace(fault.Reason)
+		fault.Role = strings.TrimSpace(fault.Role)
+		if detail != nil {
+			fault.Detail = strings.TrimSpace(string(detail.Content))
+		}
+		return fault, nil
+	}
+}
+
+// NewEnvelope wraps a body in an envelope of the given version
+func NewEnvelope(version SOAPVersion, body []byte) *Envelope {
+	return &Envelope{Version: version, Body: bytes.TrimSpace(body), Prefix: defaultPrefix}
+}
+
+// NewFaultEnvelope builds an envelope holding a fault
+func NewFaultEnvelope(version SOAPVersion, fault Fault) *Envelope {
+	envelope := &Envelope{Version: version, Fault: &fault, Prefix: defaultPrefix}
+	envelope.Body = fault.bytes(version)
+	return envelope
+}
+
+// Bytes serialises the envelope with its prefix and namespace declarations
+func (e *Envelope) Bytes() []byte {
+	namespace := SOAP11Namespace
+	if e.Version == SOAP12 {
+		namespace = SOAP12Namespace
+	}
+	soapDeclaration := xml.Attr{Name: xml.Name{Space: "xmlns", Local: e.Prefix}, Value: namespace}
+	element := func(local string) string {
+		return e.Prefix + ":" + local
+	}
+	if e.Prefix == "" {
+		soapDeclaration.Name = xml.Name{Local: "xmlns"}
+		element = func(local string) string {
+			return local
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("<" + element("Envelope"))
+	for _, attr := range append([]xml.Attr{soapDeclaration}, e.Namespaces...) {
+		name := namespaceDeclaration(attr)
+		if attr != soapDeclaration && name == namespaceDeclaration(soapDeclaration) {
+			continue
+		}
+		buf.WriteString(" " + name + `="`)
+		xml.EscapeText(&buf, []byte(attr.Value))
+		buf.WriteString(`"`)
+	}
+	buf.WriteString(">")
+	if len(e.Header) > 0 {
+		buf.WriteString("<" + element("Header") + ">")
+		buf.Write(e.Header)
+		buf.WriteString("</" + element("Header") + ">")
+	}
+	buf.WriteString("<" + element("Body") + ">")
+	buf.Write(e.Body)
+	buf.WriteString("</" + element("Body") + "></" + element("Envelope") + ">")
+	return buf.Bytes()
+}
+
+// namespaceDeclaration returns the attribute name of a namespace declaration
+func namespaceDeclaration(attr xml.Attr) string {
+	if attr.Name.Space == "" {
+		return "xmlns"
+	}
+	return "xmlns:" + attr.Name.Local
+}
+
+// PayloadBytes returns the body content as a standalone XML document, with
+// the namespace declarations of the envelope carried over to it
+func (e *Envelope) PayloadBytes() []byte {
+	if len(e.Namespaces) == 0 {
+		return e.Body
+	}
+	start := bytes.IndexByte(e.Body, '<')
+	if start < 0 {
+		return e.Body
+	}
+	end := bytes.IndexAny(e.Body[start:], " \t\r\n/>")
+	if end < 0 {
+		return e.Body
+	}
+	end += start
+
+	var declarations bytes.Buffer
+	for _, attr := range e.Namespaces {
+		declaration := namespaceDeclaration(attr) + "="
+		if bytes.Contains(e.Body, []byte(declaration)) {
+			continue
+		}
+		declarations.WriteString(" " + declaration + `"`)
+		xml.EscapeText(&declarations, []byte(attr.Value))
+		declarations.WriteString(`"`)
+	}
+
+	payload := make([]byte, 0, len(e.Body)+declarations.Len())
+	payload = append(payload, e.Body[:end]...)
+	payload = append(payload, declarations.Bytes()...)
+	return append(payload, e.Body[end:]...)
+}
+
+// faultCodes maps fault codes onto their equivalent in the other SOAP version
+var faultCodes = map[SOAPVersion]map[string]string{
+	SOAP11: {"Sender": "Client", "Receiver": "Server"},
+	SOAP12: {"Client": "Sender", "Server": "Receiver"},
+}
+
+func (f Fault) bytes(version SOAPVersion) []byte {
+	code := f.Code
+	if mapped, ok := faultCodes[version][code]; ok {
+		code = mapped
+	}
+
+	var buf bytes.Buffer
+	write := func(open string, value string, close string) {
+		buf.WriteString(open)
+		xml.EscapeText(&buf, []byte(value))
+		buf.WriteString(close)
+	}
+	buf.WriteString("<soapenv:Fault>")
+	if version == SOAP12 {
+		write("<soapenv:Code><soapenv:Value>soapenv:", code, "</soapenv:Value></soapenv:Code>")
+		write(`<soapenv:Reason><soapenv:Text xml:lang="en">`, f.Reason, "</soapenv:Text></soapenv:Reason>")
+		if f.Role != "" {
+			write("<soapenv:Role>", f.Role, "</soapenv:Role>")
+		}
+		if f.Detail != "" {
+			buf.WriteString("<soapenv:Detail>" + f.Detail + "</soapenv:Detail>")
+		}
+	} else {
+		write("<faultcode>soapenv:", code, "</faultcode>")
+		write("<faultstring>", f.Reason, "</faultstring>")
+		if f.Role != "" {
+			write("<faultactor>", f.Role, "</faultactor>")
+		}
+		if f.Detail != "" {
+			buf.WriteString("<detail>" + f.Detail + "</detail>")
+		}
+	}
+	buf.WriteString("</soapenv:Fault>")
+	return buf.Bytes()
+}
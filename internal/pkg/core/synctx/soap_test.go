@@ -0,0 +1,143 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package synctx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const soap11Request = `<soapenv:Envelope xmlns:soapenv="http://schemas.xmlsoap.org/soap/envelope/" xmlns:ser="http://services.samples">
+	<soapenv:Header><ser:token>abc</ser:token></soapenv:Header>
+	<soapenv:Body><ser:getQuote><ser:symbol>IBM</ser:symbol></ser:getQuote></soapenv:Body>
+</soapenv:Envelope>`
+
+func TestParseEnvelope_SOAP11(t *testing.T) {
+	envelope, err := ParseEnvelope([]byte(soap11Request))
+	require.NoError(t, err)
+	assert.Equal(t, SOAP11, envelope.Version)
+	assert.Equal(t, "<ser:token>abc</ser:token>", string(envelope.Header))
+	assert.Equal(t, "<ser:getQuote><ser:symbol>IBM</ser:symbol></ser:getQuote>", string(envelope.Body))
+	assert.Nil(t, envelope.Fault)
+	require.Len(t, envelope.Namespaces, 1)
+	assert.Equal(t, "ser", envelope.Namespaces[0].Name.Local)
+
+	// The body can be extracted as a standalone document
+	assert.Equal(t, `<ser:getQuote xmlns:ser="http://services.samples"><ser:symbol>IBM</ser:symbol></ser:getQuote>`, string(envelope.PayloadBytes()))
+
+	// Rebuilding keeps the header, body and namespace declarations
+	rebuilt, err := ParseEnvelope(envelope.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, envelope.Body, rebuilt.Body)
+	assert.Equal(t, envelope.Header, rebuilt.Header)
+}
+
+func TestEnvelopeBytes_KeepsPrefixAndDefaultNamespace(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+	}{
+		{
+			name:    "custom prefix",
+			payload: `<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/" xmlns="urn:quotes"><s:Header><s:token s:mustUnderstand="1">abc</s:token></s:Header><s:Body><getQuote><symbol>IBM</symbol></getQuote></s:Body></s:Envelope>`,
+		},
+		{
+			name:    "default SOAP namespace",
+			payload: `<Envelope xmlns="http://www.w3.org/2003/05/soap-envelope" xmlns:ser="http://services.samples"><Body><ser:getQuote><ser:symbol>IBM</ser:symbol></ser:getQuote></Body></Envelope>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			envelope, err := ParseEnvelope([]byte(tt.payload))
+			require.NoError(t, err)
+			assert.Equal(t, tt.payload, string(envelope.Bytes()))
+		})
+	}
+}
+
+func TestEnvelopePayloadBytes_DefaultNamespace(t *testing.T) {
+	envelope, err := ParseEnvelope([]byte(`<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/" xmlns="urn:quotes"><s:Body><getQuote/></s:Body></s:Envelope>`))
+	require.NoError(t, err)
+	assert.Equal(t, "s", envelope.Prefix)
+	assert.Equal(t, `<getQuote xmlns="urn:quotes"/>`, string(envelope.PayloadBytes()))
+}
+
+func TestParseEnvelope_Faults(t *testing.T) {
+	soap11Fault := `<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body><s:Fault>
+		<faultcode>s:Client</faultcode><faultstring>Invalid symbol</faultstring><faultactor>urn:quotes</faultactor>
+		<detail><code>42</code></detail>
+	</s:Fault></s:Body></s:Envelope>`
+	envelope, err := ParseEnvelope([]byte(soap11Fault))
+	require.NoError(t, err)
+	require.NotNil(t, envelope.Fault)
+	assert.Equal(t, Fault{Code: "Client", Reason: "Invalid symbol", Role: "urn:quotes", Detail: "<code>42</code>"}, *envelope.Fault)
+
+	soap12Fault := `<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope"><env:Body><env:Fault>
+		<env:Code><env:Value>env:Receiver</env:Value></env:Code>
+		<env:Reason><env:Text xml:lang="en">Backend unavailable</env:Text></env:Reason>
+	</env:Fault></env:Body></env:Envelope>`
+	envelope, err = ParseEnvelope([]byte(soap12Fault))
+	require.NoError(t, err)
+	assert.Equal(t, SOAP12, envelope.Version)
+	require.NotNil(t, envelope.Fault)
+	assert.Equal(t, "Receiver", envelope.Fault.Code)
+	assert.Equal(t, "Backend unavailable", envelope.Fault.Reason)
+}
+
+func TestNewFaultEnvelope(t *testing.T) {
+	fault := Fault{Code: "Sender", Reason: "Bad <input>", Detail: "<field>symbol</field>"}
+
+	for _, version := range []SOAPVersion{SOAP11, SOAP12} {
+		envelope, err := ParseEnvelope(NewFaultEnvelope(version, fault).Bytes())
+		require.NoError(t, err)
+		assert.Equal(t, version, envelope.Version)
+		require.NotNil(t, envelope.Fault)
+		assert.Equal(t, "Bad <input>", envelope.Fault.Reason)
+		assert.Equal(t, "<field>symbol</field>", envelope.Fault.Detail)
+	}
+
+	// Fault codes are mapped onto the SOAP 1.1 equivalent
+	envelope, err := ParseEnvelope(NewFaultEnvelope(SOAP11, fault).Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, "Client", envelope.Fault.Code)
+}
+
+func TestParseEnvelope_NotSOAP(t *testing.T) {
+	for _, payload := range []string{`{"json":true}`, `<order/>`, `<Envelope xmlns="urn:other"><Body/></Envelope>`} {
+		_, err := ParseEnvelope([]byte(payload))
+		assert.ErrorIs(t, err, ErrNotSOAPEnvelope, payload)
+	}
+
+	message := Message{RawPayload: []byte(soap11Request), ContentType: "application/json"}
+	_, err := message.Envelope()
+	assert.ErrorIs(t, err, ErrNotSOAPEnvelope)
+}
+
+func TestSOAPVersionAndAction(t *testing.T) {
+	assert.Equal(t, SOAP11, SOAPVersionOf("text/xml; charset=UTF-8"))
+	assert.Equal(t, SOAP12, SOAPVersionOf(`application/soap+xml; action="urn:getQuote"`))
+	assert.Equal(t, SOAPVersion(""), SOAPVersionOf("application/xml"))
+
+	assert.Equal(t, "urn:getQuote", ParseSOAPAction(`"urn:getQuote"`, "text/xml"))
+	assert.Equal(t, "urn:getQuote", ParseSOAPAction("", `application/soap+xml; action="urn:getQuote"`))
+	assert.Equal(t, "", ParseSOAPAction("", "text/xml"))
+}
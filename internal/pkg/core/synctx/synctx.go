@@ -19,21 +19,120 @@
 
 package synctx
 
+import (
+	"crypto/rand"
+	"fmt"
+	"log/slog"
+)
+
+// Properties that hold the identity of a message. MESSAGE_ID is read-only
+// and CORRELATION_ID falls back to the message ID when it is not set.
+const (
+	MessageIDProperty     = "MESSAGE_ID"
+	CorrelationIDProperty = "CORRELATION_ID"
+)
+
 type MsgContext struct {
+	// MessageID identifies the message in logs
+	MessageID  string
 	Properties map[string]interface{}
 	Message    Message
 	Headers    map[string]string
+	// FuncStack holds the parameters of the templates being executed, innermost last
+	FuncStack []map[string]string
+	// Logger logs on behalf of the artifact that received the message, such
+	// as an API or an inbound endpoint. Nil if the artifact did not set one.
+	Logger *slog.Logger
+	// done holds the functions to run when the flow of the message completes
+	done []func()
 }
 
 type Message struct {
 	RawPayload  []byte
 	ContentType string
+	SOAPAction  string
 }
 
 func CreateMsgContext() *MsgContext {
 	return &MsgContext{
+		MessageID:  NewMessageID(),
 		Properties: make(map[string]interface{}),
 		Message:    Message{},
 		Headers:    make(map[string]string),
 	}
-}
\ No newline at end of file
+}
+
+// NewMessageID returns a random urn:uuid message ID
+func NewMessageID() string {
+	return "urn:uuid:" + NewUUID()
+}
+
+// NewUUID returns a random version 4 UUID
+func NewUUID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// CorrelationID returns the ID that ties together the records logged for a
+// request and the calls it makes to backends. It is the ID the client sent
+// along, or the message ID.
+func (m *MsgContext) CorrelationID() string {
+	if id, ok := m.Properties[CorrelationIDProperty].(string); ok && id != "" {
+		return id
+	}
+	return m.MessageID
+}
+
+// SetCorrelationID sets the correlation ID, keeping the current one if id
+// is empty
+func (m *MsgContext) SetCorrelationID(id string) {
+	if id == "" {
+		return
+	}
+	if m.Properties == nil {
+		m.Properties = make(map[string]interface{})
+	}
+	m.Properties[CorrelationIDProperty] = id
+}
+
+// Property returns the value of a property, including MESSAGE_ID and the
+// CORRELATION_ID fallback
+func (m *MsgContext) Property(name string) (interface{}, bool) {
+	switch name {
+	case MessageIDProperty:
+		return m.MessageID, m.MessageID != ""
+	case CorrelationIDProperty:
+		id := m.CorrelationID()
+		return id, id != ""
+	}
+	value, ok := m.Properties[name]
+	return value, ok
+}
+
+// OnDone registers f to run when the flow of the message completes, for
+// resources held until then, such as a throttle concurrency slot
+func (m *MsgContext) OnDone(f func()) {
+	m.done = append(m.done, f)
+}
+
+// Done runs the functions registered with OnDone, the last registered first.
+// The components that receive messages call it once the flow of a message
+// completes.
+func (m *MsgContext) Done() {
+	done := m.done
+	m.done = nil
+	for i := len(done) - 1; i >= 0; i-- {
+		done[i]()
+	}
+}
+
+// LogAttrs returns the attributes that identify the message in log records
+func (m *MsgContext) LogAttrs() []slog.Attr {
+	return []slog.Attr{
+		slog.String("message_id", m.MessageID),
+		slog.String("correlation_id", m.CorrelationID()),
+	}
+}
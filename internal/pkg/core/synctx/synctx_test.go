@@ -0,0 +1,70 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package synctx
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMsgContext_CorrelationID(t *testing.T) {
+	msgContext := CreateMsgContext()
+	assert.Regexp(t, regexp.MustCompile(`^urn:uuid:[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), msgContext.MessageID)
+	assert.NotEqual(t, msgContext.MessageID, CreateMsgContext().MessageID)
+
+	// The correlation ID falls back to the message ID
+	assert.Equal(t, msgContext.MessageID, msgContext.CorrelationID())
+	msgContext.SetCorrelationID("")
+	assert.Equal(t, msgContext.MessageID, msgContext.CorrelationID())
+
+	msgContext.SetCorrelationID("order-42")
+	assert.Equal(t, "order-42", msgContext.CorrelationID())
+
+	value, ok := msgContext.Property(CorrelationIDProperty)
+	assert.True(t, ok)
+	assert.Equal(t, "order-42", value)
+	value, ok = msgContext.Property(MessageIDProperty)
+	assert.True(t, ok)
+	assert.Equal(t, msgContext.MessageID, value)
+	_, ok = msgContext.Property("missing")
+	assert.False(t, ok)
+
+	attrs := msgContext.LogAttrs()
+	assert.Equal(t, "message_id", attrs[0].Key)
+	assert.Equal(t, msgContext.MessageID, attrs[0].Value.String())
+	assert.Equal(t, "correlation_id", attrs[1].Key)
+	assert.Equal(t, "order-42", attrs[1].Value.String())
+}
+
+func TestMsgContext_Done(t *testing.T) {
+	msgContext := CreateMsgContext()
+	var calls []string
+	msgContext.OnDone(func() { calls = append(calls, "first") })
+	msgContext.OnDone(func() { calls = append(calls, "second") })
+
+	msgContext.Done()
+	assert.Equal(t, []string{"second", "first"}, calls)
+
+	// The functions run once
+	msgContext.Done()
+	assert.Len(t, calls, 2)
+}
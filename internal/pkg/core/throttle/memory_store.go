@@ -0,0 +1,160 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package throttle
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often idle counters are removed from a MemoryStore.
+const sweepInterval = time.Minute
+
+type counter struct {
+	windowStart time.Time
+	count       int
+	prevCount   int
+	tokens      float64
+	lastSeen    time.Time
+	window      time.Duration
+}
+
+// MemoryStore is a Store that keeps counters in memory. It is safe for
+// concurrent use, and counters are not shared with other nodes.
+type MemoryStore struct {
+	mu         sync.Mutex
+	counters   map[string]*counter
+	concurrent map[string]int
+	lastSweep  time.Time
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		counters:   make(map[string]*counter),
+		concurrent: make(map[string]int),
+	}
+}
+
+func (s *MemoryStore) Take(key string, policy Policy, now time.Time) Decision {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	c, exists := s.counters[key]
+	if !exists {
+		c = &counter{windowStart: now, tokens: float64(policy.Limit)}
+		s.counters[key] = c
+	}
+	c.lastSeen = now
+	c.window = policy.Window
+
+	switch policy.Algorithm {
+	case SlidingWindow:
+		return c.takeSliding(policy, now)
+	case TokenBucket:
+		return c.takeToken(policy, now)
+	default:
+		return c.takeFixed(policy, now)
+	}
+}
+
+func (c *counter) takeFixed(policy Policy, now time.Time) Decision {
+	if now.Sub(c.windowStart) >= policy.Window {
+		c.windowStart = now
+		c.count = 0
+	}
+	if c.count >= policy.Limit {
+		return Decision{RetryAfter: c.windowStart.Add(policy.Window).Sub(now)}
+	}
+	c.count++
+	return Decision{Allowed: true, Remaining: policy.Limit - c.count}
+}
+
+func (c *counter) takeSliding(policy Policy, now time.Time) Decision {
+	elapsed := now.Sub(c.windowStart)
+	if elapsed >= 2*policy.Window {
+		c.windowStart = now
+		c.prevCount = 0
+		c.count = 0
+		elapsed = 0
+	} else if elapsed >= policy.Window {
+		c.windowStart = c.windowStart.Add(policy.Window)
+		c.prevCount = c.count
+		c.count = 0
+		elapsed -= policy.Window
+	}
+
+	// Weight the previous window by how much of it the sliding window still covers.
+	overlap := 1 - float64(elapsed)/float64(policy.Window)
+	estimate := float64(c.prevCount)*overlap + float64(c.count)
+	if estimate+1 > float64(policy.Limit) {
+		return Decision{RetryAfter: c.windowStart.Add(policy.Window).Sub(now)}
+	}
+	c.count++
+	return Decision{Allowed: true, Remaining: policy.Limit - int(math.Ceil(estimate+1))}
+}
+
+func (c *counter) takeToken(policy Policy, now time.Time) Decision {
+	rate := float64(policy.Limit) / float64(policy.Window)
+	c.tokens = math.Min(float64(policy.Limit), c.tokens+float64(now.Sub(c.windowStart))*rate)
+	c.windowStart = now
+	if c.tokens < 1 {
+		return Decision{RetryAfter: time.Duration(math.Ceil((1 - c.tokens) / rate))}
+	}
+	c.tokens--
+	return Decision{Allowed: true, Remaining: int(c.tokens)}
+}
+
+func (s *MemoryStore) Acquire(key string, limit int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.concurrent[key] >= limit {
+		return false
+	}
+	s.concurrent[key]++
+	return true
+}
+
+func (s *MemoryStore) Release(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.concurrent[key] <= 1 {
+		delete(s.concurrent, key)
+		return
+	}
+	s.concurrent[key]--
+}
+
+// sweep drops counters that have been idle for longer than two windows, so
+// that one-off callers do not accumulate. The caller must hold s.mu.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, c := range s.counters {
+		if now.Sub(c.lastSeen) > 2*c.window {
+			delete(s.counters, key)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package throttle
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore_FixedWindow(t *testing.T) {
+	store := NewMemoryStore()
+	policy := Policy{Algorithm: FixedWindow, Limit: 2, Window: time.Second}
+	now := time.Unix(1000, 0)
+
+	assert.True(t, store.Take("client", policy, now).Allowed)
+	assert.True(t, store.Take("client", policy, now.Add(100*time.Millisecond)).Allowed)
+
+	decision := store.Take("client", policy, now.Add(400*time.Millisecond))
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, 600*time.Millisecond, decision.RetryAfter)
+
+	// Other keys are counted separately
+	assert.True(t, store.Take("other", policy, now).Allowed)
+
+	// A new window resets the count
+	assert.True(t, store.Take("client", policy, now.Add(time.Second)).Allowed)
+}
+
+func TestMemoryStore_SlidingWindow(t *testing.T) {
+	store := NewMemoryStore()
+	policy := Policy{Algorithm: SlidingWindow, Limit: 4, Window: time.Second}
+	now := time.Unix(1000, 0)
+
+	for i := 0; i < 4; i++ {
+		assert.True(t, store.Take("client", policy, now).Allowed)
+	}
+	assert.False(t, store.Take("client", policy, now.Add(500*time.Millisecond)).Allowed)
+
+	// Half way into the next window, half of the previous count still applies
+	assert.True(t, store.Take("client", policy, now.Add(1500*time.Millisecond)).Allowed)
+	assert.True(t, store.Take("client", policy, now.Add(1500*time.Millisecond)).Allowed)
+	assert.False(t, store.Take("client", policy, now.Add(1500*time.Millisecond)).Allowed)
+
+	// After two idle windows everything is forgotten
+	for i := 0; i < 4; i++ {
+		assert.True(t, store.Take("client", policy, now.Add(5*time.Second)).Allowed)
+	}
+}
+
+func TestMemoryStore_TokenBucket(t *testing.T) {
+	store := NewMemoryStore()
+	policy := Policy{Algorithm: TokenBucket, Limit: 2, Window: time.Second}
+	now := time.Unix(1000, 0)
+
+	assert.True(t, store.Take("client", policy, now).Allowed)
+	assert.True(t, store.Take("client", policy, now).Allowed)
+
+	decision := store.Take("client", policy, now)
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, 500*time.Millisecond, decision.RetryAfter)
+
+	// One token is refilled every half second
+	assert.True(t, store.Take("client", policy, now.Add(500*time.Millisecond)).Allowed)
+	assert.False(t, store.Take("client", policy, now.Add(500*time.Millisecond)).Allowed)
+}
+
+func TestMemoryStore_Concurrency(t *testing.T) {
+	store := NewMemoryStore()
+
+	assert.True(t, store.Acquire("client", 2))
+	assert.True(t, store.Acquire("client", 2))
+	assert.False(t, store.Acquire("client", 2))
+
+	store.Release("client")
+	assert.True(t, store.Acquire("client", 2))
+}
+
+func TestPolicy_Validate(t *testing.T) {
+	assert.NoError(t, Policy{Algorithm: TokenBucket, Limit: 1, Window: time.Second}.Validate())
+	assert.Error(t, Policy{Algorithm: FixedWindow, Limit: 0, Window: time.Second}.Validate())
+	assert.Error(t, Policy{Algorithm: FixedWindow, Limit: 1}.Validate())
+	assert.Error(t, Policy{Algorithm: "leaky-bucket", Limit: 1, Window: time.Second}.Validate())
+}
@@ -0,0 +1,111 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+// Package throttle implements the counters behind the throttle mediator and
+// API-level rate limiting.
+//
+// Counters are kept in a Store. MemoryStore keeps them in memory, per node;
+// a distributed implementation can share them across a cluster by satisfying
+// the same interface.
+package throttle
+
+import (
+	"fmt"
+	"time"
+)
+
+// Algorithm selects how requests are counted against a Policy.
+type Algorithm string
+
+const (
+	// FixedWindow counts requests in consecutive, non-overlapping windows.
+	FixedWindow Algorithm = "fixed-window"
+	// SlidingWindow weights the previous window's count by its overlap with
+	// the current sliding window.
+	SlidingWindow Algorithm = "sliding-window"
+	// TokenBucket refills Limit tokens every Window and spends one per request.
+	TokenBucket Algorithm = "token-bucket"
+)
+
+// ParseAlgorithm converts the XML representation of an algorithm. An empty
+// string selects FixedWindow.
+func ParseAlgorithm(s string) (Algorithm, error) {
+	switch Algorithm(s) {
+	case "", FixedWindow:
+		return FixedWindow, nil
+	case SlidingWindow:
+		return SlidingWindow, nil
+	case TokenBucket:
+		return TokenBucket, nil
+	}
+	return "", fmt.Errorf("unknown throttle algorithm: %s", s)
+}
+
+// Policy allows Limit requests per Window using the given Algorithm.
+type Policy struct {
+	Algorithm Algorithm
+	Limit     int
+	Window    time.Duration
+}
+
+// Validate checks that the policy can be enforced.
+func (p Policy) Validate() error {
+	if p.Limit <= 0 {
+		return fmt.Errorf("throttle limit must be positive, got: %d", p.Limit)
+	}
+	if p.Window <= 0 {
+		return fmt.Errorf("throttle window must be positive, got: %s", p.Window)
+	}
+	if _, err := ParseAlgorithm(string(p.Algorithm)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Decision is the outcome of counting one request.
+type Decision struct {
+	Allowed bool
+	// Remaining is the number of requests still allowed in the current window.
+	Remaining int
+	// RetryAfter is how long a rejected caller should wait before retrying.
+	RetryAfter time.Duration
+}
+
+// Store keeps throttle counters.
+type Store interface {
+	// Take counts one request for key under policy and reports whether it is allowed.
+	Take(key string, policy Policy, now time.Time) Decision
+	// Acquire reserves one of limit concurrent slots for key.
+	Acquire(key string, limit int) bool
+	// Release frees a slot previously reserved with Acquire.
+	Release(key string)
+}
+
+var defaultStore Store = NewMemoryStore()
+
+// DefaultStore returns the store used by artifacts that do not configure one.
+func DefaultStore() Store {
+	return defaultStore
+}
+
+// SetDefaultStore replaces the store used by artifacts deployed afterwards,
+// for example with a store shared across a cluster.
+func SetDefaultStore(store Store) {
+	defaultStore = store
+}
@@ -0,0 +1,248 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package health
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Statuses of the readiness report and of checks
+const (
+	StatusReady    = "READY"
+	StatusNotReady = "NOT READY"
+	StatusUp       = "UP"
+	StatusDown     = "DOWN"
+)
+
+const defaultTimeout = 2 * time.Second
+
+// Config is the [health] section of deployment.toml
+type Config struct {
+	// Endpoints names the endpoints whose backends are checked, all of them
+	// when it holds "*"
+	Endpoints []string
+	// Timeout bounds the connection to each backend
+	Timeout time.Duration
+}
+
+// ParseConfig validates the [health] section of deployment.toml. No backend
+// is checked when settings is empty.
+func ParseConfig(settings map[string]string) (Config, error) {
+	config := Config{Timeout: defaultTimeout}
+	for _, name := range strings.Split(settings["endpoints"], ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			config.Endpoints = append(config.Endpoints, name)
+		}
+	}
+	if value := settings["timeout"]; value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			return Config{}, fmt.Errorf("invalid health timeout value: %s, must be a positive duration such as 2s", value)
+		}
+		config.Timeout = timeout
+	}
+	return config, nil
+}
+
+// Checks reports whether the backend of the endpoint is checked
+func (c Config) Checks(name string) bool {
+	for _, endpoint := range c.Endpoints {
+		if endpoint == "*" || endpoint == name {
+			return true
+		}
+	}
+	return false
+}
+
+// EndpointStatus is the outcome of checking the backend of an endpoint
+type EndpointStatus struct {
+	Name    string `json:"name"`
+	Address string `json:"address,omitempty"`
+	Status  string `json:"status"`
+	Error   string `json:"error,omitempty"`
+}
+
+// CheckEndpoints connects to the backends of endpoints, given by name and
+// URL, at the same time and returns their statuses ordered by name. A
+// backend is UP when it accepts a TCP connection within timeout, which
+// checks it without sending a request that it would have to handle.
+func CheckEndpoints(ctx context.Context, endpoints map[string]string, timeout time.Duration) []EndpointStatus {
+	statuses := make([]EndpointStatus, 0, len(endpoints))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, rawURL := range endpoints {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			status := checkEndpoint(ctx, name, rawURL, timeout)
+			mu.Lock()
+			statuses = append(statuses, status)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+func checkEndpoint(ctx context.Context, name string, rawURL string, timeout time.Duration) EndpointStatus {
+	status := EndpointStatus{Name: name, Status: StatusDown}
+	address, err := dialAddress(rawURL)
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	status.Address = address
+	dialer := net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	conn.Close()
+	status.Status = StatusUp
+	return status
+}
+
+// dialAddress returns the host and port of an endpoint URL, with the default
+// port of its scheme when it has none
+func dialAddress(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	if u.Hostname() == "" {
+		return "", fmt.Errorf("endpoint URL has no host: %s", rawURL)
+	}
+	port := u.Port()
+	if port == "" {
+		switch u.Scheme {
+		case "http":
+			port = "80"
+		case "https":
+			port = "443"
+		default:
+			return "", fmt.Errorf("endpoint URL has no port: %s", rawURL)
+		}
+	}
+	return net.JoinHostPort(u.Hostname(), port), nil
+}
+
+// Report is the readiness of the server, as served by /readyz
+type Report struct {
+	Status     string           `json:"status"`
+	Timestamp  string           `json:"timestamp"`
+	Deployment DeploymentStatus `json:"deployment"`
+	Artifacts  []ArtifactStatus `json:"artifacts"`
+	Endpoints  []EndpointStatus `json:"endpoints,omitempty"`
+	checks     map[string]Check
+}
+
+// Check is an aspect of the health of the server
+type Check struct {
+	Status string `json:"status"`
+	// Counts counts the artifacts or endpoints by state
+	Counts map[string]int `json:"counts,omitempty"`
+	Error  string         `json:"error,omitempty"`
+}
+
+// Summary aggregates the checks of the server, as served by /healthz
+type Summary struct {
+	Status    string           `json:"status"`
+	Timestamp string           `json:"timestamp"`
+	Checks    map[string]Check `json:"checks"`
+}
+
+// Readiness reports whether the server is ready to mediate messages: the
+// artifacts were deployed, none of them failed, the inbound endpoints have
+// started and the checked backends are reachable. Suspended artifacts do not
+// make the server unready.
+func (r *Registry) Readiness(endpoints []EndpointStatus) Report {
+	report := Report{
+		Timestamp:  time.Now().Format(time.RFC3339),
+		Deployment: r.Deployment(),
+		Artifacts:  r.Artifacts(),
+		Endpoints:  endpoints,
+	}
+
+	deployment := Check{Status: StatusUp, Error: report.Deployment.Error}
+	if !report.Deployment.Completed {
+		deployment.Status = StatusDown
+	}
+	artifacts := Check{Status: StatusUp, Counts: map[string]int{}}
+	inbounds := Check{Status: StatusUp, Counts: map[string]int{}}
+	for _, artifact := range report.Artifacts {
+		artifacts.Counts[artifact.State]++
+		if artifact.State == StateFailed {
+			artifacts.Status = StatusDown
+		}
+		if artifact.Type == "Inbounds" {
+			inbounds.Counts[artifact.State]++
+			if artifact.State == StateStarting || artifact.State == StateFailed {
+				inbounds.Status = StatusDown
+			}
+		}
+	}
+	report.checks = map[string]Check{
+		"liveness":   {Status: StatusUp},
+		"deployment": deployment,
+		"artifacts":  artifacts,
+		"inbounds":   inbounds,
+	}
+	if len(endpoints) > 0 {
+		backends := Check{Status: StatusUp, Counts: map[string]int{}}
+		for _, endpoint := range endpoints {
+			backends.Counts[endpoint.Status]++
+			if endpoint.Status != StatusUp {
+				backends.Status = StatusDown
+			}
+		}
+		report.checks["endpoints"] = backends
+	}
+
+	report.Status = StatusReady
+	for _, check := range report.checks {
+		if check.Status != StatusUp {
+			report.Status = StatusNotReady
+		}
+	}
+	return report
+}
+
+// Ready reports whether the server is ready
+func (r Report) Ready() bool {
+	return r.Status == StatusReady
+}
+
+// Summary aggregates the report into one check per aspect
+func (r Report) Summary() Summary {
+	status := StatusUp
+	if !r.Ready() {
+		status = StatusDown
+	}
+	return Summary{Status: status, Timestamp: r.Timestamp, Checks: r.checks}
+}
@@ -0,0 +1,150 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+package health
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConfig(t *testing.T) {
+	config, err := ParseConfig(nil)
+	require.NoError(t, err)
+	assert.Empty(t, config.Endpoints)
+	assert.Equal(t, 2*time.Second, config.Timeout)
+
+	config, err = ParseConfig(map[string]string{"endpoints": " StockEP, OrdersEP,", "timeout": "500ms"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"StockEP", "OrdersEP"}, config.Endpoints)
+	assert.Equal(t, 500*time.Millisecond, config.Timeout)
+	assert.True(t, config.Checks("OrdersEP"))
+	assert.False(t, config.Checks("PaymentsEP"))
+
+	config, err = ParseConfig(map[string]string{"endpoints": "*"})
+	require.NoError(t, err)
+	assert.True(t, config.Checks("PaymentsEP"))
+
+	_, err = ParseConfig(map[string]string{"timeout": "0s"})
+	assert.EqualError(t, err, "invalid health timeout value: 0s, must be a positive duration such as 2s")
+}
+
+func TestReadiness(t *testing.T) {
+	registry := NewRegistry()
+	registry.Deployed("APIs/orders.xml", "OrdersAPI")
+	report := registry.Readiness(nil)
+	assert.False(t, report.Ready(), "not ready until the deployment completes")
+
+	registry.DeploymentDone(nil)
+	registry.Deployed("Inbounds/file.xml", "OrdersFile")
+	registry.Starting("Inbounds/file.xml")
+	assert.False(t, registry.Readiness(nil).Ready(), "not ready until inbound endpoints start")
+
+	registry.Started("Inbounds/file.xml")
+	registry.Deployed("Inbounds/http.xml", "OrdersHTTP")
+	registry.Suspended("Inbounds/http.xml")
+	report = registry.Readiness(nil)
+	assert.True(t, report.Ready(), "suspended inbound endpoints do not count")
+	assert.Equal(t, []ArtifactStatus{
+		{Type: "APIs", File: "orders.xml", Name: "OrdersAPI", State: StateDeployed},
+		{Type: "Inbounds", File: "file.xml", Name: "OrdersFile", State: StateDeployed},
+		{Type: "Inbounds", File: "http.xml", Name: "OrdersHTTP", State: StateSuspended},
+	}, report.Artifacts)
+
+	registry.Failed("Sequences/audit.xml", "Error unmarshalling sequence: EOF")
+	report = registry.Readiness(nil)
+	assert.False(t, report.Ready())
+	summary := report.Summary()
+	assert.Equal(t, StatusDown, summary.Status)
+	assert.Equal(t, Check{Status: StatusDown, Counts: map[string]int{StateDeployed: 2, StateSuspended: 1, StateFailed: 1}}, summary.Checks["artifacts"])
+	assert.Equal(t, StatusUp, summary.Checks["inbounds"].Status)
+
+	registry.Remove("Sequences/audit.xml")
+	assert.True(t, registry.Readiness(nil).Ready())
+
+	// A later deployment that fails leaves the previous configuration serving
+	registry.DeploymentDone(errors.New("sequence not found: audit"))
+	report = registry.Readiness(nil)
+	assert.True(t, report.Ready())
+	assert.Equal(t, DeploymentStatus{Completed: true, Error: "sequence not found: audit"}, report.Deployment)
+
+	report = registry.Readiness([]EndpointStatus{{Name: "StockEP", Status: StatusDown, Error: "connection refused"}})
+	assert.False(t, report.Ready())
+	assert.Equal(t, Check{Status: StatusDown, Counts: map[string]int{StatusDown: 1}}, report.Summary().Checks["endpoints"])
+}
+
+func TestKeptAndWatch(t *testing.T) {
+	registry := NewRegistry()
+	registry.DeploymentDone(nil)
+	registry.Deployed("APIs/orders.xml", "OrdersAPI")
+	registry.Failed("APIs/orders.xml", "Error unmarshalling api: EOF")
+	reason := registry.Reason("APIs/orders.xml")
+	registry.Deployed("APIs/orders.xml", "OrdersAPI")
+	registry.Kept("APIs/orders.xml", reason)
+	assert.True(t, registry.Readiness(nil).Ready())
+	assert.Equal(t, "Error unmarshalling api: EOF", registry.Artifacts()[0].Reason)
+
+	active := true
+	registry.Deployed("MessageProcessors/forward.xml", "ForwardProcessor")
+	registry.Watch("MessageProcessors/forward.xml", func() bool { return active })
+	assert.Equal(t, StateDeployed, registry.Artifacts()[1].State)
+	active = false
+	assert.Equal(t, StateSuspended, registry.Artifacts()[1].State)
+}
+
+func TestCheckEndpoints(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closedAddress := closed.Addr().String()
+	closed.Close()
+
+	statuses := CheckEndpoints(context.Background(), map[string]string{
+		"StockEP":  "http://" + listener.Addr().String() + "/stock/{uri.var.symbol}",
+		"OrdersEP": "http://" + closedAddress + "/orders",
+		"FileEP":   "file:///tmp/orders",
+	}, time.Second)
+	require.Len(t, statuses, 3)
+	assert.Equal(t, "FileEP", statuses[0].Name)
+	assert.Equal(t, StatusDown, statuses[0].Status)
+	assert.Equal(t, "endpoint URL has no host: file:///tmp/orders", statuses[0].Error)
+	assert.Equal(t, "OrdersEP", statuses[1].Name)
+	assert.Equal(t, StatusDown, statuses[1].Status)
+	assert.Equal(t, EndpointStatus{Name: "StockEP", Address: listener.Addr().String(), Status: StatusUp}, statuses[2])
+}
+
+func TestDialAddress(t *testing.T) {
+	address, err := dialAddress("https://backend.example.com/api")
+	require.NoError(t, err)
+	assert.Equal(t, "backend.example.com:443", address)
+
+	address, err = dialAddress("http://[::1]:9000/api")
+	require.NoError(t, err)
+	assert.Equal(t, "[::1]:9000", address)
+
+	_, err = dialAddress("jms://orders")
+	assert.EqualError(t, err, "endpoint URL has no port: jms://orders")
+}
@@ -0,0 +1,220 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+// Package health tracks the state of the deployed artifacts and of the
+// backends the server calls, so that the readiness and health endpoints can
+// report whether the server is able to mediate messages.
+package health
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// States of a deployed artifact
+const (
+	// StateDeployed is an artifact that is in the configuration and, for
+	// inbound endpoints, message processors and tasks, running
+	StateDeployed = "deployed"
+	// StateStarting is an inbound endpoint that is not listening or polling yet
+	StateStarting = "starting"
+	// StateFailed is an artifact that failed to deploy or to start
+	StateFailed = "failed"
+	// StateSuspended is an inbound endpoint deployed with suspend="true" or
+	// paused, a message processor that was deactivated or an endpoint that
+	// was deactivated
+	StateSuspended = "suspended"
+)
+
+// ArtifactStatus is the state of an artifact file
+type ArtifactStatus struct {
+	// Type is the artifact folder, such as APIs or Inbounds
+	Type  string `json:"type"`
+	File  string `json:"file"`
+	Name  string `json:"name,omitempty"`
+	State string `json:"state"`
+	// Reason is why the artifact failed or, for a deployed artifact, why its
+	// file could not be redeployed and the previous version was kept
+	Reason string `json:"reason,omitempty"`
+}
+
+// DeploymentStatus is the outcome of the deployments of the server
+type DeploymentStatus struct {
+	// Completed is set once a deployment of all artifacts succeeded
+	Completed bool `json:"completed"`
+	// Error is why the last deployment failed, if it did
+	Error string `json:"error,omitempty"`
+}
+
+type artifact struct {
+	status ArtifactStatus
+	// active reports whether a message processor is active
+	active func() bool
+}
+
+// Registry holds the state of the artifacts, keyed like the deployer keys
+// artifact files, by artifact type and file name, such as APIs/orders.xml
+type Registry struct {
+	mu         sync.RWMutex
+	deployment DeploymentStatus
+	artifacts  map[string]*artifact
+}
+
+var (
+	registry *Registry
+	once     sync.Once
+)
+
+// GetRegistry returns the registry of the server
+func GetRegistry() *Registry {
+	once.Do(func() {
+		registry = NewRegistry()
+	})
+	return registry
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{artifacts: make(map[string]*artifact)}
+}
+
+// DeploymentDone records the outcome of a deployment. Once a deployment
+// succeeded, the server stays deployed when a later one fails, because the
+// previous configuration keeps serving.
+func (r *Registry) DeploymentDone(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err != nil {
+		r.deployment.Error = err.Error()
+		return
+	}
+	r.deployment = DeploymentStatus{Completed: true}
+}
+
+// Deployed records that the artifact of key is deployed
+func (r *Registry) Deployed(key string, name string) {
+	r.set(key, func(a *artifact) {
+		a.status.Name, a.status.State, a.status.Reason = name, StateDeployed, ""
+		a.active = nil
+	})
+}
+
+// Failed records that the artifact of key failed to deploy or to start
+func (r *Registry) Failed(key string, reason string) {
+	r.set(key, func(a *artifact) {
+		a.status.State, a.status.Reason = StateFailed, reason
+		a.active = nil
+	})
+}
+
+// Kept records that the file of key failed to deploy and that the previous
+// version of the artifact was kept
+func (r *Registry) Kept(key string, reason string) {
+	r.set(key, func(a *artifact) {
+		a.status.Reason = reason
+	})
+}
+
+// Suspended records that the artifact of key is deployed but not running
+func (r *Registry) Suspended(key string) {
+	r.set(key, func(a *artifact) {
+		a.status.State = StateSuspended
+	})
+}
+
+// Starting records that the artifact of key is being started
+func (r *Registry) Starting(key string) {
+	r.set(key, func(a *artifact) {
+		a.status.State = StateStarting
+	})
+}
+
+// Started records that the artifact of key, which was starting, has started
+func (r *Registry) Started(key string) {
+	r.set(key, func(a *artifact) {
+		if a.status.State == StateStarting {
+			a.status.State = StateDeployed
+		}
+	})
+}
+
+// Watch reports the artifact of key as suspended whenever active returns false
+func (r *Registry) Watch(key string, active func() bool) {
+	r.set(key, func(a *artifact) {
+		a.active = active
+	})
+}
+
+// Remove forgets the artifact of key once its file is removed
+func (r *Registry) Remove(key string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.artifacts, key)
+}
+
+// Reason returns why the artifact of key failed, if it did
+func (r *Registry) Reason(key string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if a, ok := r.artifacts[key]; ok {
+		return a.status.Reason
+	}
+	return ""
+}
+
+func (r *Registry) set(key string, update func(a *artifact)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	a, ok := r.artifacts[key]
+	if !ok {
+		artifactType, file, _ := strings.Cut(key, "/")
+		a = &artifact{status: ArtifactStatus{Type: artifactType, File: file}}
+		r.artifacts[key] = a
+	}
+	update(a)
+}
+
+// Deployment returns the outcome of the deployments
+func (r *Registry) Deployment() DeploymentStatus {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.deployment
+}
+
+// Artifacts returns the state of every artifact, ordered by type and file
+func (r *Registry) Artifacts() []ArtifactStatus {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	keys := make([]string, 0, len(r.artifacts))
+	for key := range r.artifacts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	statuses := make([]ArtifactStatus, 0, len(keys))
+	for _, key := range keys {
+		a := r.artifacts[key]
+		status := a.status
+		if status.State == StateDeployed && a.active != nil && !a.active() {
+			status.State = StateSuspended
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
@@ -0,0 +1,142 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package loggerfactory
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// SetDefaultLevel sets the level of loggers for which neither their name
+// nor a parent name has a level
+func (cm *ConfigManager) SetDefaultLevel(level string) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cm.defaultLevel = level
+	cm.refreshLevels()
+}
+
+// SetLevel sets the level of the named logger, and of the loggers below it
+// that have no level of their own, until the server restarts. It takes
+// precedence over the level of the same name in the configuration file.
+func (cm *ConfigManager) SetLevel(name string, level string) error {
+	if name == "" {
+		return fmt.Errorf("logger name is empty")
+	}
+	if _, err := ParseLevel(level); err != nil {
+		return err
+	}
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cm.runtimeLevels[name] = strings.ToLower(level)
+	cm.refreshLevels()
+	return nil
+}
+
+// Level returns the level of the named logger, which follows later changes
+// of the configuration. The level is looked up for the name and then for
+// its parents, "inbound.file" and then "inbound" for "inbound.file.Orders",
+// falling back to the default level and then to INFO.
+func (cm *ConfigManager) Level(name string) slog.Leveler {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	levelVar, ok := cm.levelVars[name]
+	if !ok {
+		levelVar = new(slog.LevelVar)
+		levelVar.Set(cm.resolveLevel(name))
+		cm.levelVars[name] = levelVar
+	}
+	return levelVar
+}
+
+// refreshLevels applies a configuration change to the loggers handed out.
+// The caller holds cm.mu.
+func (cm *ConfigManager) refreshLevels() {
+	for name, levelVar := range cm.levelVars {
+		levelVar.Set(cm.resolveLevel(name))
+	}
+}
+
+// resolveLevel returns the configured level of the named logger. The
+// caller holds cm.mu.
+func (cm *ConfigManager) resolveLevel(name string) slog.Level {
+	for {
+		if level, ok := cm.runtimeLevels[name]; ok {
+			return LevelFromString(level).Level()
+		}
+		if cm.logLevelMap != nil {
+			if level, ok := (*cm.logLevelMap)[name]; ok {
+				return LevelFromString(level).Level()
+			}
+		}
+		i := strings.LastIndex(name, ".")
+		if i < 0 {
+			break
+		}
+		name = name[:i]
+	}
+	if cm.defaultLevel != "" {
+		return LevelFromString(cm.defaultLevel).Level()
+	}
+	return slog.LevelInfo
+}
+
+// trackArtifactLogger records that loggerName belongs to an artifact, so that
+// ForgetArtifact can release it
+func (cm *ConfigManager) trackArtifactLogger(kind string, name string, loggerName string) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	key := kind + "/" + name
+	if cm.artifactLoggers[key] == nil {
+		cm.artifactLoggers[key] = make(map[string]bool)
+	}
+	cm.artifactLoggers[key][loggerName] = true
+}
+
+// ForgetArtifact releases the levels and components of the loggers of an
+// undeployed artifact, so that they do not pile up as artifacts come and go.
+// Levels set with SetLevel are kept for an artifact deployed again later.
+func (cm *ConfigManager) ForgetArtifact(kind string, name string) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	key := kind + "/" + name
+	for loggerName := range cm.artifactLoggers[key] {
+		delete(cm.levelVars, loggerName)
+		delete(cm.registeredComponents, loggerName)
+	}
+	delete(cm.artifactLoggers, key)
+}
+
+// ParseLevel parses debug, info, warn or error, in any case
+func ParseLevel(levelStr string) (slog.Level, error) {
+	switch strings.ToLower(levelStr) {
+	case "debug", "info", "warn", "warning", "error":
+		return LevelFromString(levelStr).Level(), nil
+	}
+	return slog.LevelInfo, fmt.Errorf("invalid log level %q, expected debug, info, warn or error", levelStr)
+}
+
+// EffectiveLevel returns the level the named logger has or would have
+func (cm *ConfigManager) EffectiveLevel(name string) slog.Level {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return cm.resolveLevel(name)
+}
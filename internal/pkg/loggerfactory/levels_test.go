@@ -0,0 +1,113 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package loggerfactory
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLevel_Hierarchy(t *testing.T) {
+	cm := newConfigManager()
+	levels := map[string]string{"inbound": "warn", "inbound.file": "debug"}
+	cm.SetLogLevelMap(&levels)
+
+	tests := []struct {
+		name string
+		want slog.Level
+	}{
+		{"inbound", slog.LevelWarn},
+		{"inbound.http.Orders", slog.LevelWarn},
+		{"inbound.file", slog.LevelDebug},
+		{"inbound.file.Orders", slog.LevelDebug},
+		{"router", slog.LevelInfo},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, cm.Level(tt.name).Level())
+			assert.Equal(t, tt.want, cm.EffectiveLevel(tt.name))
+		})
+	}
+}
+
+func TestLevel_Default(t *testing.T) {
+	cm := newConfigManager()
+	router := cm.Level("router")
+	assert.Equal(t, slog.LevelInfo, router.Level())
+
+	cm.SetDefaultLevel("error")
+	assert.Equal(t, slog.LevelError, router.Level(), "loggers handed out follow the default")
+
+	levels := map[string]string{"router": "debug"}
+	cm.SetLogLevelMap(&levels)
+	assert.Equal(t, slog.LevelDebug, router.Level())
+	assert.Equal(t, slog.LevelError, cm.Level("deployers").Level())
+}
+
+func TestSetLevel(t *testing.T) {
+	cm := newConfigManager()
+	levels := map[string]string{"api": "info", "api.OrdersAPI": "error"}
+	cm.SetLogLevelMap(&levels)
+	orders := cm.Level("api.OrdersAPI")
+	stock := cm.Level("api.StockAPI")
+
+	require.NoError(t, cm.SetLevel("api", "DEBUG"))
+	assert.Equal(t, slog.LevelDebug, stock.Level())
+	assert.Equal(t, slog.LevelError, orders.Level(), "a configured level of a child is kept")
+
+	require.NoError(t, cm.SetLevel("api.OrdersAPI", "warn"))
+	assert.Equal(t, slog.LevelWarn, orders.Level(), "runtime levels take precedence over the file")
+
+	// Reloading the file keeps the runtime levels
+	reloaded := map[string]string{"api.OrdersAPI": "info"}
+	cm.SetLogLevelMap(&reloaded)
+	assert.Equal(t, slog.LevelWarn, orders.Level())
+
+	assert.EqualError(t, cm.SetLevel("api", "verbose"), `invalid log level "verbose", expected debug, info, warn or error`)
+	assert.EqualError(t, cm.SetLevel("", "info"), "logger name is empty")
+}
+
+type loggerUser struct{ updates int }
+
+func (u *loggerUser) UpdateLogger() { u.updates++ }
+
+func TestForgetArtifact(t *testing.T) {
+	cm := newConfigManager()
+	cm.trackArtifactLogger("inbound", "Orders", "inbound.http.Orders")
+	cm.trackArtifactLogger("api", "Orders", "api.Orders")
+	cm.Level("inbound.http.Orders")
+	cm.Level("api.Orders")
+	user := &loggerUser{}
+	cm.RegisterLoggerUser("inbound.http.Orders", user)
+	require.NoError(t, cm.SetLevel("inbound.http.Orders", "debug"))
+
+	cm.ForgetArtifact("inbound", "Orders")
+	assert.NotContains(t, cm.levelVars, "inbound.http.Orders")
+	assert.NotContains(t, cm.registeredComponents, "inbound.http.Orders")
+	assert.Contains(t, cm.levelVars, "api.Orders", "an artifact of another kind is kept")
+
+	// The runtime level applies when the artifact is deployed again
+	assert.Equal(t, slog.LevelDebug, cm.Level("inbound.http.Orders").Level())
+	cm.SetDefaultLevel("warn")
+	assert.Zero(t, user.updates)
+}
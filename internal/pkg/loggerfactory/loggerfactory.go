@@ -21,17 +21,32 @@ package loggerfactory
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 // ConfigManager manages logging configurations and registered components
 type ConfigManager struct {
 	mu                sync.RWMutex
 	logLevelMap       *map[string]string
+	defaultLevel      string
 	slogHandlerConfig SlogHandlerConfig
+	// Levels set at runtime, see SetLevel
+	runtimeLevels map[string]string
+	// Levels of the loggers handed out, updated when the configuration changes
+	levelVars map[string]*slog.LevelVar
+	// Names of the loggers of each artifact, keyed by kind and name
+	artifactLoggers map[string]map[string]bool
+	// Masking rules for logged messages
+	masker *Masker
 	// Track components that have requested loggers
 	registeredComponents map[string]LoggerUser
 }
@@ -48,15 +63,22 @@ var (
 
 func GetConfigManager() *ConfigManager {
 	once.Do(func() {
-		m := make(map[string]string)
-		configManagerInstance = &ConfigManager{
-			logLevelMap:          &m,
-			registeredComponents: make(map[string]LoggerUser),
-		}
+		configManagerInstance = newConfigManager()
 	})
 	return configManagerInstance
 }
 
+func newConfigManager() *ConfigManager {
+	m := make(map[string]string)
+	return &ConfigManager{
+		logLevelMap:          &m,
+		runtimeLevels:        make(map[string]string),
+		levelVars:            make(map[string]*slog.LevelVar),
+		artifactLoggers:      make(map[string]map[string]bool),
+		registeredComponents: make(map[string]LoggerUser),
+	}
+}
+
 // SetLogLevelMap sets the log level map.
 func (cm *ConfigManager) SetLogLevelMap(levelMap *map[string]string) {
 	// Make a copy of registered components to avoid holding the lock during notification
@@ -64,6 +86,7 @@ func (cm *ConfigManager) SetLogLevelMap(levelMap *map[string]string) {
 
 	cm.mu.Lock()
 	cm.logLevelMap = levelMap
+	cm.refreshLevels()
 
 	// Create a copy of the components to notify
 	for _, component := range cm.registeredComponents {
@@ -109,13 +132,13 @@ func (cm *ConfigManager) GetSlogHandlerConfig() SlogHandlerConfig {
 	return cm.slogHandlerConfig
 }
 
-// RegisterLoggerUser registers a component that uses a logger
+// RegisterLoggerUser registers a component that uses a logger. A later
+// component with the same name, such as a redeployed artifact, replaces the
+// earlier one.
 func (cm *ConfigManager) RegisterLoggerUser(packageName string, component LoggerUser) {
 	cm.mu.Lock()
 	defer cm.mu.Unlock()
-	if _, ok := cm.registeredComponents[packageName]; !ok {
-		cm.registeredComponents[packageName] = component
-	}
+	cm.registeredComponents[packageName] = component
 }
 
 // Type to extract and hold the slog handler related configurations from Config
@@ -124,32 +147,179 @@ func (cm *ConfigManager) RegisterLoggerUser(packageName string, component Logger
 type SlogHandlerConfig struct {
 	// json,text
 	Format string `koanf:"format"`
-	// stdout, file
+	// stdout, stderr, file
 	OutputPath string `koanf:"outputPath"`
+	// Log file, used when OutputPath is file
+	File FileConfig `koanf:"file"`
+	// Artifacts that also log to files of their own
+	Artifacts ArtifactLogConfig `koanf:"artifacts"`
+}
+
+// ArtifactLogConfig selects the artifact kinds (api, proxy, inbound) whose
+// logs are also written to a file per artifact, <kind>-<name>.log in Folder.
+// The files follow the rotation settings of the main log file.
+type ArtifactLogConfig struct {
+	Kinds  []string `koanf:"kinds"`
+	Folder string   `koanf:"folder"`
 }
 
+func (c ArtifactLogConfig) enabled(kind string) bool {
+	for _, k := range c.Kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	// Log files are shared by every handler that writes to them
+	logFilesMu sync.Mutex
+	logFiles   = make(map[string]*RotatingFile)
+	// Invalid handler configurations that have been reported
+	reported sync.Map
+)
+
 // Intentionally put 'slog' in future we can introduce more abstract handlers. Every handler should implement slog.Handler interface
+// GetSlogHandler never returns nil. An invalid configuration is reported
+// once and falls back to text on stderr. Records are also kept in the
+// recent logs.
 func GetSlogHandler(slogHandlerConfig SlogHandlerConfig) slog.Handler {
-	format := slogHandlerConfig.Format
-	outputPath := slogHandlerConfig.OutputPath
-	var slogHandler slog.Handler
+	if slogHandlerConfig.Format == "" && slogHandlerConfig.OutputPath == "" {
+		// Not configured yet
+		return &teeHandler{primary: slog.NewTextHandler(os.Stderr, nil), secondary: GetRecentLogs().handler()}
+	}
+	slogHandler, err := newSlogHandler(slogHandlerConfig.Format, slogHandlerConfig.OutputPath, slogHandlerConfig.File)
+	if err != nil {
+		slogHandler = slog.NewTextHandler(os.Stderr, nil)
+		report(slogHandler, "Invalid log handler configuration, logging to stderr", err)
+	}
+	return &teeHandler{primary: slogHandler, secondary: GetRecentLogs().handler()}
+}
+
+func newSlogHandler(format, outputPath string, fileConfig FileConfig) (slog.Handler, error) {
+	var w io.Writer
+	switch outputPath {
+	case "stdout":
+		w = os.Stdout
+	case "stderr":
+		w = os.Stderr
+	case "file":
+		f, err := openLogFile(fileConfig)
+		if err != nil {
+			return nil, err
+		}
+		w = f
+	default:
+		return nil, fmt.Errorf("unknown log output %q, expected stdout, stderr or file", outputPath)
+	}
 	switch format {
 	case "json":
-		switch outputPath {
-		case "stdout":
-			slogHandler = slog.NewJSONHandler(os.Stdout, nil)
-		case "file":
-			// l.Handler = slog.NewJSONHandler(slog.File(outputPath), slog.DefaultTimeFormat)
-		}
+		return slog.NewJSONHandler(w, nil), nil
 	case "text":
-		switch outputPath {
-		case "stdout":
-			slogHandler = slog.NewTextHandler(os.Stdout, nil)
-		case "file":
-			// l.Handler = slog.NewTextHandler(slog.File(outputPath), slog.DefaultTimeFormat)
+		return slog.NewTextHandler(w, nil), nil
+	default:
+		return nil, fmt.Errorf("unknown log format %q, expected json or text", format)
+	}
+}
+
+// openLogFile returns the shared RotatingFile for the path of config
+func openLogFile(config FileConfig) (*RotatingFile, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	logFilesMu.Lock()
+	defer logFilesMu.Unlock()
+	if f, ok := logFiles[config.Path]; ok {
+		f.configure(config)
+		return f, nil
+	}
+	f, err := NewRotatingFile(config)
+	if err != nil {
+		return nil, err
+	}
+	logFiles[config.Path] = f
+	return f, nil
+}
+
+// CloseFiles closes the open log files and waits for their compression.
+// Files are opened again when they are written after that.
+func CloseFiles() {
+	logFilesMu.Lock()
+	defer logFilesMu.Unlock()
+	for _, f := range logFiles {
+		f.Close()
+	}
+}
+
+// report logs a configuration problem once
+func report(handler slog.Handler, msg string, err error) {
+	if _, loaded := reported.LoadOrStore(err.Error(), true); !loaded {
+		slog.New(handler).Warn(msg, "error", err.Error())
+	}
+}
+
+// GetArtifactLogger returns the logger for records about an artifact of a
+// kind (api, proxy, inbound). The logger is named parentName + "." + name,
+// so its level is inherited from parentName unless it has one of its own.
+// The name of the artifact is added to its records and, when the kind is
+// configured in logger.handler.artifacts, the records are also written to
+// the log file of the artifact.
+func GetArtifactLogger(parentName string, kind string, name string, component interface{}) *slog.Logger {
+	loggerName := parentName + "." + name
+	cm := GetConfigManager()
+	cm.trackArtifactLogger(kind, name, loggerName)
+	if loggerUser, ok := component.(LoggerUser); ok {
+		cm.RegisterLoggerUser(loggerName, loggerUser)
+	}
+
+	slogHandlerConfig := cm.GetSlogHandlerConfig()
+	slogHandler := GetSlogHandler(slogHandlerConfig)
+	if slogHandlerConfig.Artifacts.enabled(kind) {
+		fileConfig := slogHandlerConfig.File
+		fileConfig.Path = filepath.Join(slogHandlerConfig.Artifacts.Folder, kind+"-"+fileName(name)+".log")
+		artifactHandler, err := newSlogHandler(slogHandlerConfig.Format, "file", fileConfig)
+		if err != nil {
+			report(slogHandler, "Invalid artifact log configuration", err)
+		} else {
+			slogHandler = &teeHandler{primary: slogHandler, secondary: artifactHandler}
 		}
 	}
-	return slogHandler
+	return slog.New(NewLevelHandler(cm.Level(loggerName), slogHandler)).With(slog.String(kind+"_name", name))
+}
+
+// fileName replaces the characters of an artifact name that are not safe
+// in file names
+func fileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// teeHandler writes records to two handlers. The primary handler decides
+// which levels are enabled, which callers leave to a LevelHandler.
+type teeHandler struct {
+	primary   slog.Handler
+	secondary slog.Handler
+}
+
+func (h *teeHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.primary.Enabled(ctx, level)
+}
+
+func (h *teeHandler) Handle(ctx context.Context, r slog.Record) error {
+	return errors.Join(h.primary.Handle(ctx, r.Clone()), h.secondary.Handle(ctx, r))
+}
+
+func (h *teeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &teeHandler{primary: h.primary.WithAttrs(attrs), secondary: h.secondary.WithAttrs(attrs)}
+}
+
+func (h *teeHandler) WithGroup(name string) slog.Handler {
+	return &teeHandler{primary: h.primary.WithGroup(name), secondary: h.secondary.WithGroup(name)}
 }
 
 // A LevelHandler wraps a Handler with an Enabled method
@@ -175,8 +345,20 @@ func (h *LevelHandler) Enabled(_ context.Context, level slog.Level) bool {
 	return level >= h.level.Level()
 }
 
-// Handle implements Handler.Handle.
+// Handle implements Handler.Handle. The attributes added to ctx with
+// ContextWithAttrs and the IDs of the trace span of ctx are added to the
+// record.
 func (h *LevelHandler) Handle(ctx context.Context, r slog.Record) error {
+	attrs := AttrsFromContext(ctx)
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		attrs = append(attrs[:len(attrs):len(attrs)],
+			slog.String("trace_id", spanContext.TraceID().String()),
+			slog.String("span_id", spanContext.SpanID().String()))
+	}
+	if len(attrs) > 0 {
+		r = r.Clone()
+		r.AddAttrs(attrs...)
+	}
 	return h.handler.Handle(ctx, r)
 }
 
@@ -213,7 +395,8 @@ func LevelFromString(levelStr string) slog.Leveler {
 }
 
 // GetLogger returns a logger for the specified package name and automatically
-// registers the component if it implements LoggerUser
+// registers the component if it implements LoggerUser. The level of the
+// logger follows the configuration, see ConfigManager.Level.
 func GetLogger(packageName string, component interface{}) *slog.Logger {
 	cm := GetConfigManager()
 
@@ -222,13 +405,6 @@ func GetLogger(packageName string, component interface{}) *slog.Logger {
 		cm.RegisterLoggerUser(packageName, loggerUser)
 	}
 
-	levelMap := cm.GetLogLevelMap()
 	slogHandlerConfig := cm.GetSlogHandlerConfig()
-
-	levelStr, ok := (*levelMap)[packageName]
-	if !ok {
-		slog.Error("PackageName not found in LevelMap", "PackageName", packageName)
-		return slog.New(NewLevelHandler(slog.LevelDebug, GetSlogHandler(slogHandlerConfig)))
-	}
-	return slog.New(NewLevelHandler(LevelFromString(levelStr), GetSlogHandler(slogHandlerConfig)))
+	return slog.New(NewLevelHandler(cm.Level(packageName), GetSlogHandler(slogHandlerConfig)))
 }
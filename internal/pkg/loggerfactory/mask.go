@@ -0,0 +1,194 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package loggerfactory
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultMaskReplacement replaces masked values unless configured otherwise
+const DefaultMaskReplacement = "****"
+
+// MaskConfig holds the rules that keep sensitive data out of logged
+// messages. Fields are header, property, JSON field and XML element names
+// whose values are masked. Patterns are regular expressions whose matches
+// are masked wherever they appear.
+type MaskConfig struct {
+	Fields      []string `koanf:"fields"`
+	Patterns    []string `koanf:"patterns"`
+	Replacement string   `koanf:"replacement"`
+}
+
+// Masker applies a MaskConfig. A nil Masker masks nothing.
+type Masker struct {
+	fields      map[string]bool
+	jsonFields  []*regexp.Regexp
+	xmlFields   []*regexp.Regexp
+	patterns    []*regexp.Regexp
+	replacement string
+}
+
+// jsonScalar matches a JSON string, number or literal at the start of a value
+var jsonScalar = regexp.MustCompile(`^(?:"(?:[^"\\]|\\.)*"|[-+0-9.eE]+|true|false|null)`)
+
+// NewMasker compiles the rules of config
+func NewMasker(config MaskConfig) (*Masker, error) {
+	m := &Masker{
+		fields:      make(map[string]bool),
+		replacement: config.Replacement,
+	}
+	if m.replacement == "" {
+		m.replacement = DefaultMaskReplacement
+	}
+	for _, field := range config.Fields {
+		if field == "" {
+			continue
+		}
+		m.fields[strings.ToLower(field)] = true
+		name := regexp.QuoteMeta(field)
+		// "field": followed by any JSON value
+		m.jsonFields = append(m.jsonFields, regexp.MustCompile(`(?i)"`+name+`"\s*:\s*`))
+		// <field>value</field>, <ns:field attr="x">value</ns:field>
+		m.xmlFields = append(m.xmlFields, regexp.MustCompile(`(?i)(<(?:[\w.-]+:)?`+name+`(?:\s[^>]*)?>)[^<]*`))
+	}
+	for _, pattern := range config.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid mask pattern %q: %w", pattern, err)
+		}
+		m.patterns = append(m.patterns, re)
+	}
+	return m, nil
+}
+
+// MaskPayload masks the values of the configured fields in a JSON or XML
+// payload, and the matches of the patterns
+func (m *Masker) MaskPayload(payload string) string {
+	if m == nil {
+		return payload
+	}
+	for _, re := range m.jsonFields {
+		payload = m.maskJSONField(payload, re)
+	}
+	for _, re := range m.xmlFields {
+		payload = re.ReplaceAllString(payload, "${1}"+strings.ReplaceAll(m.replacement, "$", "$$"))
+	}
+	return m.maskPatterns(payload)
+}
+
+// maskJSONField replaces the value following every match of key, whether it
+// is a string, number, literal, object or array, with the replacement as a
+// JSON string
+func (m *Masker) maskJSONField(payload string, key *regexp.Regexp) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range key.FindAllStringIndex(payload, -1) {
+		if loc[0] < last {
+			// The key is inside a value masked already
+			continue
+		}
+		end := jsonValueEnd(payload, loc[1])
+		if end < 0 {
+			continue
+		}
+		b.WriteString(payload[last:loc[1]])
+		b.WriteString(`"` + m.replacement + `"`)
+		last = end
+	}
+	if last == 0 {
+		return payload
+	}
+	b.WriteString(payload[last:])
+	return b.String()
+}
+
+// jsonValueEnd returns the end of the JSON value starting at start, or -1 if
+// there is none. An object or array that is not closed, as in a truncated
+// payload, runs to the end.
+func jsonValueEnd(s string, start int) int {
+	if start >= len(s) {
+		return -1
+	}
+	if s[start] != '{' && s[start] != '[' {
+		if loc := jsonScalar.FindStringIndex(s[start:]); loc != nil {
+			return start + loc[1]
+		}
+		return -1
+	}
+	depth := 0
+	for i := start; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			loc := jsonScalar.FindStringIndex(s[i:])
+			if loc == nil {
+				return len(s)
+			}
+			i += loc[1] - 1
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return len(s)
+}
+
+// MaskValue masks the value of a header or property. Values of the
+// configured fields are masked entirely.
+func (m *Masker) MaskValue(name string, value string) string {
+	if m == nil {
+		return value
+	}
+	if m.fields[strings.ToLower(name)] {
+		return m.replacement
+	}
+	return m.maskPatterns(value)
+}
+
+func (m *Masker) maskPatterns(s string) string {
+	for _, re := range m.patterns {
+		s = re.ReplaceAllLiteralString(s, m.replacement)
+	}
+	return s
+}
+
+// SetMaskConfig sets the masking rules for logged messages
+func (cm *ConfigManager) SetMaskConfig(config MaskConfig) error {
+	masker, err := NewMasker(config)
+	if err != nil {
+		return err
+	}
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cm.masker = masker
+	return nil
+}
+
+// Masker returns the masking rules for logged messages
+func (cm *ConfigManager) Masker() *Masker {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return cm.masker
+}
@@ -0,0 +1,75 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package loggerfactory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaskPayload(t *testing.T) {
+	masker, err := NewMasker(MaskConfig{Fields: []string{"ssn", "card"}})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		payload string
+		want    string
+	}{
+		{"string", `{"name": "bob", "ssn": "123-45-6789"}`, `{"name": "bob", "ssn": "****"}`},
+		{"escaped quote", `{"ssn": "12\"3", "a": 1}`, `{"ssn": "****", "a": 1}`},
+		{"number", `{"ssn":123456789}`, `{"ssn":"****"}`},
+		{"null", `{"ssn": null}`, `{"ssn": "****"}`},
+		{"object", `{"card": {"number": "4111", "cvv": "}"}, "name": "bob"}`, `{"card": "****", "name": "bob"}`},
+		{"array", `{"card": ["4111", ["1234"]], "name": "bob"}`, `{"card": "****", "name": "bob"}`},
+		{"nested field", `{"card": {"ssn": "1"}, "x": {"SSN": "2"}}`, `{"card": "****", "x": {"SSN": "****"}}`},
+		{"truncated", `{"name": "bob", "card": {"number": "41`, `{"name": "bob", "card": "****"`},
+		{"xml", `<ssn>123</ssn><ns:card type="visa">4111</ns:card>`, `<ssn>****</ssn><ns:card type="visa">****</ns:card>`},
+		{"other fields", `{"ssns": "1", "name": "ssn"}`, `{"ssns": "1", "name": "ssn"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, masker.MaskPayload(tt.payload))
+		})
+	}
+}
+
+func TestMaskReplacementIsLiteral(t *testing.T) {
+	masker, err := NewMasker(MaskConfig{
+		Fields:      []string{"ssn"},
+		Patterns:    []string{`(\d{4})-\d{4}`},
+		Replacement: "$1${x}",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "card $1${x}", masker.MaskValue("card", "card 1234-5678"))
+	assert.Equal(t, "$1${x}", masker.MaskValue("SSN", "123"))
+	assert.Equal(t, `{"ssn": "$1${x}"}<ssn>$1${x}</ssn>`, masker.MaskPayload(`{"ssn": "1"}<ssn>2</ssn>`))
+}
+
+func TestNewMasker_InvalidPattern(t *testing.T) {
+	_, err := NewMasker(MaskConfig{Patterns: []string{"("}})
+	assert.ErrorContains(t, err, `invalid mask pattern "("`)
+
+	var masker *Masker
+	assert.Equal(t, `{"ssn": "1"}`, masker.MaskPayload(`{"ssn": "1"}`))
+}
@@ -0,0 +1,98 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package loggerfactory
+
+import (
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// recentLogsSize is the number of lines kept by the recent logs
+const recentLogsSize = 1000
+
+// LogLine is a line of the log with its sequence number
+type LogLine struct {
+	Seq  uint64 `json:"seq"`
+	Line string `json:"line"`
+}
+
+// RecentLogs keeps the last lines logged, as JSON records, for the
+// management API to tail. Lines are numbered from 1 so that a reader can ask
+// for the lines after the last one it saw.
+type RecentLogs struct {
+	mu    sync.Mutex
+	lines []string
+	// last is the number of the last line written
+	last uint64
+}
+
+var (
+	recentLogs     *RecentLogs
+	recentLogsOnce sync.Once
+)
+
+// GetRecentLogs returns the recent logs of the server
+func GetRecentLogs() *RecentLogs {
+	recentLogsOnce.Do(func() {
+		recentLogs = NewRecentLogs(recentLogsSize)
+	})
+	return recentLogs
+}
+
+// NewRecentLogs returns recent logs that keep size lines
+func NewRecentLogs(size int) *RecentLogs {
+	return &RecentLogs{lines: make([]string, size)}
+}
+
+// Write adds the lines of p. Handlers write a record at a time.
+func (r *RecentLogs) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, line := range strings.Split(strings.TrimRight(string(p), "\n"), "\n") {
+		r.last++
+		r.lines[r.last%uint64(len(r.lines))] = line
+	}
+	return len(p), nil
+}
+
+// Since returns the lines after the line numbered after, at most the last
+// limit of them. Lines that are no longer kept are skipped.
+func (r *RecentLogs) Since(after uint64, limit int) []LogLine {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	first := after + 1
+	if size := uint64(len(r.lines)); r.last >= size && first <= r.last-size {
+		first = r.last - size + 1
+	}
+	if limit > 0 && r.last >= uint64(limit) && first <= r.last-uint64(limit) {
+		first = r.last - uint64(limit) + 1
+	}
+	lines := []LogLine{}
+	for seq := first; seq <= r.last; seq++ {
+		lines = append(lines, LogLine{Seq: seq, Line: r.lines[seq%uint64(len(r.lines))]})
+	}
+	return lines
+}
+
+// handler returns a handler that writes records to the recent logs
+func (r *RecentLogs) handler() slog.Handler {
+	return slog.NewJSONHandler(r, &slog.HandlerOptions{Level: slog.LevelDebug})
+}
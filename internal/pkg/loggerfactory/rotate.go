@@ -0,0 +1,308 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package loggerfactory
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	megabyte = 1024 * 1024
+	// backupTimeFormat is added to the name of rotated files. It sorts in
+	// time order, which pruning relies on.
+	backupTimeFormat = "2006-01-02T15-04-05.000"
+)
+
+// FileConfig holds the settings of a log file and its rotation. A file is
+// rotated when it would grow beyond MaxSize megabytes or when the Interval
+// (hourly, daily) it was opened in has passed, whichever comes first.
+type FileConfig struct {
+	Path string `koanf:"path"`
+	// Megabytes, 0 disables size based rotation
+	MaxSize int `koanf:"maxSize"`
+	// hourly, daily or empty to disable time based rotation
+	Interval string `koanf:"interval"`
+	// Number of rotated files to keep, 0 keeps all of them
+	MaxBackups int `koanf:"maxBackups"`
+	// Compress rotated files with gzip
+	Compress bool `koanf:"compress"`
+}
+
+func (c FileConfig) validate() error {
+	if c.Path == "" {
+		return fmt.Errorf("log file path is not set")
+	}
+	if c.MaxSize < 0 {
+		return fmt.Errorf("invalid maxSize %d for log file %s", c.MaxSize, c.Path)
+	}
+	if c.MaxBackups < 0 {
+		return fmt.Errorf("invalid maxBackups %d for log file %s", c.MaxBackups, c.Path)
+	}
+	switch c.Interval {
+	case "", "hourly", "daily":
+	default:
+		return fmt.Errorf("invalid interval %q for log file %s, expected hourly or daily", c.Interval, c.Path)
+	}
+	return nil
+}
+
+// RotatingFile is an io.Writer that appends to a log file and rotates it as
+// configured. Rotated files are renamed to <name>-<start time><ext>, compressed
+// in the background and pruned down to MaxBackups.
+type RotatingFile struct {
+	mu       sync.Mutex
+	config   FileConfig
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	now      func() time.Time
+	// Tracks background compression, so that Close can wait for it
+	wg sync.WaitGroup
+}
+
+// NewRotatingFile returns a RotatingFile for the configuration. The file
+// is opened on the first write.
+func NewRotatingFile(config FileConfig) (*RotatingFile, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	return &RotatingFile{config: config, now: time.Now}, nil
+}
+
+// configure applies new rotation settings to the file
+func (f *RotatingFile) configure(config FileConfig) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.config = config
+}
+
+// Write implements io.Writer
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+	if f.shouldRotate(int64(len(p))) {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Close closes the file and waits for running compressions. A later write
+// opens the file again.
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	var err error
+	if f.file != nil {
+		err = f.file.Close()
+		f.file = nil
+	}
+	f.mu.Unlock()
+	f.wg.Wait()
+	return err
+}
+
+// open opens the file for appending. An existing file keeps the time it was
+// last written as its opening time, so that a file left over from an
+// earlier interval is rotated on the first write.
+func (f *RotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(f.config.Path), 0755); err != nil {
+		return fmt.Errorf("cannot create log folder: %w", err)
+	}
+	file, err := os.OpenFile(f.config.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("cannot open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("cannot open log file: %w", err)
+	}
+	f.file = file
+	f.size = info.Size()
+	f.openedAt = f.now()
+	if f.size > 0 {
+		f.openedAt = info.ModTime()
+	}
+	return nil
+}
+
+func (f *RotatingFile) shouldRotate(n int64) bool {
+	if f.size == 0 {
+		return false
+	}
+	if f.config.MaxSize > 0 && f.size+n > int64(f.config.MaxSize)*megabyte {
+		return true
+	}
+	switch f.config.Interval {
+	case "hourly":
+		return !f.now().Truncate(time.Hour).Equal(f.openedAt.Truncate(time.Hour))
+	case "daily":
+		y1, m1, d1 := f.now().Date()
+		y2, m2, d2 := f.openedAt.Date()
+		return y1 != y2 || m1 != m2 || d1 != d2
+	}
+	return false
+}
+
+// rotate renames the current file and opens a new one
+func (f *RotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	f.file = nil
+
+	backup := f.backupName()
+	if err := os.Rename(f.config.Path, backup); err != nil {
+		return fmt.Errorf("cannot rotate log file: %w", err)
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+	f.size = 0
+	f.openedAt = f.now()
+
+	config := f.config
+	if !config.Compress {
+		prune(config)
+		return nil
+	}
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+		if err := compress(backup); err != nil {
+			fmt.Fprintf(os.Stderr, "cannot compress log file %s: %v\n", backup, err)
+		}
+		prune(config)
+	}()
+	return nil
+}
+
+// backupName returns a free name for the rotated file, stamped with the
+// time the file was started
+func (f *RotatingFile) backupName() string {
+	prefix, ext := splitExt(f.config.Path)
+	stamp := f.openedAt.Format(backupTimeFormat)
+	name := prefix + "-" + stamp + ext
+	for i := 1; fileExists(name) || fileExists(name+".gz"); i++ {
+		name = fmt.Sprintf("%s-%s.%d%s", prefix, stamp, i, ext)
+	}
+	return name
+}
+
+// compress replaces the file with a gzip compressed copy. A file that has
+// been pruned in the meantime is skipped.
+func compress(name string) error {
+	src, err := os.Open(name)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	tmp := name + ".gz.tmp"
+	dst, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(dst)
+	_, err = io.Copy(zw, src)
+	if closeErr := zw.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, name+".gz")
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	src.Close()
+	return os.Remove(name)
+}
+
+// prune removes the oldest rotated files beyond MaxBackups
+func prune(config FileConfig) {
+	if config.MaxBackups == 0 {
+		return
+	}
+	prefix, ext := splitExt(config.Path)
+	matches, err := filepath.Glob(prefix + "-*")
+	if err != nil {
+		return
+	}
+	var backups []string
+	for _, match := range matches {
+		if isBackup(match, prefix, ext) {
+			backups = append(backups, match)
+		}
+	}
+	if len(backups) <= config.MaxBackups {
+		return
+	}
+	sort.Strings(backups)
+	for _, backup := range backups[:len(backups)-config.MaxBackups] {
+		if err := os.Remove(backup); err != nil && !os.IsNotExist(err) {
+			fmt.Fprintf(os.Stderr, "cannot remove log file %s: %v\n", backup, err)
+		}
+	}
+}
+
+// isBackup reports whether name is a rotated file of prefix and ext, so
+// that files of other logs with a longer name are not pruned
+func isBackup(name, prefix, ext string) bool {
+	stamp := strings.TrimPrefix(strings.TrimSuffix(name, ".gz"), prefix+"-")
+	if !strings.HasSuffix(stamp, ext) || len(stamp) < len(backupTimeFormat) {
+		return false
+	}
+	_, err := time.Parse(backupTimeFormat, stamp[:len(backupTimeFormat)])
+	return err == nil
+}
+
+func splitExt(path string) (string, string) {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext), ext
+}
+
+func fileExists(name string) bool {
+	_, err := os.Stat(name)
+	return err == nil
+}
@@ -0,0 +1,217 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package loggerfactory
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestFile returns a RotatingFile in a temporary folder whose clock is
+// set by the returned function
+func newTestFile(t *testing.T, config FileConfig) (*RotatingFile, func(time.Time)) {
+	t.Helper()
+	config.Path = filepath.Join(t.TempDir(), "synapse.log")
+	f, err := NewRotatingFile(config)
+	require.NoError(t, err)
+	clock := time.Date(2026, 3, 14, 9, 30, 0, 0, time.Local)
+	f.now = func() time.Time { return clock }
+	t.Cleanup(func() { f.Close() })
+	return f, func(now time.Time) { clock = now }
+}
+
+// backups returns the names of the rotated files next to the log file
+func backups(t *testing.T, f *RotatingFile) []string {
+	t.Helper()
+	entries, err := os.ReadDir(filepath.Dir(f.config.Path))
+	require.NoError(t, err)
+	var names []string
+	for _, entry := range entries {
+		if entry.Name() != filepath.Base(f.config.Path) {
+			names = append(names, entry.Name())
+		}
+	}
+	return names
+}
+
+func write(t *testing.T, f *RotatingFile, line string) {
+	t.Helper()
+	_, err := f.Write([]byte(line))
+	require.NoError(t, err)
+}
+
+func TestRotatingFile_Size(t *testing.T) {
+	f, _ := newTestFile(t, FileConfig{MaxSize: 1})
+	half := strings.Repeat("a", megabyte/2)
+	write(t, f, half)
+	write(t, f, half)
+	assert.Empty(t, backups(t, f), "a file of exactly MaxSize is not rotated")
+
+	write(t, f, "b")
+	assert.Equal(t, []string{"synapse-2026-03-14T09-30-00.000.log"}, backups(t, f))
+	data, err := os.ReadFile(f.config.Path)
+	require.NoError(t, err)
+	assert.Equal(t, "b", string(data))
+
+	// A second rotation in the same interval gets a free name
+	write(t, f, strings.Repeat("c", megabyte))
+	assert.Equal(t, []string{"synapse-2026-03-14T09-30-00.000.1.log", "synapse-2026-03-14T09-30-00.000.log"}, backups(t, f))
+}
+
+func TestRotatingFile_Interval(t *testing.T) {
+	tests := []struct {
+		interval string
+		same     time.Time
+		next     time.Time
+	}{
+		{"hourly", time.Date(2026, 3, 14, 9, 59, 59, 0, time.Local), time.Date(2026, 3, 14, 10, 0, 0, 0, time.Local)},
+		{"daily", time.Date(2026, 3, 14, 23, 59, 59, 0, time.Local), time.Date(2026, 3, 15, 0, 0, 0, 0, time.Local)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.interval, func(t *testing.T) {
+			f, setClock := newTestFile(t, FileConfig{Interval: tt.interval})
+			write(t, f, "first\n")
+			setClock(tt.same)
+			write(t, f, "second\n")
+			assert.Empty(t, backups(t, f))
+
+			setClock(tt.next)
+			write(t, f, "third\n")
+			require.Equal(t, []string{"synapse-2026-03-14T09-30-00.000.log"}, backups(t, f))
+			data, err := os.ReadFile(filepath.Join(filepath.Dir(f.config.Path), "synapse-2026-03-14T09-30-00.000.log"))
+			require.NoError(t, err)
+			assert.Equal(t, "first\nsecond\n", string(data))
+		})
+	}
+}
+
+func TestRotatingFile_LeftOverFile(t *testing.T) {
+	f, _ := newTestFile(t, FileConfig{Interval: "daily"})
+	require.NoError(t, os.WriteFile(f.config.Path, []byte("old\n"), 0644))
+	yesterday := time.Date(2026, 3, 13, 18, 0, 0, 0, time.Local)
+	require.NoError(t, os.Chtimes(f.config.Path, yesterday, yesterday))
+
+	// A file from an earlier interval is rotated on the first write
+	write(t, f, "new\n")
+	assert.Equal(t, []string{"synapse-2026-03-13T18-00-00.000.log"}, backups(t, f))
+}
+
+func TestRotatingFile_Prune(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		t.Run(map[bool]string{false: "plain", true: "compressed"}[compress], func(t *testing.T) {
+			f, setClock := newTestFile(t, FileConfig{Interval: "hourly", MaxBackups: 2, Compress: compress})
+			// Files of other logs that share the prefix are kept
+			other := filepath.Join(filepath.Dir(f.config.Path), "synapse-access.log")
+			require.NoError(t, os.WriteFile(other, []byte("other\n"), 0644))
+
+			for hour := 9; hour <= 13; hour++ {
+				setClock(time.Date(2026, 3, 14, hour, 0, 0, 0, time.Local))
+				write(t, f, "line\n")
+			}
+			require.NoError(t, f.Close())
+
+			want := []string{"synapse-2026-03-14T11-00-00.000.log", "synapse-2026-03-14T12-00-00.000.log", "synapse-access.log"}
+			if compress {
+				want = []string{"synapse-2026-03-14T11-00-00.000.log.gz", "synapse-2026-03-14T12-00-00.000.log.gz", "synapse-access.log"}
+			}
+			assert.Equal(t, want, backups(t, f))
+			if compress {
+				file, err := os.Open(filepath.Join(filepath.Dir(f.config.Path), want[1]))
+				require.NoError(t, err)
+				defer file.Close()
+				reader, err := gzip.NewReader(file)
+				require.NoError(t, err)
+				data, err := io.ReadAll(reader)
+				require.NoError(t, err)
+				assert.Equal(t, "line\n", string(data))
+			}
+		})
+	}
+}
+
+func TestIsBackup(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"logs/synapse-2026-03-14T09-30-00.000.log", true},
+		{"logs/synapse-2026-03-14T09-30-00.000.log.gz", true},
+		{"logs/synapse-2026-03-14T09-30-00.000.2.log", true},
+		{"logs/synapse-access.log", false},
+		{"logs/synapse-access-2026-03-14T09-30-00.000.log", false},
+		{"logs/synapse-2026-03-14.log", false},
+		{"logs/synapse-2026-03-14T09-30-00.000.txt", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isBackup(tt.name, "logs/synapse", ".log"))
+		})
+	}
+}
+
+func TestFileConfigValidate(t *testing.T) {
+	assert.NoError(t, FileConfig{Path: "synapse.log", MaxSize: 10, Interval: "daily"}.validate())
+	assert.EqualError(t, FileConfig{}.validate(), "log file path is not set")
+	assert.EqualError(t, FileConfig{Path: "synapse.log", MaxSize: -1}.validate(), "invalid maxSize -1 for log file synapse.log")
+	assert.EqualError(t, FileConfig{Path: "synapse.log", MaxBackups: -1}.validate(), "invalid maxBackups -1 for log file synapse.log")
+	assert.EqualError(t, FileConfig{Path: "synapse.log", Interval: "weekly"}.validate(), `invalid interval "weekly" for log file synapse.log, expected hourly or daily`)
+}
+
+func TestGetSlogHandler_InvalidConfigFallsBack(t *testing.T) {
+	tests := []struct {
+		name   string
+		config SlogHandlerConfig
+		err    string
+	}{
+		{"format", SlogHandlerConfig{Format: "yaml", OutputPath: "stdout"}, `unknown log format "yaml", expected json or text`},
+		{"output", SlogHandlerConfig{Format: "json", OutputPath: "syslog"}, `unknown log output "syslog", expected stdout, stderr or file`},
+		{"file", SlogHandlerConfig{Format: "json", OutputPath: "file"}, "log file path is not set"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newSlogHandler(tt.config.Format, tt.config.OutputPath, tt.config.File)
+			assert.EqualError(t, err, tt.err)
+
+			handler, ok := GetSlogHandler(tt.config).(*teeHandler)
+			require.True(t, ok)
+			assert.IsType(t, &slog.TextHandler{}, handler.primary)
+		})
+	}
+
+	// A valid file configuration writes to the file
+	path := filepath.Join(t.TempDir(), "synapse.log")
+	handler, err := newSlogHandler("text", "file", FileConfig{Path: path})
+	require.NoError(t, err)
+	slog.New(handler).Info("Deployed API", "api", "OrdersAPI")
+	CloseFiles()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.True(t, bytes.Contains(data, []byte(`msg="Deployed API" api=OrdersAPI`)), string(data))
+}
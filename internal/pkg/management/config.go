@@ -0,0 +1,85 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package management
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// DefaultPort is the port of the management listener before the server
+// offset is added
+const DefaultPort = 9164
+
+// Config is the [management] section of deployment.toml
+type Config struct {
+	// Enabled starts the management listener, which is off by default
+	Enabled bool
+	// Hostname is the interface to listen on, the server hostname when empty
+	Hostname string
+	Port     int
+	// Username and Password are the basic authentication credentials that
+	// every request must carry
+	Username string
+	Password string
+	// CertFile and KeyFile are the PEM certificate and private key the
+	// listener serves HTTPS with, plain HTTP when they are not set
+	CertFile string
+	KeyFile  string
+}
+
+// ParseConfig validates the [management] section of deployment.toml. The
+// credentials are required when the listener is enabled.
+func ParseConfig(settings map[string]string) (Config, error) {
+	config := Config{
+		Hostname: settings["hostname"],
+		Port:     DefaultPort,
+		Username: settings["username"],
+		Password: settings["password"],
+		CertFile: settings["cert_file"],
+		KeyFile:  settings["key_file"],
+	}
+	if value := settings["enabled"]; value != "" {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid management enabled value: %s, must be true or false", value)
+		}
+		config.Enabled = enabled
+	}
+	if value := settings["port"]; value != "" {
+		port, err := strconv.Atoi(value)
+		if err != nil || port <= 0 || port > 65535 {
+			return Config{}, fmt.Errorf("invalid management port value: %s, must be a port number", value)
+		}
+		config.Port = port
+	}
+	if config.Enabled && (config.Username == "" || config.Password == "") {
+		return Config{}, fmt.Errorf("management username and password are required when the management API is enabled")
+	}
+	if (config.CertFile == "") != (config.KeyFile == "") {
+		return Config{}, fmt.Errorf("management cert_file and key_file must be set together")
+	}
+	return config, nil
+}
+
+// Address is the address the listener binds to
+func (c Config) Address() string {
+	return c.Hostname + ":" + strconv.Itoa(c.Port)
+}
@@ -0,0 +1,213 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+// Package metrics collects the statistics of the messages mediated by the
+// server and exposes them, together with the Go runtime metrics, in the
+// Prometheus exposition format. Series are labelled with the names the
+// artifacts have in the ConfigContext.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "synapse"
+
+// Kinds of artifacts that receive messages
+const (
+	KindAPI       = "api"
+	KindProxy     = "proxy"
+	KindInbound   = "inbound"
+	KindTask      = "task"
+	KindProcessor = "processor"
+)
+
+// File outcomes and actions of file inbound endpoints
+const (
+	FileProcessed = "processed"
+	FileFailed    = "failed"
+	ActionMove    = "move"
+	ActionDelete  = "delete"
+)
+
+// statusError labels backend calls that got no response
+const statusError = "error"
+
+var registry = prometheus.NewRegistry()
+
+var (
+	apiRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "api_requests_total",
+		Help:      "Requests mediated by APIs, by API, resource, method and status code.",
+	}, []string{"api", "resource", "method", "status"})
+
+	apiRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "api_request_duration_seconds",
+		Help:      "Time taken to mediate API requests, by API, resource, method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"api", "resource", "method", "status"})
+
+	proxyRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "proxy_requests_total",
+		Help:      "Requests mediated by proxy services, by proxy, method and status code.",
+	}, []string{"proxy", "method", "status"})
+
+	proxyRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "proxy_request_duration_seconds",
+		Help:      "Time taken to mediate proxy service requests, by proxy, method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"proxy", "method", "status"})
+
+	endpointCalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "endpoint_calls_total",
+		Help:      "Backend calls, by endpoint and status code, which is error when no response was received.",
+	}, []string{"endpoint", "status"})
+
+	endpointCallDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "endpoint_call_duration_seconds",
+		Help:      "Time taken by backend calls, by endpoint.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"endpoint"})
+
+	endpointErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "endpoint_errors_total",
+		Help:      "Backend calls that failed or got a 5xx response, by endpoint.",
+	}, []string{"endpoint"})
+
+	inboundMessages = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "inbound_messages_total",
+		Help:      "Messages received by inbound endpoints, by inbound and protocol.",
+	}, []string{"inbound", "protocol"})
+
+	inboundFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "inbound_failures_total",
+		Help:      "Messages inbound endpoints failed to read or mediate, by inbound and protocol.",
+	}, []string{"inbound", "protocol"})
+
+	inboundFiles = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "inbound_files_total",
+		Help:      "Files handled by file inbound endpoints, by inbound and result, which is processed or failed.",
+	}, []string{"inbound", "result"})
+
+	inboundFileActions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "inbound_file_actions_total",
+		Help:      "Files moved or deleted by file inbound endpoints after handling them, by inbound and action.",
+	}, []string{"inbound", "action"})
+
+	inFlight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "messages_in_flight",
+		Help:      "Messages being mediated, by kind and name of the artifact that received them.",
+	}, []string{"kind", "artifact"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		apiRequests, apiRequestDuration,
+		proxyRequests, proxyRequestDuration,
+		endpointCalls, endpointCallDuration, endpointErrors,
+		inboundMessages, inboundFailures, inboundFiles, inboundFileActions,
+		inFlight,
+	)
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// ObserveAPIRequest records a request mediated by a resource of an API. The
+// resource is its URI template, which keeps the number of series bounded.
+func ObserveAPIRequest(api string, resource string, method string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+	apiRequests.WithLabelValues(api, resource, method, code).Inc()
+	apiRequestDuration.WithLabelValues(api, resource, method, code).Observe(duration.Seconds())
+}
+
+// ObserveProxyRequest records a request mediated by a proxy service
+func ObserveProxyRequest(proxy string, method string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+	proxyRequests.WithLabelValues(proxy, method, code).Inc()
+	proxyRequestDuration.WithLabelValues(proxy, method, code).Observe(duration.Seconds())
+}
+
+// ObserveEndpointCall records a call to a backend. err is the transport error
+// of a call that got no response, in which case status is ignored.
+func ObserveEndpointCall(endpoint string, status int, duration time.Duration, err error) {
+	code := statusError
+	if err == nil {
+		code = strconv.Itoa(status)
+	}
+	endpointCalls.WithLabelValues(endpoint, code).Inc()
+	endpointCallDuration.WithLabelValues(endpoint).Observe(duration.Seconds())
+	if err != nil || status >= http.StatusInternalServerError {
+		endpointErrors.WithLabelValues(endpoint).Inc()
+	}
+}
+
+// InboundMessage records a message received by an inbound endpoint
+func InboundMessage(inbound string, protocol string) {
+	inboundMessages.WithLabelValues(inbound, protocol).Inc()
+}
+
+// InboundFailure records a message an inbound endpoint failed to read or mediate
+func InboundFailure(inbound string, protocol string) {
+	inboundFailures.WithLabelValues(inbound, protocol).Inc()
+}
+
+// InboundFile records a file handled by a file inbound endpoint, with
+// FileProcessed or FileFailed as result
+func InboundFile(inbound string, result string) {
+	inboundFiles.WithLabelValues(inbound, result).Inc()
+}
+
+// InboundFileAction records a file moved or deleted by a file inbound
+// endpoint, with ActionMove or ActionDelete as action
+func InboundFileAction(inbound string, action string) {
+	inboundFileActions.WithLabelValues(inbound, action).Inc()
+}
+
+// TrackInFlight counts a message as being mediated by the artifact until the
+// returned function is called:
+//
+//	defer metrics.TrackInFlight(metrics.KindAPI, api.Name)()
+func TrackInFlight(kind string, artifact string) func() {
+	gauge := inFlight.WithLabelValues(kind, artifact)
+	gauge.Inc()
+	return gauge.Dec
+}
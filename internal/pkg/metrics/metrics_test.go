@@ -0,0 +1,90 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObserveAPIRequest(t *testing.T) {
+	ObserveAPIRequest("OrdersAPI", "/orders/{id}", http.MethodGet, http.StatusOK, 20*time.Millisecond)
+	ObserveAPIRequest("OrdersAPI", "/orders/{id}", http.MethodGet, http.StatusOK, 30*time.Millisecond)
+	ObserveAPIRequest("OrdersAPI", "/orders/{id}", http.MethodGet, http.StatusNotFound, time.Millisecond)
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(apiRequests.WithLabelValues("OrdersAPI", "/orders/{id}", "GET", "200")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(apiRequests.WithLabelValues("OrdersAPI", "/orders/{id}", "GET", "404")))
+	assert.Equal(t, 2, testutil.CollectAndCount(apiRequestDuration, "synapse_api_request_duration_seconds"))
+}
+
+func TestObserveEndpointCall(t *testing.T) {
+	ObserveEndpointCall("StockEP", http.StatusOK, time.Millisecond, nil)
+	ObserveEndpointCall("StockEP", http.StatusNotFound, time.Millisecond, nil)
+	ObserveEndpointCall("StockEP", http.StatusBadGateway, time.Millisecond, nil)
+	ObserveEndpointCall("StockEP", 0, time.Millisecond, errors.New("connection refused"))
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(endpointCalls.WithLabelValues("StockEP", "200")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(endpointCalls.WithLabelValues("StockEP", "error")))
+	// Only failed calls and 5xx responses are errors
+	assert.Equal(t, 2.0, testutil.ToFloat64(endpointErrors.WithLabelValues("StockEP")))
+}
+
+func TestInboundFiles(t *testing.T) {
+	InboundMessage("OrdersFile", "file")
+	InboundMessage("OrdersFile", "file")
+	InboundFailure("OrdersFile", "file")
+	InboundFile("OrdersFile", FileProcessed)
+	InboundFile("OrdersFile", FileFailed)
+	InboundFileAction("OrdersFile", ActionMove)
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(inboundMessages.WithLabelValues("OrdersFile", "file")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(inboundFailures.WithLabelValues("OrdersFile", "file")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(inboundFiles.WithLabelValues("OrdersFile", FileProcessed)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(inboundFileActions.WithLabelValues("OrdersFile", ActionMove)))
+}
+
+func TestTrackInFlight(t *testing.T) {
+	first := TrackInFlight(KindAPI, "OrdersAPI")
+	second := TrackInFlight(KindAPI, "OrdersAPI")
+	assert.Equal(t, 2.0, testutil.ToFloat64(inFlight.WithLabelValues(KindAPI, "OrdersAPI")))
+
+	first()
+	second()
+	assert.Equal(t, 0.0, testutil.ToFloat64(inFlight.WithLabelValues(KindAPI, "OrdersAPI")))
+}
+
+func TestHandler(t *testing.T) {
+	ObserveProxyRequest("StockQuoteProxy", http.MethodPost, http.StatusOK, time.Millisecond)
+
+	recorder := httptest.NewRecorder()
+	Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	body := recorder.Body.String()
+	assert.Contains(t, body, `synapse_proxy_requests_total{method="POST",proxy="StockQuoteProxy",status="200"} 1`)
+	assert.Contains(t, body, "go_goroutines")
+	assert.Contains(t, body, "process_start_time_seconds")
+}
@@ -0,0 +1,222 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+// Package tracing records OpenTelemetry spans for the messages mediated by
+// the server and propagates W3C trace context to and from HTTP peers. Spans
+// are exported over OTLP or, for offline use, written to stdout or a file.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName names the tracer of the server
+const InstrumentationName = "github.com/apache/synapse-go"
+
+// Span exporters
+const (
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+)
+
+const defaultServiceName = "synapse"
+
+// Config is the [tracing] section of deployment.toml
+type Config struct {
+	Enabled bool
+	// Exporter is otlp, stdout or file
+	Exporter string
+	// Endpoint is the URL of the OTLP/HTTP collector, such as
+	// http://localhost:4318. The OTEL_EXPORTER_OTLP_* environment variables
+	// apply when it is empty.
+	Endpoint string
+	// Path is the file the file exporter writes to
+	Path string
+	// SamplingRatio is the fraction of the traces started by the server that
+	// are sampled. Traces continued from a caller follow its decision.
+	SamplingRatio float64
+	ServiceName   string
+}
+
+// ParseConfig validates the [tracing] section of deployment.toml. Tracing is
+// disabled when settings is empty.
+func ParseConfig(settings map[string]string) (Config, error) {
+	config := Config{Exporter: ExporterOTLP, SamplingRatio: 1, ServiceName: defaultServiceName}
+	if value := settings["enabled"]; value != "" {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return config, fmt.Errorf("invalid tracing enabled value: %s, must be true or false", value)
+		}
+		config.Enabled = enabled
+	}
+	if value := settings["exporter"]; value != "" {
+		config.Exporter = value
+	}
+	switch config.Exporter {
+	case ExporterOTLP, ExporterStdout:
+	case ExporterFile:
+		if settings["path"] == "" {
+			return config, fmt.Errorf("missing tracing path for the file exporter")
+		}
+	default:
+		return config, fmt.Errorf("invalid tracing exporter value: %s, must be otlp, stdout or file", config.Exporter)
+	}
+	config.Endpoint = settings["endpoint"]
+	config.Path = settings["path"]
+	if value := settings["sampling_ratio"]; value != "" {
+		ratio, err := strconv.ParseFloat(value, 64)
+		if err != nil || ratio < 0 || ratio > 1 {
+			return config, fmt.Errorf("invalid tracing sampling_ratio value: %s, must be between 0 and 1", value)
+		}
+		config.SamplingRatio = ratio
+	}
+	if value := settings["service_name"]; value != "" {
+		config.ServiceName = value
+	}
+	return config, nil
+}
+
+// Setup installs the W3C trace context propagator and, when tracing is
+// enabled, a tracer provider that exports spans as configured. The returned
+// function flushes the spans not exported yet and stops the exporter.
+func Setup(ctx context.Context, config Config) (func(context.Context) error, error) {
+	// Trace context is passed on even when the server does not record spans
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	if !config.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, closer, err := newExporter(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(config.ServiceName)))
+	if err != nil {
+		return nil, err
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SamplingRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closer != nil {
+			err = errors.Join(err, closer.Close())
+		}
+		return err
+	}, nil
+}
+
+// newExporter returns the span exporter and the file it writes to, if any
+func newExporter(ctx context.Context, config Config) (sdktrace.SpanExporter, io.Closer, error) {
+	switch config.Exporter {
+	case ExporterStdout:
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		return exporter, nil, err
+	case ExporterFile:
+		if err := os.MkdirAll(filepath.Dir(config.Path), 0755); err != nil {
+			return nil, nil, err
+		}
+		f, err := os.OpenFile(config.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, nil, err
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(f))
+		if err != nil {
+			f.Close()
+			return nil, nil, err
+		}
+		return exporter, f, nil
+	default:
+		var options []otlptracehttp.Option
+		if config.Endpoint != "" {
+			options = append(options, otlptracehttp.WithEndpointURL(config.Endpoint))
+		}
+		exporter, err := otlptracehttp.New(ctx, options...)
+		return exporter, nil, err
+	}
+}
+
+// Tracer returns the tracer of the server
+func Tracer() trace.Tracer {
+	return otel.Tracer(InstrumentationName)
+}
+
+// Extract returns ctx with the trace context of the traceparent header of a
+// request, if it has one
+func Extract(ctx context.Context, header http.Header) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(header))
+}
+
+// Inject sets the traceparent header of a request to the span of ctx
+func Inject(ctx context.Context, header http.Header) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+}
+
+// SetError marks span as failed with err
+func SetError(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// StartServerSpan starts the span of a request received by the server,
+// continuing the trace of the caller if the request has a traceparent header
+func StartServerSpan(ctx context.Context, r *http.Request, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	ctx = Extract(ctx, r.Header)
+	attrs = append(attrs, semconv.HTTPRequestMethodKey.String(r.Method), semconv.URLPath(r.URL.Path))
+	return Tracer().Start(ctx, name, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(attrs...))
+}
+
+// SetStatusCode records the status code of the response to a request. Server
+// errors fail the span.
+func SetStatusCode(span trace.Span, status int) {
+	span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+	if status >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(status))
+	}
+}
+
+// MessageAttributes identify a message in the span that receives it
+func MessageAttributes(messageID string, correlationID string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("synapse.message_id", messageID),
+		attribute.String("synapse.correlation_id", correlationID),
+	}
+}
@@ -0,0 +1,89 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestParseConfig(t *testing.T) {
+	config, err := ParseConfig(nil)
+	require.NoError(t, err)
+	assert.False(t, config.Enabled)
+	assert.Equal(t, ExporterOTLP, config.Exporter)
+	assert.Equal(t, 1.0, config.SamplingRatio)
+	assert.Equal(t, "synapse", config.ServiceName)
+
+	config, err = ParseConfig(map[string]string{
+		"enabled":        "1",
+		"exporter":       "file",
+		"path":           "logs/traces.json",
+		"sampling_ratio": "0.25",
+		"service_name":   "orders",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, Config{Enabled: true, Exporter: ExporterFile, Path: "logs/traces.json", SamplingRatio: 0.25, ServiceName: "orders"}, config)
+
+	for expected, settings := range map[string]map[string]string{
+		"invalid tracing enabled value: yes, must be true or false":            {"enabled": "yes"},
+		"invalid tracing exporter value: jaeger, must be otlp, stdout or file": {"exporter": "jaeger"},
+		"missing tracing path for the file exporter":                           {"exporter": "file"},
+		"invalid tracing sampling_ratio value: 1.5, must be between 0 and 1":   {"sampling_ratio": "1.5"},
+		"invalid tracing sampling_ratio value: half, must be between 0 and 1":  {"sampling_ratio": "half"},
+	} {
+		_, err := ParseConfig(settings)
+		assert.EqualError(t, err, expected)
+	}
+}
+
+func TestSetup_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "traces.json")
+	shutdown, err := Setup(context.Background(), Config{Enabled: true, Exporter: ExporterFile, Path: path, SamplingRatio: 1, ServiceName: "synapse"})
+	require.NoError(t, err)
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+
+	// A request with a traceparent continues the trace of the caller
+	request, _ := http.NewRequest(http.MethodGet, "/orders", nil)
+	request.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx, span := StartServerSpan(context.Background(), request, "api OrdersAPI")
+	SetStatusCode(span, http.StatusOK)
+	span.End()
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String())
+
+	header := http.Header{}
+	Inject(ctx, header)
+	assert.True(t, strings.HasPrefix(header.Get("traceparent"), "00-4bf92f3577b34da6a3ce929d0e0e4736-"+span.SpanContext().SpanID().String()))
+
+	require.NoError(t, shutdown(context.Background()))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"Name":"api OrdersAPI"`)
+	assert.Contains(t, string(data), `"synapse"`)
+}
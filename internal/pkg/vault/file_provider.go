@@ -0,0 +1,218 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package vault
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/knadh/koanf/parsers/toml"
+	"github.com/knadh/koanf/providers/file"
+	"github.com/knadh/koanf/v2"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// SecurityFolder is the folder under conf that holds the vault
+	SecurityFolder = "security"
+	// SecretsFileName is the file of encrypted secrets
+	SecretsFileName = "secrets.toml"
+	// KeyFileName is the file holding the key, unless a passphrase is used
+	KeyFileName = "vault.key"
+	// PassphraseEnv is the environment variable holding the passphrase that
+	// the key is derived from, instead of the key file
+	PassphraseEnv = "SYNAPSE_VAULT_PASSPHRASE"
+)
+
+const keySize = 32
+
+// Aliases are used as TOML keys and inside references
+var aliasPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// FileProvider holds secrets encrypted with AES-GCM in a local file. The key
+// is read from a key file, or derived with scrypt from a passphrase.
+type FileProvider struct {
+	dir  string
+	key  []byte
+	salt []byte
+	// newKey is set when the key file does not exist yet
+	newKey  bool
+	secrets map[string]string
+}
+
+// OpenFileProvider reads the secrets file in dir and the key that decrypts
+// it. The key is derived from the passphrase in SYNAPSE_VAULT_PASSPHRASE if
+// it is set, otherwise read from the key file. If there are no secrets yet,
+// a new key is made, which Save writes.
+func OpenFileProvider(dir string) (*FileProvider, error) {
+	p := &FileProvider{dir: dir, secrets: make(map[string]string)}
+	secretsPath := filepath.Join(dir, SecretsFileName)
+	if _, err := os.Stat(secretsPath); err == nil {
+		k := koanf.New(".")
+		if err := k.Load(file.Provider(secretsPath), toml.Parser()); err != nil {
+			return nil, fmt.Errorf("cannot read %s: %w", secretsPath, err)
+		}
+		p.secrets = k.StringMap("secrets")
+		if salt := k.String("vault.salt"); salt != "" {
+			if p.salt, err = base64.StdEncoding.DecodeString(salt); err != nil {
+				return nil, fmt.Errorf("invalid salt in %s: %w", secretsPath, err)
+			}
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	passphrase := os.Getenv(PassphraseEnv)
+	switch {
+	case passphrase != "":
+		if p.salt == nil {
+			if len(p.secrets) > 0 {
+				return nil, errors.New("secrets in " + secretsPath + " are encrypted with the key file, unset " + PassphraseEnv)
+			}
+			p.salt = make([]byte, 16)
+			if _, err := rand.Read(p.salt); err != nil {
+				return nil, err
+			}
+		}
+		key, err := scrypt.Key([]byte(passphrase), p.salt, 1<<15, 8, 1, keySize)
+		if err != nil {
+			return nil, err
+		}
+		p.key = key
+	case p.salt != nil:
+		return nil, errors.New("secrets in " + secretsPath + " are encrypted with a passphrase, set " + PassphraseEnv)
+	default:
+		if err := p.readKey(); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+func (p *FileProvider) readKey() error {
+	keyPath := filepath.Join(p.dir, KeyFileName)
+	data, err := os.ReadFile(keyPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		if len(p.secrets) > 0 {
+			return errors.New("key file " + keyPath + " not found")
+		}
+		p.key = make([]byte, keySize)
+		p.newKey = true
+		_, err := rand.Read(p.key)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	p.key, err = base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(p.key) != keySize {
+		return errors.New("invalid key in " + keyPath)
+	}
+	return nil
+}
+
+// Secret decrypts the secret stored under alias
+func (p *FileProvider) Secret(alias string) (string, error) {
+	encrypted, ok := p.secrets[alias]
+	if !ok {
+		return "", ErrSecretNotFound
+	}
+	data, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", fmt.Errorf("cannot decrypt secret %s: %w", alias, err)
+	}
+	aead, err := p.aead()
+	if err != nil {
+		return "", err
+	}
+	if len(data) < aead.NonceSize() {
+		return "", fmt.Errorf("cannot decrypt secret %s: ciphertext too short", alias)
+	}
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(alias))
+	if err != nil {
+		return "", fmt.Errorf("cannot decrypt secret %s, the key may not match: %w", alias, err)
+	}
+	return string(plaintext), nil
+}
+
+// Encrypt stores value under alias, replacing any secret with that alias.
+// The alias is authenticated with the value, so that encrypted values cannot
+// be swapped between aliases.
+func (p *FileProvider) Encrypt(alias string, value string) error {
+	if !aliasPattern.MatchString(alias) {
+		return fmt.Errorf("invalid alias %q: use letters, digits, '_' and '-'", alias)
+	}
+	aead, err := p.aead()
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(value), []byte(alias))
+	p.secrets[alias] = base64.StdEncoding.EncodeToString(sealed)
+	return nil
+}
+
+// Save writes the secrets file, and the key file if the key is new
+func (p *FileProvider) Save() error {
+	if err := os.MkdirAll(p.dir, 0o700); err != nil {
+		return err
+	}
+	if p.newKey {
+		keyPath := filepath.Join(p.dir, KeyFileName)
+		if err := os.WriteFile(keyPath, []byte(base64.StdEncoding.EncodeToString(p.key)+"\n"), 0o600); err != nil {
+			return err
+		}
+		p.newKey = false
+	}
+
+	var b strings.Builder
+	b.WriteString("# Secrets encrypted with synapse vault encrypt. Refer to them as {secret:alias}.\n")
+	if p.salt != nil {
+		b.WriteString("\n[vault]\nsalt = \"" + base64.StdEncoding.EncodeToString(p.salt) + "\"\n")
+	}
+	b.WriteString("\n[secrets]\n")
+	for _, alias := range slices.Sorted(maps.Keys(p.secrets)) {
+		b.WriteString(alias + " = \"" + p.secrets[alias] + "\"\n")
+	}
+	return os.WriteFile(filepath.Join(p.dir, SecretsFileName), []byte(b.String()), 0o600)
+}
+
+func (p *FileProvider) aead() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(p.key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,101 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package vault
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileProviderKeyFile(t *testing.T) {
+	t.Setenv(PassphraseEnv, "")
+	dir := filepath.Join(t.TempDir(), SecurityFolder)
+
+	p, err := OpenFileProvider(dir)
+	require.NoError(t, err)
+	require.NoError(t, p.Encrypt("ftp_password", "s3cr3t"))
+	require.NoError(t, p.Save())
+
+	info, err := os.Stat(filepath.Join(dir, KeyFileName))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	data, err := os.ReadFile(filepath.Join(dir, SecretsFileName))
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "s3cr3t")
+
+	reopened, err := OpenFileProvider(dir)
+	require.NoError(t, err)
+	secret, err := reopened.Secret("ftp_password")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", secret)
+	_, err = reopened.Secret("missing")
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+
+	// Secrets cannot be read without the key file
+	require.NoError(t, os.Remove(filepath.Join(dir, KeyFileName)))
+	_, err = OpenFileProvider(dir)
+	assert.ErrorContains(t, err, "key file")
+}
+
+func TestFileProviderPassphrase(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(PassphraseEnv, "correct horse")
+
+	p, err := OpenFileProvider(dir)
+	require.NoError(t, err)
+	require.NoError(t, p.Encrypt("db-password", "pa55"))
+	require.NoError(t, p.Save())
+	_, err = os.Stat(filepath.Join(dir, KeyFileName))
+	assert.True(t, os.IsNotExist(err), "no key file is written with a passphrase")
+
+	reopened, err := OpenFileProvider(dir)
+	require.NoError(t, err)
+	secret, err := reopened.Secret("db-password")
+	require.NoError(t, err)
+	assert.Equal(t, "pa55", secret)
+
+	t.Setenv(PassphraseEnv, "wrong")
+	wrong, err := OpenFileProvider(dir)
+	require.NoError(t, err)
+	_, err = wrong.Secret("db-password")
+	assert.ErrorContains(t, err, "the key may not match")
+
+	t.Setenv(PassphraseEnv, "")
+	_, err = OpenFileProvider(dir)
+	assert.ErrorContains(t, err, "encrypted with a passphrase")
+}
+
+func TestFileProviderAliases(t *testing.T) {
+	t.Setenv(PassphraseEnv, "")
+	p, err := OpenFileProvider(t.TempDir())
+	require.NoError(t, err)
+	assert.Error(t, p.Encrypt("ftp.password", "x"))
+	assert.Error(t, p.Encrypt("", "x"))
+
+	// A value moved to another alias does not decrypt
+	require.NoError(t, p.Encrypt("first", "one"))
+	p.secrets["second"] = p.secrets["first"]
+	_, err = p.Secret("second")
+	assert.Error(t, err)
+}
@@ -0,0 +1,185 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+// Package vault resolves the {secret:alias} and $secret{alias} references in
+// the configuration and artifacts to secrets held by providers, such as the
+// local file of encrypted secrets written by synapse vault encrypt.
+package vault
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ErrSecretNotFound is returned by a provider that does not hold a secret
+var ErrSecretNotFound = errors.New("secret not found")
+
+// Provider is a store of secrets, such as the local encrypted secrets file
+// or an external secret manager
+type Provider interface {
+	// Secret returns the secret stored under alias, or ErrSecretNotFound
+	Secret(alias string) (string, error)
+}
+
+// masked replaces secrets in output
+const masked = "********"
+
+// Vault looks secrets up in its providers, in the order they were added
+type Vault struct {
+	mu        sync.RWMutex
+	providers []Provider
+	// resolved holds the secrets handed out, to mask them in output
+	resolved map[string]bool
+}
+
+var (
+	vaultInstance *Vault
+	once          sync.Once
+)
+
+// GetVault returns the vault of the server
+func GetVault() *Vault {
+	once.Do(func() {
+		vaultInstance = New()
+	})
+	return vaultInstance
+}
+
+// New returns a vault without providers
+func New() *Vault {
+	return &Vault{resolved: make(map[string]bool)}
+}
+
+// AddProvider adds a provider, consulted after the ones added before it
+func (v *Vault) AddProvider(provider Provider) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.providers = append(v.providers, provider)
+}
+
+// AddFileProvider adds the local secrets file in the security folder of the
+// conf folder at confPath
+func (v *Vault) AddFileProvider(confPath string) error {
+	provider, err := OpenFileProvider(filepath.Join(confPath, SecurityFolder))
+	if err != nil {
+		return err
+	}
+	v.AddProvider(provider)
+	return nil
+}
+
+// Secret returns the secret stored under alias by the first provider that
+// holds it
+func (v *Vault) Secret(alias string) (string, error) {
+	v.mu.RLock()
+	providers := v.providers
+	v.mu.RUnlock()
+	for _, provider := range providers {
+		secret, err := provider.Secret(alias)
+		if errors.Is(err, ErrSecretNotFound) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		v.mu.Lock()
+		v.resolved[secret] = true
+		v.mu.Unlock()
+		return secret, nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrSecretNotFound, alias)
+}
+
+// ReferenceError is a secret reference that cannot be resolved
+type ReferenceError struct {
+	Reference string
+	// Offset is the position of the reference in the expanded string
+	Offset int
+	Err    error
+}
+
+func (e *ReferenceError) Error() string {
+	return "cannot resolve " + e.Reference + ": " + e.Err.Error()
+}
+
+func (e *ReferenceError) Unwrap() error {
+	return e.Err
+}
+
+// Expand replaces the {secret:alias} and $secret{alias} references in s with
+// the secrets, passed through escape if it is not nil
+func (v *Vault) Expand(s string, escape func(string) string) (string, error) {
+	var expanded strings.Builder
+	rest := s
+	for {
+		start, alias, end := nextReference(rest)
+		if start < 0 {
+			break
+		}
+		secret, err := v.Secret(alias)
+		if err != nil {
+			offset := expanded.Len() + start
+			return "", &ReferenceError{Reference: rest[start:end], Offset: offset, Err: err}
+		}
+		if escape != nil {
+			secret = escape(secret)
+		}
+		expanded.WriteString(rest[:start])
+		expanded.WriteString(secret)
+		rest = rest[end:]
+	}
+	expanded.WriteString(rest)
+	return expanded.String(), nil
+}
+
+// nextReference returns the start, the alias and the end of the first secret
+// reference in s, or a negative start if there is none
+func nextReference(s string) (int, string, int) {
+	start := -1
+	prefix := ""
+	for _, candidate := range []string{"{secret:", "$secret{"} {
+		if i := strings.Index(s, candidate); i >= 0 && (start < 0 || i < start) {
+			start, prefix = i, candidate
+		}
+	}
+	if start < 0 {
+		return -1, "", -1
+	}
+	length := strings.IndexByte(s[start+len(prefix):], '}')
+	if length < 0 {
+		return -1, "", -1
+	}
+	end := start + len(prefix) + length + 1
+	return start, s[start+len(prefix) : end-1], end
+}
+
+// Mask replaces the secrets handed out by the vault in s
+func (v *Vault) Mask(s string) string {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	for secret := range v.resolved {
+		if secret != "" {
+			s = strings.ReplaceAll(s, secret, masked)
+		}
+	}
+	return s
+}
@@ -0,0 +1,91 @@
+/*
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ *  or more contributor license agreements.  See the NOTICE file
+ *  distributed with this work for additional information
+ *  regarding copyright ownership.  The ASF licenses this file
+ *  to you under the Apache License, Version 2.0 (the
+ *  "License"); you may not use this file except in compliance
+ *  with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing,
+ *  software distributed under the License is distributed on an
+ *   * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ *  KIND, either express or implied.  See the License for the
+ *  specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package vault
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mapProvider is a provider backed by a map, as an external store would be
+type mapProvider map[string]string
+
+func (m mapProvider) Secret(alias string) (string, error) {
+	secret, ok := m[alias]
+	if !ok {
+		return "", ErrSecretNotFound
+	}
+	return secret, nil
+}
+
+type failingProvider struct{}
+
+func (failingProvider) Secret(alias string) (string, error) {
+	return "", errors.New("store unavailable")
+}
+
+func TestExpand(t *testing.T) {
+	v := New()
+	v.AddProvider(mapProvider{"ftp_password": "p@ss&word"})
+	v.AddProvider(mapProvider{"ftp_password": "shadowed", "api_key": "k3y"})
+
+	expanded, err := v.Expand("ftp://user:{secret:ftp_password}@host/in?key=$secret{api_key}", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "ftp://user:p@ss&word@host/in?key=k3y", expanded)
+
+	escaped, err := v.Expand("<value>{secret:ftp_password}</value>", func(s string) string {
+		return "[" + s + "]"
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "<value>[p@ss&word]</value>", escaped)
+
+	unchanged, err := v.Expand("{secret without a colon} $secret", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "{secret without a colon} $secret", unchanged)
+}
+
+func TestExpandErrors(t *testing.T) {
+	v := New()
+	v.AddProvider(mapProvider{"known": "value"})
+
+	_, err := v.Expand("a {secret:known} b $secret{unknown}", nil)
+	var refErr *ReferenceError
+	require.ErrorAs(t, err, &refErr)
+	assert.Equal(t, "$secret{unknown}", refErr.Reference)
+	assert.Equal(t, len("a value b "), refErr.Offset)
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+
+	v.AddProvider(failingProvider{})
+	_, err = v.Expand("{secret:other}", nil)
+	assert.EqualError(t, err, "cannot resolve {secret:other}: store unavailable")
+}
+
+func TestMask(t *testing.T) {
+	v := New()
+	v.AddProvider(mapProvider{"password": "hunter2"})
+	assert.Equal(t, "password=hunter2", v.Mask("password=hunter2"))
+
+	_, err := v.Secret("password")
+	require.NoError(t, err)
+	assert.Equal(t, "password=********", v.Mask("password=hunter2"))
+}